@@ -15,5 +15,5 @@ func main() {
 	}
 
 	go appl.Run()
-	shutdown.Graceful([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, appl)
+	shutdown.Graceful([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, appl.Closers()...)
 }
@@ -19,6 +19,11 @@ type HTTPServer struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+// GRPCServer grpc server parameters, the server uses certificate from SSLPath
+type GRPCServer struct {
+	Port string `yaml:"port" env-default:"50051"`
+}
+
 // Postgres postgres parameters
 type Postgres struct {
 	Host     string        `yaml:"host"`
@@ -35,20 +40,292 @@ type KafkaBroker struct {
 	Port string `yaml:"port"`
 }
 
+// KafkaTopics topic names of published events
+type KafkaTopics struct {
+	ProductViews   string `yaml:"product_views" env-default:"products_statistics"`
+	ProductChanges string `yaml:"product_changes" env-default:"products_changes"`
+}
+
+// KafkaProducer producer parameters.
+// Partitioner is hash, random or round_robin, RequiredAcks is all, local or none,
+// Compression is none, gzip, snappy, lz4 or zstd
+type KafkaProducer struct {
+	Partitioner  string        `yaml:"partitioner" env-default:"hash"`
+	RequiredAcks string        `yaml:"required_acks" env-default:"all"`
+	RetryMax     int           `yaml:"retry_max" env-default:"5"`
+	Compression  string        `yaml:"compression" env-default:"none"`
+	Timeout      time.Duration `yaml:"timeout" env-default:"10s"`
+	Idempotent   bool          `yaml:"idempotent"`
+}
+
+// KafkaSASL sasl authentication on brokers.
+// Mechanism is none, plain, scram-sha-256 or scram-sha-512
+type KafkaSASL struct {
+	Mechanism string `yaml:"mechanism" env-default:"none"`
+	User      string `yaml:"user"`
+	Password  string `yaml:"password" env:"KAFKA_SASL_PASSWORD"`
+}
+
+// KafkaTLS tls connection to brokers, client certificate is optional
+type KafkaTLS struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// KafkaProvisioning topics are created on startup if missing, existing topics must have the same settings.
+// Zero retention keeps broker default
+type KafkaProvisioning struct {
+	Enabled    bool          `yaml:"enabled"`
+	Partitions int32         `yaml:"partitions" env-default:"3"`
+	Retention  time.Duration `yaml:"retention" env-default:"168h"`
+}
+
 // KafkaCluster ...
 type KafkaCluster struct {
-	ReplicationFactor int           `yaml:"replication_factor"`
-	BrokerList        []KafkaBroker `yaml:"brokers"`
+	ReplicationFactor int               `yaml:"replication_factor"`
+	BrokerList        []KafkaBroker     `yaml:"brokers"`
+	Topics            KafkaTopics       `yaml:"topics"`
+	Provisioning      KafkaProvisioning `yaml:"provisioning"`
+	Producer          KafkaProducer     `yaml:"producer"`
+	SASL              KafkaSASL         `yaml:"sasl"`
+	TLS               KafkaTLS          `yaml:"tls"`
+}
+
+// PolicyRule access rule, grants actions to role.
+// Actions support wildcards, e.g. "product:update:*".
+// If OwnerOnly is set, rule applies only to the resource owner
+type PolicyRule struct {
+	Role      string   `yaml:"role"`
+	Actions   []string `yaml:"actions"`
+	OwnerOnly bool     `yaml:"owner_only"`
+}
+
+// OIDCProvider openid connect identity provider
+type OIDCProvider struct {
+	Name         string        `yaml:"name"`
+	IssuerURL    string        `yaml:"issuer_url"`
+	ClientID     string        `yaml:"client_id"`
+	ClientSecret string        `yaml:"client_secret"`
+	RedirectURL  string        `yaml:"redirect_url"`
+	Scopes       []string      `yaml:"scopes"`
+	Timeout      time.Duration `yaml:"timeout"`
+}
+
+// JwtKey asymmetric token signing key.
+// The newest key with ActiveFrom in the past signs new tokens, all keys verify tokens
+// until the next key has been active for longer than token TTL
+type JwtKey struct {
+	ID         string    `yaml:"id"`
+	Algorithm  string    `yaml:"algorithm"`
+	Path       string    `yaml:"path"`
+	ActiveFrom time.Time `yaml:"active_from"`
+}
+
+// Jwt token parameters, Secret is used for HS256 if there are no active keys
+type Jwt struct {
+	Secret string        `yaml:"secret" env:"JWT_SECRET"`
+	TTL    time.Duration `yaml:"ttl" env-default:"20m"`
+	Keys   []JwtKey      `yaml:"keys"`
+}
+
+// TOTP two-factor authentication parameters
+type TOTP struct {
+	Issuer string `yaml:"issuer" env-default:"product-keeper"`
+}
+
+// LoginThrottle brute-force protection parameters.
+// After free attempts login is blocked for BaseDelay, the delay doubles with each failure up to MaxDelay
+type LoginThrottle struct {
+	AccountFreeAttempts int           `yaml:"account_free_attempts" env-default:"5"`
+	IPFreeAttempts      int           `yaml:"ip_free_attempts" env-default:"20"`
+	BaseDelay           time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxDelay            time.Duration `yaml:"max_delay" env-default:"15m"`
+	ResetAfter          time.Duration `yaml:"reset_after" env-default:"24h"`
+}
+
+// PasswordPolicy password rules, BreachedListPath is a file with passwords from data breaches
+type PasswordPolicy struct {
+	MinLength        int    `yaml:"min_length" env-default:"8"`
+	MaxLength        int    `yaml:"max_length" env-default:"72"`
+	BreachedListPath string `yaml:"breached_list_path"`
+}
+
+const (
+	// PasswordHashArgon2id argon2id password hashing
+	PasswordHashArgon2id = "argon2id"
+	// PasswordHashBcrypt bcrypt password hashing
+	PasswordHashBcrypt = "bcrypt"
+)
+
+// PasswordHash algorithm and parameters for new password hashes.
+// Hashes with other algorithm or parameters are replaced on successful login, Argon2Memory is in KiB
+type PasswordHash struct {
+	Algorithm        string `yaml:"algorithm" env-default:"argon2id"`
+	BcryptCost       int    `yaml:"bcrypt_cost" env-default:"10"`
+	Argon2Time       uint32 `yaml:"argon2_time" env-default:"3"`
+	Argon2Memory     uint32 `yaml:"argon2_memory" env-default:"65536"`
+	Argon2Threads    uint8  `yaml:"argon2_threads" env-default:"2"`
+	Argon2SaltLength uint32 `yaml:"argon2_salt_length" env-default:"16"`
+	Argon2KeyLength  uint32 `yaml:"argon2_key_length" env-default:"32"`
+}
+
+const (
+	// NotifierLog writes notifications to the log
+	NotifierLog = "log"
+	// NotifierFile appends notifications to the file
+	NotifierFile = "file"
+	// NotifierSMTP sends notifications by email
+	NotifierSMTP = "smtp"
+)
+
+// Notifier parameters of sending notifications to users
+type Notifier struct {
+	Kind string `yaml:"kind" env-default:"log"`
+	Path string `yaml:"path"`
+	SMTP SMTP   `yaml:"smtp"`
+}
+
+// SMTP mail server, authentication is used only if Username is set
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port" env-default:"25"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD"`
+	From     string `yaml:"from"`
+}
+
+// EmailVerification parameters of verification links sent on registration
+type EmailVerification struct {
+	Secret string        `yaml:"secret" env:"EMAIL_VERIFICATION_SECRET"`
+	TTL    time.Duration `yaml:"ttl" env-default:"24h"`
+	URL    string        `yaml:"url" env-default:"https://localhost:8080/user/verify"`
+}
+
+// Outbox relay parameters, claimed messages are leased for Lease while they are published,
+// failed messages are retried after BaseDelay doubling up to MaxDelay.
+// Sent messages are deleted every CleanupInterval after Retention, zero Retention keeps them
+type Outbox struct {
+	Interval        time.Duration `yaml:"interval" env-default:"1s"`
+	BatchSize       int           `yaml:"batch_size" env-default:"100"`
+	Lease           time.Duration `yaml:"lease" env-default:"1m"`
+	BaseDelay       time.Duration `yaml:"base_delay" env-default:"1s"`
+	MaxDelay        time.Duration `yaml:"max_delay" env-default:"5m"`
+	Retention       time.Duration `yaml:"retention" env-default:"168h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+// Webhooks delivery worker parameters.
+// Failed delivery is retried after BaseDelay doubling up to MaxDelay, after MaxAttempts it is failed.
+// AllowPrivateNetworks permits webhooks to loopback and private addresses, e.g. for local development
+type Webhooks struct {
+	Interval             time.Duration `yaml:"interval" env-default:"1s"`
+	BatchSize            int           `yaml:"batch_size" env-default:"50"`
+	Timeout              time.Duration `yaml:"timeout" env-default:"10s"`
+	MaxAttempts          int           `yaml:"max_attempts" env-default:"10"`
+	BaseDelay            time.Duration `yaml:"base_delay" env-default:"10s"`
+	MaxDelay             time.Duration `yaml:"max_delay" env-default:"1h"`
+	AllowPrivateNetworks bool          `yaml:"allow_private_networks" env-default:"false"`
+}
+
+// ProductsStream parameters of product change streams.
+// Heartbeat is sent to idle streams, a stream is closed when a write takes longer than WriteTimeout.
+// TokenTTL lifetime of stream tokens for clients unable to set the authorization header
+type ProductsStream struct {
+	Heartbeat    time.Duration `yaml:"heartbeat" env-default:"15s"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env-default:"10s"`
+	TokenTTL     time.Duration `yaml:"token_ttl" env-default:"1m"`
+}
+
+// GraphQL limits of graphql queries. Every field costs 1, fields under a page of products
+// are counted page size times, introspection fields are not counted
+type GraphQL struct {
+	MaxComplexity int `yaml:"max_complexity" env-default:"1000"`
+	MaxDepth      int `yaml:"max_depth" env-default:"5"`
+}
+
+const (
+	// EventBusKafka events are published to kafka
+	EventBusKafka = "kafka"
+	// EventBusMemory events are delivered in the process, for tests and local development
+	EventBusMemory = "memory"
+	// EventBusNATS events are published to nats
+	EventBusNATS = "nats"
+)
+
+// NATS server parameters
+type NATS struct {
+	URL     string        `yaml:"url" env-default:"nats://localhost:4222"`
+	Timeout time.Duration `yaml:"timeout" env-default:"5s"`
+}
+
+// EventBus broker of published events
+type EventBus struct {
+	Kind string `yaml:"kind" env-default:"kafka"`
+	NATS NATS   `yaml:"nats"`
+}
+
+const (
+	// StatisticsOutbox product views are saved to the outbox and published by the relay
+	StatisticsOutbox = "outbox"
+	// StatisticsAsync product views are batched in memory and published by async producer,
+	// product changes are still saved to the outbox
+	StatisticsAsync = "async"
+
+	// OverflowDrop new product views are dropped when the buffer is full
+	OverflowDrop = "drop"
+	// OverflowBlock requests wait for free space in the buffer
+	OverflowBlock = "block"
+)
+
+// Statistics delivery of product views, buffer parameters are used only by async delivery.
+// Async delivery requires kafka event bus, it is never used for product changes
+type Statistics struct {
+	Delivery      string        `yaml:"delivery" env-default:"outbox"`
+	BufferSize    int           `yaml:"buffer_size" env-default:"10000"`
+	BatchSize     int           `yaml:"batch_size" env-default:"100"`
+	FlushInterval time.Duration `yaml:"flush_interval" env-default:"1s"`
+	Overflow      string        `yaml:"overflow" env-default:"drop"`
+}
+
+// StatisticsConsumer consumer of product views, after a failure it waits RetryDelay and consumes again.
+// Ids of processed events are deleted every CleanupInterval after Retention, it must not be shorter
+// than retention of the views topic, zero Retention keeps them
+type StatisticsConsumer struct {
+	Group            string        `yaml:"group" env-default:"product-stats"`
+	RetryDelay       time.Duration `yaml:"retry_delay" env-default:"5s"`
+	Retention        time.Duration `yaml:"retention" env-default:"168h"`
+	CleanupInterval  time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+	CleanupBatchSize int           `yaml:"cleanup_batch_size" env-default:"1000"`
 }
 
 // Config application config
 type Config struct {
-	Env          string   `yaml:"env"`
-	Postgres     Postgres `yaml:"postgres"`
-	PostgresTest Postgres `yaml:"postgres_test"`
-	SSLPath      `yaml:"ssl_path"`
-	HTTPServer   `yaml:"http_server"`
-	KafkaCluster `yaml:"kafka"`
+	Env                string   `yaml:"env"`
+	Postgres           Postgres `yaml:"postgres"`
+	PostgresTest       Postgres `yaml:"postgres_test"`
+	SSLPath            `yaml:"ssl_path"`
+	HTTPServer         `yaml:"http_server"`
+	GRPCServer         GRPCServer `yaml:"grpc_server"`
+	KafkaCluster       `yaml:"kafka"`
+	EventBus           EventBus           `yaml:"event_bus"`
+	Outbox             Outbox             `yaml:"outbox"`
+	Webhooks           Webhooks           `yaml:"webhooks"`
+	ProductsStream     ProductsStream     `yaml:"products_stream"`
+	GraphQL            GraphQL            `yaml:"graphql"`
+	Statistics         Statistics         `yaml:"statistics"`
+	StatisticsConsumer StatisticsConsumer `yaml:"statistics_consumer"`
+	Jwt                Jwt                `yaml:"jwt"`
+	TOTP               TOTP               `yaml:"totp"`
+	LoginThrottle      LoginThrottle      `yaml:"login_throttle"`
+	Password           PasswordPolicy     `yaml:"password"`
+	PasswordHash       PasswordHash       `yaml:"password_hash"`
+	Notifier           Notifier           `yaml:"notifier"`
+	Verification       EmailVerification  `yaml:"email_verification"`
+	Policies           []PolicyRule       `yaml:"policies"`
+	OIDCProviders      []OIDCProvider     `yaml:"oidc_providers"`
 }
 
 // MustLoad loading parameters from config file
@@ -0,0 +1,11 @@
+package authorizer
+
+import (
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/authorizer/policy"
+)
+
+// NewPolicyAuthorizer ...
+func NewPolicyAuthorizer(rules []config.PolicyRule) *policy.Authorizer {
+	return policy.NewAuthorizer(rules)
+}
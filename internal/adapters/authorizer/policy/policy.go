@@ -0,0 +1,50 @@
+package policy
+
+import (
+	"path"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// Authorizer checks permissions using rules loaded from config
+type Authorizer struct {
+	rules map[string][]config.PolicyRule
+}
+
+// DefaultRules rules used when config has no policies, users and admins have full access only to their own products
+var DefaultRules = []config.PolicyRule{
+	{Role: "user", Actions: []string{"product:*"}, OwnerOnly: true},
+	{Role: "admin", Actions: []string{"product:*"}, OwnerOnly: true},
+}
+
+// NewAuthorizer constructor for Authorizer, empty rules are replaced with DefaultRules
+func NewAuthorizer(rules []config.PolicyRule) *Authorizer {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	byRole := make(map[string][]config.PolicyRule)
+	for _, rule := range rules {
+		byRole[rule.Role] = append(byRole[rule.Role], rule)
+	}
+
+	return &Authorizer{rules: byRole}
+}
+
+// Authorize returns true if any rule of the subject role grants the action
+func (a *Authorizer) Authorize(subject shared.Subject, action string, owner string) bool {
+	for _, rule := range a.rules[subject.Role] {
+		if rule.OwnerOnly && subject.Name != owner {
+			continue
+		}
+
+		for _, pattern := range rule.Actions {
+			if matched, err := path.Match(pattern, action); err == nil && matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
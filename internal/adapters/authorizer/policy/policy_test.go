@@ -0,0 +1,99 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/authorizer/policy"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+type Suite struct {
+	suite.Suite
+	authorizer *policy.Authorizer
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	s.authorizer = policy.NewAuthorizer([]config.PolicyRule{
+		{Role: "user", Actions: []string{"product:*"}, OwnerOnly: true},
+		{Role: "warehouse", Actions: []string{"product:read", "product:update:quantity"}},
+		{Role: "admin", Actions: []string{"*"}},
+	})
+}
+
+func (s *Suite) TestAuthorize() {
+	testList := []struct {
+		name     string
+		subject  shared.Subject
+		action   string
+		owner    string
+		expected bool
+	}{
+		{
+			name:     "owner has access",
+			subject:  shared.NewSubject("test name", "user"),
+			action:   "product:update:price",
+			owner:    "test name",
+			expected: true,
+		},
+		{
+			name:     "not owner has no access",
+			subject:  shared.NewSubject("test name", "user"),
+			action:   "product:read",
+			owner:    "other name",
+			expected: false,
+		},
+		{
+			name:     "warehouse can change quantity",
+			subject:  shared.NewSubject("test name", "warehouse"),
+			action:   "product:update:quantity",
+			owner:    "other name",
+			expected: true,
+		},
+		{
+			name:     "warehouse cannot change price",
+			subject:  shared.NewSubject("test name", "warehouse"),
+			action:   "product:update:price",
+			owner:    "other name",
+			expected: false,
+		},
+		{
+			name:     "admin has full access",
+			subject:  shared.NewSubject("test name", "admin"),
+			action:   "product:delete",
+			owner:    "other name",
+			expected: true,
+		},
+		{
+			name:     "unknown role",
+			subject:  shared.NewSubject("test name", "unknown"),
+			action:   "product:read",
+			owner:    "test name",
+			expected: false,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			s.Equal(row.expected, s.authorizer.Authorize(row.subject, row.action, row.owner))
+		})
+	}
+}
+
+func (s *Suite) TestDefaultRules() {
+	// config without policies section
+	authorizer := policy.NewAuthorizer(nil)
+
+	s.True(authorizer.Authorize(shared.NewSubject("test name", "user"), "product:update:price", "test name"))
+	s.True(authorizer.Authorize(shared.NewSubject("test name", "user"), "product:delete", "test name"))
+	s.False(authorizer.Authorize(shared.NewSubject("test name", "user"), "product:read", "other name"))
+	s.True(authorizer.Authorize(shared.NewSubject("test name", "admin"), "product:read", "test name"))
+	s.False(authorizer.Authorize(shared.NewSubject("test name", "admin"), "product:read", "other name"))
+	s.False(authorizer.Authorize(shared.NewSubject("test name", "warehouse"), "product:read", "test name"))
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+type apiKeyRow struct {
+	ID         uint64         `db:"id"`
+	Prefix     string         `db:"prefix"`
+	Hash       string         `db:"hash"`
+	OwnerName  string         `db:"owner_name"`
+	Name       string         `db:"name"`
+	Scopes     pq.StringArray `db:"scopes"`
+	CreatedAt  time.Time      `db:"created_at"`
+	LastUsedAt *time.Time     `db:"last_used_at"`
+	RevokedAt  *time.Time     `db:"revoked_at"`
+}
+
+func (r apiKeyRow) toAPIKey() auth.APIKey {
+	return auth.APIKey{
+		ID:         r.ID,
+		Prefix:     r.Prefix,
+		Hash:       r.Hash,
+		OwnerName:  r.OwnerName,
+		Name:       r.Name,
+		Scopes:     r.Scopes,
+		CreatedAt:  r.CreatedAt,
+		LastUsedAt: r.LastUsedAt,
+		RevokedAt:  r.RevokedAt,
+	}
+}
+
+// CreateAPIKey ...
+func (r *AuthRepository) CreateAPIKey(tx *sqlx.Tx, key auth.APIKey) (uint64, error) {
+	sqlQuery := `
+		INSERT INTO auth$api_keys (prefix, hash, owner_name, name, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+
+	var id uint64
+	err := tx.QueryRow(sqlQuery, key.Prefix, key.Hash, key.OwnerName, key.Name, pq.Array(key.Scopes), key.CreatedAt).Scan(&id)
+
+	return id, err
+}
+
+// FindAPIKey ...
+func (r *AuthRepository) FindAPIKey(tx *sqlx.Tx, prefix string) (auth.APIKey, error) {
+	sqlQuery := `
+		SELECT *
+		FROM auth$api_keys
+		WHERE prefix = $1;
+	`
+
+	var row apiKeyRow
+	err := tx.Get(&row, sqlQuery, prefix)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.APIKey{}, auth.ErrAPIKeyNotFound
+	case err == nil:
+		return row.toAPIKey(), nil
+	default:
+		return auth.APIKey{}, err
+	}
+}
+
+// FindAPIKeyList ...
+func (r *AuthRepository) FindAPIKeyList(tx *sqlx.Tx, ownerName string) ([]auth.APIKey, error) {
+	sqlQuery := `
+		SELECT *
+		FROM auth$api_keys
+		WHERE owner_name = $1
+		ORDER BY created_at DESC;
+	`
+
+	var rows []apiKeyRow
+	if err := tx.Select(&rows, sqlQuery, ownerName); err != nil {
+		return nil, err
+	}
+
+	keys := make([]auth.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.toAPIKey())
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey ...
+func (r *AuthRepository) RevokeAPIKey(tx *sqlx.Tx, id uint64, ownerName string, revokedAt time.Time) error {
+	sqlQuery := `
+		UPDATE auth$api_keys
+		SET revoked_at = $1
+		WHERE id = $2 AND owner_name = $3 AND revoked_at IS NULL;
+	`
+
+	res, err := tx.Exec(sqlQuery, revokedAt, id, ownerName)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// UpdateAPIKeyLastUsed ...
+func (r *AuthRepository) UpdateAPIKeyLastUsed(tx *sqlx.Tx, id uint64, lastUsedAt time.Time) error {
+	sqlQuery := `
+		UPDATE auth$api_keys
+		SET last_used_at = $1
+		WHERE id = $2;
+	`
+
+	_, err := tx.Exec(sqlQuery, lastUsedAt, id)
+	return err
+}
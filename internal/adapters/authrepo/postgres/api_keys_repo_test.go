@@ -0,0 +1,213 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+func (s *Suite) TestCreateAPIKey() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockKey := auth.APIKey{
+		Prefix:    "test prefix",
+		Hash:      "test hash",
+		OwnerName: mockUser.Name,
+		Name:      "test key",
+		Scopes:    []string{auth.ScopeProductsRead, auth.ScopeProductsWrite},
+		CreatedAt: now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		mockKey.ID, err = s.repo.CreateAPIKey(tx, mockKey)
+		s.NoError(err)
+		s.NotEqual(0, mockKey.ID)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.FindAPIKey(tx, mockKey.Prefix)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockKey, data)
+		})
+	})
+}
+
+func (s *Suite) TestFindAPIKey() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockKey := auth.APIKey{
+		Prefix:    "test prefix",
+		Hash:      "test hash",
+		OwnerName: mockUser.Name,
+		Name:      "test key",
+		Scopes:    []string{auth.ScopeProductsRead},
+		CreatedAt: now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		mockKey.ID, err = s.repo.CreateAPIKey(tx, mockKey)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// key doesn't exist
+			_, err := s.repo.FindAPIKey(tx, "doesn't exist")
+			s.ErrorIs(err, auth.ErrAPIKeyNotFound)
+
+			data, err := s.repo.FindAPIKey(tx, mockKey.Prefix)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockKey, data)
+		})
+	})
+}
+
+func (s *Suite) TestFindAPIKeyList() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockKey1 := auth.APIKey{
+		Prefix:    "test prefix1",
+		Hash:      "test hash1",
+		OwnerName: mockUser.Name,
+		Name:      "test key1",
+		Scopes:    []string{auth.ScopeProductsRead},
+		CreatedAt: now,
+	}
+	mockKey2 := auth.APIKey{
+		Prefix:    "test prefix2",
+		Hash:      "test hash2",
+		OwnerName: mockUser.Name,
+		Name:      "test key2",
+		Scopes:    []string{auth.ScopeProductsWrite},
+		CreatedAt: now.Add(time.Hour),
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		mockKey1.ID, err = s.repo.CreateAPIKey(tx, mockKey1)
+		s.NoError(err)
+
+		mockKey2.ID, err = s.repo.CreateAPIKey(tx, mockKey2)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.FindAPIKeyList(tx, mockUser.Name)
+			s.NoError(err)
+			s.Len(data, 2)
+
+			data[0].CreatedAt = data[0].CreatedAt.In(time.UTC)
+			data[1].CreatedAt = data[1].CreatedAt.In(time.UTC)
+			s.Equal([]auth.APIKey{mockKey2, mockKey1}, data)
+
+			data, err = s.repo.FindAPIKeyList(tx, "doesn't exist")
+			s.NoError(err)
+			s.Empty(data)
+		})
+	})
+}
+
+func (s *Suite) TestRevokeAPIKey() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockKey := auth.APIKey{
+		Prefix:    "test prefix",
+		Hash:      "test hash",
+		OwnerName: mockUser.Name,
+		Name:      "test key",
+		Scopes:    []string{auth.ScopeProductsRead},
+		CreatedAt: now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		mockKey.ID, err = s.repo.CreateAPIKey(tx, mockKey)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// other owner
+			err := s.repo.RevokeAPIKey(tx, mockKey.ID, "other name", now)
+			s.ErrorIs(err, auth.ErrAPIKeyNotFound)
+
+			err = s.repo.RevokeAPIKey(tx, mockKey.ID, mockUser.Name, now)
+			s.NoError(err)
+
+			// already revoked
+			err = s.repo.RevokeAPIKey(tx, mockKey.ID, mockUser.Name, now)
+			s.ErrorIs(err, auth.ErrAPIKeyNotFound)
+
+			data, err := s.repo.FindAPIKey(tx, mockKey.Prefix)
+			s.NoError(err)
+			s.NotNil(data.RevokedAt)
+			s.Equal(now, data.RevokedAt.In(time.UTC))
+		})
+	})
+}
+
+func (s *Suite) TestUpdateAPIKeyLastUsed() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockKey := auth.APIKey{
+		Prefix:    "test prefix",
+		Hash:      "test hash",
+		OwnerName: mockUser.Name,
+		Name:      "test key",
+		Scopes:    []string{auth.ScopeProductsRead},
+		CreatedAt: now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		mockKey.ID, err = s.repo.CreateAPIKey(tx, mockKey)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			lastUsed := now.Add(time.Hour)
+
+			err := s.repo.UpdateAPIKeyLastUsed(tx, mockKey.ID, lastUsed)
+			s.NoError(err)
+
+			data, err := s.repo.FindAPIKey(tx, mockKey.Prefix)
+			s.NoError(err)
+			s.NotNil(data.LastUsedAt)
+			s.Equal(lastUsed, data.LastUsedAt.In(time.UTC))
+		})
+	})
+}
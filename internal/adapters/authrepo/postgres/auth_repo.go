@@ -22,11 +22,11 @@ func NewAuth() *AuthRepository {
 // CreateUser ...
 func (r *AuthRepository) CreateUser(tx *sqlx.Tx, user auth.User) error {
 	sqlQuery := `
-		INSERT INTO auth$users (name, password)
-		VALUES ($1, $2);
+		INSERT INTO auth$users (name, password, email, pending)
+		VALUES ($1, $2, $3, $4);
 	`
 
-	if _, err := tx.Exec(sqlQuery, user.Name, user.Password); err != nil {
+	if _, err := tx.Exec(sqlQuery, user.Name, user.Password, user.Email, user.Pending); err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			switch pqErr.Code {
@@ -44,10 +44,10 @@ func (r *AuthRepository) CreateUser(tx *sqlx.Tx, user auth.User) error {
 	return nil
 }
 
-// FindPassword ...
-func (r *AuthRepository) FindPassword(tx *sqlx.Tx, name string) (string, error) {
+// FindUser ...
+func (r *AuthRepository) FindUser(tx *sqlx.Tx, name string) (auth.User, error) {
 	sqlQuery := `
-		SELECT password
+		SELECT id, name, password, role, disabled, email, pending
 		FROM auth$users
 		WHERE name = $1;
 	`
@@ -57,10 +57,126 @@ func (r *AuthRepository) FindPassword(tx *sqlx.Tx, name string) (string, error)
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
-		return "", auth.ErrUserNotFound
+		return auth.User{}, auth.ErrUserNotFound
 	case err == nil:
-		return user.Password, nil
+		return user, nil
 	default:
-		return "", err
+		return auth.User{}, err
 	}
 }
+
+// FindUserList ...
+func (r *AuthRepository) FindUserList(tx *sqlx.Tx) ([]auth.User, error) {
+	sqlQuery := `
+		SELECT id, name, password, role, disabled, email, pending
+		FROM auth$users
+		ORDER BY name;
+	`
+
+	var users []auth.User
+	if err := tx.Select(&users, sqlQuery); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// DisableUser ...
+func (r *AuthRepository) DisableUser(tx *sqlx.Tx, name string) error {
+	sqlQuery := `
+		UPDATE auth$users
+		SET disabled = TRUE
+		WHERE name = $1;
+	`
+
+	res, err := tx.Exec(sqlQuery, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePassword ...
+func (r *AuthRepository) UpdatePassword(tx *sqlx.Tx, name string, password string) error {
+	sqlQuery := `
+		UPDATE auth$users
+		SET password = $2
+		WHERE name = $1;
+	`
+
+	res, err := tx.Exec(sqlQuery, name, password)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// VerifyEmail ...
+func (r *AuthRepository) VerifyEmail(tx *sqlx.Tx, name string) error {
+	sqlQuery := `
+		UPDATE auth$users
+		SET pending = FALSE
+		WHERE name = $1;
+	`
+
+	res, err := tx.Exec(sqlQuery, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// ResetEmailVerification makes account pending, used when the email is changed
+func (r *AuthRepository) ResetEmailVerification(tx *sqlx.Tx, name string) error {
+	sqlQuery := `
+		UPDATE auth$users
+		SET pending = TRUE
+		WHERE name = $1;
+	`
+
+	res, err := tx.Exec(sqlQuery, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -66,8 +66,10 @@ func (s *Suite) TestCreateUser() {
 	})
 }
 
-func (s *Suite) TestFindPassword() {
+func (s *Suite) TestFindUser() {
 	mockUser := auth.NewUser("test name", "test password")
+	mockUser.Email = "test@example.com"
+	mockUser.Pending = true
 
 	s.Run("preparing data", func() {
 		tx, err := s.db.Beginx()
@@ -80,12 +82,149 @@ func (s *Suite) TestFindPassword() {
 
 		s.Run("checking data", func() {
 			// user doesn't exist
-			_, err = s.repo.FindPassword(tx, "doesn't exist")
+			_, err = s.repo.FindUser(tx, "doesn't exist")
 			s.ErrorIs(err, auth.ErrUserNotFound)
 
-			data, err := s.repo.FindPassword(tx, mockUser.Name)
+			data, err := s.repo.FindUser(tx, mockUser.Name)
 			s.NoError(err)
-			s.Equal(mockUser.Password, data)
+			s.NotZero(data.ID)
+			s.Equal(auth.User{
+				ID:       data.ID,
+				Name:     mockUser.Name,
+				Password: mockUser.Password,
+				Role:     auth.RoleUser,
+				Disabled: false,
+				Email:    mockUser.Email,
+				Pending:  true,
+			}, data)
+		})
+	})
+}
+
+func (s *Suite) TestFindUserList() {
+	mockUser1 := auth.NewUser("test name1", "test password1")
+	mockUser2 := auth.NewUser("test name2", "test password2")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// create users
+		err = s.repo.CreateUser(tx, mockUser2)
+		s.NoError(err)
+
+		err = s.repo.CreateUser(tx, mockUser1)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.FindUserList(tx)
+			s.NoError(err)
+			s.Len(data, 2)
+			s.Equal([]auth.User{
+				{ID: data[0].ID, Name: mockUser1.Name, Password: mockUser1.Password, Role: auth.RoleUser},
+				{ID: data[1].ID, Name: mockUser2.Name, Password: mockUser2.Password, Role: auth.RoleUser},
+			}, data)
+		})
+	})
+}
+
+func (s *Suite) TestDisableUser() {
+	mockUser := auth.NewUser("test name", "test password")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// create user
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// user doesn't exist
+			err = s.repo.DisableUser(tx, "doesn't exist")
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			err = s.repo.DisableUser(tx, mockUser.Name)
+			s.NoError(err)
+
+			sqlQuery := `
+				SELECT disabled
+				FROM auth$users
+				WHERE name = $1;
+			`
+
+			var disabled bool
+			err = tx.QueryRow(sqlQuery, mockUser.Name).Scan(&disabled)
+			s.NoError(err)
+			s.True(disabled)
+		})
+	})
+}
+
+func (s *Suite) TestUpdatePassword() {
+	mockUser := auth.NewUser("test name", "test password")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// create user
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// user doesn't exist
+			err = s.repo.UpdatePassword(tx, "doesn't exist", "new password")
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			err = s.repo.UpdatePassword(tx, mockUser.Name, "new password")
+			s.NoError(err)
+
+			data, err := s.repo.FindUser(tx, mockUser.Name)
+			s.NoError(err)
+			s.Equal("new password", data.Password)
+		})
+	})
+}
+
+func (s *Suite) TestVerifyEmail() {
+	mockUser := auth.NewUser("test name", "test password")
+	mockUser.Email = "test@example.com"
+	mockUser.Pending = true
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// create user
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// user doesn't exist
+			err = s.repo.VerifyEmail(tx, "doesn't exist")
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			err = s.repo.VerifyEmail(tx, mockUser.Name)
+			s.NoError(err)
+
+			data, err := s.repo.FindUser(tx, mockUser.Name)
+			s.NoError(err)
+			s.False(data.Pending)
+
+			err = s.repo.ResetEmailVerification(tx, "doesn't exist")
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			err = s.repo.ResetEmailVerification(tx, mockUser.Name)
+			s.NoError(err)
+
+			data, err = s.repo.FindUser(tx, mockUser.Name)
+			s.NoError(err)
+			s.True(data.Pending)
 		})
 	})
 }
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// FindLoginAttempts ...
+func (r *AuthRepository) FindLoginAttempts(tx *sqlx.Tx, key string) (auth.LoginAttempts, error) {
+	sqlQuery := `
+		SELECT key, failures, last_failure_at
+		FROM auth$login_attempts
+		WHERE key = $1;
+	`
+
+	var attempts auth.LoginAttempts
+	err := tx.Get(&attempts, sqlQuery, key)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.LoginAttempts{}, shared.ErrNoData
+	case err == nil:
+		return attempts, nil
+	default:
+		return auth.LoginAttempts{}, err
+	}
+}
+
+// IncrementLoginAttempts adds failed attempt atomically and returns the counter,
+// failures before resetBefore are forgotten
+func (r *AuthRepository) IncrementLoginAttempts(tx *sqlx.Tx, key string, failedAt time.Time, resetBefore time.Time) (auth.LoginAttempts, error) {
+	sqlQuery := `
+		INSERT INTO auth$login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN auth$login_attempts.last_failure_at < $3 THEN 1
+				ELSE auth$login_attempts.failures + 1
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at;
+	`
+
+	var attempts auth.LoginAttempts
+	err := tx.Get(&attempts, sqlQuery, key, failedAt, resetBefore)
+	return attempts, err
+}
+
+// DeleteLoginAttempts ...
+func (r *AuthRepository) DeleteLoginAttempts(tx *sqlx.Tx, key string) error {
+	sqlQuery := `
+		DELETE
+		FROM auth$login_attempts
+		WHERE key = $1;
+	`
+
+	_, err := tx.Exec(sqlQuery, key)
+	return err
+}
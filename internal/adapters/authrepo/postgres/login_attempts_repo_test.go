@@ -0,0 +1,58 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+func (s *Suite) TestLoginAttempts() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockAttempts := auth.LoginAttempts{
+		Key:           auth.AccountAttemptsKey("test name"),
+		Failures:      1,
+		LastFailureAt: now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		s.Run("checking data", func() {
+			_, err := s.repo.FindLoginAttempts(tx, mockAttempts.Key)
+			s.ErrorIs(err, shared.ErrNoData)
+
+			data, err := s.repo.IncrementLoginAttempts(tx, mockAttempts.Key, now, now.Add(-time.Hour))
+			s.NoError(err)
+
+			data.LastFailureAt = data.LastFailureAt.In(time.UTC)
+			s.Equal(mockAttempts, data)
+
+			mockAttempts.Failures = 2
+			mockAttempts.LastFailureAt = now.Add(time.Minute)
+
+			_, err = s.repo.IncrementLoginAttempts(tx, mockAttempts.Key, now.Add(time.Minute), now.Add(-time.Hour))
+			s.NoError(err)
+
+			data, err = s.repo.FindLoginAttempts(tx, mockAttempts.Key)
+			s.NoError(err)
+
+			data.LastFailureAt = data.LastFailureAt.In(time.UTC)
+			s.Equal(mockAttempts, data)
+
+			// old failures are forgotten
+			data, err = s.repo.IncrementLoginAttempts(tx, mockAttempts.Key, now.Add(2*time.Hour), now.Add(time.Hour))
+			s.NoError(err)
+			s.Equal(1, data.Failures)
+
+			err = s.repo.DeleteLoginAttempts(tx, mockAttempts.Key)
+			s.NoError(err)
+
+			_, err = s.repo.FindLoginAttempts(tx, mockAttempts.Key)
+			s.ErrorIs(err, shared.ErrNoData)
+		})
+	})
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+// CreateOIDCState ...
+func (r *AuthRepository) CreateOIDCState(tx *sqlx.Tx, state auth.OIDCState) error {
+	sqlQuery := `
+		INSERT INTO auth$oidc_states (state, provider, code_verifier, nonce, created_at)
+		VALUES ($1, $2, $3, $4, $5);
+	`
+
+	_, err := tx.Exec(sqlQuery, state.State, state.Provider, state.CodeVerifier, state.Nonce, state.CreatedAt)
+	return err
+}
+
+// PopOIDCState finds and deletes oidc state, so it can be used only once
+func (r *AuthRepository) PopOIDCState(tx *sqlx.Tx, state string) (auth.OIDCState, error) {
+	sqlQuery := `
+		DELETE
+		FROM auth$oidc_states
+		WHERE state = $1
+		RETURNING *;
+	`
+
+	var data auth.OIDCState
+	err := tx.Get(&data, sqlQuery, state)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.OIDCState{}, auth.ErrInvalidOIDCState
+	case err == nil:
+		return data, nil
+	default:
+		return auth.OIDCState{}, err
+	}
+}
+
+// FindIdentity ...
+func (r *AuthRepository) FindIdentity(tx *sqlx.Tx, provider string, subject string) (string, error) {
+	sqlQuery := `
+		SELECT user_name
+		FROM auth$identities
+		WHERE provider = $1 AND subject = $2;
+	`
+
+	var username string
+	err := tx.Get(&username, sqlQuery, provider, subject)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", auth.ErrIdentityNotFound
+	case err == nil:
+		return username, nil
+	default:
+		return "", err
+	}
+}
+
+// CreateIdentity ...
+func (r *AuthRepository) CreateIdentity(tx *sqlx.Tx, provider string, subject string, username string) error {
+	sqlQuery := `
+		INSERT INTO auth$identities (provider, subject, user_name)
+		VALUES ($1, $2, $3);
+	`
+
+	_, err := tx.Exec(sqlQuery, provider, subject, username)
+	return err
+}
@@ -0,0 +1,102 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+func (s *Suite) TestCreateOIDCState() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockState := auth.OIDCState{
+		State:        "test state",
+		Provider:     "test provider",
+		CodeVerifier: "test verifier",
+		Nonce:        "test nonce",
+		CreatedAt:    now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateOIDCState(tx, mockState)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			sqlQuery := `
+				SELECT *
+				FROM auth$oidc_states
+				WHERE state = $1;
+			`
+
+			var data auth.OIDCState
+			err := tx.Get(&data, sqlQuery, mockState.State)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockState, data)
+		})
+	})
+}
+
+func (s *Suite) TestPopOIDCState() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockState := auth.OIDCState{
+		State:        "test state",
+		Provider:     "test provider",
+		CodeVerifier: "test verifier",
+		Nonce:        "test nonce",
+		CreatedAt:    now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateOIDCState(tx, mockState)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.PopOIDCState(tx, mockState.State)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockState, data)
+
+			// state can be used only once
+			_, err = s.repo.PopOIDCState(tx, mockState.State)
+			s.ErrorIs(err, auth.ErrInvalidOIDCState)
+		})
+	})
+}
+
+func (s *Suite) TestIdentity() {
+	mockUser := auth.NewUser("test name", "")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// identity doesn't exist
+			_, err := s.repo.FindIdentity(tx, "test provider", "test subject")
+			s.ErrorIs(err, auth.ErrIdentityNotFound)
+
+			err = s.repo.CreateIdentity(tx, "test provider", "test subject", mockUser.Name)
+			s.NoError(err)
+
+			data, err := s.repo.FindIdentity(tx, "test provider", "test subject")
+			s.NoError(err)
+			s.Equal(mockUser.Name, data)
+		})
+	})
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+// SavePasswordReset saves password reset, previous reset of the user is replaced
+func (r *AuthRepository) SavePasswordReset(tx *sqlx.Tx, reset auth.PasswordReset) error {
+	sqlQuery := `
+		INSERT INTO auth$password_resets (selector, hash, user_name, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_name) DO UPDATE
+		SET selector = EXCLUDED.selector, hash = EXCLUDED.hash, created_at = EXCLUDED.created_at;
+	`
+
+	_, err := tx.Exec(sqlQuery, reset.Selector, reset.Hash, reset.UserName, reset.CreatedAt)
+	return err
+}
+
+// PopPasswordReset finds and deletes password reset, so it can be used only once
+func (r *AuthRepository) PopPasswordReset(tx *sqlx.Tx, selector string) (auth.PasswordReset, error) {
+	sqlQuery := `
+		DELETE
+		FROM auth$password_resets
+		WHERE selector = $1
+		RETURNING selector, hash, user_name, created_at;
+	`
+
+	var reset auth.PasswordReset
+	err := tx.Get(&reset, sqlQuery, selector)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.PasswordReset{}, auth.ErrInvalidResetToken
+	case err == nil:
+		return reset, nil
+	default:
+		return auth.PasswordReset{}, err
+	}
+}
@@ -0,0 +1,57 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+func (s *Suite) TestPopPasswordReset() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUser := auth.NewUser("test name", "test hashed pass")
+
+	oldReset := auth.PasswordReset{
+		Selector:  "old selector",
+		Hash:      "old hash",
+		UserName:  mockUser.Name,
+		CreatedAt: now,
+	}
+
+	mockReset := auth.PasswordReset{
+		Selector:  "test selector",
+		Hash:      "test hash",
+		UserName:  mockUser.Name,
+		CreatedAt: now.Add(time.Minute),
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		err = s.repo.SavePasswordReset(tx, oldReset)
+		s.NoError(err)
+
+		// replaces the old reset
+		err = s.repo.SavePasswordReset(tx, mockReset)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			_, err := s.repo.PopPasswordReset(tx, oldReset.Selector)
+			s.ErrorIs(err, auth.ErrInvalidResetToken)
+
+			data, err := s.repo.PopPasswordReset(tx, mockReset.Selector)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockReset, data)
+
+			// reset can be used only once
+			_, err = s.repo.PopPasswordReset(tx, mockReset.Selector)
+			s.ErrorIs(err, auth.ErrInvalidResetToken)
+		})
+	})
+}
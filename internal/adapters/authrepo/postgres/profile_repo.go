@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+// FindProfile ...
+func (r *AuthRepository) FindProfile(tx *sqlx.Tx, name string) (auth.Profile, error) {
+	sqlQuery := `
+		SELECT id, name, display_name, email, timezone
+		FROM auth$users
+		WHERE name = $1;
+	`
+
+	var profile auth.Profile
+	err := tx.Get(&profile, sqlQuery, name)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.Profile{}, auth.ErrUserNotFound
+	case err == nil:
+		return profile, nil
+	default:
+		return auth.Profile{}, err
+	}
+}
+
+// UpdateProfile ...
+func (r *AuthRepository) UpdateProfile(tx *sqlx.Tx, profile auth.Profile) (auth.Profile, error) {
+	sqlQuery := `
+		UPDATE auth$users
+		SET display_name = $2, email = $3, timezone = $4
+		WHERE name = $1
+		RETURNING id, name, display_name, email, timezone;
+	`
+
+	var updated auth.Profile
+	err := tx.Get(&updated, sqlQuery, profile.Name, profile.DisplayName, profile.Email, profile.Timezone)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.Profile{}, auth.ErrUserNotFound
+	case err == nil:
+		return updated, nil
+	default:
+		return auth.Profile{}, err
+	}
+}
+
+// DeleteUser deletes user with api keys, identities, sessions and two-factor authentication data
+func (r *AuthRepository) DeleteUser(tx *sqlx.Tx, id uint64) error {
+	sqlQueries := []string{
+		`DELETE FROM auth$api_keys WHERE owner_name = (SELECT name FROM auth$users WHERE id = $1);`,
+		`DELETE FROM auth$identities WHERE user_name = (SELECT name FROM auth$users WHERE id = $1);`,
+		`DELETE FROM auth$recovery_codes WHERE user_name = (SELECT name FROM auth$users WHERE id = $1);`,
+		`DELETE FROM auth$mfa_challenges WHERE user_name = (SELECT name FROM auth$users WHERE id = $1);`,
+		`DELETE FROM auth$totp WHERE user_name = (SELECT name FROM auth$users WHERE id = $1);`,
+		`DELETE FROM auth$password_resets WHERE user_name = (SELECT name FROM auth$users WHERE id = $1);`,
+		`DELETE FROM auth$sessions WHERE user_name = (SELECT name FROM auth$users WHERE id = $1);`,
+	}
+
+	for _, sqlQuery := range sqlQueries {
+		if _, err := tx.Exec(sqlQuery, id); err != nil {
+			return err
+		}
+	}
+
+	sqlQuery := `
+		DELETE
+		FROM auth$users
+		WHERE id = $1;
+	`
+
+	res, err := tx.Exec(sqlQuery, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrUserNotFound
+	}
+
+	return nil
+}
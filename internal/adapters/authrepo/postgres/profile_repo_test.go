@@ -0,0 +1,89 @@
+package postgres_test
+
+import (
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+func (s *Suite) TestUpdateProfile() {
+	mockUser := auth.NewUser("test name", "test hashed pass")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// user doesn't exist
+			_, err := s.repo.FindProfile(tx, "doesn't exist")
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			_, err = s.repo.UpdateProfile(tx, auth.Profile{Name: "doesn't exist"})
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			// default profile
+			data, err := s.repo.FindProfile(tx, mockUser.Name)
+			s.NoError(err)
+			s.NotZero(data.ID)
+			s.Equal(auth.Profile{ID: data.ID, Name: mockUser.Name, Timezone: auth.DefaultTimezone}, data)
+
+			mockProfile := auth.Profile{
+				ID:          data.ID,
+				Name:        mockUser.Name,
+				DisplayName: "Test Name",
+				Email:       "test@example.com",
+				Timezone:    "Europe/Moscow",
+			}
+
+			updated, err := s.repo.UpdateProfile(tx, mockProfile)
+			s.NoError(err)
+			s.Equal(mockProfile, updated)
+
+			data, err = s.repo.FindProfile(tx, mockUser.Name)
+			s.NoError(err)
+			s.Equal(mockProfile, data)
+		})
+	})
+}
+
+func (s *Suite) TestDeleteUser() {
+	mockUser := auth.NewUser("test name", "test hashed pass")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		user, err := s.repo.FindUser(tx, mockUser.Name)
+		s.NoError(err)
+
+		err = s.repo.SaveTOTP(tx, auth.TOTP{UserName: mockUser.Name, Secret: "test secret"})
+		s.NoError(err)
+
+		err = s.repo.CreateIdentity(tx, "test provider", "test subject", mockUser.Name)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			err := s.repo.DeleteUser(tx, user.ID)
+			s.NoError(err)
+
+			_, err = s.repo.FindUser(tx, mockUser.Name)
+			s.ErrorIs(err, auth.ErrUserNotFound)
+
+			_, err = s.repo.FindTOTP(tx, mockUser.Name)
+			s.ErrorIs(err, auth.ErrTOTPNotFound)
+
+			_, err = s.repo.FindIdentity(tx, "test provider", "test subject")
+			s.ErrorIs(err, auth.ErrIdentityNotFound)
+
+			// user doesn't exist
+			err = s.repo.DeleteUser(tx, user.ID)
+			s.ErrorIs(err, auth.ErrUserNotFound)
+		})
+	})
+}
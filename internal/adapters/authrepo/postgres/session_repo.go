@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+// CreateSession ...
+func (r *AuthRepository) CreateSession(tx *sqlx.Tx, session auth.Session) error {
+	sqlQuery := `
+		INSERT INTO auth$sessions (id, user_name, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+
+	_, err := tx.Exec(sqlQuery,
+		session.ID,
+		session.UserName,
+		session.UserAgent,
+		session.IP,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	)
+	return err
+}
+
+// FindSession ...
+func (r *AuthRepository) FindSession(tx *sqlx.Tx, id string) (auth.Session, error) {
+	sqlQuery := `
+		SELECT id, user_name, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM auth$sessions
+		WHERE id = $1;
+	`
+
+	var session auth.Session
+	err := tx.Get(&session, sqlQuery, id)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.Session{}, auth.ErrSessionNotFound
+	case err == nil:
+		return session, nil
+	default:
+		return auth.Session{}, err
+	}
+}
+
+// FindSessionList sessions which are not expired at now, recently used first
+func (r *AuthRepository) FindSessionList(tx *sqlx.Tx, username string, now time.Time) ([]auth.Session, error) {
+	sqlQuery := `
+		SELECT id, user_name, user_agent, ip, created_at, last_seen_at, expires_at
+		FROM auth$sessions
+		WHERE user_name = $1 AND expires_at > $2
+		ORDER BY last_seen_at DESC;
+	`
+
+	var sessions []auth.Session
+	if err := tx.Select(&sessions, sqlQuery, username, now); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// TouchSession ...
+func (r *AuthRepository) TouchSession(tx *sqlx.Tx, id string, lastSeenAt time.Time, ip string) error {
+	sqlQuery := `
+		UPDATE auth$sessions
+		SET last_seen_at = $2, ip = $3
+		WHERE id = $1;
+	`
+
+	_, err := tx.Exec(sqlQuery, id, lastSeenAt, ip)
+	return err
+}
+
+// DeleteSession ...
+func (r *AuthRepository) DeleteSession(tx *sqlx.Tx, id string, username string) error {
+	sqlQuery := `
+		DELETE
+		FROM auth$sessions
+		WHERE id = $1 AND user_name = $2;
+	`
+
+	res, err := tx.Exec(sqlQuery, id, username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions ...
+func (r *AuthRepository) DeleteExpiredSessions(tx *sqlx.Tx, username string, now time.Time) error {
+	sqlQuery := `
+		DELETE
+		FROM auth$sessions
+		WHERE user_name = $1 AND expires_at <= $2;
+	`
+
+	_, err := tx.Exec(sqlQuery, username, now)
+	return err
+}
+
+// DeleteSessions deletes all sessions of the user except exceptID, empty exceptID deletes all of them
+func (r *AuthRepository) DeleteSessions(tx *sqlx.Tx, username string, exceptID string) error {
+	sqlQuery := `
+		DELETE
+		FROM auth$sessions
+		WHERE user_name = $1 AND id <> $2;
+	`
+
+	_, err := tx.Exec(sqlQuery, username, exceptID)
+	return err
+}
@@ -0,0 +1,107 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+func (s *Suite) TestSessions() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUser := auth.NewUser("test name", "test hashed pass")
+
+	mockSession := auth.Session{
+		ID:         "test session",
+		UserName:   mockUser.Name,
+		UserAgent:  "test agent",
+		IP:         "127.0.0.1",
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Hour),
+	}
+
+	expiredSession := auth.Session{
+		ID:         "expired session",
+		UserName:   mockUser.Name,
+		UserAgent:  "test agent",
+		IP:         "127.0.0.1",
+		CreatedAt:  now.Add(-2 * time.Hour),
+		LastSeenAt: now.Add(-2 * time.Hour),
+		ExpiresAt:  now.Add(-time.Hour),
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		err = s.repo.CreateSession(tx, mockSession)
+		s.NoError(err)
+
+		err = s.repo.CreateSession(tx, expiredSession)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			_, err := s.repo.FindSession(tx, "doesn't exist")
+			s.ErrorIs(err, auth.ErrSessionNotFound)
+
+			// expired sessions are not listed
+			data, err := s.repo.FindSessionList(tx, mockUser.Name, now)
+			s.NoError(err)
+			s.Len(data, 1)
+			s.Equal(mockSession.ID, data[0].ID)
+
+			err = s.repo.TouchSession(tx, mockSession.ID, now.Add(time.Minute), "127.0.0.2")
+			s.NoError(err)
+
+			session, err := s.repo.FindSession(tx, mockSession.ID)
+			s.NoError(err)
+			s.Equal(now.Add(time.Minute), session.LastSeenAt.In(time.UTC))
+			s.Equal("127.0.0.2", session.IP)
+
+			err = s.repo.DeleteExpiredSessions(tx, mockUser.Name, now)
+			s.NoError(err)
+
+			_, err = s.repo.FindSession(tx, expiredSession.ID)
+			s.ErrorIs(err, auth.ErrSessionNotFound)
+
+			// session of another user
+			err = s.repo.DeleteSession(tx, mockSession.ID, "another name")
+			s.ErrorIs(err, auth.ErrSessionNotFound)
+
+			err = s.repo.DeleteSession(tx, mockSession.ID, mockUser.Name)
+			s.NoError(err)
+
+			_, err = s.repo.FindSession(tx, mockSession.ID)
+			s.ErrorIs(err, auth.ErrSessionNotFound)
+
+			otherSession := mockSession
+			otherSession.ID = "other session"
+
+			err = s.repo.CreateSession(tx, mockSession)
+			s.NoError(err)
+
+			err = s.repo.CreateSession(tx, otherSession)
+			s.NoError(err)
+
+			// the current session is kept
+			err = s.repo.DeleteSessions(tx, mockUser.Name, mockSession.ID)
+			s.NoError(err)
+
+			_, err = s.repo.FindSession(tx, otherSession.ID)
+			s.ErrorIs(err, auth.ErrSessionNotFound)
+
+			_, err = s.repo.FindSession(tx, mockSession.ID)
+			s.NoError(err)
+
+			err = s.repo.DeleteSessions(tx, mockUser.Name, "")
+			s.NoError(err)
+
+			_, err = s.repo.FindSession(tx, mockSession.ID)
+			s.ErrorIs(err, auth.ErrSessionNotFound)
+		})
+	})
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+// SaveTOTP creates or replaces user totp
+func (r *AuthRepository) SaveTOTP(tx *sqlx.Tx, totp auth.TOTP) error {
+	sqlQuery := `
+		INSERT INTO auth$totp (user_name, secret, enabled, last_step)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_name) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled = EXCLUDED.enabled, last_step = EXCLUDED.last_step;
+	`
+
+	_, err := tx.Exec(sqlQuery, totp.UserName, totp.Secret, totp.Enabled, totp.LastStep)
+	return err
+}
+
+// FindTOTP ...
+func (r *AuthRepository) FindTOTP(tx *sqlx.Tx, username string) (auth.TOTP, error) {
+	sqlQuery := `
+		SELECT user_name, secret, enabled, last_step
+		FROM auth$totp
+		WHERE user_name = $1;
+	`
+
+	var totp auth.TOTP
+	err := tx.Get(&totp, sqlQuery, username)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.TOTP{}, auth.ErrTOTPNotFound
+	case err == nil:
+		return totp, nil
+	default:
+		return auth.TOTP{}, err
+	}
+}
+
+// DeleteTOTP deletes user totp together with recovery codes
+func (r *AuthRepository) DeleteTOTP(tx *sqlx.Tx, username string) error {
+	sqlQuery := `
+		DELETE
+		FROM auth$recovery_codes
+		WHERE user_name = $1;
+	`
+
+	if _, err := tx.Exec(sqlQuery, username); err != nil {
+		return err
+	}
+
+	sqlQuery = `
+		DELETE
+		FROM auth$totp
+		WHERE user_name = $1;
+	`
+
+	res, err := tx.Exec(sqlQuery, username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrTOTPNotFound
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes deletes old recovery codes and saves the new ones
+func (r *AuthRepository) ReplaceRecoveryCodes(tx *sqlx.Tx, username string, hashes []string) error {
+	sqlQuery := `
+		DELETE
+		FROM auth$recovery_codes
+		WHERE user_name = $1;
+	`
+
+	if _, err := tx.Exec(sqlQuery, username); err != nil {
+		return err
+	}
+
+	sqlQuery = `
+		INSERT INTO auth$recovery_codes (user_name, hash)
+		VALUES ($1, $2);
+	`
+
+	for _, hash := range hashes {
+		if _, err := tx.Exec(sqlQuery, username, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindRecoveryCodes finds unused recovery codes
+func (r *AuthRepository) FindRecoveryCodes(tx *sqlx.Tx, username string) ([]auth.RecoveryCode, error) {
+	sqlQuery := `
+		SELECT id, user_name, hash, used_at
+		FROM auth$recovery_codes
+		WHERE user_name = $1 AND used_at IS NULL
+		ORDER BY id;
+	`
+
+	var codes []auth.RecoveryCode
+	if err := tx.Select(&codes, sqlQuery, username); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode marks recovery code as used
+func (r *AuthRepository) UseRecoveryCode(tx *sqlx.Tx, id uint64, usedAt time.Time) error {
+	sqlQuery := `
+		UPDATE auth$recovery_codes
+		SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL;
+	`
+
+	res, err := tx.Exec(sqlQuery, id, usedAt)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return auth.ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+// CreateMFAChallenge ...
+func (r *AuthRepository) CreateMFAChallenge(tx *sqlx.Tx, challenge auth.MFAChallenge) error {
+	sqlQuery := `
+		INSERT INTO auth$mfa_challenges (challenge, user_name, created_at)
+		VALUES ($1, $2, $3);
+	`
+
+	_, err := tx.Exec(sqlQuery, challenge.Challenge, challenge.UserName, challenge.CreatedAt)
+	return err
+}
+
+// PopMFAChallenge finds and deletes mfa challenge, so it can be used only once
+func (r *AuthRepository) PopMFAChallenge(tx *sqlx.Tx, challenge string) (auth.MFAChallenge, error) {
+	sqlQuery := `
+		DELETE
+		FROM auth$mfa_challenges
+		WHERE challenge = $1
+		RETURNING *;
+	`
+
+	var data auth.MFAChallenge
+	err := tx.Get(&data, sqlQuery, challenge)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return auth.MFAChallenge{}, auth.ErrInvalidMFAChallenge
+	case err == nil:
+		return data, nil
+	default:
+		return auth.MFAChallenge{}, err
+	}
+}
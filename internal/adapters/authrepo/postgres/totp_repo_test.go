@@ -0,0 +1,124 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+func (s *Suite) TestTOTP() {
+	mockUser := auth.NewUser("test name", "test hashed pass")
+	mockTOTP := auth.TOTP{UserName: mockUser.Name, Secret: "test secret"}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			_, err := s.repo.FindTOTP(tx, mockUser.Name)
+			s.ErrorIs(err, auth.ErrTOTPNotFound)
+
+			err = s.repo.SaveTOTP(tx, mockTOTP)
+			s.NoError(err)
+
+			data, err := s.repo.FindTOTP(tx, mockUser.Name)
+			s.NoError(err)
+			s.Equal(mockTOTP, data)
+
+			mockTOTP.Enabled = true
+			mockTOTP.LastStep = 100
+
+			err = s.repo.SaveTOTP(tx, mockTOTP)
+			s.NoError(err)
+
+			data, err = s.repo.FindTOTP(tx, mockUser.Name)
+			s.NoError(err)
+			s.Equal(mockTOTP, data)
+
+			err = s.repo.DeleteTOTP(tx, mockUser.Name)
+			s.NoError(err)
+
+			err = s.repo.DeleteTOTP(tx, mockUser.Name)
+			s.ErrorIs(err, auth.ErrTOTPNotFound)
+		})
+	})
+}
+
+func (s *Suite) TestRecoveryCodes() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUser := auth.NewUser("test name", "test hashed pass")
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		err = s.repo.ReplaceRecoveryCodes(tx, mockUser.Name, []string{"old hash"})
+		s.NoError(err)
+
+		err = s.repo.ReplaceRecoveryCodes(tx, mockUser.Name, []string{"test hash1", "test hash2"})
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			codes, err := s.repo.FindRecoveryCodes(tx, mockUser.Name)
+			s.NoError(err)
+			s.Len(codes, 2)
+			s.Equal("test hash1", codes[0].Hash)
+			s.Equal("test hash2", codes[1].Hash)
+
+			err = s.repo.UseRecoveryCode(tx, codes[0].ID, now)
+			s.NoError(err)
+
+			// code can be used only once
+			err = s.repo.UseRecoveryCode(tx, codes[0].ID, now)
+			s.ErrorIs(err, auth.ErrInvalidTOTPCode)
+
+			codes, err = s.repo.FindRecoveryCodes(tx, mockUser.Name)
+			s.NoError(err)
+			s.Len(codes, 1)
+			s.Equal("test hash2", codes[0].Hash)
+		})
+	})
+}
+
+func (s *Suite) TestPopMFAChallenge() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUser := auth.NewUser("test name", "test hashed pass")
+
+	mockChallenge := auth.MFAChallenge{
+		Challenge: "test challenge",
+		UserName:  mockUser.Name,
+		CreatedAt: now,
+	}
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.CreateUser(tx, mockUser)
+		s.NoError(err)
+
+		err = s.repo.CreateMFAChallenge(tx, mockChallenge)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.PopMFAChallenge(tx, mockChallenge.Challenge)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockChallenge, data)
+
+			// challenge can be used only once
+			_, err = s.repo.PopMFAChallenge(tx, mockChallenge.Challenge)
+			s.ErrorIs(err, auth.ErrInvalidMFAChallenge)
+		})
+	})
+}
@@ -0,0 +1,10 @@
+package breachedpasswords
+
+import (
+	"github.com/fallra1n/product-keeper/internal/adapters/breachedpasswords/file"
+)
+
+// NewFileBreachedPasswords ...
+func NewFileBreachedPasswords(path string) (*file.List, error) {
+	return file.NewList(path)
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// List breached passwords loaded from local file.
+// Each line is either a password or an uppercase SHA-1 hash of it in the "HASH:COUNT"
+// format of Have I Been Pwned downloads. Empty lines and lines starting with # are skipped
+type List struct {
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+// NewList loads the list, empty path means empty list
+func NewList(path string) (*List, error) {
+	l := &List{hashes: make(map[[sha1.Size]byte]struct{})}
+	if path == "" {
+		return l, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached passwords: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if hash, ok := parseHash(line); ok {
+			l.hashes[hash] = struct{}{}
+			continue
+		}
+
+		l.hashes[sha1.Sum([]byte(line))] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached passwords: %w", err)
+	}
+
+	return l, nil
+}
+
+// Contains ...
+func (l *List) Contains(password string) bool {
+	_, ok := l.hashes[sha1.Sum([]byte(password))]
+	return ok
+}
+
+func parseHash(line string) ([sha1.Size]byte, bool) {
+	var hash [sha1.Size]byte
+
+	digest, _, _ := strings.Cut(line, ":")
+	if len(digest) != hex.EncodedLen(sha1.Size) || strings.ToUpper(digest) != digest {
+		return hash, false
+	}
+
+	if _, err := hex.Decode(hash[:], []byte(digest)); err != nil {
+		return hash, false
+	}
+
+	return hash, true
+}
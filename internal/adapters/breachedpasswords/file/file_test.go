@@ -0,0 +1,82 @@
+package file_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/internal/adapters/breachedpasswords/file"
+)
+
+type Suite struct {
+	suite.Suite
+	list *file.List
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	path := filepath.Join(s.T().TempDir(), "breached.txt")
+
+	data := "# common passwords\n" +
+		"qwerty123\n" +
+		"\n" +
+		// sha1 of "password1"
+		"E38AD214943DAAD1D64C102FAEC29DE4AFE9DA3D:2413945\n"
+
+	s.Require().NoError(os.WriteFile(path, []byte(data), 0o600))
+
+	list, err := file.NewList(path)
+	s.Require().NoError(err)
+	s.list = list
+}
+
+func (s *Suite) TestContains() {
+	testList := []struct {
+		name     string
+		password string
+		expected bool
+	}{
+		{
+			name:     "plain password",
+			password: "qwerty123",
+			expected: true,
+		},
+		{
+			name:     "hashed password",
+			password: "password1",
+			expected: true,
+		},
+		{
+			name:     "comment is skipped",
+			password: "# common passwords",
+			expected: false,
+		},
+		{
+			name:     "unknown password",
+			password: "correct horse battery staple",
+			expected: false,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			s.Equal(row.expected, s.list.Contains(row.password))
+		})
+	}
+}
+
+func (s *Suite) TestEmptyPath() {
+	list, err := file.NewList("")
+	s.NoError(err)
+	s.False(list.Contains("qwerty123"))
+}
+
+func (s *Suite) TestMissingFile() {
+	_, err := file.NewList(filepath.Join(s.T().TempDir(), "missing.txt"))
+	s.Error(err)
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus/kafka"
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus/memory"
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus/nats"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/pkg/access"
+	kafkaproducer "github.com/fallra1n/product-keeper/pkg/kafka"
+)
+
+// EventBus event bus closed on shutdown
+type EventBus interface {
+	shared.EventBus
+	io.Closer
+}
+
+// NewEventBus creates event bus of configured kind
+func NewEventBus(log *slog.Logger, cfg *config.Config) (EventBus, error) {
+	switch cfg.EventBus.Kind {
+	case config.EventBusKafka:
+		producer, err := kafkaproducer.NewSyncProducer(access.KafkaConnect(cfg), cfg.KafkaCluster)
+		if err != nil {
+			return nil, err
+		}
+		return kafka.NewEventBus(producer), nil
+	case config.EventBusMemory:
+		return memory.NewEventBus(log), nil
+	case config.EventBusNATS:
+		bus, err := nats.NewEventBus(cfg.EventBus.NATS)
+		if err != nil {
+			return nil, err
+		}
+		return bus, nil
+	default:
+		return nil, fmt.Errorf("unknown event bus kind %q", cfg.EventBus.Kind)
+	}
+}
@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+
+	"github.com/fallra1n/product-keeper/pkg/kafka"
+)
+
+// EventBus publishes messages to kafka, messages with the same key go to the same partition
+type EventBus struct {
+	mq sarama.SyncProducer
+}
+
+// NewEventBus constructor for EventBus
+func NewEventBus(mq sarama.SyncProducer) *EventBus {
+	return &EventBus{mq: mq}
+}
+
+// Publish ...
+func (b *EventBus) Publish(topic, key string, headers map[string]string, payload []byte) error {
+	msg := sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.StringEncoder(key),
+		Headers: kafka.RecordHeaders(headers),
+		Value:   sarama.ByteEncoder(payload),
+	}
+
+	if _, _, err := b.mq.SendMessage(&msg); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Close ...
+func (b *EventBus) Close() error {
+	return b.mq.Close()
+}
@@ -0,0 +1,51 @@
+package kafka_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus/kafka"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestPublish() {
+	cfg := mocks.NewTestConfig()
+	cfg.Producer.Return.Successes = true
+
+	mq := mocks.NewSyncProducer(s.T(), cfg)
+	mq.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, _ := msg.Key.Encode()
+		value, _ := msg.Value.Encode()
+
+		switch {
+		case msg.Topic != "test topic":
+			return errors.New("unexpected topic " + msg.Topic)
+		case string(key) != "test key":
+			return errors.New("unexpected key " + string(key))
+		case string(value) != "test payload":
+			return errors.New("unexpected payload " + string(value))
+		case len(msg.Headers) != 2 || string(msg.Headers[0].Key) != "a" || string(msg.Headers[1].Value) != "test value b":
+			return errors.New("unexpected headers")
+		}
+		return nil
+	})
+	mq.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+
+	bus := kafka.NewEventBus(mq)
+	headers := map[string]string{"b": "test value b", "a": "test value a"}
+
+	s.NoError(bus.Publish("test topic", "test key", headers, []byte("test payload")))
+	s.ErrorIs(bus.Publish("test topic", "test key", nil, []byte("test payload")), sarama.ErrOutOfBrokers)
+	s.NoError(bus.Close())
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Message published message
+type Message struct {
+	Topic   string
+	Key     string
+	Headers map[string]string
+	Payload []byte
+}
+
+// EventBus delivers messages to subscribers in the same process, used for tests and local development.
+// Messages without subscribers are only logged
+type EventBus struct {
+	log *slog.Logger
+
+	mu          sync.RWMutex
+	subscribers map[string][]func(Message)
+}
+
+// NewEventBus constructor for EventBus
+func NewEventBus(log *slog.Logger) *EventBus {
+	return &EventBus{
+		log:         log,
+		subscribers: make(map[string][]func(Message)),
+	}
+}
+
+// Publish calls subscribers of the topic synchronously
+func (b *EventBus) Publish(topic, key string, headers map[string]string, payload []byte) error {
+	b.log.Debug("event has been published", "topic", topic, "key", key, "size", len(payload))
+
+	b.mu.RLock()
+	subscribers := b.subscribers[topic]
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(Message{
+			Topic:   topic,
+			Key:     key,
+			Headers: headers,
+			Payload: payload,
+		})
+	}
+
+	return nil
+}
+
+// Subscribe adds handler of the topic messages
+func (b *EventBus) Subscribe(topic string, handler func(Message)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[topic] = append(b.subscribers[topic], handler)
+}
+
+// Close ...
+func (b *EventBus) Close() error {
+	return nil
+}
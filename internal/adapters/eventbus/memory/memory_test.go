@@ -0,0 +1,36 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus/memory"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestPublish() {
+	bus := memory.NewEventBus(logging.SetupLogger("local"))
+
+	var received []memory.Message
+	bus.Subscribe("test topic", func(msg memory.Message) {
+		received = append(received, msg)
+	})
+
+	headers := map[string]string{"test header": "test value"}
+	s.NoError(bus.Publish("test topic", "test key", headers, []byte("test payload")))
+	s.NoError(bus.Publish("other topic", "test key", nil, []byte("other payload")))
+
+	s.Equal([]memory.Message{
+		{Topic: "test topic", Key: "test key", Headers: headers, Payload: []byte("test payload")},
+	}, received)
+	s.NoError(bus.Close())
+}
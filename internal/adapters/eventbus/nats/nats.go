@@ -0,0 +1,54 @@
+package nats
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/fallra1n/product-keeper/config"
+)
+
+// KeyHeader header with message key, nats subjects have no partitions
+const KeyHeader = "message-key"
+
+// EventBus publishes messages to nats, topic is used as subject
+type EventBus struct {
+	conn    *nats.Conn
+	timeout time.Duration
+}
+
+// NewEventBus connects to nats server
+func NewEventBus(cfg config.NATS) (*EventBus, error) {
+	conn, err := nats.Connect(cfg.URL, nats.Name("product-keeper"), nats.Timeout(cfg.Timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventBus{conn: conn, timeout: cfg.Timeout}, nil
+}
+
+// Publish sends the message and waits until the server has received it
+func (b *EventBus) Publish(topic, key string, headers map[string]string, payload []byte) error {
+	msg := nats.NewMsg(topic)
+	msg.Data = payload
+
+	for name, value := range headers {
+		msg.Header.Set(name, value)
+	}
+
+	if key != "" {
+		msg.Header.Set(KeyHeader, key)
+	}
+
+	if err := b.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	return b.conn.FlushTimeout(b.timeout)
+}
+
+// Close ...
+func (b *EventBus) Close() error {
+	b.conn.Close()
+	return nil
+}
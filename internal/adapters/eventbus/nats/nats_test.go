@@ -0,0 +1,137 @@
+package nats_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus/nats"
+)
+
+// fakeServer minimal nats server accepting published messages
+type fakeServer struct {
+	listener net.Listener
+	messages chan fakeMessage
+}
+
+type fakeMessage struct {
+	Subject string
+	Headers string
+	Payload string
+}
+
+func newFakeServer() (*fakeServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &fakeServer{
+		listener: listener,
+		messages: make(chan fakeMessage, 1),
+	}
+	go srv.serve()
+
+	return srv, nil
+}
+
+func (f *fakeServer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"test\",\"version\":\"2.10.0\",\"headers\":true,\"max_payload\":1048576,\"proto\":1}\r\n")
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "PING":
+			fmt.Fprintf(conn, "PONG\r\n")
+		case "HPUB":
+			headersLen, _ := strconv.Atoi(fields[2])
+			totalLen, _ := strconv.Atoi(fields[3])
+
+			data := make([]byte, totalLen+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+
+			f.messages <- fakeMessage{
+				Subject: fields[1],
+				Headers: string(data[:headersLen]),
+				Payload: string(data[headersLen:totalLen]),
+			}
+		}
+	}
+}
+
+type Suite struct {
+	suite.Suite
+	srv *fakeServer
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	srv, err := newFakeServer()
+	s.Require().NoError(err)
+	s.srv = srv
+}
+
+func (s *Suite) TearDownTest() {
+	s.srv.listener.Close()
+}
+
+func (s *Suite) TestPublish() {
+	bus, err := nats.NewEventBus(config.NATS{
+		URL:     "nats://" + s.srv.listener.Addr().String(),
+		Timeout: time.Second,
+	})
+	s.Require().NoError(err)
+	defer bus.Close()
+
+	err = bus.Publish("test.topic", "test key", map[string]string{"test-header": "test value"}, []byte("test payload"))
+	s.NoError(err)
+
+	msg := <-s.srv.messages
+	s.Equal("test.topic", msg.Subject)
+	s.Equal("test payload", msg.Payload)
+	s.Contains(msg.Headers, "test-header: test value")
+	s.Contains(msg.Headers, nats.KeyHeader+": test key")
+}
+
+func (s *Suite) TestConnectionRefused() {
+	addr := s.srv.listener.Addr().String()
+	s.srv.listener.Close()
+
+	_, err := nats.NewEventBus(config.NATS{URL: "nats://" + addr, Timeout: time.Second})
+	s.Error(err)
+}
@@ -0,0 +1,11 @@
+package identityproviders
+
+import (
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/identityproviders/oidc"
+)
+
+// NewOIDCProviders ...
+func NewOIDCProviders(providers []config.OIDCProvider) *oidc.Providers {
+	return oidc.NewProviders(providers)
+}
@@ -0,0 +1,150 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Providers openid connect providers from config.
+// Provider discovery is done on first use, so unavailable provider does not block the start
+type Providers struct {
+	configs map[string]config.OIDCProvider
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+type client struct {
+	oauth2   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+	timeout  time.Duration
+}
+
+type claims struct {
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email"`
+}
+
+// NewProviders constructor for Providers
+func NewProviders(providers []config.OIDCProvider) *Providers {
+	configs := make(map[string]config.OIDCProvider, len(providers))
+	for _, provider := range providers {
+		configs[provider.Name] = provider
+	}
+
+	return &Providers{
+		configs: configs,
+		clients: make(map[string]*client),
+	}
+}
+
+// AuthCodeURL get provider authorization url with pkce challenge
+func (p *Providers) AuthCodeURL(provider string, state string, nonce string, codeVerifier string) (string, error) {
+	c, err := p.client(provider)
+	if err != nil {
+		return "", err
+	}
+
+	return c.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+// Exchange exchanges authorization code and verifies id token
+func (p *Providers) Exchange(provider string, code string, codeVerifier string, nonce string) (auth.ExternalIdentity, error) {
+	c, err := p.client(provider)
+	if err != nil {
+		return auth.ExternalIdentity{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	token, err := c.oauth2.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return auth.ExternalIdentity{}, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return auth.ExternalIdentity{}, errors.New("token response does not contain id_token")
+	}
+
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return auth.ExternalIdentity{}, fmt.Errorf("failed to verify id token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return auth.ExternalIdentity{}, errors.New("id token nonce does not match")
+	}
+
+	var data claims
+	if err := idToken.Claims(&data); err != nil {
+		return auth.ExternalIdentity{}, fmt.Errorf("failed to parse id token claims: %w", err)
+	}
+
+	username := data.PreferredUsername
+	if username == "" {
+		username = data.Email
+	}
+	if username == "" {
+		username = idToken.Subject
+	}
+
+	return auth.ExternalIdentity{
+		Subject:  idToken.Subject,
+		Username: username,
+	}, nil
+}
+
+func (p *Providers) client(provider string) (*client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.clients[provider]; ok {
+		return c, nil
+	}
+
+	cfg, ok := p.configs[provider]
+	if !ok {
+		return nil, auth.ErrUnknownProvider
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	discovered, err := gooidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover provider %s: %w", provider, err)
+	}
+
+	c := &client{
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       append([]string{gooidc.ScopeOpenID}, cfg.Scopes...),
+		},
+		verifier: discovered.Verifier(&gooidc.Config{ClientID: cfg.ClientID}),
+		timeout:  timeout,
+	}
+
+	p.clients[provider] = c
+	return c, nil
+}
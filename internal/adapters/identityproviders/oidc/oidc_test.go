@@ -0,0 +1,198 @@
+package oidc_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/identityproviders/oidc"
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+)
+
+const (
+	mockClientID = "test client"
+	mockCode     = "test code"
+	mockKeyID    = "test key"
+)
+
+// mockServer minimal openid connect provider
+type mockServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	challenge string
+	nonce     string
+}
+
+func newMockServer() (*mockServer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mockServer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/keys", m.keys)
+	mux.HandleFunc("/token", m.token)
+
+	m.Server = httptest.NewServer(mux)
+	return m, nil
+}
+
+func (m *mockServer) discovery(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                m.URL,
+		"authorization_endpoint":                m.URL + "/auth",
+		"token_endpoint":                        m.URL + "/token",
+		"jwks_uri":                              m.URL + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *mockServer) keys(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": mockKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("code") != mockCode || base64.RawURLEncoding.EncodeToString(hash[:]) != m.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                m.URL,
+		"aud":                mockClientID,
+		"sub":                "test subject",
+		"nonce":              m.nonce,
+		"preferred_username": "gopher",
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = mockKeyID
+
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "test access token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+type Suite struct {
+	suite.Suite
+	server    *mockServer
+	providers *oidc.Providers
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	var err error
+	s.server, err = newMockServer()
+	s.Require().NoError(err)
+
+	s.providers = oidc.NewProviders([]config.OIDCProvider{{
+		Name:        "test",
+		IssuerURL:   s.server.URL,
+		ClientID:    mockClientID,
+		RedirectURL: "https://localhost:8080/user/oidc/callback",
+		Scopes:      []string{"profile"},
+	}})
+}
+
+func (s *Suite) TearDownTest() {
+	s.server.Close()
+}
+
+// authorize imitates the user passing authorization on provider side
+func (s *Suite) authorize(verifier string, nonce string) {
+	authURL, err := s.providers.AuthCodeURL("test", "test state", nonce, verifier)
+	s.Require().NoError(err)
+
+	parsed, err := url.Parse(authURL)
+	s.Require().NoError(err)
+
+	query := parsed.Query()
+	s.Equal(s.server.URL+"/auth", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	s.Equal(mockClientID, query.Get("client_id"))
+	s.Equal("test state", query.Get("state"))
+	s.Equal("S256", query.Get("code_challenge_method"))
+	s.Equal("openid profile", query.Get("scope"))
+
+	s.server.challenge = query.Get("code_challenge")
+	s.server.nonce = query.Get("nonce")
+}
+
+func (s *Suite) TestExchange() {
+	verifier := "test verifier which is long enough for pkce check"
+
+	s.authorize(verifier, "test nonce")
+
+	data, err := s.providers.Exchange("test", mockCode, verifier, "test nonce")
+	s.NoError(err)
+	s.Equal(auth.ExternalIdentity{Subject: "test subject", Username: "gopher"}, data)
+}
+
+func (s *Suite) TestExchangeWrongVerifier() {
+	s.authorize("test verifier which is long enough for pkce check", "test nonce")
+
+	_, err := s.providers.Exchange("test", mockCode, "other verifier which is long enough for pkce check", "test nonce")
+	s.Error(err)
+}
+
+func (s *Suite) TestExchangeWrongNonce() {
+	verifier := "test verifier which is long enough for pkce check"
+
+	s.authorize(verifier, "test nonce")
+
+	_, err := s.providers.Exchange("test", mockCode, verifier, "other nonce")
+	s.Error(err)
+}
+
+func (s *Suite) TestUnknownProvider() {
+	_, err := s.providers.AuthCodeURL("unknown", "test state", "test nonce", "test verifier")
+	s.ErrorIs(err, auth.ErrUnknownProvider)
+
+	_, err = s.providers.Exchange("unknown", mockCode, "test verifier", "test nonce")
+	s.ErrorIs(err, auth.ErrUnknownProvider)
+}
@@ -0,0 +1,51 @@
+package logfile
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// Notifier writes notifications to the log or appends them to a file, for local testing
+type Notifier struct {
+	log  *slog.Logger
+	path string
+
+	mu sync.Mutex
+}
+
+// NewLogNotifier constructor for Notifier writing to the log
+func NewLogNotifier(log *slog.Logger) *Notifier {
+	return &Notifier{log: log}
+}
+
+// NewFileNotifier constructor for Notifier writing to the file
+func NewFileNotifier(log *slog.Logger, path string) *Notifier {
+	return &Notifier{log: log, path: path}
+}
+
+// Send ...
+func (n *Notifier) Send(message shared.Message) error {
+	if n.path == "" {
+		n.log.Info("notification", "to", message.To, "subject", message.Subject, "body", message.Body)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open notifications file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "To: %s\nSubject: %s\n\n%s\n\n", message.To, message.Subject, message.Body); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package logfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/internal/adapters/notifier/logfile"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestFileNotifier() {
+	path := filepath.Join(s.T().TempDir(), "notifications.txt")
+	notifier := logfile.NewFileNotifier(logging.SetupLogger("local"), path)
+
+	err := notifier.Send(shared.Message{To: "test name1", Subject: "test subject1", Body: "test body1"})
+	s.NoError(err)
+
+	err = notifier.Send(shared.Message{To: "test name2", Subject: "test subject2", Body: "test body2"})
+	s.NoError(err)
+
+	data, err := os.ReadFile(path)
+	s.NoError(err)
+	s.Equal(
+		"To: test name1\nSubject: test subject1\n\ntest body1\n\n"+
+			"To: test name2\nSubject: test subject2\n\ntest body2\n\n",
+		string(data),
+	)
+}
+
+func (s *Suite) TestLogNotifier() {
+	notifier := logfile.NewLogNotifier(logging.SetupLogger("local"))
+
+	err := notifier.Send(shared.Message{To: "test name", Subject: "test subject", Body: "test body"})
+	s.NoError(err)
+}
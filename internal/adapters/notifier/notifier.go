@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/notifier/logfile"
+	"github.com/fallra1n/product-keeper/internal/adapters/notifier/smtp"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// NewNotifier creates notifier of configured kind
+func NewNotifier(log *slog.Logger, cfg config.Notifier) (shared.Notifier, error) {
+	switch cfg.Kind {
+	case config.NotifierLog:
+		return logfile.NewLogNotifier(log), nil
+	case config.NotifierFile:
+		return logfile.NewFileNotifier(log, cfg.Path), nil
+	case config.NotifierSMTP:
+		notifier, err := smtp.NewNotifier(cfg.SMTP)
+		if err != nil {
+			return nil, err
+		}
+		return notifier, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier kind %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,82 @@
+package smtp
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+var (
+	// ErrEmptyHost smtp host is not set
+	ErrEmptyHost = errors.New("empty smtp host")
+
+	// ErrInvalidHeader header contains line breaks
+	ErrInvalidHeader = errors.New("invalid message header")
+)
+
+// Notifier sends notifications by email, the message recipient must be an email address
+type Notifier struct {
+	addr string
+	auth smtp.Auth
+	from mail.Address
+}
+
+// NewNotifier constructor for Notifier
+func NewNotifier(cfg config.SMTP) (*Notifier, error) {
+	if cfg.Host == "" {
+		return nil, ErrEmptyHost
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	n := &Notifier{
+		addr: net.JoinHostPort(cfg.Host, cfg.Port),
+		from: *from,
+	}
+
+	// plain auth is sent only over tls or to localhost
+	if cfg.Username != "" {
+		n.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return n, nil
+}
+
+// Send ...
+func (n *Notifier) Send(message shared.Message) error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(message.Body)
+	b.WriteString("\r\n")
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from.Address, []string{to.Address}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
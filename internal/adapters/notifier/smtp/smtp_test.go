@@ -0,0 +1,183 @@
+package smtp_test
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/notifier/smtp"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// fakeServer minimal smtp server accepting one message per connection
+type fakeServer struct {
+	listener net.Listener
+	messages chan fakeMessage
+}
+
+type fakeMessage struct {
+	Auth string
+	From string
+	To   []string
+	Data string
+}
+
+func newFakeServer() (*fakeServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &fakeServer{
+		listener: listener,
+		messages: make(chan fakeMessage, 1),
+	}
+	go srv.serve()
+
+	return srv, nil
+}
+
+func (f *fakeServer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost fake smtp")
+
+	var msg fakeMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			msg.Auth = arg
+			_ = tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			msg.From = strings.TrimPrefix(arg, "FROM:")
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			msg.To = append(msg.To, strings.TrimPrefix(arg, "TO:"))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			_ = tp.PrintfLine("250 queued")
+			f.messages <- msg
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (f *fakeServer) Close() error {
+	return f.listener.Close()
+}
+
+type Suite struct {
+	suite.Suite
+	srv *fakeServer
+	cfg config.SMTP
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	srv, err := newFakeServer()
+	s.Require().NoError(err)
+	s.srv = srv
+
+	host, port, err := net.SplitHostPort(srv.listener.Addr().String())
+	s.Require().NoError(err)
+
+	s.cfg = config.SMTP{
+		Host: host,
+		Port: port,
+		From: "product-keeper <noreply@example.com>",
+	}
+}
+
+func (s *Suite) TearDownTest() {
+	s.NoError(s.srv.Close())
+}
+
+func (s *Suite) TestSend() {
+	notifier, err := smtp.NewNotifier(s.cfg)
+	s.Require().NoError(err)
+
+	err = notifier.Send(shared.Message{To: "test@example.com", Subject: "test subject", Body: "test body\nsecond line"})
+	s.Require().NoError(err)
+
+	msg := <-s.srv.messages
+	s.Empty(msg.Auth)
+	s.Equal("<noreply@example.com>", msg.From)
+	s.Equal([]string{"<test@example.com>"}, msg.To)
+
+	header, body, ok := strings.Cut(msg.Data, "\n\n")
+	s.True(ok)
+	s.Contains(header, "From: \"product-keeper\" <noreply@example.com>\n")
+	s.Contains(header, "To: <test@example.com>\n")
+	s.Contains(header, "Subject: test subject\n")
+	s.Equal("test body\nsecond line\n", body)
+}
+
+func (s *Suite) TestSendWithAuth() {
+	s.cfg.Username = "test user"
+	s.cfg.Password = "test password"
+
+	notifier, err := smtp.NewNotifier(s.cfg)
+	s.Require().NoError(err)
+
+	err = notifier.Send(shared.Message{To: "test@example.com", Subject: "test subject", Body: "test body"})
+	s.Require().NoError(err)
+
+	msg := <-s.srv.messages
+	s.Equal("PLAIN AHRlc3QgdXNlcgB0ZXN0IHBhc3N3b3Jk", msg.Auth)
+}
+
+func (s *Suite) TestSendInvalidMessage() {
+	notifier, err := smtp.NewNotifier(s.cfg)
+	s.Require().NoError(err)
+
+	err = notifier.Send(shared.Message{To: "test@example.com", Subject: "test\r\nBcc: other@example.com", Body: "test body"})
+	s.ErrorIs(err, smtp.ErrInvalidHeader)
+
+	err = notifier.Send(shared.Message{To: "test name", Subject: "test subject", Body: "test body"})
+	s.Error(err)
+}
+
+func (s *Suite) TestNewNotifier() {
+	_, err := smtp.NewNotifier(config.SMTP{From: "noreply@example.com"})
+	s.ErrorIs(err, smtp.ErrEmptyHost)
+
+	_, err = smtp.NewNotifier(config.SMTP{Host: "localhost", From: "invalid"})
+	s.Error(err)
+}
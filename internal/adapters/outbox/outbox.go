@@ -0,0 +1,10 @@
+package outbox
+
+import (
+	"github.com/fallra1n/product-keeper/internal/adapters/outbox/postgres"
+)
+
+// NewPostgresOutbox ...
+func NewPostgresOutbox() *postgres.OutboxRepository {
+	return postgres.NewOutbox()
+}
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/outbox"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// OutboxRepository ...
+type OutboxRepository struct{}
+
+// NewOutbox constructor for OutboxRepository
+func NewOutbox() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// messageRow outbox message with headers stored as json
+type messageRow struct {
+	outbox.Message
+	Headers []byte `db:"headers"`
+}
+
+// AddMessage saves message in the transaction of the request, it is published by the relay after commit
+func (r *OutboxRepository) AddMessage(tx *sqlx.Tx, topic, key string, headers map[string]string, payload []byte, createdAt time.Time) error {
+	sqlQuery := `
+		INSERT INTO outbox (topic, message_key, headers, payload, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $5);
+	`
+
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(sqlQuery, topic, key, headersJSON, payload, createdAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClaimPendingMessages leases due messages until leaseUntil and returns them in the order they were added.
+// Claims of relays are serialized with an advisory lock, so a relay sees leases committed by the others.
+// Messages of a key are claimed only when none of its earlier unsent messages is leased or postponed
+func (r *OutboxRepository) ClaimPendingMessages(tx *sqlx.Tx, now, leaseUntil time.Time, limit int) ([]outbox.Message, error) {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('outbox'));`); err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		WITH claimed AS (
+		  UPDATE outbox
+		  SET locked_until = $2
+		  WHERE id IN (
+		    SELECT id
+		    FROM outbox o
+		    WHERE sent_at IS NULL AND next_attempt_at <= $1
+		      AND (locked_until IS NULL OR locked_until <= $1)
+		      AND NOT EXISTS (
+		        SELECT 1
+		        FROM outbox earlier
+		        WHERE earlier.topic = o.topic
+		          AND earlier.message_key = o.message_key
+		          AND earlier.id < o.id
+		          AND earlier.sent_at IS NULL
+		          AND (earlier.next_attempt_at > $1 OR earlier.locked_until > $1)
+		      )
+		    ORDER BY id
+		    LIMIT $3
+		  )
+		  RETURNING id, topic, message_key, headers, payload, attempts, created_at
+		)
+		SELECT id, topic, message_key, headers, payload, attempts, created_at
+		FROM claimed
+		ORDER BY id;
+	`
+
+	var rows []messageRow
+	if err := tx.Select(&rows, sqlQuery, now, leaseUntil, limit); err != nil {
+		return nil, err
+	}
+
+	messages := make([]outbox.Message, len(rows))
+	for i, row := range rows {
+		messages[i] = row.Message
+		if err := json.Unmarshal(row.Headers, &messages[i].Headers); err != nil {
+			return nil, err
+		}
+	}
+
+	return messages, nil
+}
+
+// MarkSent ...
+func (r *OutboxRepository) MarkSent(tx *sqlx.Tx, id uint64, sentAt time.Time) error {
+	sqlQuery := `
+		UPDATE outbox
+		SET sent_at = $2, locked_until = NULL
+		WHERE id = $1;
+	`
+
+	return execAffected(tx, sqlQuery, id, sentAt)
+}
+
+// MarkFailed ...
+func (r *OutboxRepository) MarkFailed(tx *sqlx.Tx, id uint64, attempts int, nextAttemptAt time.Time) error {
+	sqlQuery := `
+		UPDATE outbox
+		SET attempts = $2, next_attempt_at = $3, locked_until = NULL
+		WHERE id = $1;
+	`
+
+	return execAffected(tx, sqlQuery, id, attempts, nextAttemptAt)
+}
+
+// ReleaseMessage ...
+func (r *OutboxRepository) ReleaseMessage(tx *sqlx.Tx, id uint64) error {
+	sqlQuery := `
+		UPDATE outbox
+		SET locked_until = NULL
+		WHERE id = $1;
+	`
+
+	return execAffected(tx, sqlQuery, id)
+}
+
+// DeleteSentMessages deletes up to limit messages sent before sentBefore, returns the number of deleted messages
+func (r *OutboxRepository) DeleteSentMessages(tx *sqlx.Tx, sentBefore time.Time, limit int) (int, error) {
+	sqlQuery := `
+		DELETE FROM outbox
+		WHERE id IN (
+		  SELECT id
+		  FROM outbox
+		  WHERE sent_at IS NOT NULL AND sent_at < $1
+		  ORDER BY sent_at
+		  LIMIT $2
+		);
+	`
+
+	res, err := tx.Exec(sqlQuery, sentBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
+func execAffected(tx *sqlx.Tx, sqlQuery string, args ...any) error {
+	res, err := tx.Exec(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return shared.ErrNoData
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/outbox/postgres"
+	"github.com/fallra1n/product-keeper/internal/core/outbox"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/pkg/access"
+	"github.com/fallra1n/product-keeper/pkg/postgresdb"
+)
+
+type Suite struct {
+	suite.Suite
+	repo *postgres.OutboxRepository
+	db   *sqlx.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	cfg := config.MustLoad()
+	s.db = postgresdb.NewPostgresDB(access.PostgresTestConnect(cfg), cfg.Postgres.Timeout)
+	s.repo = postgres.NewOutbox()
+}
+
+func (s *Suite) TestClaimPendingMessages() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", map[string]string{"test header": "test value"}, []byte("test payload1"), now)
+		s.NoError(err)
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", nil, []byte("test payload2"), now)
+		s.NoError(err)
+
+		err = s.repo.AddMessage(tx, "test topic", "other key", nil, []byte("test payload3"), now)
+		s.NoError(err)
+
+		err = s.repo.AddMessage(tx, "test topic", "other key", nil, []byte("test payload4"), now.Add(time.Hour))
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.ClaimPendingMessages(tx, now, lease, 1)
+			s.NoError(err)
+			s.Len(data, 1)
+			s.Equal(outbox.Message{
+				ID:        data[0].ID,
+				Topic:     "test topic",
+				Key:       "test key",
+				Headers:   map[string]string{"test header": "test value"},
+				Payload:   []byte("test payload1"),
+				Attempts:  0,
+				CreatedAt: now,
+			}, data[0])
+
+			// the second message waits for the leased one with the same key, the fourth message is not due yet
+			data, err = s.repo.ClaimPendingMessages(tx, now, lease, 10)
+			s.NoError(err)
+			s.Len(data, 1)
+			s.Equal([]byte("test payload3"), data[0].Payload)
+
+			// expired leases are claimed again
+			data, err = s.repo.ClaimPendingMessages(tx, lease, lease.Add(time.Minute), 10)
+			s.NoError(err)
+			s.Len(data, 3)
+			s.Equal([]byte("test payload1"), data[0].Payload)
+			s.Equal([]byte("test payload2"), data[1].Payload)
+			s.Equal([]byte("test payload3"), data[2].Payload)
+		})
+	})
+}
+
+func (s *Suite) TestMarkSent() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", nil, []byte("test payload"), now)
+		s.NoError(err)
+
+		data, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+		s.NoError(err)
+		s.Len(data, 1)
+
+		s.Run("checking data", func() {
+			// message doesn't exist
+			err = s.repo.MarkSent(tx, data[0].ID+1, now)
+			s.ErrorIs(err, shared.ErrNoData)
+
+			err = s.repo.MarkSent(tx, data[0].ID, now)
+			s.NoError(err)
+
+			pending, err := s.repo.ClaimPendingMessages(tx, lease, lease.Add(time.Minute), 10)
+			s.NoError(err)
+			s.Empty(pending)
+		})
+	})
+}
+
+func (s *Suite) TestMarkFailed() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Hour)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", nil, []byte("test payload"), now)
+		s.NoError(err)
+
+		data, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+		s.NoError(err)
+		s.Len(data, 1)
+
+		s.Run("checking data", func() {
+			// message doesn't exist
+			err = s.repo.MarkFailed(tx, data[0].ID+1, 1, now.Add(time.Minute))
+			s.ErrorIs(err, shared.ErrNoData)
+
+			err = s.repo.MarkFailed(tx, data[0].ID, 1, now.Add(time.Minute))
+			s.NoError(err)
+
+			// postponed message is pending only after the next attempt time, the lease is removed
+			pending, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+			s.NoError(err)
+			s.Empty(pending)
+
+			pending, err = s.repo.ClaimPendingMessages(tx, now.Add(time.Minute), lease, 10)
+			s.NoError(err)
+			s.Len(pending, 1)
+			s.Equal(1, pending[0].Attempts)
+		})
+	})
+}
+
+func (s *Suite) TestReleaseMessage() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", nil, []byte("test payload"), now)
+		s.NoError(err)
+
+		data, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+		s.NoError(err)
+		s.Len(data, 1)
+
+		s.Run("checking data", func() {
+			// message doesn't exist
+			err = s.repo.ReleaseMessage(tx, data[0].ID+1)
+			s.ErrorIs(err, shared.ErrNoData)
+
+			err = s.repo.ReleaseMessage(tx, data[0].ID)
+			s.NoError(err)
+
+			pending, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+			s.NoError(err)
+			s.Len(pending, 1)
+			s.Equal(0, pending[0].Attempts)
+		})
+	})
+}
+
+func (s *Suite) TestDeleteSentMessages() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		for _, key := range []string{"test key1", "test key2", "test key3"} {
+			err = s.repo.AddMessage(tx, "test topic", key, nil, []byte("test payload"), now)
+			s.NoError(err)
+		}
+
+		data, err := s.repo.ClaimPendingMessages(tx, now, now, 10)
+		s.NoError(err)
+		s.Len(data, 3)
+
+		// the third message stays pending
+		s.NoError(s.repo.MarkSent(tx, data[0].ID, now.Add(-time.Hour)))
+		s.NoError(s.repo.MarkSent(tx, data[1].ID, now))
+
+		s.Run("checking data", func() {
+			// messages sent at sentBefore are kept
+			deleted, err := s.repo.DeleteSentMessages(tx, now, 10)
+			s.NoError(err)
+			s.Equal(1, deleted)
+
+			deleted, err = s.repo.DeleteSentMessages(tx, now.Add(time.Minute), 10)
+			s.NoError(err)
+			s.Equal(1, deleted)
+
+			// pending messages are never deleted
+			deleted, err = s.repo.DeleteSentMessages(tx, now.Add(time.Hour), 10)
+			s.NoError(err)
+			s.Zero(deleted)
+
+			pending, err := s.repo.ClaimPendingMessages(tx, now, now, 10)
+			s.NoError(err)
+			s.Len(pending, 1)
+			s.Equal(data[2].ID, pending[0].ID)
+		})
+	})
+}
+
+func (s *Suite) TestPostponedKeyOrder() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", nil, []byte("test payload1"), now)
+		s.NoError(err)
+
+		err = s.repo.AddMessage(tx, "test topic", "test key", nil, []byte("test payload2"), now)
+		s.NoError(err)
+
+		err = s.repo.AddMessage(tx, "test topic", "other key", nil, []byte("test payload3"), now)
+		s.NoError(err)
+
+		data, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+		s.NoError(err)
+		s.Len(data, 3)
+
+		s.Run("checking data", func() {
+			s.NoError(s.repo.MarkFailed(tx, data[0].ID, 1, now.Add(time.Minute)))
+			s.NoError(s.repo.ReleaseMessage(tx, data[1].ID))
+			s.NoError(s.repo.ReleaseMessage(tx, data[2].ID))
+
+			// the second message waits for the postponed one with the same key
+			pending, err := s.repo.ClaimPendingMessages(tx, now, lease, 10)
+			s.NoError(err)
+			s.Len(pending, 1)
+			s.Equal([]byte("test payload3"), pending[0].Payload)
+
+			pending, err = s.repo.ClaimPendingMessages(tx, now.Add(time.Minute), lease.Add(time.Minute), 10)
+			s.NoError(err)
+			s.Len(pending, 3)
+			s.Equal(data[0].ID, pending[0].ID)
+		})
+	})
+}
@@ -0,0 +1,216 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/events"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/pkg/kafka"
+)
+
+// ErrClosed producer has been closed, product views are not accepted
+var ErrClosed = errors.New("statistics producer is closed")
+
+// Changes product changes saved in the request transaction, they must not be published
+// before the transaction is committed or lost on a crash
+type Changes interface {
+	Send(tx *sqlx.Tx, event products.Event) error
+}
+
+// Metrics counters of product events
+type Metrics struct {
+	Queued  uint64
+	Sent    uint64
+	Failed  uint64
+	Dropped uint64
+}
+
+// ProductsStatistics batches product views in memory and publishes them with async producer.
+// Delivery is at most once, views in the buffer are lost if the process crashes.
+// Other product events are passed to changes in the transaction
+type ProductsStatistics struct {
+	log     *slog.Logger
+	mq      sarama.AsyncProducer
+	changes Changes
+	cfg     config.Statistics
+	topics  config.KafkaTopics
+
+	queue  chan products.Event
+	mu     sync.RWMutex
+	closed bool
+
+	done    chan struct{}
+	drained sync.WaitGroup
+
+	queued  atomic.Uint64
+	sent    atomic.Uint64
+	failed  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewProducts constructor for ProductsStatistics, starts batching and draining of producer results
+func NewProducts(log *slog.Logger, mq sarama.AsyncProducer, changes Changes, cfg config.Statistics, topics config.KafkaTopics) (*ProductsStatistics, error) {
+	if changes == nil {
+		return nil, errors.New("async delivery is used only for product views, product changes require transactional delivery")
+	}
+
+	if cfg.Overflow != config.OverflowDrop && cfg.Overflow != config.OverflowBlock {
+		return nil, fmt.Errorf("unknown overflow policy %q", cfg.Overflow)
+	}
+
+	if cfg.BufferSize <= 0 || cfg.BatchSize <= 0 || cfg.FlushInterval <= 0 {
+		return nil, errors.New("buffer size, batch size and flush interval must be positive")
+	}
+
+	s := &ProductsStatistics{
+		log:     log,
+		mq:      mq,
+		changes: changes,
+		cfg:     cfg,
+		topics:  topics,
+
+		queue: make(chan products.Event, cfg.BufferSize),
+		done:  make(chan struct{}),
+	}
+
+	s.drained.Add(2)
+	go s.drainSuccesses()
+	go s.drainErrors()
+	go s.run()
+
+	return s, nil
+}
+
+// Send puts product view to the buffer, tx is not used for views.
+// If the buffer is full the view is dropped or the call waits, depending on overflow policy.
+// Other events are sent to changes in tx
+func (s *ProductsStatistics) Send(tx *sqlx.Tx, event products.Event) error {
+	if event.Type != products.EventViewed {
+		return s.changes.Send(tx, event)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if s.cfg.Overflow == config.OverflowBlock {
+		s.queue <- event
+		s.queued.Add(1)
+		return nil
+	}
+
+	select {
+	case s.queue <- event:
+		s.queued.Add(1)
+	default:
+		s.dropped.Add(1)
+		s.log.Warn("statistics buffer is full, product view has been dropped", "type", event.Type, "id", event.Product.ID)
+	}
+
+	return nil
+}
+
+// Metrics ...
+func (s *ProductsStatistics) Metrics() Metrics {
+	return Metrics{
+		Queued:  s.queued.Load(),
+		Sent:    s.sent.Load(),
+		Failed:  s.failed.Load(),
+		Dropped: s.dropped.Load(),
+	}
+}
+
+// Close flushes buffered events and waits until the producer returns results for all of them
+func (s *ProductsStatistics) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	s.mq.AsyncClose()
+	s.drained.Wait()
+
+	m := s.Metrics()
+	s.log.Info("statistics producer has been closed", "queued", m.Queued, "sent", m.Sent, "failed", m.Failed, "dropped", m.Dropped)
+	return nil
+}
+
+// run collects events into batches, a batch is flushed when it is full or on FlushInterval
+func (s *ProductsStatistics) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]products.Event, 0, s.cfg.BatchSize)
+	for {
+		select {
+		case event, ok := <-s.queue:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+
+			batch = append(batch, event)
+			if len(batch) >= s.cfg.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (s *ProductsStatistics) flush(batch []products.Event) {
+	for _, event := range batch {
+		value, err := events.Marshal(event)
+		if err != nil {
+			s.failed.Add(1)
+			s.log.Error("failed to encode product event", "error", err, "id", event.ID, "failed", s.failed.Load())
+			continue
+		}
+
+		s.mq.Input() <- &sarama.ProducerMessage{
+			Topic:   events.Topic(s.topics, event.Type),
+			Key:     sarama.StringEncoder(events.Key(event)),
+			Headers: kafka.RecordHeaders(events.Headers(event)),
+			Value:   sarama.ByteEncoder(value),
+		}
+	}
+}
+
+func (s *ProductsStatistics) drainSuccesses() {
+	defer s.drained.Done()
+
+	for range s.mq.Successes() {
+		s.sent.Add(1)
+	}
+}
+
+func (s *ProductsStatistics) drainErrors() {
+	defer s.drained.Done()
+
+	for err := range s.mq.Errors() {
+		s.failed.Add(1)
+		s.log.Error("failed to send product event to statistics", "error", err.Err, "failed", s.failed.Load())
+	}
+}
@@ -0,0 +1,226 @@
+package async_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/async"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/events"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+// stuckProducer does not read messages until release is called
+type stuckProducer struct {
+	sarama.AsyncProducer
+
+	input     chan *sarama.ProducerMessage
+	successes chan *sarama.ProducerMessage
+	errors    chan *sarama.ProducerError
+}
+
+func newStuckProducer() *stuckProducer {
+	return &stuckProducer{
+		input:     make(chan *sarama.ProducerMessage),
+		successes: make(chan *sarama.ProducerMessage),
+		errors:    make(chan *sarama.ProducerError),
+	}
+}
+
+func (p *stuckProducer) release() {
+	go func() {
+		for msg := range p.input {
+			p.successes <- msg
+		}
+		close(p.successes)
+		close(p.errors)
+	}()
+}
+
+func (p *stuckProducer) Input() chan<- *sarama.ProducerMessage     { return p.input }
+func (p *stuckProducer) Successes() <-chan *sarama.ProducerMessage { return p.successes }
+func (p *stuckProducer) Errors() <-chan *sarama.ProducerError      { return p.errors }
+func (p *stuckProducer) AsyncClose()                               { close(p.input) }
+
+// recordedChanges records transactions of product changes
+type recordedChanges struct {
+	txs []*sqlx.Tx
+	err error
+}
+
+func (c *recordedChanges) Send(tx *sqlx.Tx, _ products.Event) error {
+	c.txs = append(c.txs, tx)
+	return c.err
+}
+
+type Suite struct {
+	suite.Suite
+	changes *recordedChanges
+	cfg     config.Statistics
+	topics  config.KafkaTopics
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	s.changes = &recordedChanges{}
+	s.cfg = config.Statistics{
+		Delivery:      config.StatisticsAsync,
+		BufferSize:    1,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Overflow:      config.OverflowDrop,
+	}
+	s.topics = config.KafkaTopics{ProductViews: "test views", ProductChanges: "test changes"}
+}
+
+func newMockProducer(t *testing.T) *mocks.AsyncProducer {
+	cfg := mocks.NewTestConfig()
+	cfg.Producer.Return.Successes = true
+
+	return mocks.NewAsyncProducer(t, cfg)
+}
+
+func (s *Suite) TestFlushOnClose() {
+	s.cfg.BufferSize = 10
+
+	mq := newMockProducer(s.T())
+	mq.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != "test views" {
+			return errors.New("unexpected topic " + msg.Topic)
+		}
+		if key, _ := msg.Key.Encode(); string(key) != "1" {
+			return errors.New("unexpected key " + string(key))
+		}
+		for _, header := range msg.Headers {
+			if string(header.Key) == events.HeaderEventType && string(header.Value) == string(products.EventViewed) {
+				return nil
+			}
+		}
+		return errors.New("event type header is not set")
+	})
+	mq.ExpectInputAndSucceed()
+	mq.ExpectInputAndFail(sarama.ErrOutOfBrokers)
+
+	statistics, err := async.NewProducts(logging.SetupLogger("local"), mq, s.changes, s.cfg, s.topics)
+	s.Require().NoError(err)
+
+	for i := uint64(1); i <= 3; i++ {
+		s.NoError(statistics.Send(nil, products.Event{Type: products.EventViewed, Product: products.Product{ID: i}}))
+	}
+
+	// the last view is not in a full batch and is flushed only on close
+	s.NoError(statistics.Close())
+	s.Equal(async.Metrics{Queued: 3, Sent: 2, Failed: 1}, statistics.Metrics())
+
+	s.ErrorIs(statistics.Send(nil, products.Event{Type: products.EventViewed, Product: products.Product{ID: 4}}), async.ErrClosed)
+}
+
+func (s *Suite) TestFlushInterval() {
+	s.cfg.BufferSize = 10
+	s.cfg.FlushInterval = 10 * time.Millisecond
+
+	mq := newMockProducer(s.T())
+	mq.ExpectInputAndSucceed()
+
+	statistics, err := async.NewProducts(logging.SetupLogger("local"), mq, s.changes, s.cfg, s.topics)
+	s.Require().NoError(err)
+
+	s.NoError(statistics.Send(nil, products.Event{Type: products.EventViewed, Product: products.Product{ID: 1}}))
+	s.Eventually(func() bool {
+		return statistics.Metrics().Sent == 1
+	}, time.Second, 10*time.Millisecond)
+
+	s.NoError(statistics.Close())
+}
+
+func (s *Suite) TestDropOverflow() {
+	s.cfg.BatchSize = 1
+
+	mq := newStuckProducer()
+	statistics, err := async.NewProducts(logging.SetupLogger("local"), mq, s.changes, s.cfg, s.topics)
+	s.Require().NoError(err)
+
+	// one view is waiting for the producer and one is in the buffer, others are dropped
+	for i := uint64(1); i <= 5; i++ {
+		s.NoError(statistics.Send(nil, products.Event{Type: products.EventViewed, Product: products.Product{ID: i}}))
+	}
+
+	m := statistics.Metrics()
+	s.LessOrEqual(m.Queued, uint64(2))
+	s.Equal(uint64(5), m.Queued+m.Dropped)
+
+	mq.release()
+	s.NoError(statistics.Close())
+	s.Equal(m.Queued, statistics.Metrics().Sent)
+}
+
+func (s *Suite) TestBlockOverflow() {
+	s.cfg.BatchSize = 1
+	s.cfg.Overflow = config.OverflowBlock
+
+	mq := newStuckProducer()
+	statistics, err := async.NewProducts(logging.SetupLogger("local"), mq, s.changes, s.cfg, s.topics)
+	s.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	for i := uint64(1); i <= 5; i++ {
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			s.NoError(statistics.Send(nil, products.Event{Type: products.EventViewed, Product: products.Product{ID: id}}))
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	s.LessOrEqual(statistics.Metrics().Queued, uint64(2))
+
+	mq.release()
+	wg.Wait()
+
+	s.NoError(statistics.Close())
+	s.Equal(async.Metrics{Queued: 5, Sent: 5}, statistics.Metrics())
+}
+
+func (s *Suite) TestInvalidConfig() {
+	s.cfg.Overflow = "unknown"
+
+	_, err := async.NewProducts(logging.SetupLogger("local"), newStuckProducer(), s.changes, s.cfg, s.topics)
+	s.Error(err)
+}
+
+func (s *Suite) TestChangesWithoutTransaction() {
+	_, err := async.NewProducts(logging.SetupLogger("local"), newStuckProducer(), nil, s.cfg, s.topics)
+	s.Error(err)
+}
+
+func (s *Suite) TestChanges() {
+	mq := newStuckProducer()
+	statistics, err := async.NewProducts(logging.SetupLogger("local"), mq, s.changes, s.cfg, s.topics)
+	s.Require().NoError(err)
+
+	tx := &sqlx.Tx{}
+	for _, eventType := range []products.EventType{products.EventCreated, products.EventUpdated, products.EventDeleted} {
+		s.NoError(statistics.Send(tx, products.Event{Type: eventType, Product: products.Product{ID: 1}}))
+	}
+
+	s.changes.err = errors.New("test error")
+	s.Error(statistics.Send(tx, products.Event{Type: products.EventCreated, Product: products.Product{ID: 2}}))
+
+	// changes are not buffered
+	s.Zero(statistics.Metrics().Queued)
+	s.Equal([]*sqlx.Tx{tx, tx, tx, tx}, s.changes.txs)
+
+	mq.release()
+	s.NoError(statistics.Close())
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	eventsv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/events/v1"
+)
+
+// ContentType content type of encoded events
+const ContentType = "application/x-protobuf; messageType=productkeeper.events.v1.ProductEvent"
+
+// message headers
+const (
+	HeaderContentType = "content-type"
+	HeaderEventType   = "event-type"
+	HeaderTraceID     = "trace-id"
+	HeaderRequestID   = "request-id"
+)
+
+// ErrMalformedEvent event can not be decoded
+var ErrMalformedEvent = errors.New("malformed product event")
+
+// Marshal encodes event as ProductEvent message of api/events/product_event.proto
+func Marshal(event products.Event) ([]byte, error) {
+	msg := &eventsv1.ProductEvent{
+		Id:            event.ID,
+		Type:          string(event.Type),
+		OccurredAt:    timestamp(event.OccurredAt),
+		Actor:         event.Actor,
+		SchemaVersion: uint32(event.SchemaVersion),
+		Product:       productMessage(event.Product),
+	}
+	if event.Previous != nil {
+		msg.Previous = productMessage(*event.Previous)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Unmarshal decodes ProductEvent message, unknown fields are skipped
+func Unmarshal(data []byte) (products.Event, error) {
+	var msg eventsv1.ProductEvent
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return products.Event{}, fmt.Errorf("%w: %s", ErrMalformedEvent, err)
+	}
+
+	event := products.Event{
+		ID:            msg.GetId(),
+		Type:          products.EventType(msg.GetType()),
+		OccurredAt:    fromTimestamp(msg.GetOccurredAt()),
+		Actor:         msg.GetActor(),
+		SchemaVersion: int(msg.GetSchemaVersion()),
+		Product:       fromProductMessage(msg.GetProduct()),
+	}
+	if msg.GetPrevious() != nil {
+		previous := fromProductMessage(msg.GetPrevious())
+		event.Previous = &previous
+	}
+
+	return event, nil
+}
+
+func productMessage(p products.Product) *eventsv1.Product {
+	return &eventsv1.Product{
+		Id:        p.ID,
+		Name:      p.Name,
+		Price:     p.Price,
+		Quantity:  p.Quantity,
+		OwnerId:   p.OwnerID,
+		OwnerName: p.OwnerName,
+		CreatedAt: timestamp(p.CreatedAt),
+	}
+}
+
+func fromProductMessage(p *eventsv1.Product) products.Product {
+	return products.Product{
+		ID:        p.GetId(),
+		Name:      p.GetName(),
+		Price:     p.GetPrice(),
+		Quantity:  p.GetQuantity(),
+		OwnerID:   p.GetOwnerId(),
+		OwnerName: p.GetOwnerName(),
+		CreatedAt: fromTimestamp(p.GetCreatedAt()),
+	}
+}
+
+// timestamp zero time is omitted
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
+
+// Key message key of the event, events of the same product go to the same partition in order
+func Key(event products.Event) string {
+	return strconv.FormatUint(event.Product.ID, 10)
+}
+
+// Headers message headers of the event, trace headers are omitted for events without request
+func Headers(event products.Event) map[string]string {
+	headers := map[string]string{
+		HeaderContentType: ContentType,
+		HeaderEventType:   string(event.Type),
+	}
+
+	if event.Trace.TraceID != "" {
+		headers[HeaderTraceID] = event.Trace.TraceID
+	}
+
+	if event.Trace.RequestID != "" {
+		headers[HeaderRequestID] = event.Trace.RequestID
+	}
+
+	return headers
+}
+
+// Topic topic of the event type, views and changes of products are published to different topics
+func Topic(topics config.KafkaTopics, eventType products.EventType) string {
+	if eventType == products.EventViewed {
+		return topics.ProductViews
+	}
+
+	return topics.ProductChanges
+}
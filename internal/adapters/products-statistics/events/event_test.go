@@ -0,0 +1,157 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/events"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	eventsv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/events/v1"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+var mockEvent = products.Event{
+	ID:            "test event id",
+	Type:          products.EventUpdated,
+	OccurredAt:    time.Date(2000, 1, 2, 3, 4, 5, 6, time.UTC),
+	Actor:         "test username",
+	SchemaVersion: products.EventSchemaVersion,
+	Product: products.Product{
+		ID:        123,
+		Name:      "test name",
+		Price:     42,
+		Quantity:  7,
+		OwnerID:   1,
+		OwnerName: "test username",
+		CreatedAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+	Previous: &products.Product{
+		ID:        123,
+		Name:      "test old name",
+		Price:     40,
+		OwnerID:   1,
+		OwnerName: "test username",
+		CreatedAt: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func (s *Suite) TestRoundTrip() {
+	data, err := events.Marshal(mockEvent)
+	s.Require().NoError(err)
+
+	event, err := events.Unmarshal(data)
+	s.NoError(err)
+	s.Equal(mockEvent, event)
+
+	// default values are omitted
+	data, err = events.Marshal(products.Event{Type: products.EventViewed})
+	s.Require().NoError(err)
+
+	event, err = events.Unmarshal(data)
+	s.NoError(err)
+	s.Equal(products.Event{Type: products.EventViewed}, event)
+}
+
+func (s *Suite) TestSchema() {
+	data, err := events.Marshal(mockEvent)
+	s.Require().NoError(err)
+
+	// every field of the event is mapped to the field of api/events/product_event.proto
+	var msg eventsv1.ProductEvent
+	s.Require().NoError(proto.Unmarshal(data, &msg))
+	s.True(proto.Equal(&eventsv1.ProductEvent{
+		Id:            "test event id",
+		Type:          "product.updated",
+		OccurredAt:    timestamppb.New(mockEvent.OccurredAt),
+		Actor:         "test username",
+		SchemaVersion: uint32(products.EventSchemaVersion),
+		Product: &eventsv1.Product{
+			Id:        123,
+			Name:      "test name",
+			Price:     42,
+			Quantity:  7,
+			OwnerId:   1,
+			OwnerName: "test username",
+			CreatedAt: timestamppb.New(mockEvent.Product.CreatedAt),
+		},
+		Previous: &eventsv1.Product{
+			Id:        123,
+			Name:      "test old name",
+			Price:     40,
+			OwnerId:   1,
+			OwnerName: "test username",
+			CreatedAt: timestamppb.New(mockEvent.Previous.CreatedAt),
+		},
+	}, &msg), msg.String())
+}
+
+func (s *Suite) TestUnknownFields() {
+	data, err := events.Marshal(mockEvent)
+	s.Require().NoError(err)
+
+	data = protowire.AppendTag(data, 100, protowire.BytesType)
+	data = protowire.AppendString(data, "field from newer schema")
+	data = protowire.AppendTag(data, 101, protowire.Fixed64Type)
+	data = protowire.AppendFixed64(data, 42)
+
+	event, err := events.Unmarshal(data)
+	s.NoError(err)
+	s.Equal(mockEvent, event)
+}
+
+func (s *Suite) TestMalformed() {
+	data, err := events.Marshal(mockEvent)
+	s.Require().NoError(err)
+
+	_, err = events.Unmarshal(data[:len(data)-1])
+	s.ErrorIs(err, events.ErrMalformedEvent)
+
+	// proto3 strings must be valid utf-8
+	event := mockEvent
+	event.Product.Name = "\xff"
+	_, err = events.Marshal(event)
+	s.Error(err)
+}
+
+func (s *Suite) TestTopic() {
+	topics := config.KafkaTopics{ProductViews: "test views", ProductChanges: "test changes"}
+
+	s.Equal("test views", events.Topic(topics, products.EventViewed))
+	s.Equal("test changes", events.Topic(topics, products.EventCreated))
+	s.Equal("test changes", events.Topic(topics, products.EventUpdated))
+	s.Equal("test changes", events.Topic(topics, products.EventDeleted))
+}
+
+func (s *Suite) TestKey() {
+	s.Equal("123", events.Key(mockEvent))
+}
+
+func (s *Suite) TestHeaders() {
+	s.Equal(map[string]string{
+		events.HeaderContentType: events.ContentType,
+		events.HeaderEventType:   "product.updated",
+	}, events.Headers(mockEvent))
+
+	event := mockEvent
+	event.Trace = shared.Trace{TraceID: "test trace id", RequestID: "test request id"}
+	s.Equal(map[string]string{
+		events.HeaderContentType: events.ContentType,
+		events.HeaderEventType:   "product.updated",
+		events.HeaderTraceID:     "test trace id",
+		events.HeaderRequestID:   "test request id",
+	}, events.Headers(event))
+}
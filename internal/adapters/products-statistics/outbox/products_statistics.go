@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/events"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+)
+
+// Outbox messages published after the transaction is committed
+type Outbox interface {
+	AddMessage(tx *sqlx.Tx, topic, key string, headers map[string]string, payload []byte, createdAt time.Time) error
+}
+
+// ProductsStatistics saves product events to the outbox
+type ProductsStatistics struct {
+	outbox Outbox
+	topics config.KafkaTopics
+}
+
+// NewProducts constructor for ProductsStatistics
+func NewProducts(outbox Outbox, topics config.KafkaTopics) *ProductsStatistics {
+	return &ProductsStatistics{outbox: outbox, topics: topics}
+}
+
+// Send ...
+func (s *ProductsStatistics) Send(tx *sqlx.Tx, event products.Event) error {
+	value, err := events.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.outbox.AddMessage(tx, events.Topic(s.topics, event.Type), events.Key(event), events.Headers(event), value, event.OccurredAt)
+}
@@ -1,12 +1,21 @@
 package productsstatistics
 
 import (
+	"log/slog"
+
 	"github.com/IBM/sarama"
 
-	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/kafka"
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/async"
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/outbox"
 )
 
-// NewKafkaProducts ...
-func NewKafkaProducts(mq sarama.SyncProducer) *kafka.ProductsStatistics {
-	return kafka.NewProducts(mq)
+// NewOutboxProducts ...
+func NewOutboxProducts(o outbox.Outbox, topics config.KafkaTopics) *outbox.ProductsStatistics {
+	return outbox.NewProducts(o, topics)
+}
+
+// NewAsyncProducts product views are published with async producer, other events are saved to the outbox
+func NewAsyncProducts(log *slog.Logger, mq sarama.AsyncProducer, o outbox.Outbox, cfg config.Statistics, topics config.KafkaTopics) (*async.ProductsStatistics, error) {
+	return async.NewProducts(log, mq, outbox.NewProducts(o, topics), cfg, topics)
 }
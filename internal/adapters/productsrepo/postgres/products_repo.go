@@ -3,6 +3,7 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 
@@ -10,6 +11,9 @@ import (
 	"github.com/fallra1n/product-keeper/internal/core/shared"
 )
 
+// likeReplacer escapes wildcards of LIKE pattern
+var likeReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // ProductsRepository ...
 type ProductsRepository struct{}
 
@@ -21,8 +25,10 @@ func NewProducts() *ProductsRepository {
 // CreateProduct ...
 func (r *ProductsRepository) CreateProduct(tx *sqlx.Tx, product products.Product) (uint64, error) {
 	sqlQuery := `
-		INSERT INTO products (name, price, quantity, owner_name, created_at) 
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO products (name, price, quantity, owner_id, created_at)
+		SELECT $1, $2, $3, id, $5
+		FROM auth$users
+		WHERE name = $4
 		RETURNING id;
 	`
 
@@ -44,9 +50,33 @@ func (r *ProductsRepository) CreateProduct(tx *sqlx.Tx, product products.Product
 // FindProduct ...
 func (r *ProductsRepository) FindProduct(tx *sqlx.Tx, id uint64) (products.Product, error) {
 	sqlQuery := `
-		SELECT * 
-		FROM products 
-		WHERE id = $1;
+		SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+		FROM products p
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE p.id = $1;
+	`
+
+	var data products.Product
+	err := tx.Get(&data, sqlQuery, id)
+
+	switch err {
+	case sql.ErrNoRows:
+		return products.Product{}, shared.ErrNoData
+	case nil:
+		return data, nil
+	default:
+		return products.Product{}, err
+	}
+}
+
+// FindProductForUpdate finds product and locks it until the end of the transaction
+func (r *ProductsRepository) FindProductForUpdate(tx *sqlx.Tx, id uint64) (products.Product, error) {
+	sqlQuery := `
+		SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+		FROM products p
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE p.id = $1
+		FOR UPDATE OF p;
 	`
 
 	var data products.Product
@@ -65,10 +95,11 @@ func (r *ProductsRepository) FindProduct(tx *sqlx.Tx, id uint64) (products.Produ
 // UpdateProduct ...
 func (r *ProductsRepository) UpdateProduct(tx *sqlx.Tx, newProduct products.Product) (products.Product, error) {
 	sqlQuery := `
-    UPDATE products
-    SET name = $1, price = $2, quantity = $3
-    WHERE id = $4
-    RETURNING *;
+		UPDATE products p
+		SET name = $1, price = $2, quantity = $3
+		FROM auth$users u
+		WHERE p.id = $4 AND u.id = p.owner_id
+		RETURNING p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at;
 	`
 
 	var data products.Product
@@ -99,27 +130,30 @@ func (r *ProductsRepository) DeleteProduct(tx *sqlx.Tx, id uint64) error {
 // FindProductList ...
 func (r *ProductsRepository) FindProductList(tx *sqlx.Tx, username string, productName string, sortBy products.SortType) ([]products.Product, error) {
 	sqlQuery := `
-		SELECT * 
-		FROM products 
-		WHERE owner_name = $1
+		SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+		FROM products p
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE u.name = $1
 	`
 
+	args := []any{username}
 	if productName != "" {
-		sqlQuery += fmt.Sprintf(" AND name = '%s'", productName)
+		args = append(args, productName)
+		sqlQuery += " AND p.name = $2"
 	}
 
 	switch sortBy {
 	case products.Name:
-		sqlQuery += " ORDER BY name"
+		sqlQuery += " ORDER BY p.name"
 	case products.LastCreate:
-		sqlQuery += " ORDER BY created_at DESC"
+		sqlQuery += " ORDER BY p.created_at DESC"
 	default:
 	}
 
 	sqlQuery += ";"
 
 	var data []products.Product
-	err := tx.Select(&data, sqlQuery, username)
+	err := tx.Select(&data, sqlQuery, args...)
 
 	switch err {
 	case sql.ErrNoRows:
@@ -130,3 +164,106 @@ func (r *ProductsRepository) FindProductList(tx *sqlx.Tx, username string, produ
 		return nil, err
 	}
 }
+
+// FindOwnerProducts finds all products of the owner and locks them
+func (r *ProductsRepository) FindOwnerProducts(tx *sqlx.Tx, ownerName string) ([]products.Product, error) {
+	sqlQuery := `
+		SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+		FROM products p
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE u.name = $1
+		ORDER BY p.id
+		FOR UPDATE OF p;
+	`
+
+	var data []products.Product
+	if err := tx.Select(&data, sqlQuery, ownerName); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FindProductPage ...
+func (r *ProductsRepository) FindProductPage(tx *sqlx.Tx, username string, filter products.ProductFilter) (products.ProductPage, error) {
+	sqlFrom := `
+		FROM products p
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE u.name = $1
+	`
+	args := []any{username}
+
+	if filter.Name != "" {
+		args = append(args, "%"+likeReplacer.Replace(filter.Name)+"%")
+		sqlFrom += fmt.Sprintf(" AND p.name ILIKE $%d", len(args))
+	}
+
+	if filter.MinPrice > 0 {
+		args = append(args, filter.MinPrice)
+		sqlFrom += fmt.Sprintf(" AND p.price >= $%d", len(args))
+	}
+
+	if filter.MaxPrice > 0 {
+		args = append(args, filter.MaxPrice)
+		sqlFrom += fmt.Sprintf(" AND p.price <= $%d", len(args))
+	}
+
+	if filter.InStock {
+		sqlFrom += " AND p.quantity > 0"
+	}
+
+	var page products.ProductPage
+	if err := tx.Get(&page.Total, "SELECT COUNT(*)"+sqlFrom, args...); err != nil {
+		return products.ProductPage{}, err
+	}
+
+	sqlQuery := "SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at" + sqlFrom
+
+	// id keeps the order of equal names and times stable between pages
+	switch filter.SortBy {
+	case products.Name:
+		sqlQuery += " ORDER BY p.name, p.id"
+	case products.LastCreate:
+		sqlQuery += " ORDER BY p.created_at DESC, p.id DESC"
+	default:
+		sqlQuery += " ORDER BY p.id"
+	}
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	args = append(args, filter.Offset)
+	sqlQuery += fmt.Sprintf(" OFFSET $%d;", len(args))
+
+	if err := tx.Select(&page.Products, sqlQuery, args...); err != nil {
+		return products.ProductPage{}, err
+	}
+
+	return page, nil
+}
+
+// TransferProducts ...
+func (r *ProductsRepository) TransferProducts(tx *sqlx.Tx, fromName, toName string) error {
+	sqlQuery := `
+		UPDATE products
+		SET owner_id = (SELECT id FROM auth$users WHERE name = $2)
+		WHERE owner_id = (SELECT id FROM auth$users WHERE name = $1);
+	`
+
+	_, err := tx.Exec(sqlQuery, fromName, toName)
+	return err
+}
+
+// DeleteProducts ...
+func (r *ProductsRepository) DeleteProducts(tx *sqlx.Tx, ownerName string) error {
+	sqlQuery := `
+		DELETE
+		FROM products
+		WHERE owner_id = (SELECT id FROM auth$users WHERE name = $1);
+	`
+
+	_, err := tx.Exec(sqlQuery, ownerName)
+	return err
+}
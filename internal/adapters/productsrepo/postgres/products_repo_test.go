@@ -32,24 +32,27 @@ func (s *Suite) SetupTest() {
 	s.repo = postgres.NewProducts()
 }
 
-func createUser(tx *sqlx.Tx, user auth.User) error {
+func createUser(tx *sqlx.Tx, user auth.User) (uint64, error) {
 	sqlQuery := `
 		INSERT INTO auth$users (name, password)
-		VALUES ($1, $2);
+		VALUES ($1, $2)
+		RETURNING id;
 	`
 
-	_, err := tx.Exec(sqlQuery, user.Name, user.Password)
-	return err
+	var id uint64
+	err := tx.QueryRow(sqlQuery, user.Name, user.Password).Scan(&id)
+
+	return id, err
 }
 
 func createProduct(tx *sqlx.Tx, product products.Product) (uint64, error) {
 	sqlQuery := `
-		INSERT INTO products (name, price, quantity, owner_name, created_at) 
+		INSERT INTO products (name, price, quantity, owner_id, created_at)
 		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id;
 	`
 
-	row := tx.QueryRow(sqlQuery, product.Name, product.Price, product.Quantity, product.OwnerName, product.CreatedAt)
+	row := tx.QueryRow(sqlQuery, product.Name, product.Price, product.Quantity, product.OwnerID, product.CreatedAt)
 
 	var id uint64
 	err := row.Scan(&id)
@@ -68,12 +71,7 @@ func (s *Suite) TestCreateProduct() {
 		s.NoError(err)
 		defer tx.Rollback()
 
-		sqlQuery := `
-			INSERT INTO auth$users (name, password)
-			VALUES ($1, $2);
-		`
-
-		_, err = tx.Exec(sqlQuery, mockUser.Name, mockUser.Password)
+		mockProduct.OwnerID, err = createUser(tx, mockUser)
 		s.NoError(err)
 
 		// creating product
@@ -83,9 +81,10 @@ func (s *Suite) TestCreateProduct() {
 
 		s.Run("checking data", func() {
 			sqlQuery := `
-				SELECT * 
-				FROM products 
-				WHERE id = $1;
+				SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+				FROM products p
+				JOIN auth$users u ON u.id = p.owner_id
+				WHERE p.id = $1;
 			`
 
 			var data products.Product
@@ -110,7 +109,7 @@ func (s *Suite) TestFindProduct() {
 		defer tx.Rollback()
 
 		// creating user and product
-		err = createUser(tx, mockUser)
+		mockProduct.OwnerID, err = createUser(tx, mockUser)
 		s.NoError(err)
 
 		mockProduct.ID, err = createProduct(tx, mockProduct)
@@ -131,6 +130,38 @@ func (s *Suite) TestFindProduct() {
 	})
 }
 
+func (s *Suite) TestFindProductForUpdate() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockProduct := products.NewProduct(0, "test product", 42, 42, "test name", now)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// creating user and product
+		mockProduct.OwnerID, err = createUser(tx, mockUser)
+		s.NoError(err)
+
+		mockProduct.ID, err = createProduct(tx, mockProduct)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			// call with non-existent id
+			_, err := s.repo.FindProductForUpdate(tx, 0)
+			s.ErrorIs(err, shared.ErrNoData)
+
+			data, err := s.repo.FindProductForUpdate(tx, mockProduct.ID)
+			s.NoError(err)
+
+			data.CreatedAt = data.CreatedAt.In(time.UTC)
+			s.Equal(mockProduct, data)
+		})
+	})
+}
+
 func (s *Suite) TestUpdateProduct() {
 	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 
@@ -143,7 +174,7 @@ func (s *Suite) TestUpdateProduct() {
 		defer tx.Rollback()
 
 		// creating product and user
-		err = createUser(tx, mockUser)
+		mockProduct.OwnerID, err = createUser(tx, mockUser)
 		s.NoError(err)
 
 		mockProduct.ID, err = createProduct(tx, mockProduct)
@@ -155,6 +186,7 @@ func (s *Suite) TestUpdateProduct() {
 		s.ErrorIs(err, shared.ErrNoData)
 
 		mockUpdatedProduct := products.NewProduct(mockProduct.ID, "test updated product", 43, 43, "test name", now)
+		mockUpdatedProduct.OwnerID = mockProduct.OwnerID
 
 		data, err := s.repo.UpdateProduct(tx, mockUpdatedProduct)
 		s.NoError(err)
@@ -164,9 +196,10 @@ func (s *Suite) TestUpdateProduct() {
 
 		s.Run("checking data", func() {
 			sqlQuery := `
-				SELECT * 
-				FROM products 
-				WHERE id = $1;
+				SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+				FROM products p
+				JOIN auth$users u ON u.id = p.owner_id
+				WHERE p.id = $1;
 			`
 
 			var data products.Product
@@ -191,7 +224,7 @@ func (s *Suite) TestDeleteProduct() {
 		defer tx.Rollback()
 
 		// creating product and user
-		err = createUser(tx, mockUser)
+		mockProduct.OwnerID, err = createUser(tx, mockUser)
 		s.NoError(err)
 
 		mockProduct.ID, err = createProduct(tx, mockProduct)
@@ -199,9 +232,10 @@ func (s *Suite) TestDeleteProduct() {
 		s.NotEqual(0, mockProduct.ID)
 
 		sqlQuery := `
-			SELECT * 
-			FROM products 
-			WHERE id = $1;
+			SELECT p.id, p.name, p.price, p.quantity, p.owner_id, u.name AS owner_name, p.created_at
+			FROM products p
+			JOIN auth$users u ON u.id = p.owner_id
+			WHERE p.id = $1;
 		`
 
 		var data products.Product
@@ -233,9 +267,12 @@ func (s *Suite) TestFindProductList() {
 		defer tx.Rollback()
 
 		// creating product and user
-		err = createUser(tx, mockUser)
+		ownerID, err := createUser(tx, mockUser)
 		s.NoError(err)
 
+		mockProduct1.OwnerID = ownerID
+		mockProduct2.OwnerID = ownerID
+
 		mockProduct1.ID, err = createProduct(tx, mockProduct1)
 		s.NoError(err)
 		s.NotEqual(0, mockProduct1.ID)
@@ -276,3 +313,153 @@ func (s *Suite) TestFindProductList() {
 		})
 	})
 }
+
+func (s *Suite) TestFindProductPage() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockProduct1 := products.NewProduct(0, "test product1", 42, 0, "test name", now)
+	mockProduct2 := products.NewProduct(0, "test product2", 43, 43, "test name", now.Add(5*time.Hour))
+	mockProduct3 := products.NewProduct(0, "test_other", 100, 1, "test name", now.Add(10*time.Hour))
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// creating user and products
+		ownerID, err := createUser(tx, mockUser)
+		s.NoError(err)
+
+		for _, product := range []*products.Product{&mockProduct1, &mockProduct2, &mockProduct3} {
+			product.OwnerID = ownerID
+			product.ID, err = createProduct(tx, *product)
+			s.NoError(err)
+		}
+
+		s.Run("checking data", func() {
+			// first page of all products, sort by time
+			page, err := s.repo.FindProductPage(tx, "test name", products.ProductFilter{SortBy: products.LastCreate, Limit: 2})
+			s.NoError(err)
+			s.Equal(uint64(3), page.Total)
+			s.Require().Len(page.Products, 2)
+			s.Equal(mockProduct3.ID, page.Products[0].ID)
+			s.Equal(mockProduct2.ID, page.Products[1].ID)
+
+			// second page
+			page, err = s.repo.FindProductPage(tx, "test name", products.ProductFilter{SortBy: products.LastCreate, Limit: 2, Offset: 2})
+			s.NoError(err)
+			s.Equal(uint64(3), page.Total)
+			s.Require().Len(page.Products, 1)
+			s.Equal(mockProduct1.ID, page.Products[0].ID)
+
+			// name substring, wildcards are matched literally
+			page, err = s.repo.FindProductPage(tx, "test name", products.ProductFilter{Name: "PRODUCT", SortBy: products.Name})
+			s.NoError(err)
+			s.Equal(uint64(2), page.Total)
+
+			page, err = s.repo.FindProductPage(tx, "test name", products.ProductFilter{Name: "_"})
+			s.NoError(err)
+			s.Equal(uint64(1), page.Total)
+			s.Equal(mockProduct3.ID, page.Products[0].ID)
+
+			// price range and stock
+			page, err = s.repo.FindProductPage(tx, "test name", products.ProductFilter{MinPrice: 43, MaxPrice: 99})
+			s.NoError(err)
+			s.Equal(uint64(1), page.Total)
+			s.Equal(mockProduct2.ID, page.Products[0].ID)
+
+			page, err = s.repo.FindProductPage(tx, "test name", products.ProductFilter{InStock: true})
+			s.NoError(err)
+			s.Equal(uint64(2), page.Total)
+
+			// other user
+			page, err = s.repo.FindProductPage(tx, "test other name", products.ProductFilter{})
+			s.NoError(err)
+			s.Equal(uint64(0), page.Total)
+			s.Empty(page.Products)
+		})
+	})
+}
+
+func (s *Suite) TestTransferProducts() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockReceiver := auth.NewUser("test receiver", "test password")
+	mockProduct := products.NewProduct(0, "test product", 42, 42, "test name", now)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// creating users and product
+		mockProduct.OwnerID, err = createUser(tx, mockUser)
+		s.NoError(err)
+
+		receiverID, err := createUser(tx, mockReceiver)
+		s.NoError(err)
+
+		mockProduct.ID, err = createProduct(tx, mockProduct)
+		s.NoError(err)
+
+		data, err := s.repo.FindOwnerProducts(tx, mockUser.Name)
+		s.NoError(err)
+		s.Len(data, 1)
+		s.Equal(mockProduct.ID, data[0].ID)
+		s.Equal(mockUser.Name, data[0].OwnerName)
+
+		err = s.repo.TransferProducts(tx, mockUser.Name, mockReceiver.Name)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			list, err := s.repo.FindOwnerProducts(tx, mockUser.Name)
+			s.NoError(err)
+			s.Empty(list)
+
+			data, err := s.repo.FindProduct(tx, mockProduct.ID)
+			s.NoError(err)
+			s.Equal(receiverID, data.OwnerID)
+			s.Equal(mockReceiver.Name, data.OwnerName)
+		})
+	})
+}
+
+func (s *Suite) TestDeleteProducts() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUser := auth.NewUser("test name", "test password")
+	mockProduct1 := products.NewProduct(0, "test product1", 42, 42, "test name", now)
+	mockProduct2 := products.NewProduct(0, "test product2", 43, 43, "test name", now)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		// creating user and products
+		ownerID, err := createUser(tx, mockUser)
+		s.NoError(err)
+
+		mockProduct1.OwnerID = ownerID
+		mockProduct2.OwnerID = ownerID
+
+		mockProduct1.ID, err = createProduct(tx, mockProduct1)
+		s.NoError(err)
+
+		mockProduct2.ID, err = createProduct(tx, mockProduct2)
+		s.NoError(err)
+
+		err = s.repo.DeleteProducts(tx, mockUser.Name)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			_, err := s.repo.FindProduct(tx, mockProduct1.ID)
+			s.ErrorIs(err, shared.ErrNoData)
+
+			_, err = s.repo.FindProduct(tx, mockProduct2.ID)
+			s.ErrorIs(err, shared.ErrNoData)
+		})
+	})
+}
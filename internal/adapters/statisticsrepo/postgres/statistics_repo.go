@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+)
+
+// StatisticsRepository ...
+type StatisticsRepository struct{}
+
+// NewStatistics constructor for StatisticsRepository
+func NewStatistics() *StatisticsRepository {
+	return &StatisticsRepository{}
+}
+
+// AddProcessedEvent ...
+func (r *StatisticsRepository) AddProcessedEvent(tx *sqlx.Tx, id string, processedAt time.Time) (bool, error) {
+	sqlQuery := `
+		INSERT INTO statistics$processed_events (id, processed_at)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO NOTHING;
+	`
+
+	res, err := tx.Exec(sqlQuery, id, processedAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteProcessedEvents deletes up to limit events processed before processedBefore, returns the number of deleted events
+func (r *StatisticsRepository) DeleteProcessedEvents(tx *sqlx.Tx, processedBefore time.Time, limit int) (int, error) {
+	sqlQuery := `
+		DELETE FROM statistics$processed_events
+		WHERE id IN (
+		  SELECT id
+		  FROM statistics$processed_events
+		  WHERE processed_at < $1
+		  ORDER BY processed_at
+		  LIMIT $2
+		);
+	`
+
+	res, err := tx.Exec(sqlQuery, processedBefore, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
+// AddViews ...
+func (r *StatisticsRepository) AddViews(tx *sqlx.Tx, productID uint64, period statistics.Period, periodStart time.Time, views uint64) error {
+	sqlQuery := `
+		INSERT INTO statistics$product_views (product_id, period, period_start, views)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, period, period_start) DO UPDATE
+		SET views = statistics$product_views.views + EXCLUDED.views;
+	`
+
+	if _, err := tx.Exec(sqlQuery, productID, period, periodStart, views); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindViews ...
+func (r *StatisticsRepository) FindViews(tx *sqlx.Tx, productID uint64, period statistics.Period, from, to time.Time) ([]statistics.ViewCount, error) {
+	sqlQuery := `
+		SELECT period_start, views
+		FROM statistics$product_views
+		WHERE product_id = $1 AND period = $2 AND period_start >= $3 AND period_start < $4
+		ORDER BY period_start;
+	`
+
+	data := make([]statistics.ViewCount, 0)
+	if err := tx.Select(&data, sqlQuery, productID, period, from, to); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FindTopViewed ...
+func (r *StatisticsRepository) FindTopViewed(tx *sqlx.Tx, ownerName string, from, to time.Time, limit int) ([]statistics.ProductViews, error) {
+	sqlQuery := `
+		SELECT p.id AS product_id, p.name, u.name AS owner_name, SUM(v.views) AS views
+		FROM statistics$product_views v
+		JOIN products p ON p.id = v.product_id
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE v.period = $1 AND v.period_start >= $2 AND v.period_start < $3 AND ($4::VARCHAR = '' OR u.name = $4)
+		GROUP BY p.id, p.name, u.name
+		ORDER BY views DESC, p.id
+		LIMIT $5;
+	`
+
+	data := make([]statistics.ProductViews, 0)
+	if err := tx.Select(&data, sqlQuery, statistics.PeriodHour, from, to, ownerName, limit); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FindProductOwner ...
+func (r *StatisticsRepository) FindProductOwner(tx *sqlx.Tx, productID uint64) (string, error) {
+	sqlQuery := `
+		SELECT u.name
+		FROM products p
+		JOIN auth$users u ON u.id = p.owner_id
+		WHERE p.id = $1;
+	`
+
+	var owner string
+	err := tx.Get(&owner, sqlQuery, productID)
+
+	switch err {
+	case sql.ErrNoRows:
+		return "", shared.ErrNoData
+	case nil:
+		return owner, nil
+	default:
+		return "", err
+	}
+}
@@ -0,0 +1,217 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/statisticsrepo/postgres"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+	"github.com/fallra1n/product-keeper/pkg/access"
+	"github.com/fallra1n/product-keeper/pkg/postgresdb"
+)
+
+type Suite struct {
+	suite.Suite
+	repo *postgres.StatisticsRepository
+	db   *sqlx.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	cfg := config.MustLoad()
+	s.db = postgresdb.NewPostgresDB(access.PostgresTestConnect(cfg), cfg.Postgres.Timeout)
+	s.repo = postgres.NewStatistics()
+}
+
+func createProduct(tx *sqlx.Tx, username, productName string) (uint64, error) {
+	sqlQuery := `
+		WITH u AS (
+			INSERT INTO auth$users (name, password)
+			VALUES ($1, 'test password')
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		)
+		INSERT INTO products (name, price, quantity, owner_id, created_at)
+		SELECT $2, 42, 42, id, NOW()
+		FROM u
+		RETURNING id;
+	`
+
+	var id uint64
+	err := tx.QueryRow(sqlQuery, username, productName).Scan(&id)
+
+	return id, err
+}
+
+func (s *Suite) TestAddProcessedEvent() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		added, err := s.repo.AddProcessedEvent(tx, "test event id", now)
+		s.NoError(err)
+		s.True(added)
+
+		s.Run("checking data", func() {
+			// the same event is processed only once
+			added, err := s.repo.AddProcessedEvent(tx, "test event id", now)
+			s.NoError(err)
+			s.False(added)
+		})
+	})
+}
+
+func (s *Suite) TestDeleteProcessedEvents() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		for _, row := range []struct {
+			id          string
+			processedAt time.Time
+		}{
+			{id: "test event id1", processedAt: now.Add(-2 * time.Hour)},
+			{id: "test event id2", processedAt: now.Add(-time.Hour)},
+			{id: "test event id3", processedAt: now},
+		} {
+			added, err := s.repo.AddProcessedEvent(tx, row.id, row.processedAt)
+			s.NoError(err)
+			s.True(added)
+		}
+
+		s.Run("checking data", func() {
+			// only the oldest event fits the limit
+			deleted, err := s.repo.DeleteProcessedEvents(tx, now, 1)
+			s.NoError(err)
+			s.Equal(1, deleted)
+
+			added, err := s.repo.AddProcessedEvent(tx, "test event id1", now)
+			s.NoError(err)
+			s.True(added)
+
+			// events processed at processedBefore are kept
+			deleted, err = s.repo.DeleteProcessedEvents(tx, now, 10)
+			s.NoError(err)
+			s.Equal(1, deleted)
+
+			added, err = s.repo.AddProcessedEvent(tx, "test event id3", now)
+			s.NoError(err)
+			s.False(added)
+		})
+	})
+}
+
+func (s *Suite) TestFindViews() {
+	hour := time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		productID, err := createProduct(tx, "test name", "test product")
+		s.NoError(err)
+
+		s.NoError(s.repo.AddViews(tx, productID, statistics.PeriodHour, hour, 1))
+		s.NoError(s.repo.AddViews(tx, productID, statistics.PeriodHour, hour, 2))
+		s.NoError(s.repo.AddViews(tx, productID, statistics.PeriodHour, hour.Add(time.Hour), 1))
+		s.NoError(s.repo.AddViews(tx, productID, statistics.PeriodDay, hour.Truncate(24*time.Hour), 4))
+
+		s.Run("checking data", func() {
+			data, err := s.repo.FindViews(tx, productID, statistics.PeriodHour, hour, hour.Add(2*time.Hour))
+			s.NoError(err)
+			s.Len(data, 2)
+			s.Equal(uint64(3), data[0].Views)
+			s.Equal(hour, data[0].PeriodStart.In(time.UTC))
+			s.Equal(uint64(1), data[1].Views)
+
+			// to is excluded
+			data, err = s.repo.FindViews(tx, productID, statistics.PeriodHour, hour, hour.Add(time.Hour))
+			s.NoError(err)
+			s.Len(data, 1)
+
+			data, err = s.repo.FindViews(tx, productID, statistics.PeriodDay, hour.Truncate(24*time.Hour), hour.Add(24*time.Hour))
+			s.NoError(err)
+			s.Len(data, 1)
+			s.Equal(uint64(4), data[0].Views)
+
+			// no views is not an error
+			data, err = s.repo.FindViews(tx, productID+1, statistics.PeriodHour, hour, hour.Add(time.Hour))
+			s.NoError(err)
+			s.Empty(data)
+		})
+	})
+}
+
+func (s *Suite) TestFindTopViewed() {
+	hour := time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		firstID, err := createProduct(tx, "test name", "test product1")
+		s.NoError(err)
+
+		secondID, err := createProduct(tx, "other name", "test product2")
+		s.NoError(err)
+
+		s.NoError(s.repo.AddViews(tx, firstID, statistics.PeriodHour, hour, 1))
+		s.NoError(s.repo.AddViews(tx, secondID, statistics.PeriodHour, hour, 2))
+		s.NoError(s.repo.AddViews(tx, secondID, statistics.PeriodHour, hour.Add(time.Hour), 2))
+
+		s.Run("checking data", func() {
+			data, err := s.repo.FindTopViewed(tx, "", hour, hour.Add(2*time.Hour), 10)
+			s.NoError(err)
+			s.Equal([]statistics.ProductViews{
+				{ProductID: secondID, Name: "test product2", OwnerName: "other name", Views: 4},
+				{ProductID: firstID, Name: "test product1", OwnerName: "test name", Views: 1},
+			}, data)
+
+			data, err = s.repo.FindTopViewed(tx, "test name", hour, hour.Add(2*time.Hour), 10)
+			s.NoError(err)
+			s.Equal([]statistics.ProductViews{
+				{ProductID: firstID, Name: "test product1", OwnerName: "test name", Views: 1},
+			}, data)
+
+			data, err = s.repo.FindTopViewed(tx, "", hour, hour.Add(2*time.Hour), 1)
+			s.NoError(err)
+			s.Len(data, 1)
+		})
+	})
+}
+
+func (s *Suite) TestFindProductOwner() {
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		productID, err := createProduct(tx, "test name", "test product")
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			owner, err := s.repo.FindProductOwner(tx, productID)
+			s.NoError(err)
+			s.Equal("test name", owner)
+
+			_, err = s.repo.FindProductOwner(tx, productID+1)
+			s.ErrorIs(err, shared.ErrNoData)
+		})
+	})
+}
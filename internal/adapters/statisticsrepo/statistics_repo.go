@@ -0,0 +1,10 @@
+package statisticsrepo
+
+import (
+	"github.com/fallra1n/product-keeper/internal/adapters/statisticsrepo/postgres"
+)
+
+// NewPostgresStatistics ...
+func NewPostgresStatistics() *postgres.StatisticsRepository {
+	return postgres.NewStatistics()
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+)
+
+const (
+	// HeaderEvent type of the delivered event
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderDelivery id of the delivery, the same for all attempts
+	HeaderDelivery = "X-Webhook-Delivery"
+	// HeaderTimestamp unix time of the attempt, receivers should reject old timestamps
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature hmac-sha256 signature of timestamp and body
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+
+	// maxResponseSize part of response body read before closing the connection
+	maxResponseSize = 4 << 10
+)
+
+// ErrForbiddenAddress webhook host resolves to loopback, private, link-local or unspecified address
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// WebhookSender sends deliveries with http post requests
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender constructor for WebhookSender, redirects are not followed.
+// Unless allowPrivateNetworks is set, connections to internal addresses are refused after dns resolution,
+// so webhooks cannot be used to reach services inside the network
+func NewWebhookSender(timeout time.Duration, allowPrivateNetworks bool) *WebhookSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = checkAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to the target instead of the checked dialer
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts delivery payload signed with the secret and returns response status code
+func (s *WebhookSender) Send(url string, secret string, delivery webhooks.Delivery, sentAt time.Time) (int, error) {
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Signature(secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// the resolved address is not reported to the webhook owner
+		if errors.Is(err, ErrForbiddenAddress) {
+			return 0, ErrForbiddenAddress
+		}
+
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// the body is read to reuse the connection
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	return resp.StatusCode, nil
+}
+
+// Signature signature of the request: hex encoded hmac-sha256 of "<timestamp>.<body>" with the webhook secret
+func Signature(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkAddress dialer control rejecting internal addresses, it is called with the resolved ip address
+func checkAddress(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return ErrForbiddenAddress
+	}
+
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package http_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	httpsender "github.com/fallra1n/product-keeper/internal/adapters/webhooksender/http"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+)
+
+type request struct {
+	header nethttp.Header
+	body   []byte
+}
+
+type Suite struct {
+	suite.Suite
+	sender *httpsender.WebhookSender
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	// receivers are httptest servers on loopback
+	s.sender = httpsender.NewWebhookSender(time.Second, true)
+}
+
+// receiver httptest server responding with status and passing received requests to the channel
+func receiver(status int) (*httptest.Server, chan request) {
+	requests := make(chan request, 1)
+
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+
+	return srv, requests
+}
+
+func (s *Suite) TestSend() {
+	srv, requests := receiver(nethttp.StatusNoContent)
+	defer srv.Close()
+
+	sentAt := time.Unix(946684800, 0)
+	delivery := webhooks.Delivery{ID: 42, EventType: products.EventCreated, Payload: []byte(`{"id":"test event id"}`)}
+
+	status, err := s.sender.Send(srv.URL, "test secret", delivery, sentAt)
+	s.NoError(err)
+	s.Equal(nethttp.StatusNoContent, status)
+
+	req := <-requests
+	s.Equal(delivery.Payload, req.body)
+	s.Equal("application/json", req.header.Get("Content-Type"))
+	s.Equal("product.created", req.header.Get(httpsender.HeaderEvent))
+	s.Equal("42", req.header.Get(httpsender.HeaderDelivery))
+	s.Equal("946684800", req.header.Get(httpsender.HeaderTimestamp))
+
+	// receivers verify the signature with the shared secret
+	mac := hmac.New(sha256.New, []byte("test secret"))
+	mac.Write([]byte(`946684800.{"id":"test event id"}`))
+	s.Equal("sha256="+hex.EncodeToString(mac.Sum(nil)), req.header.Get(httpsender.HeaderSignature))
+}
+
+func (s *Suite) TestErrorStatus() {
+	srv, requests := receiver(nethttp.StatusInternalServerError)
+	defer srv.Close()
+
+	status, err := s.sender.Send(srv.URL, "test secret", webhooks.Delivery{ID: 1}, time.Now())
+	s.NoError(err)
+	s.Equal(nethttp.StatusInternalServerError, status)
+	<-requests
+}
+
+func (s *Suite) TestRedirectIsNotFollowed() {
+	target, requests := receiver(nethttp.StatusOK)
+	defer target.Close()
+
+	srv := httptest.NewServer(nethttp.RedirectHandler(target.URL, nethttp.StatusFound))
+	defer srv.Close()
+
+	status, err := s.sender.Send(srv.URL, "test secret", webhooks.Delivery{ID: 1}, time.Now())
+	s.NoError(err)
+	s.Equal(nethttp.StatusFound, status)
+	s.Empty(requests)
+}
+
+func (s *Suite) TestUnavailableReceiver() {
+	srv, _ := receiver(nethttp.StatusOK)
+	srv.Close()
+
+	status, err := s.sender.Send(srv.URL, "test secret", webhooks.Delivery{ID: 1}, time.Now())
+	s.Error(err)
+	s.Zero(status)
+}
+
+func (s *Suite) TestTimeout() {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	sender := httpsender.NewWebhookSender(50*time.Millisecond, true)
+	_, err := sender.Send(srv.URL, "test secret", webhooks.Delivery{ID: 1}, time.Now())
+	s.Error(err)
+}
+
+func (s *Suite) TestInternalAddressIsRefused() {
+	srv, requests := receiver(nethttp.StatusOK)
+	defer srv.Close()
+
+	sender := httpsender.NewWebhookSender(time.Second, false)
+
+	urls := []string{
+		srv.URL,
+		// the name is resolved to loopback address
+		strings.Replace(srv.URL, "127.0.0.1", "localhost", 1),
+		"http://10.0.0.1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://0.0.0.0/",
+		"http://[::ffff:127.0.0.1]/",
+	}
+
+	for _, url := range urls {
+		status, err := sender.Send(url, "test secret", webhooks.Delivery{ID: 1}, time.Now())
+		s.ErrorIs(err, httpsender.ErrForbiddenAddress)
+		s.Equal(httpsender.ErrForbiddenAddress.Error(), err.Error())
+		s.Zero(status)
+	}
+
+	s.Empty(requests)
+}
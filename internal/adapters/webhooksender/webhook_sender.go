@@ -0,0 +1,12 @@
+package webhooksender
+
+import (
+	"time"
+
+	httpsender "github.com/fallra1n/product-keeper/internal/adapters/webhooksender/http"
+)
+
+// NewHTTPWebhookSender ...
+func NewHTTPWebhookSender(timeout time.Duration, allowPrivateNetworks bool) *httpsender.WebhookSender {
+	return httpsender.NewWebhookSender(timeout, allowPrivateNetworks)
+}
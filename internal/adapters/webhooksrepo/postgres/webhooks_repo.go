@@ -0,0 +1,283 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+)
+
+// WebhooksRepository ...
+type WebhooksRepository struct{}
+
+// NewWebhooks constructor for WebhooksRepository
+func NewWebhooks() *WebhooksRepository {
+	return &WebhooksRepository{}
+}
+
+type webhookRow struct {
+	ID        uint64         `db:"id"`
+	OwnerName string         `db:"owner_name"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"`
+	Events    pq.StringArray `db:"events"`
+	Active    bool           `db:"active"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (r webhookRow) toWebhook() webhooks.Webhook {
+	events := make([]products.EventType, len(r.Events))
+	for i, event := range r.Events {
+		events[i] = products.EventType(event)
+	}
+
+	return webhooks.Webhook{
+		ID:        r.ID,
+		OwnerName: r.OwnerName,
+		URL:       r.URL,
+		Secret:    r.Secret,
+		Events:    events,
+		Active:    r.Active,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func eventsArray(events []products.EventType) any {
+	data := make([]string, len(events))
+	for i, event := range events {
+		data[i] = string(event)
+	}
+
+	return pq.Array(data)
+}
+
+// CreateWebhook ...
+func (r *WebhooksRepository) CreateWebhook(tx *sqlx.Tx, webhook webhooks.Webhook) (uint64, error) {
+	sqlQuery := `
+		INSERT INTO webhooks (owner_name, url, secret, events, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+
+	var id uint64
+	err := tx.QueryRow(sqlQuery, webhook.OwnerName, webhook.URL, webhook.Secret, eventsArray(webhook.Events), webhook.Active, webhook.CreatedAt).Scan(&id)
+
+	return id, err
+}
+
+// FindWebhook ...
+func (r *WebhooksRepository) FindWebhook(tx *sqlx.Tx, id uint64, ownerName string) (webhooks.Webhook, error) {
+	sqlQuery := `
+		SELECT *
+		FROM webhooks
+		WHERE id = $1 AND owner_name = $2;
+	`
+
+	var row webhookRow
+	err := tx.Get(&row, sqlQuery, id, ownerName)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return webhooks.Webhook{}, shared.ErrNoData
+	case err == nil:
+		return row.toWebhook(), nil
+	default:
+		return webhooks.Webhook{}, err
+	}
+}
+
+// FindWebhookList ...
+func (r *WebhooksRepository) FindWebhookList(tx *sqlx.Tx, ownerName string) ([]webhooks.Webhook, error) {
+	sqlQuery := `
+		SELECT *
+		FROM webhooks
+		WHERE owner_name = $1
+		ORDER BY id;
+	`
+
+	return r.selectWebhooks(tx, sqlQuery, ownerName)
+}
+
+// UpdateWebhook ...
+func (r *WebhooksRepository) UpdateWebhook(tx *sqlx.Tx, webhook webhooks.Webhook) error {
+	sqlQuery := `
+		UPDATE webhooks
+		SET url = $3, events = $4, active = $5
+		WHERE id = $1 AND owner_name = $2;
+	`
+
+	return execAffected(tx, sqlQuery, webhook.ID, webhook.OwnerName, webhook.URL, eventsArray(webhook.Events), webhook.Active)
+}
+
+// DeleteWebhook deletes webhook, its deliveries are deleted by cascade
+func (r *WebhooksRepository) DeleteWebhook(tx *sqlx.Tx, id uint64, ownerName string) error {
+	sqlQuery := `
+		DELETE
+		FROM webhooks
+		WHERE id = $1 AND owner_name = $2;
+	`
+
+	return execAffected(tx, sqlQuery, id, ownerName)
+}
+
+// FindSubscribedWebhooks active webhooks of the user subscribed to the event
+func (r *WebhooksRepository) FindSubscribedWebhooks(tx *sqlx.Tx, ownerName string, eventType products.EventType) ([]webhooks.Webhook, error) {
+	sqlQuery := `
+		SELECT *
+		FROM webhooks
+		WHERE owner_name = $1 AND active AND $2 = ANY (events)
+		ORDER BY id;
+	`
+
+	return r.selectWebhooks(tx, sqlQuery, ownerName, eventType)
+}
+
+func (r *WebhooksRepository) selectWebhooks(tx *sqlx.Tx, sqlQuery string, args ...any) ([]webhooks.Webhook, error) {
+	var rows []webhookRow
+	if err := tx.Select(&rows, sqlQuery, args...); err != nil {
+		return nil, err
+	}
+
+	data := make([]webhooks.Webhook, len(rows))
+	for i, row := range rows {
+		data[i] = row.toWebhook()
+	}
+
+	return data, nil
+}
+
+// AddDelivery ...
+func (r *WebhooksRepository) AddDelivery(tx *sqlx.Tx, delivery webhooks.Delivery) error {
+	sqlQuery := `
+		INSERT INTO webhooks$deliveries (webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+
+	_, err := tx.Exec(sqlQuery, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.CreatedAt)
+
+	return err
+}
+
+// FindPendingDeliveries finds due deliveries of active webhooks and locks them, deliveries locked by another worker are skipped
+func (r *WebhooksRepository) FindPendingDeliveries(tx *sqlx.Tx, now time.Time, limit int) ([]webhooks.PendingDelivery, error) {
+	sqlQuery := `
+		SELECT d.*, w.url, w.secret
+		FROM webhooks$deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = $1 AND d.next_attempt_at <= $2 AND w.active
+		ORDER BY d.id
+		LIMIT $3
+		FOR UPDATE OF d SKIP LOCKED;
+	`
+
+	data := make([]webhooks.PendingDelivery, 0)
+	if err := tx.Select(&data, sqlQuery, webhooks.DeliveryPending, now, limit); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FindDeliveryList the last deliveries of the webhook
+func (r *WebhooksRepository) FindDeliveryList(tx *sqlx.Tx, webhookID uint64, limit int) ([]webhooks.Delivery, error) {
+	sqlQuery := `
+		SELECT *
+		FROM webhooks$deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2;
+	`
+
+	data := make([]webhooks.Delivery, 0)
+	if err := tx.Select(&data, sqlQuery, webhookID, limit); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// FindDelivery ...
+func (r *WebhooksRepository) FindDelivery(tx *sqlx.Tx, id uint64, webhookID uint64) (webhooks.Delivery, error) {
+	sqlQuery := `
+		SELECT *
+		FROM webhooks$deliveries
+		WHERE id = $1 AND webhook_id = $2;
+	`
+
+	var delivery webhooks.Delivery
+	err := tx.Get(&delivery, sqlQuery, id, webhookID)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return webhooks.Delivery{}, shared.ErrNoData
+	case err == nil:
+		return delivery, nil
+	default:
+		return webhooks.Delivery{}, err
+	}
+}
+
+// UpdateDelivery saves the result of the attempt
+func (r *WebhooksRepository) UpdateDelivery(tx *sqlx.Tx, delivery webhooks.Delivery) error {
+	sqlQuery := `
+		UPDATE webhooks$deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, delivered_at = $6
+		WHERE id = $1;
+	`
+
+	return execAffected(tx, sqlQuery, delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.DeliveredAt)
+}
+
+// AddAttempt ...
+func (r *WebhooksRepository) AddAttempt(tx *sqlx.Tx, attempt webhooks.Attempt) error {
+	sqlQuery := `
+		INSERT INTO webhooks$delivery_attempts (delivery_id, attempted_at, status_code, error)
+		VALUES ($1, $2, $3, $4);
+	`
+
+	_, err := tx.Exec(sqlQuery, attempt.DeliveryID, attempt.AttemptedAt, attempt.StatusCode, attempt.Error)
+
+	return err
+}
+
+// FindAttempts attempts of the delivery in order
+func (r *WebhooksRepository) FindAttempts(tx *sqlx.Tx, deliveryID uint64) ([]webhooks.Attempt, error) {
+	sqlQuery := `
+		SELECT delivery_id, attempted_at, status_code, error
+		FROM webhooks$delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id;
+	`
+
+	data := make([]webhooks.Attempt, 0)
+	if err := tx.Select(&data, sqlQuery, deliveryID); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func execAffected(tx *sqlx.Tx, sqlQuery string, args ...any) error {
+	res, err := tx.Exec(sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return shared.ErrNoData
+	}
+
+	return nil
+}
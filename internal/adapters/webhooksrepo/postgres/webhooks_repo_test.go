@@ -0,0 +1,178 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/webhooksrepo/postgres"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+	"github.com/fallra1n/product-keeper/pkg/access"
+	"github.com/fallra1n/product-keeper/pkg/postgresdb"
+)
+
+type Suite struct {
+	suite.Suite
+	repo *postgres.WebhooksRepository
+	db   *sqlx.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	cfg := config.MustLoad()
+	s.db = postgresdb.NewPostgresDB(access.PostgresTestConnect(cfg), cfg.Postgres.Timeout)
+	s.repo = postgres.NewWebhooks()
+}
+
+func createUser(tx *sqlx.Tx, username string) error {
+	sqlQuery := `
+		INSERT INTO auth$users (name, password)
+		VALUES ($1, 'test password');
+	`
+
+	_, err := tx.Exec(sqlQuery, username)
+	return err
+}
+
+func (s *Suite) TestWebhooks() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		s.NoError(createUser(tx, "test name"))
+
+		webhook := webhooks.Webhook{
+			OwnerName: "test name",
+			URL:       "https://example.com/hook",
+			Secret:    "test secret",
+			Events:    []products.EventType{products.EventCreated, products.EventDeleted},
+			Active:    true,
+			CreatedAt: now,
+		}
+
+		webhook.ID, err = s.repo.CreateWebhook(tx, webhook)
+		s.NoError(err)
+
+		s.Run("checking data", func() {
+			data, err := s.repo.FindWebhook(tx, webhook.ID, "test name")
+			s.NoError(err)
+			s.Equal(webhook.Events, data.Events)
+			s.Equal("test secret", data.Secret)
+
+			// webhooks of other users are not found
+			_, err = s.repo.FindWebhook(tx, webhook.ID, "other name")
+			s.ErrorIs(err, shared.ErrNoData)
+
+			list, err := s.repo.FindWebhookList(tx, "test name")
+			s.NoError(err)
+			s.Len(list, 1)
+
+			subscribed, err := s.repo.FindSubscribedWebhooks(tx, "test name", products.EventDeleted)
+			s.NoError(err)
+			s.Len(subscribed, 1)
+
+			subscribed, err = s.repo.FindSubscribedWebhooks(tx, "test name", products.EventUpdated)
+			s.NoError(err)
+			s.Empty(subscribed)
+
+			// inactive webhooks are not subscribed
+			webhook.Active = false
+			s.NoError(s.repo.UpdateWebhook(tx, webhook))
+
+			subscribed, err = s.repo.FindSubscribedWebhooks(tx, "test name", products.EventDeleted)
+			s.NoError(err)
+			s.Empty(subscribed)
+
+			s.NoError(s.repo.DeleteWebhook(tx, webhook.ID, "test name"))
+			s.ErrorIs(s.repo.DeleteWebhook(tx, webhook.ID, "test name"), shared.ErrNoData)
+		})
+	})
+}
+
+func (s *Suite) TestDeliveries() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Run("preparing data", func() {
+		tx, err := s.db.Beginx()
+		s.NoError(err)
+		defer tx.Rollback()
+
+		s.NoError(createUser(tx, "test name"))
+
+		webhookID, err := s.repo.CreateWebhook(tx, webhooks.Webhook{
+			OwnerName: "test name",
+			URL:       "https://example.com/hook",
+			Secret:    "test secret",
+			Events:    []products.EventType{products.EventCreated},
+			Active:    true,
+			CreatedAt: now,
+		})
+		s.NoError(err)
+
+		s.NoError(s.repo.AddDelivery(tx, webhooks.Delivery{
+			WebhookID:     webhookID,
+			EventID:       "test event id",
+			EventType:     products.EventCreated,
+			Payload:       []byte("test payload"),
+			Status:        webhooks.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}))
+
+		s.Run("checking data", func() {
+			// not due yet
+			pending, err := s.repo.FindPendingDeliveries(tx, now.Add(-time.Second), 10)
+			s.NoError(err)
+			s.Empty(pending)
+
+			pending, err = s.repo.FindPendingDeliveries(tx, now, 10)
+			s.NoError(err)
+			s.Len(pending, 1)
+			s.Equal("https://example.com/hook", pending[0].URL)
+			s.Equal("test secret", pending[0].Secret)
+			s.Equal([]byte("test payload"), pending[0].Payload)
+
+			delivery := pending[0].Delivery
+			delivery.Attempts = 1
+			delivery.Status = webhooks.DeliveryFailed
+			delivery.LastError = "test error"
+			s.NoError(s.repo.UpdateDelivery(tx, delivery))
+			s.NoError(s.repo.AddAttempt(tx, webhooks.Attempt{DeliveryID: delivery.ID, AttemptedAt: now, StatusCode: 500, Error: "test error"}))
+
+			// failed deliveries are not pending
+			pending, err = s.repo.FindPendingDeliveries(tx, now, 10)
+			s.NoError(err)
+			s.Empty(pending)
+
+			data, err := s.repo.FindDelivery(tx, delivery.ID, webhookID)
+			s.NoError(err)
+			s.Equal(webhooks.DeliveryFailed, data.Status)
+			s.Equal("test error", data.LastError)
+
+			_, err = s.repo.FindDelivery(tx, delivery.ID, webhookID+1)
+			s.ErrorIs(err, shared.ErrNoData)
+
+			list, err := s.repo.FindDeliveryList(tx, webhookID, 10)
+			s.NoError(err)
+			s.Len(list, 1)
+
+			attempts, err := s.repo.FindAttempts(tx, delivery.ID)
+			s.NoError(err)
+			s.Len(attempts, 1)
+			s.Equal(500, attempts[0].StatusCode)
+			s.Equal("test error", attempts[0].Error)
+			s.Equal(now, attempts[0].AttemptedAt.In(time.UTC))
+		})
+	})
+}
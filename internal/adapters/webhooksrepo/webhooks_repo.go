@@ -0,0 +1,10 @@
+package webhooksrepo
+
+import (
+	"github.com/fallra1n/product-keeper/internal/adapters/webhooksrepo/postgres"
+)
+
+// NewPostgresWebhooks ...
+func NewPostgresWebhooks() *postgres.WebhooksRepository {
+	return postgres.NewWebhooks()
+}
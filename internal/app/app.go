@@ -4,56 +4,113 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"time"
 
-	"github.com/IBM/sarama"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/authorizer"
 	"github.com/fallra1n/product-keeper/internal/adapters/authrepo"
+	"github.com/fallra1n/product-keeper/internal/adapters/breachedpasswords"
+	"github.com/fallra1n/product-keeper/internal/adapters/eventbus"
+	"github.com/fallra1n/product-keeper/internal/adapters/identityproviders"
+	"github.com/fallra1n/product-keeper/internal/adapters/notifier"
+	outboxadapter "github.com/fallra1n/product-keeper/internal/adapters/outbox"
 	productsstatistics "github.com/fallra1n/product-keeper/internal/adapters/products-statistics"
 	"github.com/fallra1n/product-keeper/internal/adapters/productsrepo"
+	"github.com/fallra1n/product-keeper/internal/adapters/statisticsrepo"
+	"github.com/fallra1n/product-keeper/internal/adapters/webhooksender"
+	"github.com/fallra1n/product-keeper/internal/adapters/webhooksrepo"
 	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/outbox"
 	"github.com/fallra1n/product-keeper/internal/core/products"
 	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+	grpchandler "github.com/fallra1n/product-keeper/internal/handler/grpc"
+	authgrpchandler "github.com/fallra1n/product-keeper/internal/handler/grpc/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/interceptor"
+	productsgrpchandler "github.com/fallra1n/product-keeper/internal/handler/grpc/products"
 	httphandler "github.com/fallra1n/product-keeper/internal/handler/http"
+	adminhttphandler "github.com/fallra1n/product-keeper/internal/handler/http/admin"
 	authhttphandler "github.com/fallra1n/product-keeper/internal/handler/http/auth"
+	graphqlhttphandler "github.com/fallra1n/product-keeper/internal/handler/http/graphql"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
 	productshttphandler "github.com/fallra1n/product-keeper/internal/handler/http/products"
+	statisticshttphandler "github.com/fallra1n/product-keeper/internal/handler/http/statistics"
+	webhookshttphandler "github.com/fallra1n/product-keeper/internal/handler/http/webhooks"
 	"github.com/fallra1n/product-keeper/pkg/access"
+	productkeeperv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/v1"
 	"github.com/fallra1n/product-keeper/pkg/crypto"
 	"github.com/fallra1n/product-keeper/pkg/datefunctions"
+	"github.com/fallra1n/product-keeper/pkg/ids"
 	"github.com/fallra1n/product-keeper/pkg/jwt"
 	"github.com/fallra1n/product-keeper/pkg/kafka"
 	"github.com/fallra1n/product-keeper/pkg/logging"
 	"github.com/fallra1n/product-keeper/pkg/postgresdb"
+	"github.com/fallra1n/product-keeper/pkg/signer"
+	"github.com/fallra1n/product-keeper/pkg/totp"
 )
 
 // App application
 type App struct {
-	cfg               *config.Config
-	log               *slog.Logger
-	db                *sqlx.DB
-	kafkaSyncProducer sarama.SyncProducer
-	crypto            shared.Crypto
-	jwt               shared.Jwt
-	date              shared.DateTool
+	cfg        *config.Config
+	log        *slog.Logger
+	db         *sqlx.DB
+	crypto     shared.Crypto
+	jwt        shared.Jwt
+	date       shared.DateTool
+	ids        shared.IDGenerator
+	totp       shared.TOTP
+	notifier   shared.Notifier
+	signer     shared.Signer
+	authorizer shared.Authorizer
 
 	authRepo           auth.AuthRepo
+	identityProviders  auth.IdentityProviders
+	breachedPasswords  auth.BreachedPasswords
+	productsOwnership  auth.ProductsOwnership
+	outboxRepo         outbox.OutboxRepo
+	eventBus           eventbus.EventBus
 	productsRepo       products.ProductsRepo
 	productsStatistics products.ProductsStatistics
+	statisticsRepo     statistics.StatisticsRepo
+	webhooksRepo       webhooks.WebhooksRepo
+	webhookSender      webhooks.WebhookSender
 
-	authService     *auth.AuthService
-	productsService *products.ProductsService
+	authService       *auth.AuthService
+	productsService   *products.ProductsService
+	outboxService     *outbox.OutboxService
+	statisticsService *statistics.StatisticsService
+	webhooksService   *webhooks.WebhooksService
 
-	authHandler     httphandler.AuthHandler
-	productsHandler httphandler.ProductsHandler
+	authHandler       httphandler.AuthHandler
+	productsHandler   httphandler.ProductsHandler
+	statisticsHandler httphandler.StatisticsHandler
+	webhooksHandler   httphandler.WebhooksHandler
+	adminHandler      httphandler.AdminHandler
+	graphqlHandler    httphandler.GraphQLHandler
 
-	httpServer *http.Server
+	authGRPCHandler     productkeeperv1.AuthServiceServer
+	productsGRPCHandler productkeeperv1.ProductServiceServer
+
+	httpServer     *http.Server
+	grpcServer     *grpc.Server
+	outboxRelay    *batchWorker
+	outboxCleanup  *batchWorker
+	webhooksWorker *batchWorker
+
+	// closed after the http server, e.g. producers flushing buffered messages
+	closers []io.Closer
 }
 
 // NewApp creating new app
@@ -63,32 +120,169 @@ func NewApp() (*App, error) {
 	}
 
 	cfg := config.MustLoad()
+	logger := logging.SetupLogger(cfg.Env)
+
+	tokens, err := jwt.NewJwt(cfg.Jwt)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot load jwt keys: %s", err))
+		return nil, err
+	}
+
+	hasher, err := crypto.NewCrypto(cfg.PasswordHash)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot create password hasher: %s", err))
+		return nil, err
+	}
+
+	notifications, err := notifier.NewNotifier(logger, cfg.Notifier)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot create notifier: %s", err))
+		return nil, err
+	}
+
+	verificationSigner, err := signer.NewSigner(cfg.Verification.Secret)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot create email verification signer: %s", err))
+		return nil, err
+	}
+
+	breached, err := breachedpasswords.NewFileBreachedPasswords(cfg.Password.BreachedListPath)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot load breached passwords: %s", err))
+		return nil, err
+	}
+
+	productsRepository := productsrepo.NewPostgresProducts()
+	outboxRepository := outboxadapter.NewPostgresOutbox()
 
 	a := &App{
-		cfg:               cfg,
-		log:               logging.SetupLogger(cfg.Env),
-		db:                postgresdb.NewPostgresDB(access.PostgresConnect(cfg), cfg.Postgres.Timeout),
-		kafkaSyncProducer: kafka.NewSyncProducer(access.KafkaConnect(cfg)),
-		crypto:            crypto.NewCrypto(),
-		jwt:               jwt.NewJwt(),
-		date:              datefunctions.NewDateTool(),
+		cfg:        cfg,
+		log:        logger,
+		db:         postgresdb.NewPostgresDB(access.PostgresConnect(cfg), cfg.Postgres.Timeout),
+		crypto:     hasher,
+		jwt:        tokens,
+		date:       datefunctions.NewDateTool(),
+		ids:        ids.NewGenerator(),
+		totp:       totp.NewTOTP(cfg.TOTP.Issuer),
+		notifier:   notifications,
+		signer:     verificationSigner,
+		authorizer: authorizer.NewPolicyAuthorizer(cfg.Policies),
+
+		outboxRepo:     outboxRepository,
+		productsRepo:   productsRepository,
+		statisticsRepo: statisticsrepo.NewPostgresStatistics(),
+		webhooksRepo:   webhooksrepo.NewPostgresWebhooks(),
+		webhookSender:  webhooksender.NewHTTPWebhookSender(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateNetworks),
+		authRepo:       authrepo.NewPostgresAuth(),
 
-		productsRepo: productsrepo.NewPostgresProducts(),
-		authRepo:     authrepo.NewPostgresAuth(),
+		identityProviders: identityproviders.NewOIDCProviders(cfg.OIDCProviders),
+		breachedPasswords: breached,
 	}
 
-	a.productsStatistics = productsstatistics.NewKafkaProducts(a.kafkaSyncProducer)
+	if cfg.EventBus.Kind == config.EventBusKafka && cfg.Provisioning.Enabled {
+		topics := []string{cfg.Topics.ProductViews, cfg.Topics.ProductChanges}
+		if err := kafka.EnsureTopics(access.KafkaConnect(cfg), cfg.KafkaCluster, topics); err != nil {
+			logger.Error(fmt.Sprintf("cannot provision kafka topics: %s", err))
+			return nil, err
+		}
+	}
+
+	bus, err := eventbus.NewEventBus(logger, cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot create event bus: %s", err))
+		return nil, err
+	}
+
+	a.eventBus = bus
+
+	switch cfg.Statistics.Delivery {
+	case config.StatisticsOutbox:
+		a.productsStatistics = productsstatistics.NewOutboxProducts(outboxRepository, cfg.Topics)
+	case config.StatisticsAsync:
+		if cfg.EventBus.Kind != config.EventBusKafka {
+			err := fmt.Errorf("async statistics delivery requires kafka event bus, got %q", cfg.EventBus.Kind)
+			logger.Error(err.Error())
+			return nil, err
+		}
+
+		producer, err := kafka.NewAsyncProducer(access.KafkaConnect(cfg), cfg.KafkaCluster)
+		if err != nil {
+			logger.Error(fmt.Sprintf("cannot create statistics producer: %s", err))
+			return nil, err
+		}
+
+		statistics, err := productsstatistics.NewAsyncProducts(logger, producer, outboxRepository, cfg.Statistics, cfg.Topics)
+		if err != nil {
+			logger.Error(fmt.Sprintf("cannot create statistics producer: %s", err))
+			return nil, err
+		}
+
+		a.productsStatistics = statistics
+		a.closers = append(a.closers, statistics)
+	default:
+		err := fmt.Errorf("unknown statistics delivery %q", cfg.Statistics.Delivery)
+		logger.Error(err.Error())
+		return nil, err
+	}
 
 	// services init
-	a.authService = auth.NewAuthService(a.log, a.crypto, a.jwt, a.authRepo)
-	a.productsService = products.NewProductsService(a.log, a.date, a.productsRepo, a.productsStatistics)
+	a.webhooksService = webhooks.NewWebhooksService(a.log, a.date, a.crypto, a.webhooksRepo, a.webhookSender, webhooks.Settings{
+		BatchSize:   cfg.Webhooks.BatchSize,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseDelay:   cfg.Webhooks.BaseDelay,
+		MaxDelay:    cfg.Webhooks.MaxDelay,
+		Timeout:     cfg.Webhooks.Timeout,
+	})
+	a.productsService = products.NewProductsService(a.log, a.date, a.ids, a.authorizer, a.productsRepo, a.productsStatistics, a.webhooksService)
+	a.productsOwnership = a.productsService
+	a.authService = auth.NewAuthService(
+		a.log,
+		a.crypto,
+		a.jwt,
+		a.date,
+		a.totp,
+		a.notifier,
+		a.signer,
+		a.authRepo,
+		a.identityProviders,
+		a.breachedPasswords,
+		a.productsOwnership,
+		authSettings(cfg),
+	)
+	a.outboxService = outbox.NewOutboxService(a.log, a.date, a.outboxRepo, a.eventBus, outbox.Settings{
+		BatchSize: cfg.Outbox.BatchSize,
+		Lease:     cfg.Outbox.Lease,
+		BaseDelay: cfg.Outbox.BaseDelay,
+		MaxDelay:  cfg.Outbox.MaxDelay,
+		Retention: cfg.Outbox.Retention,
+	})
+	// processed events are deleted by the statistics consumer
+	a.statisticsService = statistics.NewStatisticsService(a.log, a.date, a.authorizer, a.statisticsRepo, statistics.Settings{})
 
 	// http handlers init
 	a.authHandler = authhttphandler.NewAuthHandler(a.log, a.db, a.authService)
-	a.productsHandler = productshttphandler.NewProductsHandler(a.log, a.db, a.productsService)
+	a.productsHandler = productshttphandler.NewProductsHandler(a.log, a.db, a.productsService, productshttphandler.StreamSettings{
+		Heartbeat:    cfg.ProductsStream.Heartbeat,
+		WriteTimeout: cfg.ProductsStream.WriteTimeout,
+	})
+	a.statisticsHandler = statisticshttphandler.NewStatisticsHandler(a.log, a.db, a.statisticsService)
+	a.webhooksHandler = webhookshttphandler.NewWebhooksHandler(a.log, a.db, a.webhooksService)
+	a.adminHandler = adminhttphandler.NewAdminHandler(a.log, a.db, a.authService, a.productsService)
+	a.graphqlHandler, err = graphqlhttphandler.NewGraphQLHandler(a.log, a.db, a.productsService, graphqlhttphandler.Settings{
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot create graphql schema: %s", err))
+		return nil, err
+	}
 
 	// http server init
-	router := httphandler.SetupRouter(a.log, a.authHandler, a.productsHandler)
+	requestTrace := middleware.RequestTrace(a.log, a.ids)
+	userIdentity := middleware.UserIdentity(a.log, a.db, a.authService)
+	streamIdentity := middleware.StreamIdentity(a.log, a.db, a.authService)
+	requireVerified := middleware.RequireVerified(a.log, a.db, a.authService)
+	router := httphandler.SetupRouter(a.log, requestTrace, userIdentity, streamIdentity, requireVerified, a.authHandler, a.productsHandler, a.statisticsHandler, a.webhooksHandler, a.adminHandler, a.graphqlHandler)
 
 	a.httpServer = &http.Server{
 		Addr:         fmt.Sprintf("0.0.0.0:%s", a.cfg.HTTPServer.Port),
@@ -96,11 +290,78 @@ func NewApp() (*App, error) {
 		ReadTimeout:  a.cfg.HTTPServer.Timeout,
 		WriteTimeout: a.cfg.HTTPServer.Timeout,
 	}
+	// streams are not finished by themselves and would hold the shutdown until its timeout
+	a.httpServer.RegisterOnShutdown(a.productsService.CloseStreams)
+
+	// grpc handlers init
+	a.authGRPCHandler = authgrpchandler.NewAuthHandler(a.log, a.db, a.authService)
+	a.productsGRPCHandler = productsgrpchandler.NewProductsHandler(a.log, a.db, a.productsService)
+
+	// grpc server init
+	creds, err := credentials.NewServerTLSFromFile(cfg.SSLPath.Certfile, cfg.SSLPath.Keyfile)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot load grpc server certificate: %s", err))
+		return nil, err
+	}
+
+	a.grpcServer = grpchandler.SetupServer(
+		a.log,
+		creds,
+		interceptor.RequestTrace(a.log, a.ids),
+		interceptor.UserIdentity(a.log, a.db, a.authService, productkeeperv1.AuthService_ServiceDesc.ServiceName),
+		interceptor.RequireVerified(a.log, a.db, a.authService),
+		a.authGRPCHandler,
+		a.productsGRPCHandler,
+	)
+
+	// background workers init
+	a.outboxRelay = newBatchWorker(a.log, "outbox relay", relayOutbox(a.db, a.outboxService), cfg.Outbox.Interval, cfg.Outbox.BatchSize)
+	a.outboxCleanup = newBatchWorker(a.log, "outbox cleanup", inTransaction(a.db, a.outboxService.DeleteSentBatch), cfg.Outbox.CleanupInterval, cfg.Outbox.BatchSize)
+	a.webhooksWorker = newBatchWorker(a.log, "webhooks worker", deliverWebhooks(a.db, a.webhooksService), cfg.Webhooks.Interval, cfg.Webhooks.BatchSize)
 
 	return a, nil
 }
 
+func authSettings(cfg *config.Config) auth.Settings {
+	return auth.Settings{
+		Throttle: auth.LoginThrottle{
+			AccountFreeAttempts: cfg.LoginThrottle.AccountFreeAttempts,
+			IPFreeAttempts:      cfg.LoginThrottle.IPFreeAttempts,
+			BaseDelay:           cfg.LoginThrottle.BaseDelay,
+			MaxDelay:            cfg.LoginThrottle.MaxDelay,
+			ResetAfter:          cfg.LoginThrottle.ResetAfter,
+		},
+		Password: auth.PasswordPolicy{
+			MinLength: cfg.Password.MinLength,
+			MaxLength: cfg.Password.MaxLength,
+		},
+		SessionTTL:     cfg.Jwt.TTL,
+		StreamTokenTTL: cfg.ProductsStream.TokenTTL,
+		Verification: auth.EmailVerification{
+			TTL: cfg.Verification.TTL,
+			URL: cfg.Verification.URL,
+		},
+	}
+}
+
 func (a *App) Run() {
+	go a.outboxRelay.run()
+	go a.outboxCleanup.run()
+	go a.webhooksWorker.run()
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", a.cfg.GRPCServer.Port))
+	if err != nil {
+		a.log.Error(fmt.Sprintf("cannot listen grpc port: %s", err))
+		os.Exit(1)
+	}
+
+	go func() {
+		if err := a.grpcServer.Serve(listener); err != nil {
+			a.log.Error(fmt.Sprintf("error ocurred while running grpc server: %s", err))
+			os.Exit(1)
+		}
+	}()
+
 	if err := a.httpServer.ListenAndServeTLS(a.cfg.SSLPath.Certfile, a.cfg.SSLPath.Keyfile); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		a.log.Error(fmt.Sprintf("error ocurred while running http-server server: %s", err))
 		os.Exit(1)
@@ -111,5 +372,27 @@ func (a *App) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	return a.httpServer.Shutdown(ctx)
+	grpcStopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	err := a.httpServer.Shutdown(ctx)
+
+	// grpc calls still running after the timeout are cancelled
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		a.grpcServer.Stop()
+	}
+
+	return err
+}
+
+// Closers app and its background workers in the order of graceful shutdown
+func (a *App) Closers() []io.Closer {
+	// messages and deliveries saved by the last requests are sent by the workers on the next start,
+	// event bus is closed after the relay and producers
+	return append(append([]io.Closer{a, a.outboxRelay, a.outboxCleanup, a.webhooksWorker}, a.closers...), a.eventBus)
 }
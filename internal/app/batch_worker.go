@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/outbox"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+)
+
+// batchWorker processes batches of pending records in the background, e.g. outbox messages or webhook deliveries
+type batchWorker struct {
+	log  *slog.Logger
+	name string
+
+	// process handles a batch and returns its size
+	process func() (int, error)
+
+	interval  time.Duration
+	batchSize int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newBatchWorker(log *slog.Logger, name string, process func() (int, error), interval time.Duration, batchSize int) *batchWorker {
+	return &batchWorker{
+		log:  log,
+		name: name,
+
+		process: process,
+
+		interval:  interval,
+		batchSize: batchSize,
+
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// run processes pending records every interval until Close
+func (w *batchWorker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.processPending()
+		}
+	}
+}
+
+// processPending processes batches while they are full
+func (w *batchWorker) processPending() {
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		n, err := w.process()
+		if err != nil {
+			w.log.Error(fmt.Sprintf("%s: %s", w.name, err))
+			return
+		}
+
+		if n < w.batchSize {
+			return
+		}
+	}
+}
+
+// inTransaction processes a batch in one transaction
+func inTransaction(db *sqlx.DB, process func(tx *sqlx.Tx) (int, error)) func() (int, error) {
+	return func() (int, error) {
+		tx, err := db.Beginx()
+		if err != nil {
+			return 0, fmt.Errorf("cannot start transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		n, err := process(tx)
+		if err != nil {
+			return 0, err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("cannot commit transaction: %w", err)
+		}
+
+		return n, nil
+	}
+}
+
+// relayOutbox claims a batch of outbox messages, publishes them without holding locks
+// and records the results, every step with the database is a short transaction
+func relayOutbox(db *sqlx.DB, outboxService *outbox.OutboxService) func() (int, error) {
+	return func() (int, error) {
+		var messages []outbox.Message
+
+		_, err := inTransaction(db, func(tx *sqlx.Tx) (int, error) {
+			var err error
+			messages, err = outboxService.ClaimMessages(tx)
+			return len(messages), err
+		})()
+		if err != nil || len(messages) == 0 {
+			return 0, err
+		}
+
+		results := outboxService.PublishMessages(messages)
+
+		_, err = inTransaction(db, func(tx *sqlx.Tx) (int, error) {
+			return len(results), outboxService.RecordResults(tx, messages, results)
+		})()
+		if err != nil {
+			return 0, err
+		}
+
+		return len(messages), nil
+	}
+}
+
+// deliverWebhooks claims a batch of deliveries, sends them without holding locks
+// and records the attempts, every step with the database is a short transaction
+func deliverWebhooks(db *sqlx.DB, webhooksService *webhooks.WebhooksService) func() (int, error) {
+	return func() (int, error) {
+		var deliveries []webhooks.PendingDelivery
+
+		_, err := inTransaction(db, func(tx *sqlx.Tx) (int, error) {
+			var err error
+			deliveries, err = webhooksService.ClaimDeliveries(tx)
+			return len(deliveries), err
+		})()
+		if err != nil || len(deliveries) == 0 {
+			return 0, err
+		}
+
+		attempts := webhooksService.SendDeliveries(deliveries)
+
+		_, err = inTransaction(db, func(tx *sqlx.Tx) (int, error) {
+			return len(attempts), webhooksService.RecordAttempts(tx, deliveries, attempts)
+		})()
+		if err != nil {
+			return 0, err
+		}
+
+		return len(deliveries), nil
+	}
+}
+
+// Close stops the worker and waits for the current batch
+func (w *batchWorker) Close() error {
+	close(w.stop)
+	<-w.done
+	return nil
+}
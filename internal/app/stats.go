@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/internal/adapters/authorizer"
+	"github.com/fallra1n/product-keeper/internal/adapters/statisticsrepo"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+	kafkahandler "github.com/fallra1n/product-keeper/internal/handler/kafka"
+	"github.com/fallra1n/product-keeper/pkg/access"
+	"github.com/fallra1n/product-keeper/pkg/datefunctions"
+	"github.com/fallra1n/product-keeper/pkg/kafka"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+	"github.com/fallra1n/product-keeper/pkg/postgresdb"
+)
+
+// StatsApp consumer of product views, aggregates them per hour and day
+type StatsApp struct {
+	cfg           *config.Config
+	log           *slog.Logger
+	db            *sqlx.DB
+	consumerGroup sarama.ConsumerGroup
+
+	statisticsService *statistics.StatisticsService
+
+	viewsHandler *kafkahandler.ViewsHandler
+
+	cleanupWorker *batchWorker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewStatsApp creating new statistics consumer
+func NewStatsApp() (*StatsApp, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("cannot loading .env file: %s", err)
+	}
+
+	cfg := config.MustLoad()
+	logger := logging.SetupLogger(cfg.Env)
+
+	if cfg.Provisioning.Enabled {
+		if err := kafka.EnsureTopics(access.KafkaConnect(cfg), cfg.KafkaCluster, []string{cfg.Topics.ProductViews}); err != nil {
+			logger.Error(fmt.Sprintf("cannot provision kafka topics: %s", err))
+			return nil, err
+		}
+	}
+
+	consumerGroup, err := kafka.NewConsumerGroup(access.KafkaConnect(cfg), cfg.KafkaCluster, cfg.StatisticsConsumer.Group)
+	if err != nil {
+		logger.Error(fmt.Sprintf("cannot create statistics consumer: %s", err))
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := &StatsApp{
+		cfg:           cfg,
+		log:           logger,
+		db:            postgresdb.NewPostgresDB(access.PostgresConnect(cfg), cfg.Postgres.Timeout),
+		consumerGroup: consumerGroup,
+
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	a.statisticsService = statistics.NewStatisticsService(
+		a.log,
+		datefunctions.NewDateTool(),
+		authorizer.NewPolicyAuthorizer(cfg.Policies),
+		statisticsrepo.NewPostgresStatistics(),
+		statistics.Settings{
+			ProcessedEventsRetention: cfg.StatisticsConsumer.Retention,
+			CleanupBatchSize:         cfg.StatisticsConsumer.CleanupBatchSize,
+		},
+	)
+
+	a.viewsHandler = kafkahandler.NewViewsHandler(a.log, a.db, a.statisticsService)
+	a.cleanupWorker = newBatchWorker(
+		a.log,
+		"processed events cleanup",
+		inTransaction(a.db, a.statisticsService.DeleteProcessedEvents),
+		cfg.StatisticsConsumer.CleanupInterval,
+		cfg.StatisticsConsumer.CleanupBatchSize,
+	)
+
+	return a, nil
+}
+
+// Run consumes product views until Close, after a failure consuming starts again from the last marked offset.
+// Old ids of processed events are deleted in the background
+func (a *StatsApp) Run() {
+	defer close(a.done)
+
+	go a.cleanupWorker.run()
+
+	go func() {
+		for err := range a.consumerGroup.Errors() {
+			a.log.Error(fmt.Sprintf("statistics consumer: %s", err))
+		}
+	}()
+
+	topics := []string{a.cfg.Topics.ProductViews}
+	for {
+		err := a.consumerGroup.Consume(a.ctx, topics, a.viewsHandler)
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) || a.ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			a.log.Error(fmt.Sprintf("statistics consumer: %s", err))
+
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-time.After(a.cfg.StatisticsConsumer.RetryDelay):
+			}
+		}
+	}
+}
+
+// Close stops consuming, the offset of the last recorded view is committed by the consumer group
+func (a *StatsApp) Close() error {
+	a.cancel()
+	<-a.done
+
+	if err := a.cleanupWorker.Close(); err != nil {
+		a.log.Error(fmt.Sprintf("processed events cleanup: %s", err))
+	}
+
+	return a.consumerGroup.Close()
+}
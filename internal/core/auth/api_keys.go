@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+const (
+	apiKeyPrefixSize = 8
+	apiKeySecretSize = 32
+)
+
+// CreateAPIKey creates api key, returns the key itself which is shown only once
+func (s *AuthService) CreateAPIKey(tx *sqlx.Tx, ownerName string, name string, scopes []string) (string, APIKey, error) {
+	if len(scopes) == 0 {
+		return "", APIKey{}, ErrInvalidScope
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			s.log.Error(ErrInvalidScope.Error(), "username", ownerName, "scope", scope)
+			return "", APIKey{}, ErrInvalidScope
+		}
+	}
+
+	prefix, err := s.crypto.RandomString(apiKeyPrefixSize)
+	if err != nil {
+		s.log.Error("failed to generate api key prefix", "error", err)
+		return "", APIKey{}, shared.ErrInternal
+	}
+
+	secret, err := s.crypto.RandomString(apiKeySecretSize)
+	if err != nil {
+		s.log.Error("failed to generate api key secret", "error", err)
+		return "", APIKey{}, shared.ErrInternal
+	}
+
+	key := APIKey{
+		Prefix:    prefix,
+		Hash:      s.crypto.HashToken(secret),
+		OwnerName: ownerName,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: s.date.Now(),
+	}
+
+	key.ID, err = s.authRepo.CreateAPIKey(tx, key)
+	if err != nil {
+		s.log.Error("failed to create api key", "error", err, "username", ownerName)
+		return "", APIKey{}, shared.ErrInternal
+	}
+
+	s.log.Info("api key has been created", "id", key.ID, "username", ownerName)
+	return strings.Join([]string{APIKeyPrefix, prefix, secret}, "_"), key, nil
+}
+
+// FindAPIKeyList ...
+func (s *AuthService) FindAPIKeyList(tx *sqlx.Tx, ownerName string) ([]APIKey, error) {
+	keys, err := s.authRepo.FindAPIKeyList(tx, ownerName)
+	if err != nil {
+		s.log.Error("failed to find api key list", "error", err, "username", ownerName)
+		return nil, shared.ErrInternal
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey ...
+func (s *AuthService) RevokeAPIKey(tx *sqlx.Tx, id uint64, ownerName string) error {
+	if err := s.authRepo.RevokeAPIKey(tx, id, ownerName, s.date.Now()); err != nil {
+		s.log.Error("failed to revoke api key", "error", err, "id", id, "username", ownerName)
+
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return ErrAPIKeyNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	s.log.Info("api key has been revoked", "id", id, "username", ownerName)
+	return nil
+}
+
+// AuthenticateAPIKey checks api key and returns its owner and scopes
+func (s *AuthService) AuthenticateAPIKey(tx *sqlx.Tx, rawKey string) (shared.Subject, []string, error) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != APIKeyPrefix {
+		return shared.Subject{}, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.authRepo.FindAPIKey(tx, parts[1])
+	if err != nil {
+		s.log.Error("failed to find api key", "error", err, "prefix", parts[1])
+
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return shared.Subject{}, nil, ErrInvalidAPIKey
+		}
+
+		return shared.Subject{}, nil, shared.ErrInternal
+	}
+
+	if key.RevokedAt != nil {
+		s.log.Error("api key has been revoked", "id", key.ID)
+		return shared.Subject{}, nil, ErrInvalidAPIKey
+	}
+
+	if err := s.crypto.CompareHashAndToken(key.Hash, parts[2]); err != nil {
+		s.log.Error("api key does not match", "id", key.ID)
+		return shared.Subject{}, nil, ErrInvalidAPIKey
+	}
+
+	owner, err := s.authRepo.FindUser(tx, key.OwnerName)
+	if err != nil {
+		s.log.Error("failed to find api key owner", "error", err, "id", key.ID, "username", key.OwnerName)
+		return shared.Subject{}, nil, shared.ErrInternal
+	}
+
+	if owner.Disabled {
+		s.log.Error(ErrUserDisabled.Error(), "username", owner.Name)
+		return shared.Subject{}, nil, ErrUserDisabled
+	}
+
+	if err := s.authRepo.UpdateAPIKeyLastUsed(tx, key.ID, s.date.Now()); err != nil {
+		s.log.Error("failed to update api key last usage", "error", err, "id", key.ID)
+		return shared.Subject{}, nil, shared.ErrInternal
+	}
+
+	return shared.NewSubject(owner.Name, string(owner.Role)), key.Scopes, nil
+}
@@ -0,0 +1,530 @@
+package auth_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestCreateAPIKey() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	type args struct {
+		ownerName string
+		name      string
+		scopes    []string
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockKey = auth.APIKey{
+			Prefix:    "prefix",
+			Hash:      "hashed secret",
+			OwnerName: "test name",
+			Name:      "test key",
+			Scopes:    []string{auth.ScopeProductsRead},
+			CreatedAt: now,
+		}
+	)
+
+	testList := []struct {
+		name        string
+		prepare     func(f *fields)
+		args        args
+		expectedKey string
+		expected    auth.APIKey
+		err         error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("prefix", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("secret", nil),
+					f.crypto.EXPECT().HashToken("secret").Return("hashed secret"),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().CreateAPIKey(f.tx, mockKey).Return(uint64(42), nil),
+				)
+			},
+			args: args{
+				ownerName: "test name",
+				name:      "test key",
+				scopes:    []string{auth.ScopeProductsRead},
+			},
+			expectedKey: "pk_prefix_secret",
+			expected: auth.APIKey{
+				ID:        42,
+				Prefix:    "prefix",
+				Hash:      "hashed secret",
+				OwnerName: "test name",
+				Name:      "test key",
+				Scopes:    []string{auth.ScopeProductsRead},
+				CreatedAt: now,
+			},
+			err: nil,
+		},
+		{
+			name: "unknown scope",
+			args: args{
+				ownerName: "test name",
+				name:      "test key",
+				scopes:    []string{auth.ScopeProductsRead, "users:delete"},
+			},
+			expectedKey: "",
+			expected:    auth.APIKey{},
+			err:         auth.ErrInvalidScope,
+		},
+		{
+			name: "empty scopes",
+			args: args{
+				ownerName: "test name",
+				name:      "test key",
+			},
+			expectedKey: "",
+			expected:    auth.APIKey{},
+			err:         auth.ErrInvalidScope,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("prefix", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("secret", nil),
+					f.crypto.EXPECT().HashToken("secret").Return("hashed secret"),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().CreateAPIKey(f.tx, mockKey).Return(uint64(0), shared.ErrNoData),
+				)
+			},
+			args: args{
+				ownerName: "test name",
+				name:      "test key",
+				scopes:    []string{auth.ScopeProductsRead},
+			},
+			expectedKey: "",
+			expected:    auth.APIKey{},
+			err:         shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			rawKey, data, err := service.CreateAPIKey(f.tx, row.args.ownerName, row.args.name, row.args.scopes)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedKey, rawKey)
+			s.Equal(row.expected, data)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestFindAPIKeyList() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockKeys = []auth.APIKey{
+			{ID: 1, OwnerName: "test name", Name: "test key", Scopes: []string{auth.ScopeProductsRead}},
+		}
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected []auth.APIKey
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKeyList(f.tx, "test name").Return(mockKeys, nil),
+				)
+			},
+			expected: mockKeys,
+			err:      nil,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKeyList(f.tx, "test name").Return(nil, shared.ErrNoData),
+				)
+			},
+			expected: nil,
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, err := service.FindAPIKeyList(f.tx, "test name")
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestRevokeAPIKey() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().RevokeAPIKey(f.tx, uint64(42), "test name", now).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "api key not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().RevokeAPIKey(f.tx, uint64(42), "test name", now).Return(auth.ErrAPIKeyNotFound),
+				)
+			},
+			err: auth.ErrAPIKeyNotFound,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().RevokeAPIKey(f.tx, uint64(42), "test name", now).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.RevokeAPIKey(f.tx, 42, "test name")
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestAuthenticateAPIKey() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockKey = auth.APIKey{
+			ID:        42,
+			Prefix:    "prefix",
+			Hash:      "hashed secret",
+			OwnerName: "test name",
+			Scopes:    []string{auth.ScopeProductsRead},
+		}
+		mockRevokedKey = auth.APIKey{
+			ID:        42,
+			Prefix:    "prefix",
+			Hash:      "hashed secret",
+			OwnerName: "test name",
+			RevokedAt: &now,
+		}
+		mockOwner = auth.User{Name: "test name", Role: auth.RoleUser}
+	)
+
+	testList := []struct {
+		name           string
+		prepare        func(f *fields)
+		args           string
+		expected       shared.Subject
+		expectedScopes []string
+		err            error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKey(f.tx, "prefix").Return(mockKey, nil),
+					f.crypto.EXPECT().CompareHashAndToken("hashed secret", "secret").Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(mockOwner, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().UpdateAPIKeyLastUsed(f.tx, uint64(42), now).Return(nil),
+				)
+			},
+			args:           "pk_prefix_secret",
+			expected:       shared.NewSubject("test name", "user"),
+			expectedScopes: []string{auth.ScopeProductsRead},
+			err:            nil,
+		},
+		{
+			name:     "malformed key",
+			args:     "prefix_secret",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidAPIKey,
+		},
+		{
+			name: "key not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKey(f.tx, "prefix").Return(auth.APIKey{}, auth.ErrAPIKeyNotFound),
+				)
+			},
+			args:     "pk_prefix_secret",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidAPIKey,
+		},
+		{
+			name: "revoked key",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKey(f.tx, "prefix").Return(mockRevokedKey, nil),
+				)
+			},
+			args:     "pk_prefix_secret",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidAPIKey,
+		},
+		{
+			name: "wrong secret",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKey(f.tx, "prefix").Return(mockKey, nil),
+					f.crypto.EXPECT().CompareHashAndToken("hashed secret", "wrong").Return(shared.ErrNoData),
+				)
+			},
+			args:     "pk_prefix_wrong",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidAPIKey,
+		},
+		{
+			name: "owner disabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindAPIKey(f.tx, "prefix").Return(mockKey, nil),
+					f.crypto.EXPECT().CompareHashAndToken("hashed secret", "secret").Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(auth.User{Name: "test name", Disabled: true}, nil),
+				)
+			},
+			args:     "pk_prefix_secret",
+			expected: shared.Subject{},
+			err:      auth.ErrUserDisabled,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, scopes, err := service.AuthenticateAPIKey(f.tx, row.args)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+			s.Equal(row.expectedScopes, scopes)
+		})
+	}
+}
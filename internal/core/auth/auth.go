@@ -11,11 +11,20 @@ import (
 
 // AuthService ...
 type AuthService struct {
-	log    *slog.Logger
-	crypto shared.Crypto
-	jwt    shared.Jwt
+	log      *slog.Logger
+	crypto   shared.Crypto
+	jwt      shared.Jwt
+	date     shared.DateTool
+	totp     shared.TOTP
+	notifier shared.Notifier
+	signer   shared.Signer
 
-	authRepo AuthRepo
+	authRepo          AuthRepo
+	identityProviders IdentityProviders
+	breachedPasswords BreachedPasswords
+	productsOwnership ProductsOwnership
+
+	settings Settings
 }
 
 // NewAuthService constructor for AuthService
@@ -23,20 +32,48 @@ func NewAuthService(
 	log *slog.Logger,
 	crypto shared.Crypto,
 	jwt shared.Jwt,
+	date shared.DateTool,
+	totp shared.TOTP,
+	notifier shared.Notifier,
+	signer shared.Signer,
 
 	authRepo AuthRepo,
+	identityProviders IdentityProviders,
+	breachedPasswords BreachedPasswords,
+	productsOwnership ProductsOwnership,
+
+	settings Settings,
 ) *AuthService {
 	return &AuthService{
-		log:    log,
-		crypto: crypto,
-		jwt:    jwt,
+		log:      log,
+		crypto:   crypto,
+		jwt:      jwt,
+		date:     date,
+		totp:     totp,
+		notifier: notifier,
+		signer:   signer,
 
-		authRepo: authRepo,
+		authRepo:          authRepo,
+		identityProviders: identityProviders,
+		breachedPasswords: breachedPasswords,
+		productsOwnership: productsOwnership,
+
+		settings: settings,
 	}
 }
 
-// CreateUser ...
+// CreateUser creates pending account and sends verification link to user email
 func (s *AuthService) CreateUser(tx *sqlx.Tx, user User) error {
+	if !validEmail(user.Email) {
+		s.log.Error(ErrInvalidEmail.Error(), "username", user.Name, "email", user.Email)
+		return ErrInvalidEmail
+	}
+
+	if err := s.validatePassword(user.Password); err != nil {
+		s.log.Error("password does not match policy", "error", err, "username", user.Name)
+		return err
+	}
+
 	hash, err := s.crypto.HashPassword(user.Password)
 	if err != nil {
 		s.log.Error("failed to hash password", "error", err, "password", user.Password)
@@ -44,6 +81,8 @@ func (s *AuthService) CreateUser(tx *sqlx.Tx, user User) error {
 	}
 
 	hashedUser := NewUser(user.Name, hash)
+	hashedUser.Email = user.Email
+	hashedUser.Pending = true
 
 	if err := s.authRepo.CreateUser(tx, hashedUser); err != nil {
 		s.log.Error("failed to create user", "error", err, "username", user.Name, "hashed_password", hashedUser.Password)
@@ -54,32 +93,219 @@ func (s *AuthService) CreateUser(tx *sqlx.Tx, user User) error {
 		return err
 	}
 
-	return nil
+	return s.sendVerification(hashedUser)
 }
 
-// LoginUser ...
-func (s *AuthService) LoginUser(tx *sqlx.Tx, user User) (string, error) {
-	hashedPassword, err := s.authRepo.FindPassword(tx, user.Name)
+// LoginUser returns token or, if two-factor authentication is enabled, mfa challenge for LoginMFA.
+// Unknown user and incorrect password are both reported as ErrIncorrectPassword,
+// failures are saved, so the caller must commit on ErrIncorrectPassword
+func (s *AuthService) LoginUser(tx *sqlx.Tx, user User, client Client) (LoginResult, error) {
+	now := s.date.Now()
+
+	accountAttempts, err := s.findLoginAttempts(tx, AccountAttemptsKey(user.Name), s.settings.Throttle.AccountFreeAttempts, now)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	ipAttempts, err := s.findLoginAttempts(tx, IPAttemptsKey(client.IP), s.settings.Throttle.IPFreeAttempts, now)
 	if err != nil {
-		s.log.Error("failed to find password", "error", err, "username", user.Name)
+		return LoginResult{}, err
+	}
+
+	foundUser, err := s.authRepo.FindUser(tx, user.Name)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", user.Name, "ip", client.IP)
 
 		if errors.Is(err, ErrUserNotFound) {
-			return "", ErrUserNotFound
+			// hashing takes as long as comparing, so response time does not reveal registered usernames.
+			// Attempts are counted for unknown names too, otherwise only existing accounts would be locked
+			_, _ = s.crypto.HashPassword(user.Password)
+
+			if err := s.recordLoginFailure(tx, now, accountAttempts.Key, ipAttempts.Key); err != nil {
+				return LoginResult{}, err
+			}
+
+			return LoginResult{}, ErrIncorrectPassword
 		}
 
-		return "", shared.ErrInternal
+		return LoginResult{}, shared.ErrInternal
 	}
 
-	if err := s.crypto.CompareHashAndPassword(hashedPassword, user.Password); err != nil {
-		s.log.Error("incorrect password", "password", user.Password, "hashed_password", hashedPassword)
-		return "", ErrIncorrectPassword
+	if err := s.crypto.CompareHashAndPassword(foundUser.Password, user.Password); err != nil {
+		s.log.Error("incorrect password", "username", user.Name, "ip", client.IP)
+
+		if err := s.recordLoginFailure(tx, now, accountAttempts.Key, ipAttempts.Key); err != nil {
+			return LoginResult{}, err
+		}
+
+		return LoginResult{}, ErrIncorrectPassword
+	}
+
+	if foundUser.Disabled {
+		s.log.Error(ErrUserDisabled.Error(), "username", user.Name)
+		return LoginResult{}, ErrUserDisabled
 	}
 
-	token, err := s.jwt.GenerateToken(user.Name)
+	if err := s.upgradePasswordHash(tx, foundUser.Name, foundUser.Password, user.Password); err != nil {
+		return LoginResult{}, err
+	}
+
+	totp, err := s.authRepo.FindTOTP(tx, foundUser.Name)
+	if err != nil && !errors.Is(err, ErrTOTPNotFound) {
+		s.log.Error("failed to find totp", "error", err, "username", user.Name)
+		return LoginResult{}, shared.ErrInternal
+	}
+
+	// failures are reset only after the second factor
+	if totp.Enabled {
+		challenge, err := s.createMFAChallenge(tx, foundUser.Name)
+		if err != nil {
+			return LoginResult{}, err
+		}
+
+		return LoginResult{MFAChallenge: challenge}, nil
+	}
+
+	if err := s.authRepo.DeleteLoginAttempts(tx, accountAttempts.Key); err != nil {
+		s.log.Error("failed to reset login attempts", "error", err, "username", user.Name)
+		return LoginResult{}, shared.ErrInternal
+	}
+
+	token, err := s.createSession(tx, foundUser, client, now)
 	if err != nil {
-		s.log.Error("failed to generate token", "error", err, "username", user.Name)
-		return "", shared.ErrInternal
+		return LoginResult{}, err
+	}
+
+	return LoginResult{Token: token}, nil
+}
+
+// Identify checks token and its session, returns the user and session id.
+// The role is taken from the account, not from the token, so role changes apply to issued tokens
+func (s *AuthService) Identify(tx *sqlx.Tx, tokenString string, ip string) (shared.Subject, string, error) {
+	username, _, sessionID, err := s.jwt.ParseToken(tokenString)
+	if err != nil {
+		s.log.Error("failed to parse token", "error", err)
+		return shared.Subject{}, "", ErrInvalidToken
+	}
+
+	if sessionID == "" {
+		s.log.Error("token is not bound to session", "username", username)
+		return shared.Subject{}, "", ErrInvalidToken
+	}
+
+	subject, err := s.identifySession(tx, username, sessionID, ip)
+	if err != nil {
+		return shared.Subject{}, "", err
+	}
+
+	return subject, sessionID, nil
+}
+
+// identifySession checks that the session of the user is active and the user is not disabled
+func (s *AuthService) identifySession(tx *sqlx.Tx, username string, sessionID string, ip string) (shared.Subject, error) {
+	session, err := s.authRepo.FindSession(tx, sessionID)
+	if err != nil {
+		s.log.Error("failed to find session", "error", err, "username", username, "session", sessionID)
+
+		if errors.Is(err, ErrSessionNotFound) {
+			return shared.Subject{}, ErrInvalidToken
+		}
+
+		return shared.Subject{}, shared.ErrInternal
 	}
 
-	return token, nil
+	now := s.date.Now()
+	if session.UserName != username || !now.Before(session.ExpiresAt) {
+		s.log.Error("session does not match token", "username", username, "session", sessionID)
+		return shared.Subject{}, ErrInvalidToken
+	}
+
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username, "session", sessionID)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return shared.Subject{}, ErrInvalidToken
+		}
+
+		return shared.Subject{}, shared.ErrInternal
+	}
+
+	if user.Disabled {
+		s.log.Error(ErrUserDisabled.Error(), "username", username, "session", sessionID)
+		return shared.Subject{}, ErrInvalidToken
+	}
+
+	if now.Sub(session.LastSeenAt) >= SessionTouchInterval {
+		if err := s.authRepo.TouchSession(tx, sessionID, now, ip); err != nil {
+			s.log.Error("failed to update session", "error", err, "username", username, "session", sessionID)
+			return shared.Subject{}, shared.ErrInternal
+		}
+	}
+
+	return shared.NewSubject(user.Name, string(user.Role)), nil
+}
+
+// JWKS public keys for verifying issued tokens
+func (s *AuthService) JWKS() ([]byte, error) {
+	jwks, err := s.jwt.JWKS()
+	if err != nil {
+		s.log.Error("failed to build jwks", "error", err)
+		return nil, shared.ErrInternal
+	}
+
+	return jwks, nil
+}
+
+// FindUserList ...
+func (s *AuthService) FindUserList(tx *sqlx.Tx) ([]User, error) {
+	users, err := s.authRepo.FindUserList(tx)
+	if err != nil {
+		s.log.Error("failed to find user list", "error", err)
+		return nil, shared.ErrInternal
+	}
+
+	return users, nil
+}
+
+// DisableUser disables user and logs out all its sessions
+func (s *AuthService) DisableUser(tx *sqlx.Tx, name string) error {
+	if err := s.authRepo.DisableUser(tx, name); err != nil {
+		s.log.Error("failed to disable user", "error", err, "username", name)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if err := s.authRepo.DeleteSessions(tx, name, ""); err != nil {
+		s.log.Error("failed to delete sessions", "error", err, "username", name)
+		return shared.ErrInternal
+	}
+
+	s.log.Info("user has been disabled", "username", name)
+	return nil
+}
+
+// UnlockUser resets failed login attempts of user
+func (s *AuthService) UnlockUser(tx *sqlx.Tx, name string) error {
+	if _, err := s.authRepo.FindUser(tx, name); err != nil {
+		s.log.Error("failed to find user", "error", err, "username", name)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if err := s.authRepo.DeleteLoginAttempts(tx, AccountAttemptsKey(name)); err != nil {
+		s.log.Error("failed to reset login attempts", "error", err, "username", name)
+		return shared.ErrInternal
+	}
+
+	s.log.Info("user has been unlocked", "username", name)
+	return nil
 }
@@ -2,7 +2,9 @@ package auth_test
 
 import (
 	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/suite"
@@ -15,6 +17,26 @@ import (
 	"github.com/fallra1n/product-keeper/pkg/logging"
 )
 
+var mockSettings = auth.Settings{
+	Throttle: auth.LoginThrottle{
+		AccountFreeAttempts: 5,
+		IPFreeAttempts:      20,
+		BaseDelay:           time.Second,
+		MaxDelay:            15 * time.Minute,
+		ResetAfter:          24 * time.Hour,
+	},
+	Password: auth.PasswordPolicy{
+		MinLength: 8,
+		MaxLength: 72,
+	},
+	SessionTTL:     20 * time.Minute,
+	StreamTokenTTL: time.Minute,
+	Verification: auth.EmailVerification{
+		TTL: 24 * time.Hour,
+		URL: "https://localhost/user/verify",
+	},
+}
+
 type RunAuthSuite struct {
 	suite.Suite
 	log *slog.Logger
@@ -33,12 +55,24 @@ func (s *RunAuthSuite) TestCreateUser() {
 		tx       *sqlx.Tx
 		crypto   *mockshared.MockCrypto
 		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
 		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
 	}
 
 	var (
-		mockUser       = auth.User{Name: "test name", Password: "test pass"}
-		mockHashedUser = auth.User{Name: "test name", Password: "test hashed pass"}
+		mockNow        = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockUser       = auth.User{Name: "test name", Password: "test pass", Email: "test@example.com"}
+		mockHashedUser = auth.User{Name: "test name", Password: "test hashed pass", Email: "test@example.com", Pending: true}
+
+		// base64url of name and email, unix time of mockNow plus verification ttl
+		mockPayload = "dGVzdCBuYW1l.dGVzdEBleGFtcGxlLmNvbQ.946771200"
 	)
 
 	testList := []struct {
@@ -51,17 +85,71 @@ func (s *RunAuthSuite) TestCreateUser() {
 			name: "successful launch",
 			prepare: func(f *fields) {
 				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains(mockUser.Password).Return(false),
 					f.crypto.EXPECT().HashPassword(mockUser.Password).Return(mockHashedUser.Password, nil),
 					f.authRepo.EXPECT().CreateUser(f.tx, mockHashedUser).Return(nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.signer.EXPECT().Sign(mockPayload).Return("signature"),
+					f.notifier.EXPECT().Send(gomock.Cond(func(x any) bool {
+						message := x.(shared.Message)
+						return message.To == mockUser.Email &&
+							strings.Contains(message.Body, mockSettings.Verification.URL+"?token="+mockPayload+".signature")
+					})).Return(nil),
 				)
 			},
 			args: mockUser,
 			err:  nil,
 		},
+		{
+			name: "failed to send verification",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains(mockUser.Password).Return(false),
+					f.crypto.EXPECT().HashPassword(mockUser.Password).Return(mockHashedUser.Password, nil),
+					f.authRepo.EXPECT().CreateUser(f.tx, mockHashedUser).Return(nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.signer.EXPECT().Sign(mockPayload).Return("signature"),
+					f.notifier.EXPECT().Send(gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args: mockUser,
+			err:  shared.ErrInternal,
+		},
+		{
+			name: "invalid email",
+			args: auth.User{Name: "test name", Password: "test pass", Email: "test name <test@example.com>"},
+			err:  auth.ErrInvalidEmail,
+		},
+		{
+			name: "empty email",
+			args: auth.User{Name: "test name", Password: "test pass"},
+			err:  auth.ErrInvalidEmail,
+		},
+		{
+			name: "password too short",
+			args: auth.User{Name: "test name", Password: "short", Email: "test@example.com"},
+			err:  auth.ErrPasswordTooShort,
+		},
+		{
+			name: "password too long",
+			args: auth.User{Name: "test name", Password: strings.Repeat("a", 73), Email: "test@example.com"},
+			err:  auth.ErrPasswordTooLong,
+		},
+		{
+			name: "breached password",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains("password1").Return(true),
+				)
+			},
+			args: auth.User{Name: "test name", Password: "password1", Email: "test@example.com"},
+			err:  auth.ErrPasswordBreached,
+		},
 		{
 			name: "hashing failed",
 			prepare: func(f *fields) {
 				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains(mockUser.Password).Return(false),
 					f.crypto.EXPECT().HashPassword(mockUser.Password).Return("", auth.ErrIncorrectPassword),
 				)
 			},
@@ -72,6 +160,7 @@ func (s *RunAuthSuite) TestCreateUser() {
 			name: "user already exist",
 			prepare: func(f *fields) {
 				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains(mockUser.Password).Return(false),
 					f.crypto.EXPECT().HashPassword(mockUser.Password).Return(mockHashedUser.Password, nil),
 					f.authRepo.EXPECT().CreateUser(f.tx, mockHashedUser).Return(auth.ErrUserAlreadyExist),
 				)
@@ -90,7 +179,15 @@ func (s *RunAuthSuite) TestCreateUser() {
 				tx:       &sqlx.Tx{},
 				crypto:   mockshared.NewMockCrypto(ctrl),
 				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
 				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -100,7 +197,15 @@ func (s *RunAuthSuite) TestCreateUser() {
 				s.log,
 				f.crypto,
 				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
 				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
 			)
 
 			err := service.CreateUser(f.tx, row.args)
@@ -114,69 +219,350 @@ func (s *RunAuthSuite) TestLoginUser() {
 		tx       *sqlx.Tx
 		crypto   *mockshared.MockCrypto
 		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
 		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
 	}
 
 	var (
 		mockUser           = auth.User{Name: "test name", Password: "test pass"}
+		mockIP             = "127.0.0.1"
+		mockClient         = auth.NewClient(mockIP, "test agent")
 		mockHashedPassword = "test hashed pass"
+		mockFoundUser      = auth.User{Name: "test name", Password: mockHashedPassword, Role: auth.RoleUser}
+		mockDisabledUser   = auth.User{Name: "test name", Password: mockHashedPassword, Role: auth.RoleUser, Disabled: true}
 		mockToken          = "test jwt token"
+		mockChallenge      = "test challenge"
+		mockNow            = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockSessionID      = "test session"
+		mockSession        = auth.Session{
+			ID:         mockSessionID,
+			UserName:   mockUser.Name,
+			UserAgent:  "test agent",
+			IP:         mockIP,
+			CreatedAt:  mockNow,
+			LastSeenAt: mockNow,
+			ExpiresAt:  mockNow.Add(mockSettings.SessionTTL),
+		}
+
+		accountKey = auth.AccountAttemptsKey(mockUser.Name)
+		ipKey      = auth.IPAttemptsKey(mockIP)
 	)
 
+	noAttempts := func(f *fields) []any {
+		return []any{
+			f.date.EXPECT().Now().Return(mockNow),
+			f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+			f.authRepo.EXPECT().FindLoginAttempts(f.tx, ipKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+		}
+	}
+
 	testList := []struct {
 		name     string
 		prepare  func(f *fields)
 		args     auth.User
-		expected string
+		expected auth.LoginResult
 		err      error
 	}{
 		{
 			name: "successful launch",
 			prepare: func(f *fields) {
-				gomock.InOrder(
-					f.authRepo.EXPECT().FindPassword(f.tx, mockUser.Name).Return(mockHashedPassword, nil),
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
 					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
-					f.jwt.EXPECT().GenerateToken(mockUser.Name).Return(mockToken, nil),
-				)
+					f.crypto.EXPECT().NeedsRehash(mockHashedPassword).Return(false),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, mockUser.Name, mockNow).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSessionID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken(mockUser.Name, string(auth.RoleUser), mockSessionID).Return(mockToken, nil),
+				)...)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{Token: mockToken},
+			err:      nil,
+		},
+		{
+			name: "outdated password hash is upgraded",
+			prepare: func(f *fields) {
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
+					f.crypto.EXPECT().NeedsRehash(mockHashedPassword).Return(true),
+					f.crypto.EXPECT().HashPassword(mockUser.Password).Return("new hashed password", nil),
+					f.authRepo.EXPECT().UpdatePassword(f.tx, mockUser.Name, "new hashed password").Return(nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, mockUser.Name, mockNow).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSessionID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken(mockUser.Name, string(auth.RoleUser), mockSessionID).Return(mockToken, nil),
+				)...)
 			},
 			args:     mockUser,
-			expected: mockToken,
+			expected: auth.LoginResult{Token: mockToken},
+			err:      nil,
+		},
+		{
+			name: "failed to upgrade password hash",
+			prepare: func(f *fields) {
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
+					f.crypto.EXPECT().NeedsRehash(mockHashedPassword).Return(true),
+					f.crypto.EXPECT().HashPassword(mockUser.Password).Return("new hashed password", nil),
+					f.authRepo.EXPECT().UpdatePassword(f.tx, mockUser.Name, "new hashed password").Return(shared.ErrNoData),
+				)...)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{},
+			err:      shared.ErrInternal,
+		},
+		{
+			name: "two-factor authentication enabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
+					f.crypto.EXPECT().NeedsRehash(mockHashedPassword).Return(false),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(auth.TOTP{UserName: mockUser.Name, Enabled: true}, nil),
+					f.crypto.EXPECT().RandomString(32).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().CreateMFAChallenge(f.tx, auth.MFAChallenge{
+						Challenge: mockChallenge,
+						UserName:  mockUser.Name,
+						CreatedAt: mockNow,
+					}).Return(nil),
+				)...)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{MFAChallenge: mockChallenge},
+			err:      nil,
+		},
+		{
+			name: "two-factor authentication not verified",
+			prepare: func(f *fields) {
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
+					f.crypto.EXPECT().NeedsRehash(mockHashedPassword).Return(false),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(auth.TOTP{UserName: mockUser.Name}, nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, mockUser.Name, mockNow).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSessionID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken(mockUser.Name, string(auth.RoleUser), mockSessionID).Return(mockToken, nil),
+				)...)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{Token: mockToken},
 			err:      nil,
 		},
 		{
 			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{}, auth.ErrUserNotFound),
+					f.crypto.EXPECT().HashPassword(mockUser.Password).Return(mockHashedPassword, nil),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, accountKey, mockNow, mockNow.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: accountKey, Failures: 1, LastFailureAt: mockNow}, nil),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, ipKey, mockNow, mockNow.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: ipKey, Failures: 1, LastFailureAt: mockNow}, nil),
+				)...)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{},
+			err:      auth.ErrIncorrectPassword,
+		},
+		{
+			name: "incorrect password",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.authRepo.EXPECT().FindPassword(f.tx, mockUser.Name).Return("", auth.ErrUserNotFound),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{Key: accountKey, Failures: 2, LastFailureAt: mockNow.Add(-time.Hour)}, nil),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, ipKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(shared.ErrNoData),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, accountKey, mockNow, mockNow.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: accountKey, Failures: 3, LastFailureAt: mockNow}, nil),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, ipKey, mockNow, mockNow.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: ipKey, Failures: 1, LastFailureAt: mockNow}, nil),
 				)
 			},
 			args:     mockUser,
-			expected: "",
-			err:      auth.ErrUserNotFound,
+			expected: auth.LoginResult{},
+			err:      auth.ErrIncorrectPassword,
 		},
 		{
-			name: "incorrect password",
+			name: "account is locked",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.authRepo.EXPECT().FindPassword(f.tx, mockUser.Name).Return(mockHashedPassword, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{Key: accountKey, Failures: 7, LastFailureAt: mockNow.Add(-3 * time.Second)}, nil),
+				)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{},
+			err:      auth.ErrTooManyAttempts,
+		},
+		{
+			name: "ip is locked",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, ipKey).Return(auth.LoginAttempts{Key: ipKey, Failures: 100, LastFailureAt: mockNow.Add(-time.Minute)}, nil),
+				)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{},
+			err:      auth.ErrTooManyAttempts,
+		},
+		{
+			name: "lock has expired",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{Key: accountKey, Failures: 7, LastFailureAt: mockNow.Add(-5 * time.Second)}, nil),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, ipKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
 					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(shared.ErrNoData),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, accountKey, mockNow, mockNow.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: accountKey, Failures: 8, LastFailureAt: mockNow}, nil),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, ipKey, mockNow, mockNow.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: ipKey, Failures: 1, LastFailureAt: mockNow}, nil),
 				)
 			},
 			args:     mockUser,
-			expected: "",
+			expected: auth.LoginResult{},
 			err:      auth.ErrIncorrectPassword,
 		},
+		{
+			name: "user disabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockDisabledUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
+				)...)
+			},
+			args:     mockUser,
+			expected: auth.LoginResult{},
+			err:      auth.ErrUserDisabled,
+		},
 		{
 			name: "failed to generate token",
 			prepare: func(f *fields) {
-				gomock.InOrder(
-					f.authRepo.EXPECT().FindPassword(f.tx, mockUser.Name).Return(mockHashedPassword, nil),
+				gomock.InOrder(append(noAttempts(f),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockFoundUser, nil),
 					f.crypto.EXPECT().CompareHashAndPassword(mockHashedPassword, mockUser.Password).Return(nil),
-					f.jwt.EXPECT().GenerateToken(mockUser.Name).Return("", shared.ErrNoData),
-				)
+					f.crypto.EXPECT().NeedsRehash(mockHashedPassword).Return(false),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, mockUser.Name, mockNow).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSessionID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken(mockUser.Name, string(auth.RoleUser), mockSessionID).Return("", shared.ErrNoData),
+				)...)
 			},
 			args:     mockUser,
-			expected: "",
+			expected: auth.LoginResult{},
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, err := service.LoginUser(f.tx, row.args, mockClient)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestFindUserList() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsers = []auth.User{
+			{Name: "test name1", Password: "test hashed pass1", Role: auth.RoleAdmin},
+			{Name: "test name2", Password: "test hashed pass2", Role: auth.RoleUser, Disabled: true},
+		}
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected []auth.User
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUserList(f.tx).Return(mockUsers, nil),
+				)
+			},
+			expected: mockUsers,
+			err:      nil,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUserList(f.tx).Return(nil, shared.ErrNoData),
+				)
+			},
+			expected: nil,
 			err:      shared.ErrInternal,
 		},
 	}
@@ -190,7 +576,15 @@ func (s *RunAuthSuite) TestLoginUser() {
 				tx:       &sqlx.Tx{},
 				crypto:   mockshared.NewMockCrypto(ctrl),
 				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
 				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -200,12 +594,471 @@ func (s *RunAuthSuite) TestLoginUser() {
 				s.log,
 				f.crypto,
 				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
 				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
 			)
 
-			data, err := service.LoginUser(f.tx, row.args)
+			data, err := service.FindUserList(f.tx)
 			s.Equal(row.err, err)
 			s.Equal(row.expected, data)
 		})
 	}
 }
+
+func (s *RunAuthSuite) TestDisableUser() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsername = "test name"
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    string
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DisableUser(f.tx, mockUsername).Return(nil),
+					f.authRepo.EXPECT().DeleteSessions(f.tx, mockUsername, "").Return(nil),
+				)
+			},
+			args: mockUsername,
+			err:  nil,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DisableUser(f.tx, mockUsername).Return(auth.ErrUserNotFound),
+				)
+			},
+			args: mockUsername,
+			err:  auth.ErrUserNotFound,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DisableUser(f.tx, mockUsername).Return(shared.ErrNoData),
+				)
+			},
+			args: mockUsername,
+			err:  shared.ErrInternal,
+		},
+		{
+			name: "failed to delete sessions",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DisableUser(f.tx, mockUsername).Return(nil),
+					f.authRepo.EXPECT().DeleteSessions(f.tx, mockUsername, "").Return(shared.ErrNoData),
+				)
+			},
+			args: mockUsername,
+			err:  shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.DisableUser(f.tx, row.args)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestUnlockUser() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsername = "test name"
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    string
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{Name: mockUsername}, nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, auth.AccountAttemptsKey(mockUsername)).Return(nil),
+				)
+			},
+			args: mockUsername,
+			err:  nil,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			args: mockUsername,
+			err:  auth.ErrUserNotFound,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{Name: mockUsername}, nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, auth.AccountAttemptsKey(mockUsername)).Return(shared.ErrNoData),
+				)
+			},
+			args: mockUsername,
+			err:  shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.UnlockUser(f.tx, row.args)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestIdentify() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockToken     = "test token"
+		mockIP        = "127.0.0.1"
+		mockSessionID = "test session"
+		mockNow       = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockSession   = auth.Session{
+			ID:         mockSessionID,
+			UserName:   "test name",
+			CreatedAt:  mockNow.Add(-10 * time.Minute),
+			LastSeenAt: mockNow.Add(-10 * time.Second),
+			ExpiresAt:  mockNow.Add(10 * time.Minute),
+		}
+		mockUser = auth.User{Name: "test name", Role: auth.RoleAdmin}
+	)
+
+	testList := []struct {
+		name      string
+		prepare   func(f *fields)
+		args      string
+		expected  shared.Subject
+		sessionID string
+		err       error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(mockUser, nil),
+				)
+			},
+			args:      mockToken,
+			expected:  shared.NewSubject("test name", string(auth.RoleAdmin)),
+			sessionID: mockSessionID,
+			err:       nil,
+		},
+		{
+			name: "last seen time is updated",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow.Add(time.Minute)),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(mockUser, nil),
+					f.authRepo.EXPECT().TouchSession(f.tx, mockSessionID, mockNow.Add(time.Minute), mockIP).Return(nil),
+				)
+			},
+			args:      mockToken,
+			expected:  shared.NewSubject("test name", string(auth.RoleAdmin)),
+			sessionID: mockSessionID,
+			err:       nil,
+		},
+		{
+			name: "role is taken from account",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(auth.User{Name: "test name", Role: auth.RoleUser}, nil),
+				)
+			},
+			args:      mockToken,
+			expected:  shared.NewSubject("test name", string(auth.RoleUser)),
+			sessionID: mockSessionID,
+			err:       nil,
+		},
+		{
+			name: "user is disabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(auth.User{Name: "test name", Role: auth.RoleAdmin, Disabled: true}, nil),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "user has been deleted",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "invalid token",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("", "", "", shared.ErrNoData),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "token without session",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), "", nil),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "session has been deleted",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(auth.Session{}, auth.ErrSessionNotFound),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "session of another user",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("another name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "session has expired",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockSession.ExpiresAt),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "failed to find session",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken(mockToken).Return("test name", string(auth.RoleAdmin), mockSessionID, nil),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(auth.Session{}, shared.ErrNoData),
+				)
+			},
+			args:     mockToken,
+			expected: shared.Subject{},
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			subject, sessionID, err := service.Identify(f.tx, row.args, mockIP)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, subject)
+			s.Equal(row.sessionID, sessionID)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -13,12 +14,114 @@ var (
 
 	// ErrUserAlreadyExist user already exists
 	ErrUserAlreadyExist = errors.New("user already exists")
+
+	// ErrUserDisabled user account has been disabled
+	ErrUserDisabled = errors.New("user account has been disabled")
+
+	// ErrAPIKeyNotFound api key not found
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrInvalidAPIKey api key is malformed, revoked or does not match
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrInvalidScope unknown api key scope
+	ErrInvalidScope = errors.New("invalid api key scope")
+
+	// ErrUnknownProvider identity provider is not configured
+	ErrUnknownProvider = errors.New("unknown identity provider")
+
+	// ErrInvalidOIDCState oidc login state not found or expired
+	ErrInvalidOIDCState = errors.New("invalid or expired oidc state")
+
+	// ErrPasswordNotSet user signs in with an identity provider and has no password to confirm
+	ErrPasswordNotSet = errors.New("account has no password, it signs in with an identity provider")
+
+	// ErrOIDCExchange failed to exchange authorization code or verify id token
+	ErrOIDCExchange = errors.New("failed to exchange oidc authorization code")
+
+	// ErrInvalidToken token is malformed, expired or signed with unknown key
+	ErrInvalidToken = errors.New("invalid auth token")
+
+	// ErrIdentityNotFound external identity is not linked to any user
+	ErrIdentityNotFound = errors.New("identity not found")
+
+	// ErrTOTPNotFound two-factor authentication is not set up
+	ErrTOTPNotFound = errors.New("two-factor authentication is not set up")
+
+	// ErrTOTPAlreadyEnabled two-factor authentication is already enabled
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+	// ErrInvalidTOTPCode one-time code or recovery code does not match
+	ErrInvalidTOTPCode = errors.New("invalid two-factor code")
+
+	// ErrInvalidMFAChallenge mfa challenge not found or expired
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+
+	// ErrTooManyAttempts login is blocked after too many failed attempts
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+	// ErrPasswordTooShort password is shorter than policy allows
+	ErrPasswordTooShort = errors.New("password is too short")
+
+	// ErrPasswordTooLong password is longer than policy allows
+	ErrPasswordTooLong = errors.New("password is too long")
+
+	// ErrPasswordBreached password is in the list of breached passwords
+	ErrPasswordBreached = errors.New("password has been found in data breaches")
+
+	// ErrInvalidResetToken password reset token is malformed, used or expired
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+	// ErrInvalidEmail email address is malformed
+	ErrInvalidEmail = errors.New("invalid email address")
+
+	// ErrInvalidTimezone timezone is not in the IANA database
+	ErrInvalidTimezone = errors.New("invalid timezone")
+
+	// ErrInvalidProductsAction unknown action with products of deleted account
+	ErrInvalidProductsAction = errors.New("invalid products action")
+
+	// ErrInvalidTransferTarget products can not be transferred to the user
+	ErrInvalidTransferTarget = errors.New("invalid products transfer target")
+
+	// ErrSessionNotFound session not found, expired or belongs to another user
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrEmailNotVerified email of the account is not verified yet
+	ErrEmailNotVerified = errors.New("email is not verified")
+
+	// ErrEmailAlreadyVerified email of the account is already verified
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+
+	// ErrInvalidVerificationToken verification token is malformed, expired or issued for another email
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+)
+
+// Role user role
+type Role string
+
+const (
+	// RoleUser regular user, has access only to own products
+	RoleUser Role = "user"
+
+	// RoleWarehouse warehouse staff, permissions are defined by policies
+	RoleWarehouse Role = "warehouse"
+
+	// RoleAdmin administrator, can manage users and view any product
+	RoleAdmin Role = "admin"
 )
 
 // User user info for auth
 type User struct {
+	ID       uint64 `db:"id"`
 	Name     string `db:"name"`
 	Password string `db:"password"`
+	Role     Role   `db:"role"`
+	Disabled bool   `db:"disabled"`
+	Email    string `db:"email"`
+
+	// Pending email of the registered user is not verified yet
+	Pending bool `db:"pending"`
 }
 
 // NewUser constructor for User
@@ -28,3 +131,237 @@ func NewUser(name string, password string) User {
 		Password: password,
 	}
 }
+
+const (
+	// SessionIDSize random bytes in session id
+	SessionIDSize = 16
+
+	// SessionTouchInterval last seen time of the session is updated not more often than that
+	SessionTouchInterval = time.Minute
+
+	// streamTokenPurpose first part of stream token payload, so it cannot be used as another signed token
+	streamTokenPurpose = "stream"
+)
+
+// Client device which logs in
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// NewClient constructor for Client
+func NewClient(ip string, userAgent string) Client {
+	return Client{
+		IP:        ip,
+		UserAgent: userAgent,
+	}
+}
+
+// Session created on each login, issued tokens are bound to it
+type Session struct {
+	ID         string    `db:"id"`
+	UserName   string    `db:"user_name"`
+	UserAgent  string    `db:"user_agent"`
+	IP         string    `db:"ip"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+// DefaultTimezone timezone of users who have not set it
+const DefaultTimezone = "UTC"
+
+// Profile user info shown and edited by the user
+type Profile struct {
+	ID          uint64 `db:"id"`
+	Name        string `db:"name"`
+	DisplayName string `db:"display_name"`
+	Email       string `db:"email"`
+	Timezone    string `db:"timezone"`
+}
+
+// ProductsAction what to do with products of deleted account
+type ProductsAction string
+
+const (
+	// ProductsTransfer transfer products to another user
+	ProductsTransfer ProductsAction = "transfer"
+
+	// ProductsDelete delete products with the account
+	ProductsDelete ProductsAction = "delete"
+)
+
+const (
+	// ScopeProductsRead view products
+	ScopeProductsRead = "products:read"
+
+	// ScopeProductsWrite create, update and delete products
+	ScopeProductsWrite = "products:write"
+
+	// ScopeAdmin access to admin endpoints, works only for admin keys
+	ScopeAdmin = "admin"
+
+	// APIKeyPrefix prefix of all api keys
+	APIKeyPrefix = "pk"
+)
+
+// Scopes all available api key scopes
+var Scopes = []string{ScopeProductsRead, ScopeProductsWrite, ScopeAdmin}
+
+// APIKey long-lived key for machine clients
+type APIKey struct {
+	ID         uint64     `db:"id"`
+	Prefix     string     `db:"prefix"`
+	Hash       string     `db:"hash"`
+	OwnerName  string     `db:"owner_name"`
+	Name       string     `db:"name"`
+	Scopes     []string   `db:"scopes"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+const (
+	// OIDCStateTTL time to finish oidc login
+	OIDCStateTTL = 10 * time.Minute
+)
+
+// OIDCState pending oidc login, stored until callback
+type OIDCState struct {
+	State        string    `db:"state"`
+	Provider     string    `db:"provider"`
+	CodeVerifier string    `db:"code_verifier"`
+	Nonce        string    `db:"nonce"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// ExternalIdentity user info from identity provider
+type ExternalIdentity struct {
+	Subject  string
+	Username string
+}
+
+const (
+	// MFAChallengeTTL time to enter the second factor after password login
+	MFAChallengeTTL = 5 * time.Minute
+
+	// RecoveryCodeCount number of recovery codes issued on 2fa enrolment
+	RecoveryCodeCount = 10
+
+	// RecoveryCodeSize random bytes of recovery code, codes are stored as sha-256 hashes,
+	// so they must be long enough not to be guessed from the hash
+	RecoveryCodeSize = 10
+
+	// TOTPCodeLength length of one-time code, longer codes are treated as recovery codes
+	TOTPCodeLength = 6
+)
+
+// LoginResult either token or mfa challenge, if user has two-factor authentication enabled
+type LoginResult struct {
+	Token        string
+	MFAChallenge string
+}
+
+// TOTP second factor of user, enabled after the first valid code
+type TOTP struct {
+	UserName string `db:"user_name"`
+	Secret   string `db:"secret"`
+	Enabled  bool   `db:"enabled"`
+	LastStep int64  `db:"last_step"`
+}
+
+// RecoveryCode single-use replacement for one-time code
+type RecoveryCode struct {
+	ID       uint64     `db:"id"`
+	UserName string     `db:"user_name"`
+	Hash     string     `db:"hash"`
+	UsedAt   *time.Time `db:"used_at"`
+}
+
+// MFAChallenge pending login, waiting for the second factor
+type MFAChallenge struct {
+	Challenge string    `db:"challenge"`
+	UserName  string    `db:"user_name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Settings auth service parameters
+type Settings struct {
+	Throttle LoginThrottle
+	Password PasswordPolicy
+
+	// SessionTTL lifetime of the session, the same as lifetime of the token
+	SessionTTL time.Duration
+
+	// StreamTokenTTL lifetime of tokens for product streams passed in URL
+	StreamTokenTTL time.Duration
+
+	Verification EmailVerification
+}
+
+// EmailVerification verification link parameters, the token is appended to URL as query parameter
+type EmailVerification struct {
+	TTL time.Duration
+	URL string
+}
+
+// PasswordPolicy password rules, MaxLength is in bytes
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+}
+
+// LoginThrottle brute-force protection parameters.
+// After FreeAttempts failures login is blocked for BaseDelay, the delay doubles
+// with each next failure up to MaxDelay. Failures are forgotten after ResetAfter
+type LoginThrottle struct {
+	AccountFreeAttempts int
+	IPFreeAttempts      int
+	BaseDelay           time.Duration
+	MaxDelay            time.Duration
+	ResetAfter          time.Duration
+}
+
+// LockedUntil time until the next login attempt is not allowed
+func (t LoginThrottle) LockedUntil(attempts LoginAttempts, freeAttempts int) time.Time {
+	if attempts.Failures < freeAttempts {
+		return time.Time{}
+	}
+
+	delay := t.MaxDelay
+	if shift := attempts.Failures - freeAttempts; shift < 32 && t.BaseDelay<<shift < t.MaxDelay {
+		delay = t.BaseDelay << shift
+	}
+
+	return attempts.LastFailureAt.Add(delay)
+}
+
+// LoginAttempts failed login attempts for account or ip address
+type LoginAttempts struct {
+	Key           string    `db:"key"`
+	Failures      int       `db:"failures"`
+	LastFailureAt time.Time `db:"last_failure_at"`
+}
+
+// AccountAttemptsKey key of failed attempts for account
+func AccountAttemptsKey(username string) string {
+	return "account:" + username
+}
+
+// IPAttemptsKey key of failed attempts for ip address
+func IPAttemptsKey(ip string) string {
+	return "ip:" + ip
+}
+
+const (
+	// PasswordResetTTL lifetime of password reset token
+	PasswordResetTTL = time.Hour
+)
+
+// PasswordReset pending password reset, the token consists of selector and secret, only secret hash is stored
+type PasswordReset struct {
+	Selector  string    `db:"selector"`
+	Hash      string    `db:"hash"`
+	UserName  string    `db:"user_name"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// SetupTOTP generates new totp secret and returns otpauth uri for authenticator app,
+// 2fa is enabled only after VerifyTOTP
+func (s *AuthService) SetupTOTP(tx *sqlx.Tx, username string) (string, error) {
+	totp, err := s.authRepo.FindTOTP(tx, username)
+	if err != nil && !errors.Is(err, ErrTOTPNotFound) {
+		s.log.Error("failed to find totp", "error", err, "username", username)
+		return "", shared.ErrInternal
+	}
+
+	if totp.Enabled {
+		s.log.Error(ErrTOTPAlreadyEnabled.Error(), "username", username)
+		return "", ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := s.totp.GenerateSecret()
+	if err != nil {
+		s.log.Error("failed to generate totp secret", "error", err)
+		return "", shared.ErrInternal
+	}
+
+	if err := s.authRepo.SaveTOTP(tx, TOTP{UserName: username, Secret: secret}); err != nil {
+		s.log.Error("failed to save totp", "error", err, "username", username)
+		return "", shared.ErrInternal
+	}
+
+	return s.totp.URI(username, secret), nil
+}
+
+// VerifyTOTP enables 2fa after the first valid code, returns recovery codes
+func (s *AuthService) VerifyTOTP(tx *sqlx.Tx, username string, code string) ([]string, error) {
+	totp, err := s.authRepo.FindTOTP(tx, username)
+	if err != nil {
+		s.log.Error("failed to find totp", "error", err, "username", username)
+
+		if errors.Is(err, ErrTOTPNotFound) {
+			return nil, ErrTOTPNotFound
+		}
+
+		return nil, shared.ErrInternal
+	}
+
+	if totp.Enabled {
+		s.log.Error(ErrTOTPAlreadyEnabled.Error(), "username", username)
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	step, ok := s.totp.Validate(totp.Secret, code, s.date.Now())
+	if !ok {
+		s.log.Error(ErrInvalidTOTPCode.Error(), "username", username)
+		return nil, ErrInvalidTOTPCode
+	}
+
+	totp.Enabled = true
+	totp.LastStep = step
+
+	if err := s.authRepo.SaveTOTP(tx, totp); err != nil {
+		s.log.Error("failed to save totp", "error", err, "username", username)
+		return nil, shared.ErrInternal
+	}
+
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := s.crypto.RandomString(RecoveryCodeSize)
+		if err != nil {
+			s.log.Error("failed to generate recovery code", "error", err)
+			return nil, shared.ErrInternal
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, s.crypto.HashToken(code))
+	}
+
+	if err := s.authRepo.ReplaceRecoveryCodes(tx, username, hashes); err != nil {
+		s.log.Error("failed to save recovery codes", "error", err, "username", username)
+		return nil, shared.ErrInternal
+	}
+
+	s.log.Info("two-factor authentication has been enabled", "username", username)
+	return codes, nil
+}
+
+// LoginMFA finishes login with one-time code or recovery code.
+// The challenge is consumed and the failure is saved even if the code is wrong,
+// so the caller must commit on ErrInvalidTOTPCode
+func (s *AuthService) LoginMFA(tx *sqlx.Tx, challenge string, code string, client Client) (string, error) {
+	data, err := s.authRepo.PopMFAChallenge(tx, challenge)
+	if err != nil {
+		s.log.Error("failed to find mfa challenge", "error", err)
+
+		if errors.Is(err, ErrInvalidMFAChallenge) {
+			return "", ErrInvalidMFAChallenge
+		}
+
+		return "", shared.ErrInternal
+	}
+
+	now := s.date.Now()
+	if now.Sub(data.CreatedAt) > MFAChallengeTTL {
+		s.log.Error("mfa challenge has expired", "username", data.UserName)
+		return "", ErrInvalidMFAChallenge
+	}
+
+	attempts, err := s.findLoginAttempts(tx, AccountAttemptsKey(data.UserName), s.settings.Throttle.AccountFreeAttempts, now)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkSecondFactor(tx, data.UserName, code); err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			if err := s.recordLoginFailure(tx, now, attempts.Key); err != nil {
+				return "", err
+			}
+		}
+
+		return "", err
+	}
+
+	if err := s.authRepo.DeleteLoginAttempts(tx, attempts.Key); err != nil {
+		s.log.Error("failed to reset login attempts", "error", err, "username", data.UserName)
+		return "", shared.ErrInternal
+	}
+
+	user, err := s.authRepo.FindUser(tx, data.UserName)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", data.UserName)
+		return "", shared.ErrInternal
+	}
+
+	if user.Disabled {
+		s.log.Error(ErrUserDisabled.Error(), "username", user.Name)
+		return "", ErrUserDisabled
+	}
+
+	return s.createSession(tx, user, client, now)
+}
+
+// DisableTOTP disables 2fa, requires password and one-time code or recovery code.
+// Users without password sign in with an identity provider, they confirm with the code only
+func (s *AuthService) DisableTOTP(tx *sqlx.Tx, username string, password string, code string) error {
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if user.Password != "" {
+		if err := s.crypto.CompareHashAndPassword(user.Password, password); err != nil {
+			s.log.Error("incorrect password", "username", username)
+			return ErrIncorrectPassword
+		}
+	}
+
+	if err := s.checkSecondFactor(tx, username, code); err != nil {
+		return err
+	}
+
+	if err := s.authRepo.DeleteTOTP(tx, username); err != nil {
+		s.log.Error("failed to delete totp", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	s.log.Info("two-factor authentication has been disabled", "username", username)
+	return nil
+}
+
+func (s *AuthService) createMFAChallenge(tx *sqlx.Tx, username string) (string, error) {
+	challenge, err := s.crypto.RandomString(32)
+	if err != nil {
+		s.log.Error("failed to generate mfa challenge", "error", err)
+		return "", shared.ErrInternal
+	}
+
+	data := MFAChallenge{
+		Challenge: challenge,
+		UserName:  username,
+		CreatedAt: s.date.Now(),
+	}
+
+	if err := s.authRepo.CreateMFAChallenge(tx, data); err != nil {
+		s.log.Error("failed to create mfa challenge", "error", err, "username", username)
+		return "", shared.ErrInternal
+	}
+
+	return challenge, nil
+}
+
+// checkSecondFactor accepts one-time code, each code only once, or unused recovery code
+func (s *AuthService) checkSecondFactor(tx *sqlx.Tx, username string, code string) error {
+	totp, err := s.authRepo.FindTOTP(tx, username)
+	if err != nil && !errors.Is(err, ErrTOTPNotFound) {
+		s.log.Error("failed to find totp", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	if !totp.Enabled {
+		s.log.Error(ErrTOTPNotFound.Error(), "username", username)
+		return ErrTOTPNotFound
+	}
+
+	now := s.date.Now()
+
+	if len(code) == TOTPCodeLength {
+		step, ok := s.totp.Validate(totp.Secret, code, now)
+		if !ok || step <= totp.LastStep {
+			s.log.Error(ErrInvalidTOTPCode.Error(), "username", username)
+			return ErrInvalidTOTPCode
+		}
+
+		totp.LastStep = step
+		if err := s.authRepo.SaveTOTP(tx, totp); err != nil {
+			s.log.Error("failed to save totp", "error", err, "username", username)
+			return shared.ErrInternal
+		}
+
+		return nil
+	}
+
+	codes, err := s.authRepo.FindRecoveryCodes(tx, username)
+	if err != nil {
+		s.log.Error("failed to find recovery codes", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	for _, recoveryCode := range codes {
+		if s.crypto.CompareHashAndToken(recoveryCode.Hash, code) != nil {
+			continue
+		}
+
+		if err := s.authRepo.UseRecoveryCode(tx, recoveryCode.ID, now); err != nil {
+			s.log.Error("failed to use recovery code", "error", err, "username", username)
+
+			if errors.Is(err, ErrInvalidTOTPCode) {
+				return ErrInvalidTOTPCode
+			}
+
+			return shared.ErrInternal
+		}
+
+		s.log.Info("recovery code has been used", "username", username, "left", len(codes)-1)
+		return nil
+	}
+
+	s.log.Error(ErrInvalidTOTPCode.Error(), "username", username)
+	return ErrInvalidTOTPCode
+}
@@ -0,0 +1,614 @@
+package auth_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestSetupTOTP() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsername = "test name"
+		mockSecret   = "test secret"
+		mockURI      = "otpauth://totp/product-keeper:test%20name?secret=test%20secret"
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected string
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.totp.EXPECT().GenerateSecret().Return(mockSecret, nil),
+					f.authRepo.EXPECT().SaveTOTP(f.tx, auth.TOTP{UserName: mockUsername, Secret: mockSecret}).Return(nil),
+					f.totp.EXPECT().URI(mockUsername, mockSecret).Return(mockURI),
+				)
+			},
+			expected: mockURI,
+			err:      nil,
+		},
+		{
+			name: "already enabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(auth.TOTP{UserName: mockUsername, Enabled: true}, nil),
+				)
+			},
+			expected: "",
+			err:      auth.ErrTOTPAlreadyEnabled,
+		},
+		{
+			name: "failed to save totp",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.totp.EXPECT().GenerateSecret().Return(mockSecret, nil),
+					f.authRepo.EXPECT().SaveTOTP(f.tx, auth.TOTP{UserName: mockUsername, Secret: mockSecret}).Return(shared.ErrNoData),
+				)
+			},
+			expected: "",
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, err := service.SetupTOTP(f.tx, mockUsername)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestVerifyTOTP() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockUsername = "test name"
+		mockCode     = "123456"
+		mockTOTP     = auth.TOTP{UserName: mockUsername, Secret: "test secret"}
+		mockEnabled  = auth.TOTP{UserName: mockUsername, Secret: "test secret", Enabled: true, LastStep: 100}
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected []string
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				calls := []any{
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, mockCode, now).Return(int64(100), true),
+					f.authRepo.EXPECT().SaveTOTP(f.tx, mockEnabled).Return(nil),
+				}
+				for i := 0; i < auth.RecoveryCodeCount; i++ {
+					calls = append(calls,
+						f.crypto.EXPECT().RandomString(auth.RecoveryCodeSize).Return("code", nil),
+						f.crypto.EXPECT().HashToken("code").Return("hash"),
+					)
+				}
+				calls = append(calls, f.authRepo.EXPECT().ReplaceRecoveryCodes(f.tx, mockUsername, gomock.Len(auth.RecoveryCodeCount)).Return(nil))
+
+				gomock.InOrder(calls...)
+			},
+			expected: []string{"code", "code", "code", "code", "code", "code", "code", "code", "code", "code"},
+			err:      nil,
+		},
+		{
+			name: "not set up",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+				)
+			},
+			expected: nil,
+			err:      auth.ErrTOTPNotFound,
+		},
+		{
+			name: "already enabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(mockEnabled, nil),
+				)
+			},
+			expected: nil,
+			err:      auth.ErrTOTPAlreadyEnabled,
+		},
+		{
+			name: "invalid code",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUsername).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, mockCode, now).Return(int64(0), false),
+				)
+			},
+			expected: nil,
+			err:      auth.ErrInvalidTOTPCode,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, err := service.VerifyTOTP(f.tx, mockUsername, mockCode)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestLoginMFA() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockUser      = auth.User{Name: "test name", Password: "test hashed pass", Role: auth.RoleUser}
+		mockChallenge = auth.MFAChallenge{Challenge: "test challenge", UserName: mockUser.Name, CreatedAt: now}
+		mockTOTP      = auth.TOTP{UserName: mockUser.Name, Secret: "test secret", Enabled: true, LastStep: 100}
+		mockCodes     = []auth.RecoveryCode{
+			{ID: 1, UserName: mockUser.Name, Hash: "test hash1"},
+			{ID: 2, UserName: mockUser.Name, Hash: "test hash2"},
+		}
+		mockToken     = "test jwt token"
+		mockClient    = auth.NewClient("127.0.0.1", "test agent")
+		mockSessionID = "test session"
+		mockSession   = auth.Session{
+			ID:         mockSessionID,
+			UserName:   mockUser.Name,
+			UserAgent:  mockClient.UserAgent,
+			IP:         mockClient.IP,
+			CreatedAt:  now,
+			LastSeenAt: now,
+			ExpiresAt:  now.Add(mockSettings.SessionTTL),
+		}
+
+		accountKey = auth.AccountAttemptsKey(mockUser.Name)
+	)
+
+	type args struct {
+		challenge string
+		code      string
+	}
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		args     args
+		expected string
+		err      error
+	}{
+		{
+			name: "successful launch with one-time code",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, "123456", now).Return(int64(101), true),
+					f.authRepo.EXPECT().SaveTOTP(f.tx, auth.TOTP{UserName: mockUser.Name, Secret: "test secret", Enabled: true, LastStep: 101}).Return(nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, mockUser.Name, now).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSessionID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken(mockUser.Name, string(auth.RoleUser), mockSessionID).Return(mockToken, nil),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "123456"},
+			expected: mockToken,
+			err:      nil,
+		},
+		{
+			name: "successful launch with recovery code",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindRecoveryCodes(f.tx, mockUser.Name).Return(mockCodes, nil),
+					f.crypto.EXPECT().CompareHashAndToken("test hash1", "abcdef0123").Return(shared.ErrNoData),
+					f.crypto.EXPECT().CompareHashAndToken("test hash2", "abcdef0123").Return(nil),
+					f.authRepo.EXPECT().UseRecoveryCode(f.tx, uint64(2), now).Return(nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, mockUser.Name, now).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSessionID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken(mockUser.Name, string(auth.RoleUser), mockSessionID).Return(mockToken, nil),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "abcdef0123"},
+			expected: mockToken,
+			err:      nil,
+		},
+		{
+			name: "challenge not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(auth.MFAChallenge{}, auth.ErrInvalidMFAChallenge),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "123456"},
+			expected: "",
+			err:      auth.ErrInvalidMFAChallenge,
+		},
+		{
+			name: "challenge expired",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(now.Add(auth.MFAChallengeTTL+time.Second)),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "123456"},
+			expected: "",
+			err:      auth.ErrInvalidMFAChallenge,
+		},
+		{
+			name: "account is locked",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{Key: accountKey, Failures: 10, LastFailureAt: now}, nil),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "123456"},
+			expected: "",
+			err:      auth.ErrTooManyAttempts,
+		},
+		{
+			name: "one-time code reused",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, "123456", now).Return(int64(100), true),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, accountKey, now, now.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: accountKey, Failures: 1, LastFailureAt: now}, nil),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "123456"},
+			expected: "",
+			err:      auth.ErrInvalidTOTPCode,
+		},
+		{
+			name: "invalid recovery code",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopMFAChallenge(f.tx, mockChallenge.Challenge).Return(mockChallenge, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindLoginAttempts(f.tx, accountKey).Return(auth.LoginAttempts{}, shared.ErrNoData),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().FindRecoveryCodes(f.tx, mockUser.Name).Return(mockCodes, nil),
+					f.crypto.EXPECT().CompareHashAndToken("test hash1", "abcdef0123").Return(shared.ErrNoData),
+					f.crypto.EXPECT().CompareHashAndToken("test hash2", "abcdef0123").Return(shared.ErrNoData),
+					f.authRepo.EXPECT().IncrementLoginAttempts(f.tx, accountKey, now, now.Add(-mockSettings.Throttle.ResetAfter)).Return(auth.LoginAttempts{Key: accountKey, Failures: 1, LastFailureAt: now}, nil),
+				)
+			},
+			args:     args{mockChallenge.Challenge, "abcdef0123"},
+			expected: "",
+			err:      auth.ErrInvalidTOTPCode,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, err := service.LoginMFA(f.tx, row.args.challenge, row.args.code, mockClient)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestDisableTOTP() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockUser     = auth.User{Name: "test name", Password: "test hashed pass", Role: auth.RoleUser}
+		mockPassword = "test pass"
+		mockTOTP     = auth.TOTP{UserName: mockUser.Name, Secret: "test secret", Enabled: true, LastStep: 100}
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, "123456", now).Return(int64(101), true),
+					f.authRepo.EXPECT().SaveTOTP(f.tx, gomock.Any()).Return(nil),
+					f.authRepo.EXPECT().DeleteTOTP(f.tx, mockUser.Name).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "incorrect password",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(shared.ErrNoData),
+				)
+			},
+			err: auth.ErrIncorrectPassword,
+		},
+		{
+			name: "user of identity provider confirms with the code only",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{Name: mockUser.Name, Role: auth.RoleUser}, nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, "123456", now).Return(int64(101), true),
+					f.authRepo.EXPECT().SaveTOTP(f.tx, gomock.Any()).Return(nil),
+					f.authRepo.EXPECT().DeleteTOTP(f.tx, mockUser.Name).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "not enabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+				)
+			},
+			err: auth.ErrTOTPNotFound,
+		},
+		{
+			name: "invalid code",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, mockUser.Name).Return(mockTOTP, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.totp.EXPECT().Validate(mockTOTP.Secret, "123456", now).Return(int64(0), false),
+				)
+			},
+			err: auth.ErrInvalidTOTPCode,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.DisableTOTP(f.tx, mockUser.Name, mockPassword, "123456")
+			s.Equal(row.err, err)
+		})
+	}
+}
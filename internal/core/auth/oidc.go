@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+const (
+	oidcStateSize    = 16
+	oidcNonceSize    = 16
+	oidcVerifierSize = 32
+)
+
+// StartOIDCLogin saves login state and returns provider authorization url and the state,
+// the state must be bound to the browser that started the login, e.g. with a cookie
+func (s *AuthService) StartOIDCLogin(tx *sqlx.Tx, provider string) (string, string, error) {
+	var values [3]string
+	for i, size := range []int{oidcStateSize, oidcNonceSize, oidcVerifierSize} {
+		value, err := s.crypto.RandomString(size)
+		if err != nil {
+			s.log.Error("failed to generate oidc state", "error", err)
+			return "", "", shared.ErrInternal
+		}
+
+		values[i] = value
+	}
+
+	state := OIDCState{
+		State:        values[0],
+		Provider:     provider,
+		Nonce:        values[1],
+		CodeVerifier: values[2],
+	}
+
+	url, err := s.identityProviders.AuthCodeURL(provider, state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		s.log.Error("failed to get oidc authorization url", "error", err, "provider", provider)
+
+		if errors.Is(err, ErrUnknownProvider) {
+			return "", "", ErrUnknownProvider
+		}
+
+		return "", "", shared.ErrInternal
+	}
+
+	state.CreatedAt = s.date.Now()
+
+	if err := s.authRepo.CreateOIDCState(tx, state); err != nil {
+		s.log.Error("failed to save oidc state", "error", err, "provider", provider)
+		return "", "", shared.ErrInternal
+	}
+
+	return url, state.State, nil
+}
+
+// FinishOIDCLogin exchanges authorization code, creates user on first login and returns token
+// or mfa challenge, if user has two-factor authentication enabled.
+// boundState is the state bound to the browser, the callback is accepted only in the browser that started the login
+func (s *AuthService) FinishOIDCLogin(tx *sqlx.Tx, stateValue string, boundState string, code string, client Client) (LoginResult, error) {
+	if boundState == "" || subtle.ConstantTimeCompare([]byte(stateValue), []byte(boundState)) != 1 {
+		s.log.Error("oidc state is not bound to the client", "ip", client.IP)
+		return LoginResult{}, ErrInvalidOIDCState
+	}
+
+	state, err := s.authRepo.PopOIDCState(tx, stateValue)
+	if err != nil {
+		s.log.Error("failed to find oidc state", "error", err)
+
+		if errors.Is(err, ErrInvalidOIDCState) {
+			return LoginResult{}, ErrInvalidOIDCState
+		}
+
+		return LoginResult{}, shared.ErrInternal
+	}
+
+	now := s.date.Now()
+	if now.Sub(state.CreatedAt) > OIDCStateTTL {
+		s.log.Error("oidc state has expired", "provider", state.Provider, "created_at", state.CreatedAt)
+		return LoginResult{}, ErrInvalidOIDCState
+	}
+
+	identity, err := s.identityProviders.Exchange(state.Provider, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		s.log.Error("failed to exchange oidc code", "error", err, "provider", state.Provider)
+		return LoginResult{}, ErrOIDCExchange
+	}
+
+	username, err := s.authRepo.FindIdentity(tx, state.Provider, identity.Subject)
+	switch {
+	case errors.Is(err, ErrIdentityNotFound):
+		username, err = s.provisionUser(tx, state.Provider, identity)
+		if err != nil {
+			return LoginResult{}, err
+		}
+	case err != nil:
+		s.log.Error("failed to find identity", "error", err, "provider", state.Provider, "subject", identity.Subject)
+		return LoginResult{}, shared.ErrInternal
+	}
+
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+		return LoginResult{}, shared.ErrInternal
+	}
+
+	if user.Disabled {
+		s.log.Error(ErrUserDisabled.Error(), "username", username)
+		return LoginResult{}, ErrUserDisabled
+	}
+
+	totp, err := s.authRepo.FindTOTP(tx, user.Name)
+	if err != nil && !errors.Is(err, ErrTOTPNotFound) {
+		s.log.Error("failed to find totp", "error", err, "username", user.Name)
+		return LoginResult{}, shared.ErrInternal
+	}
+
+	if totp.Enabled {
+		challenge, err := s.createMFAChallenge(tx, user.Name)
+		if err != nil {
+			return LoginResult{}, err
+		}
+
+		return LoginResult{MFAChallenge: challenge}, nil
+	}
+
+	token, err := s.createSession(tx, user, client, now)
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	return LoginResult{Token: token}, nil
+}
+
+// provisionUser creates user without password for external identity
+func (s *AuthService) provisionUser(tx *sqlx.Tx, provider string, identity ExternalIdentity) (string, error) {
+	if err := s.authRepo.CreateUser(tx, NewUser(identity.Username, "")); err != nil {
+		s.log.Error("failed to create oidc user", "error", err, "provider", provider, "username", identity.Username)
+
+		if errors.Is(err, ErrUserAlreadyExist) {
+			return "", ErrUserAlreadyExist
+		}
+
+		return "", shared.ErrInternal
+	}
+
+	if err := s.authRepo.CreateIdentity(tx, provider, identity.Subject, identity.Username); err != nil {
+		s.log.Error("failed to link identity", "error", err, "provider", provider, "subject", identity.Subject)
+		return "", shared.ErrInternal
+	}
+
+	s.log.Info("user has been provisioned from identity provider", "provider", provider, "username", identity.Username)
+	return identity.Username, nil
+}
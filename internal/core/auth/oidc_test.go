@@ -0,0 +1,387 @@
+package auth_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestStartOIDCLogin() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockState = auth.OIDCState{
+			State:        "state",
+			Provider:     "company",
+			CodeVerifier: "verifier",
+			Nonce:        "nonce",
+			CreatedAt:    now,
+		}
+		mockURL = "https://id.example.com/auth?state=state"
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected string
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("state", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("nonce", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("verifier", nil),
+					f.identityProviders.EXPECT().AuthCodeURL("company", "state", "nonce", "verifier").Return(mockURL, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().CreateOIDCState(f.tx, mockState).Return(nil),
+				)
+			},
+			expected: mockURL,
+			err:      nil,
+		},
+		{
+			name: "unknown provider",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("state", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("nonce", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("verifier", nil),
+					f.identityProviders.EXPECT().AuthCodeURL("company", "state", "nonce", "verifier").Return("", auth.ErrUnknownProvider),
+				)
+			},
+			expected: "",
+			err:      auth.ErrUnknownProvider,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("state", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("nonce", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("verifier", nil),
+					f.identityProviders.EXPECT().AuthCodeURL("company", "state", "nonce", "verifier").Return(mockURL, nil),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().CreateOIDCState(f.tx, mockState).Return(shared.ErrNoData),
+				)
+			},
+			expected: "",
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, state, err := service.StartOIDCLogin(f.tx, "company")
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+			if err == nil {
+				s.Equal(mockState.State, state)
+			}
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestFinishOIDCLogin() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockState = auth.OIDCState{
+			State:        "state",
+			Provider:     "company",
+			CodeVerifier: "verifier",
+			Nonce:        "nonce",
+			CreatedAt:    now,
+		}
+		mockIdentity = auth.ExternalIdentity{Subject: "subject", Username: "gopher"}
+		mockUser     = auth.User{Name: "gopher", Role: auth.RoleUser}
+		mockToken    = "test jwt token"
+		mockClient   = auth.NewClient("127.0.0.1", "test agent")
+		mockSession  = auth.Session{
+			ID:         "test session",
+			UserName:   "gopher",
+			UserAgent:  mockClient.UserAgent,
+			IP:         mockClient.IP,
+			CreatedAt:  now.Add(time.Minute),
+			LastSeenAt: now.Add(time.Minute),
+			ExpiresAt:  now.Add(time.Minute).Add(mockSettings.SessionTTL),
+		}
+	)
+
+	testList := []struct {
+		name       string
+		prepare    func(f *fields)
+		boundState string
+		expected   auth.LoginResult
+		err        error
+	}{
+		{
+			name: "successful launch(existing identity)",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(mockIdentity, nil),
+					f.authRepo.EXPECT().FindIdentity(f.tx, "company", "subject").Return("gopher", nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "gopher").Return(mockUser, nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, "gopher").Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, "gopher", now.Add(time.Minute)).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSession.ID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken("gopher", string(auth.RoleUser), mockSession.ID).Return(mockToken, nil),
+				)
+			},
+			boundState: "state",
+			expected:   auth.LoginResult{Token: mockToken},
+			err:        nil,
+		},
+		{
+			name: "successful launch(new user)",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(mockIdentity, nil),
+					f.authRepo.EXPECT().FindIdentity(f.tx, "company", "subject").Return("", auth.ErrIdentityNotFound),
+					f.authRepo.EXPECT().CreateUser(f.tx, auth.NewUser("gopher", "")).Return(nil),
+					f.authRepo.EXPECT().CreateIdentity(f.tx, "company", "subject", "gopher").Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "gopher").Return(mockUser, nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, "gopher").Return(auth.TOTP{}, auth.ErrTOTPNotFound),
+					f.authRepo.EXPECT().DeleteExpiredSessions(f.tx, "gopher", now.Add(time.Minute)).Return(nil),
+					f.crypto.EXPECT().RandomString(auth.SessionIDSize).Return(mockSession.ID, nil),
+					f.authRepo.EXPECT().CreateSession(f.tx, mockSession).Return(nil),
+					f.jwt.EXPECT().GenerateToken("gopher", string(auth.RoleUser), mockSession.ID).Return(mockToken, nil),
+				)
+			},
+			boundState: "state",
+			expected:   auth.LoginResult{Token: mockToken},
+			err:        nil,
+		},
+		{
+			name: "username taken by local user",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(mockIdentity, nil),
+					f.authRepo.EXPECT().FindIdentity(f.tx, "company", "subject").Return("", auth.ErrIdentityNotFound),
+					f.authRepo.EXPECT().CreateUser(f.tx, auth.NewUser("gopher", "")).Return(auth.ErrUserAlreadyExist),
+				)
+			},
+			boundState: "state",
+			err:        auth.ErrUserAlreadyExist,
+		},
+		{
+			name: "second factor is required",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(mockIdentity, nil),
+					f.authRepo.EXPECT().FindIdentity(f.tx, "company", "subject").Return("gopher", nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "gopher").Return(mockUser, nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, "gopher").Return(auth.TOTP{UserName: "gopher", Enabled: true}, nil),
+					f.crypto.EXPECT().RandomString(32).Return("test challenge", nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.authRepo.EXPECT().CreateMFAChallenge(f.tx, auth.MFAChallenge{
+						Challenge: "test challenge",
+						UserName:  "gopher",
+						CreatedAt: now.Add(time.Minute),
+					}).Return(nil),
+				)
+			},
+			boundState: "state",
+			expected:   auth.LoginResult{MFAChallenge: "test challenge"},
+			err:        nil,
+		},
+		{
+			name: "failed to find totp",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(mockIdentity, nil),
+					f.authRepo.EXPECT().FindIdentity(f.tx, "company", "subject").Return("gopher", nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "gopher").Return(mockUser, nil),
+					f.authRepo.EXPECT().FindTOTP(f.tx, "gopher").Return(auth.TOTP{}, shared.ErrNoData),
+				)
+			},
+			boundState: "state",
+			err:        shared.ErrInternal,
+		},
+		{
+			name:       "state is not bound to the client",
+			boundState: "",
+			err:        auth.ErrInvalidOIDCState,
+		},
+		{
+			name:       "state is bound to another login",
+			boundState: "other state",
+			err:        auth.ErrInvalidOIDCState,
+		},
+		{
+			name: "unknown state",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(auth.OIDCState{}, auth.ErrInvalidOIDCState),
+				)
+			},
+			boundState: "state",
+			err:        auth.ErrInvalidOIDCState,
+		},
+		{
+			name: "expired state",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Hour)),
+				)
+			},
+			boundState: "state",
+			err:        auth.ErrInvalidOIDCState,
+		},
+		{
+			name: "failed to exchange code",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(auth.ExternalIdentity{}, shared.ErrNoData),
+				)
+			},
+			boundState: "state",
+			err:        auth.ErrOIDCExchange,
+		},
+		{
+			name: "user disabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopOIDCState(f.tx, "state").Return(mockState, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.identityProviders.EXPECT().Exchange("company", "code", "verifier", "nonce").Return(mockIdentity, nil),
+					f.authRepo.EXPECT().FindIdentity(f.tx, "company", "subject").Return("gopher", nil),
+					f.authRepo.EXPECT().FindUser(f.tx, "gopher").Return(auth.User{Name: "gopher", Disabled: true}, nil),
+				)
+			},
+			boundState: "state",
+			err:        auth.ErrUserDisabled,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			data, err := service.FinishOIDCLogin(f.tx, "state", row.boundState, "code", mockClient)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, data)
+		})
+	}
+}
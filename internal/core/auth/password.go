@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+const (
+	resetSelectorSize = 8
+	resetSecretSize   = 32
+)
+
+// ChangePassword sets new password and logs out other sessions of the user, sessionID is the session of the caller.
+// Users without password sign in with an identity provider and cannot set one
+func (s *AuthService) ChangePassword(tx *sqlx.Tx, username string, sessionID string, oldPassword string, newPassword string) error {
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if user.Password == "" {
+		s.log.Error(ErrPasswordNotSet.Error(), "username", username)
+		return ErrPasswordNotSet
+	}
+
+	if err := s.crypto.CompareHashAndPassword(user.Password, oldPassword); err != nil {
+		s.log.Error("incorrect password", "username", username)
+		return ErrIncorrectPassword
+	}
+
+	if err := s.setPassword(tx, username, newPassword, sessionID); err != nil {
+		return err
+	}
+
+	s.log.Info("password has been changed", "username", username)
+	return nil
+}
+
+// RequestPasswordReset sends reset token to user. Unknown and disabled users are ignored,
+// so the response does not reveal registered usernames
+func (s *AuthService) RequestPasswordReset(tx *sqlx.Tx, username string) error {
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			s.log.Info("password reset for unknown user", "username", username)
+			return nil
+		}
+
+		s.log.Error("failed to find user", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	if user.Disabled {
+		s.log.Info("password reset for disabled user", "username", username)
+		return nil
+	}
+
+	if user.Email == "" {
+		s.log.Info("password reset for user without email", "username", username)
+		return nil
+	}
+
+	selector, err := s.crypto.RandomString(resetSelectorSize)
+	if err != nil {
+		s.log.Error("failed to generate reset selector", "error", err)
+		return shared.ErrInternal
+	}
+
+	secret, err := s.crypto.RandomString(resetSecretSize)
+	if err != nil {
+		s.log.Error("failed to generate reset secret", "error", err)
+		return shared.ErrInternal
+	}
+
+	reset := PasswordReset{
+		Selector:  selector,
+		Hash:      s.crypto.HashToken(secret),
+		UserName:  username,
+		CreatedAt: s.date.Now(),
+	}
+
+	if err := s.authRepo.SavePasswordReset(tx, reset); err != nil {
+		s.log.Error("failed to save password reset", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	message := shared.Message{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Use this token to reset your password: %s.%s\nThe token is valid for %s. If you did not request a reset, ignore this message.",
+			selector, secret, PasswordResetTTL,
+		),
+	}
+
+	if err := s.notifier.Send(message); err != nil {
+		s.log.Error("failed to send password reset", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	s.log.Info("password reset has been requested", "username", username)
+	return nil
+}
+
+// ResetPassword sets new password by reset token and logs out all sessions, the token can be used only once
+func (s *AuthService) ResetPassword(tx *sqlx.Tx, token string, newPassword string) error {
+	selector, secret, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidResetToken
+	}
+
+	reset, err := s.authRepo.PopPasswordReset(tx, selector)
+	if err != nil {
+		s.log.Error("failed to find password reset", "error", err)
+
+		if errors.Is(err, ErrInvalidResetToken) {
+			return ErrInvalidResetToken
+		}
+
+		return shared.ErrInternal
+	}
+
+	if s.date.Now().Sub(reset.CreatedAt) > PasswordResetTTL {
+		s.log.Error("password reset token has expired", "username", reset.UserName)
+		return ErrInvalidResetToken
+	}
+
+	if err := s.crypto.CompareHashAndToken(reset.Hash, secret); err != nil {
+		s.log.Error("password reset secret does not match", "username", reset.UserName)
+		return ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(tx, reset.UserName, newPassword, ""); err != nil {
+		return err
+	}
+
+	s.log.Info("password has been reset", "username", reset.UserName)
+	return nil
+}
+
+// setPassword updates password and deletes sessions of the user except keepSessionID
+func (s *AuthService) setPassword(tx *sqlx.Tx, username string, password string, keepSessionID string) error {
+	if err := s.validatePassword(password); err != nil {
+		s.log.Error("password does not match policy", "error", err, "username", username)
+		return err
+	}
+
+	hash, err := s.crypto.HashPassword(password)
+	if err != nil {
+		s.log.Error("failed to hash password", "error", err)
+		return shared.ErrInternal
+	}
+
+	if err := s.authRepo.UpdatePassword(tx, username, hash); err != nil {
+		s.log.Error("failed to update password", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if err := s.authRepo.DeleteSessions(tx, username, keepSessionID); err != nil {
+		s.log.Error("failed to delete sessions", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	return nil
+}
+
+func (s *AuthService) validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < s.settings.Password.MinLength {
+		return ErrPasswordTooShort
+	}
+
+	if s.settings.Password.MaxLength > 0 && len(password) > s.settings.Password.MaxLength {
+		return ErrPasswordTooLong
+	}
+
+	if s.breachedPasswords.Contains(password) {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
+
+// upgradePasswordHash rehashes password if stored hash uses outdated algorithm or parameters
+func (s *AuthService) upgradePasswordHash(tx *sqlx.Tx, username, hashedPassword, password string) error {
+	if !s.crypto.NeedsRehash(hashedPassword) {
+		return nil
+	}
+
+	hash, err := s.crypto.HashPassword(password)
+	if err != nil {
+		s.log.Error("failed to rehash password", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	if err := s.authRepo.UpdatePassword(tx, username, hash); err != nil {
+		s.log.Error("failed to update password hash", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	return nil
+}
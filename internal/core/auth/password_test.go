@@ -0,0 +1,435 @@
+package auth_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestChangePassword() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUser        = auth.User{Name: "test name", Password: "test hashed pass", Role: auth.RoleUser}
+		mockOldPassword = "test old pass"
+		mockNewPassword = "test new pass"
+		mockSessionID   = "test session"
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    string
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockOldPassword).Return(nil),
+					f.breachedPasswords.EXPECT().Contains(mockNewPassword).Return(false),
+					f.crypto.EXPECT().HashPassword(mockNewPassword).Return("test new hash", nil),
+					f.authRepo.EXPECT().UpdatePassword(f.tx, mockUser.Name, "test new hash").Return(nil),
+					f.authRepo.EXPECT().DeleteSessions(f.tx, mockUser.Name, mockSessionID).Return(nil),
+				)
+			},
+			args: mockNewPassword,
+			err:  nil,
+		},
+		{
+			name: "incorrect old password",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockOldPassword).Return(shared.ErrNoData),
+				)
+			},
+			args: mockNewPassword,
+			err:  auth.ErrIncorrectPassword,
+		},
+		{
+			name: "user of identity provider has no password",
+			prepare: func(f *fields) {
+				f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{Name: mockUser.Name, Role: auth.RoleUser}, nil)
+			},
+			args: mockNewPassword,
+			err:  auth.ErrPasswordNotSet,
+		},
+		{
+			name: "new password too short",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockOldPassword).Return(nil),
+				)
+			},
+			args: "short",
+			err:  auth.ErrPasswordTooShort,
+		},
+		{
+			name: "failed to update password",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockOldPassword).Return(nil),
+					f.breachedPasswords.EXPECT().Contains(mockNewPassword).Return(false),
+					f.crypto.EXPECT().HashPassword(mockNewPassword).Return("test new hash", nil),
+					f.authRepo.EXPECT().UpdatePassword(f.tx, mockUser.Name, "test new hash").Return(shared.ErrNoData),
+				)
+			},
+			args: mockNewPassword,
+			err:  shared.ErrInternal,
+		},
+		{
+			name: "failed to delete sessions",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockOldPassword).Return(nil),
+					f.breachedPasswords.EXPECT().Contains(mockNewPassword).Return(false),
+					f.crypto.EXPECT().HashPassword(mockNewPassword).Return("test new hash", nil),
+					f.authRepo.EXPECT().UpdatePassword(f.tx, mockUser.Name, "test new hash").Return(nil),
+					f.authRepo.EXPECT().DeleteSessions(f.tx, mockUser.Name, mockSessionID).Return(shared.ErrNoData),
+				)
+			},
+			args: mockNewPassword,
+			err:  shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.ChangePassword(f.tx, mockUser.Name, mockSessionID, mockOldPassword, row.args)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestRequestPasswordReset() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockUser  = auth.User{Name: "test name", Password: "test hashed pass", Role: auth.RoleUser, Email: "test@example.com"}
+		mockReset = auth.PasswordReset{
+			Selector:  "selector",
+			Hash:      "secret hash",
+			UserName:  mockUser.Name,
+			CreatedAt: now,
+		}
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("selector", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("secret", nil),
+					f.crypto.EXPECT().HashToken("secret").Return("secret hash"),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().SavePasswordReset(f.tx, mockReset).Return(nil),
+					f.notifier.EXPECT().Send(gomock.Cond(func(x any) bool {
+						message := x.(shared.Message)
+						return message.To == mockUser.Email && gomock.Regex("selector.secret").Matches(message.Body)
+					})).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "unknown user is ignored",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "disabled user is ignored",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{Name: mockUser.Name, Disabled: true}, nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "user without email is ignored",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{Name: mockUser.Name}, nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "failed to send notification",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("selector", nil),
+					f.crypto.EXPECT().RandomString(gomock.Any()).Return("secret", nil),
+					f.crypto.EXPECT().HashToken("secret").Return("secret hash"),
+					f.date.EXPECT().Now().Return(now),
+					f.authRepo.EXPECT().SavePasswordReset(f.tx, mockReset).Return(nil),
+					f.notifier.EXPECT().Send(gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.RequestPasswordReset(f.tx, mockUser.Name)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestResetPassword() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockReset = auth.PasswordReset{
+			Selector:  "selector",
+			Hash:      "secret hash",
+			UserName:  "test name",
+			CreatedAt: now,
+		}
+		mockNewPassword = "test new pass"
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    string
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopPasswordReset(f.tx, "selector").Return(mockReset, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.crypto.EXPECT().CompareHashAndToken("secret hash", "secret").Return(nil),
+					f.breachedPasswords.EXPECT().Contains(mockNewPassword).Return(false),
+					f.crypto.EXPECT().HashPassword(mockNewPassword).Return("test new hash", nil),
+					f.authRepo.EXPECT().UpdatePassword(f.tx, mockReset.UserName, "test new hash").Return(nil),
+					f.authRepo.EXPECT().DeleteSessions(f.tx, mockReset.UserName, "").Return(nil),
+				)
+			},
+			args: "selector.secret",
+			err:  nil,
+		},
+		{
+			name: "malformed token",
+			args: "selector",
+			err:  auth.ErrInvalidResetToken,
+		},
+		{
+			name: "token not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopPasswordReset(f.tx, "selector").Return(auth.PasswordReset{}, auth.ErrInvalidResetToken),
+				)
+			},
+			args: "selector.secret",
+			err:  auth.ErrInvalidResetToken,
+		},
+		{
+			name: "token expired",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopPasswordReset(f.tx, "selector").Return(mockReset, nil),
+					f.date.EXPECT().Now().Return(now.Add(auth.PasswordResetTTL+time.Second)),
+				)
+			},
+			args: "selector.secret",
+			err:  auth.ErrInvalidResetToken,
+		},
+		{
+			name: "secret does not match",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().PopPasswordReset(f.tx, "selector").Return(mockReset, nil),
+					f.date.EXPECT().Now().Return(now.Add(time.Minute)),
+					f.crypto.EXPECT().CompareHashAndToken("secret hash", "wrong").Return(shared.ErrNoData),
+				)
+			},
+			args: "selector.wrong",
+			err:  auth.ErrInvalidResetToken,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.ResetPassword(f.tx, row.args, mockNewPassword)
+			s.Equal(row.err, err)
+		})
+	}
+}
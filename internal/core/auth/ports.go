@@ -1,11 +1,76 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
 )
 
 // AuthRepo ...
 type AuthRepo interface {
 	CreateUser(tx *sqlx.Tx, user User) error
-	FindPassword(tx *sqlx.Tx, name string) (string, error)
+	FindUser(tx *sqlx.Tx, name string) (User, error)
+	FindUserList(tx *sqlx.Tx) ([]User, error)
+	DisableUser(tx *sqlx.Tx, name string) error
+	UpdatePassword(tx *sqlx.Tx, name string, password string) error
+	FindProfile(tx *sqlx.Tx, name string) (Profile, error)
+	UpdateProfile(tx *sqlx.Tx, profile Profile) (Profile, error)
+	DeleteUser(tx *sqlx.Tx, id uint64) error
+	VerifyEmail(tx *sqlx.Tx, name string) error
+	ResetEmailVerification(tx *sqlx.Tx, name string) error
+
+	CreateAPIKey(tx *sqlx.Tx, key APIKey) (uint64, error)
+	FindAPIKey(tx *sqlx.Tx, prefix string) (APIKey, error)
+	FindAPIKeyList(tx *sqlx.Tx, ownerName string) ([]APIKey, error)
+	RevokeAPIKey(tx *sqlx.Tx, id uint64, ownerName string, revokedAt time.Time) error
+	UpdateAPIKeyLastUsed(tx *sqlx.Tx, id uint64, lastUsedAt time.Time) error
+
+	CreateOIDCState(tx *sqlx.Tx, state OIDCState) error
+	PopOIDCState(tx *sqlx.Tx, state string) (OIDCState, error)
+	FindIdentity(tx *sqlx.Tx, provider string, subject string) (string, error)
+	CreateIdentity(tx *sqlx.Tx, provider string, subject string, username string) error
+
+	SaveTOTP(tx *sqlx.Tx, totp TOTP) error
+	FindTOTP(tx *sqlx.Tx, username string) (TOTP, error)
+	DeleteTOTP(tx *sqlx.Tx, username string) error
+	ReplaceRecoveryCodes(tx *sqlx.Tx, username string, hashes []string) error
+	FindRecoveryCodes(tx *sqlx.Tx, username string) ([]RecoveryCode, error)
+	UseRecoveryCode(tx *sqlx.Tx, id uint64, usedAt time.Time) error
+	CreateMFAChallenge(tx *sqlx.Tx, challenge MFAChallenge) error
+	PopMFAChallenge(tx *sqlx.Tx, challenge string) (MFAChallenge, error)
+
+	FindLoginAttempts(tx *sqlx.Tx, key string) (LoginAttempts, error)
+	IncrementLoginAttempts(tx *sqlx.Tx, key string, failedAt time.Time, resetBefore time.Time) (LoginAttempts, error)
+	DeleteLoginAttempts(tx *sqlx.Tx, key string) error
+
+	CreateSession(tx *sqlx.Tx, session Session) error
+	FindSession(tx *sqlx.Tx, id string) (Session, error)
+	FindSessionList(tx *sqlx.Tx, username string, now time.Time) ([]Session, error)
+	TouchSession(tx *sqlx.Tx, id string, lastSeenAt time.Time, ip string) error
+	DeleteSession(tx *sqlx.Tx, id string, username string) error
+	DeleteExpiredSessions(tx *sqlx.Tx, username string, now time.Time) error
+	DeleteSessions(tx *sqlx.Tx, username string, exceptID string) error
+
+	SavePasswordReset(tx *sqlx.Tx, reset PasswordReset) error
+	PopPasswordReset(tx *sqlx.Tx, selector string) (PasswordReset, error)
+}
+
+// IdentityProviders openid connect providers
+type IdentityProviders interface {
+	AuthCodeURL(provider string, state string, nonce string, codeVerifier string) (string, error)
+	Exchange(provider string, code string, codeVerifier string, nonce string) (ExternalIdentity, error)
+}
+
+// BreachedPasswords list of passwords known from data breaches
+type BreachedPasswords interface {
+	Contains(password string) bool
+}
+
+// ProductsOwnership products owned by users, used when account is deleted.
+// Product events are sent in the transaction, the returned function publishes them after commit
+type ProductsOwnership interface {
+	TransferProducts(tx *sqlx.Tx, actor shared.Subject, fromName, toName string) (func(), error)
+	DeleteProducts(tx *sqlx.Tx, actor shared.Subject, ownerName string) (func(), error)
 }
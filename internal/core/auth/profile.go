@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// FindProfile ...
+func (s *AuthService) FindProfile(tx *sqlx.Tx, username string) (Profile, error) {
+	profile, err := s.authRepo.FindProfile(tx, username)
+	if err != nil {
+		s.log.Error("failed to find profile", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return Profile{}, ErrUserNotFound
+		}
+
+		return Profile{}, shared.ErrInternal
+	}
+
+	return profile, nil
+}
+
+// UpdateProfile changes display name, email and timezone, empty timezone means DefaultTimezone.
+// Changed email makes the account pending until the new email is verified
+func (s *AuthService) UpdateProfile(tx *sqlx.Tx, username string, profile Profile) (Profile, error) {
+	if profile.Email != "" && !validEmail(profile.Email) {
+		s.log.Error(ErrInvalidEmail.Error(), "username", username, "email", profile.Email)
+		return Profile{}, ErrInvalidEmail
+	}
+
+	if profile.Timezone == "" {
+		profile.Timezone = DefaultTimezone
+	}
+
+	if _, err := time.LoadLocation(profile.Timezone); err != nil {
+		s.log.Error(ErrInvalidTimezone.Error(), "error", err, "username", username, "timezone", profile.Timezone)
+		return Profile{}, ErrInvalidTimezone
+	}
+
+	current, err := s.authRepo.FindProfile(tx, username)
+	if err != nil {
+		s.log.Error("failed to find profile", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return Profile{}, ErrUserNotFound
+		}
+
+		return Profile{}, shared.ErrInternal
+	}
+
+	profile.Name = username
+
+	updated, err := s.authRepo.UpdateProfile(tx, profile)
+	if err != nil {
+		s.log.Error("failed to update profile", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return Profile{}, ErrUserNotFound
+		}
+
+		return Profile{}, shared.ErrInternal
+	}
+
+	if updated.Email == current.Email {
+		return updated, nil
+	}
+
+	if err := s.authRepo.ResetEmailVerification(tx, username); err != nil {
+		s.log.Error("failed to reset email verification", "error", err, "username", username)
+		return Profile{}, shared.ErrInternal
+	}
+
+	// without email the account stays pending until a new email is set and verified
+	if updated.Email != "" {
+		if err := s.sendVerification(User{Name: username, Email: updated.Email}); err != nil {
+			return Profile{}, err
+		}
+	}
+
+	s.log.Info("email has been changed, verification is required", "username", username)
+	return updated, nil
+}
+
+// DeleteAccount deletes user after password confirmation, owned products are transferred to another user or deleted.
+// Users without password sign in with an identity provider and cannot confirm the deletion.
+// The returned function publishes product changes and must be called after the transaction is committed
+func (s *AuthService) DeleteAccount(tx *sqlx.Tx, username, password string, action ProductsAction, transferTo string) (func(), error) {
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, shared.ErrInternal
+	}
+
+	if user.Password == "" {
+		s.log.Error(ErrPasswordNotSet.Error(), "username", username)
+		return nil, ErrPasswordNotSet
+	}
+
+	if err := s.crypto.CompareHashAndPassword(user.Password, password); err != nil {
+		s.log.Error("incorrect password", "username", username)
+		return nil, ErrIncorrectPassword
+	}
+
+	actor := shared.NewSubject(user.Name, string(user.Role))
+
+	var publish func()
+	switch action {
+	case ProductsTransfer:
+		if transferTo == "" || transferTo == username {
+			s.log.Error(ErrInvalidTransferTarget.Error(), "username", username, "transfer_to", transferTo)
+			return nil, ErrInvalidTransferTarget
+		}
+
+		receiver, err := s.authRepo.FindUser(tx, transferTo)
+		if err != nil {
+			s.log.Error("failed to find transfer target", "error", err, "username", username, "transfer_to", transferTo)
+
+			if errors.Is(err, ErrUserNotFound) {
+				return nil, ErrInvalidTransferTarget
+			}
+
+			return nil, shared.ErrInternal
+		}
+
+		if receiver.Disabled {
+			s.log.Error(ErrInvalidTransferTarget.Error(), "username", username, "transfer_to", transferTo)
+			return nil, ErrInvalidTransferTarget
+		}
+
+		publish, err = s.productsOwnership.TransferProducts(tx, actor, username, receiver.Name)
+		if err != nil {
+			s.log.Error("failed to transfer products", "error", err, "username", username, "transfer_to", transferTo)
+			return nil, shared.ErrInternal
+		}
+	case ProductsDelete:
+		publish, err = s.productsOwnership.DeleteProducts(tx, actor, username)
+		if err != nil {
+			s.log.Error("failed to delete products", "error", err, "username", username)
+			return nil, shared.ErrInternal
+		}
+	default:
+		s.log.Error(ErrInvalidProductsAction.Error(), "username", username, "action", action)
+		return nil, ErrInvalidProductsAction
+	}
+
+	if err := s.authRepo.DeleteLoginAttempts(tx, AccountAttemptsKey(username)); err != nil {
+		s.log.Error("failed to delete login attempts", "error", err, "username", username)
+		return nil, shared.ErrInternal
+	}
+
+	if err := s.authRepo.DeleteUser(tx, user.ID); err != nil {
+		s.log.Error("failed to delete user", "error", err, "username", username)
+		return nil, shared.ErrInternal
+	}
+
+	return publish, nil
+}
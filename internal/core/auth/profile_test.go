@@ -0,0 +1,532 @@
+package auth_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestFindProfile() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	mockProfile := auth.Profile{ID: 1, Name: "test name", DisplayName: "Test Name", Email: "test@example.com", Timezone: "UTC"}
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected auth.Profile
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockProfile.Name).Return(mockProfile, nil),
+				)
+			},
+			expected: mockProfile,
+			err:      nil,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockProfile.Name).Return(auth.Profile{}, auth.ErrUserNotFound),
+				)
+			},
+			expected: auth.Profile{},
+			err:      auth.ErrUserNotFound,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockProfile.Name).Return(auth.Profile{}, shared.ErrNoData),
+				)
+			},
+			expected: auth.Profile{},
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			profile, err := service.FindProfile(f.tx, mockProfile.Name)
+			s.Equal(row.expected, profile)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestUpdateProfile() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsername = "test name"
+		mockProfile  = auth.Profile{DisplayName: "Test Name", Email: "test@example.com", Timezone: "Europe/Moscow"}
+		mockUpdated  = auth.Profile{ID: 1, Name: mockUsername, DisplayName: "Test Name", Email: "test@example.com", Timezone: "Europe/Moscow"}
+		mockCurrent  = auth.Profile{ID: 1, Name: mockUsername, Email: "test@example.com", Timezone: auth.DefaultTimezone}
+		mockNow      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// base64url of name and new email, unix time of mockNow plus verification ttl
+		mockPayload = "dGVzdCBuYW1l.bmV3QGV4YW1wbGUuY29t.946771200"
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		args     auth.Profile
+		expected auth.Profile
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(mockCurrent, nil),
+					f.authRepo.EXPECT().UpdateProfile(f.tx, auth.Profile{
+						Name:        mockUsername,
+						DisplayName: mockProfile.DisplayName,
+						Email:       mockProfile.Email,
+						Timezone:    mockProfile.Timezone,
+					}).Return(mockUpdated, nil),
+				)
+			},
+			args:     mockProfile,
+			expected: mockUpdated,
+			err:      nil,
+		},
+		{
+			name: "default timezone",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(auth.Profile{ID: 1, Name: mockUsername}, nil),
+					f.authRepo.EXPECT().UpdateProfile(f.tx, auth.Profile{
+						Name:     mockUsername,
+						Timezone: auth.DefaultTimezone,
+					}).Return(auth.Profile{ID: 1, Name: mockUsername, Timezone: auth.DefaultTimezone}, nil),
+				)
+			},
+			args:     auth.Profile{},
+			expected: auth.Profile{ID: 1, Name: mockUsername, Timezone: auth.DefaultTimezone},
+			err:      nil,
+		},
+		{
+			name: "changed email must be verified",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(mockCurrent, nil),
+					f.authRepo.EXPECT().UpdateProfile(f.tx, auth.Profile{
+						Name:     mockUsername,
+						Email:    "new@example.com",
+						Timezone: auth.DefaultTimezone,
+					}).Return(auth.Profile{ID: 1, Name: mockUsername, Email: "new@example.com", Timezone: auth.DefaultTimezone}, nil),
+					f.authRepo.EXPECT().ResetEmailVerification(f.tx, mockUsername).Return(nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.signer.EXPECT().Sign(mockPayload).Return("signature"),
+					f.notifier.EXPECT().Send(gomock.Cond(func(x any) bool {
+						message := x.(shared.Message)
+						return message.To == "new@example.com" &&
+							strings.Contains(message.Body, mockSettings.Verification.URL+"?token="+mockPayload+".signature")
+					})).Return(nil),
+				)
+			},
+			args:     auth.Profile{Email: "new@example.com"},
+			expected: auth.Profile{ID: 1, Name: mockUsername, Email: "new@example.com", Timezone: auth.DefaultTimezone},
+			err:      nil,
+		},
+		{
+			name: "removed email makes account pending",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(mockCurrent, nil),
+					f.authRepo.EXPECT().UpdateProfile(f.tx, auth.Profile{
+						Name:     mockUsername,
+						Timezone: auth.DefaultTimezone,
+					}).Return(auth.Profile{ID: 1, Name: mockUsername, Timezone: auth.DefaultTimezone}, nil),
+					f.authRepo.EXPECT().ResetEmailVerification(f.tx, mockUsername).Return(nil),
+				)
+			},
+			args:     auth.Profile{},
+			expected: auth.Profile{ID: 1, Name: mockUsername, Timezone: auth.DefaultTimezone},
+			err:      nil,
+		},
+		{
+			name: "failed to send verification",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(mockCurrent, nil),
+					f.authRepo.EXPECT().UpdateProfile(f.tx, gomock.Any()).Return(auth.Profile{ID: 1, Name: mockUsername, Email: "new@example.com"}, nil),
+					f.authRepo.EXPECT().ResetEmailVerification(f.tx, mockUsername).Return(nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.signer.EXPECT().Sign(mockPayload).Return("signature"),
+					f.notifier.EXPECT().Send(gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args:     auth.Profile{Email: "new@example.com"},
+			expected: auth.Profile{},
+			err:      shared.ErrInternal,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(auth.Profile{}, auth.ErrUserNotFound),
+				)
+			},
+			args:     mockProfile,
+			expected: auth.Profile{},
+			err:      auth.ErrUserNotFound,
+		},
+		{
+			name:     "invalid email",
+			args:     auth.Profile{Email: "Test <test@example.com>", Timezone: "UTC"},
+			expected: auth.Profile{},
+			err:      auth.ErrInvalidEmail,
+		},
+		{
+			name:     "invalid timezone",
+			args:     auth.Profile{Timezone: "Mars/Olympus"},
+			expected: auth.Profile{},
+			err:      auth.ErrInvalidTimezone,
+		},
+		{
+			name: "failed to update profile",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindProfile(f.tx, mockUsername).Return(mockCurrent, nil),
+					f.authRepo.EXPECT().UpdateProfile(f.tx, gomock.Any()).Return(auth.Profile{}, shared.ErrNoData),
+				)
+			},
+			args:     mockProfile,
+			expected: auth.Profile{},
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			profile, err := service.UpdateProfile(f.tx, mockUsername, row.args)
+			s.Equal(row.expected, profile)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestDeleteAccount() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUser     = auth.User{ID: 1, Name: "test name", Password: "test hashed pass", Role: auth.RoleUser}
+		mockReceiver = auth.User{ID: 2, Name: "test receiver", Role: auth.RoleUser}
+		mockPassword = "test pass"
+		accountKey   = auth.AccountAttemptsKey(mockUser.Name)
+		mockActor    = shared.NewSubject(mockUser.Name, string(mockUser.Role))
+		mockPublish  = func() {}
+	)
+
+	type args struct {
+		action     auth.ProductsAction
+		transferTo string
+	}
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    args
+		err     error
+	}{
+		{
+			name: "transfer products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, mockReceiver.Name).Return(mockReceiver, nil),
+					f.productsOwnership.EXPECT().TransferProducts(f.tx, mockActor, mockUser.Name, mockReceiver.Name).Return(mockPublish, nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteUser(f.tx, mockUser.ID).Return(nil),
+				)
+			},
+			args: args{action: auth.ProductsTransfer, transferTo: mockReceiver.Name},
+			err:  nil,
+		},
+		{
+			name: "delete products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.productsOwnership.EXPECT().DeleteProducts(f.tx, mockActor, mockUser.Name).Return(mockPublish, nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteUser(f.tx, mockUser.ID).Return(nil),
+				)
+			},
+			args: args{action: auth.ProductsDelete},
+			err:  nil,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			args: args{action: auth.ProductsDelete},
+			err:  auth.ErrUserNotFound,
+		},
+		{
+			name: "incorrect password",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(shared.ErrNoData),
+				)
+			},
+			args: args{action: auth.ProductsDelete},
+			err:  auth.ErrIncorrectPassword,
+		},
+		{
+			name: "user of identity provider has no password",
+			prepare: func(f *fields) {
+				f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{ID: mockUser.ID, Name: mockUser.Name, Role: auth.RoleUser}, nil)
+			},
+			args: args{action: auth.ProductsDelete},
+			err:  auth.ErrPasswordNotSet,
+		},
+		{
+			name: "invalid products action",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+				)
+			},
+			args: args{action: "keep"},
+			err:  auth.ErrInvalidProductsAction,
+		},
+		{
+			name: "transfer to yourself",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+				)
+			},
+			args: args{action: auth.ProductsTransfer, transferTo: mockUser.Name},
+			err:  auth.ErrInvalidTransferTarget,
+		},
+		{
+			name: "transfer target not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, mockReceiver.Name).Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			args: args{action: auth.ProductsTransfer, transferTo: mockReceiver.Name},
+			err:  auth.ErrInvalidTransferTarget,
+		},
+		{
+			name: "transfer target disabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.authRepo.EXPECT().FindUser(f.tx, mockReceiver.Name).Return(auth.User{ID: 2, Name: mockReceiver.Name, Disabled: true}, nil),
+				)
+			},
+			args: args{action: auth.ProductsTransfer, transferTo: mockReceiver.Name},
+			err:  auth.ErrInvalidTransferTarget,
+		},
+		{
+			name: "failed to delete user",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.productsOwnership.EXPECT().DeleteProducts(f.tx, mockActor, mockUser.Name).Return(mockPublish, nil),
+					f.authRepo.EXPECT().DeleteLoginAttempts(f.tx, accountKey).Return(nil),
+					f.authRepo.EXPECT().DeleteUser(f.tx, mockUser.ID).Return(shared.ErrNoData),
+				)
+			},
+			args: args{action: auth.ProductsDelete},
+			err:  shared.ErrInternal,
+		},
+		{
+			name: "failed to delete products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, mockPassword).Return(nil),
+					f.productsOwnership.EXPECT().DeleteProducts(f.tx, mockActor, mockUser.Name).Return(nil, shared.ErrInternal),
+				)
+			},
+			args: args{action: auth.ProductsDelete},
+			err:  shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			publish, err := service.DeleteAccount(f.tx, mockUser.Name, mockPassword, row.args.action, row.args.transferTo)
+			s.Equal(row.err, err)
+			s.Equal(row.err == nil, publish != nil)
+		})
+	}
+}
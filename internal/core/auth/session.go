@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// FindSessionList active sessions of the user
+func (s *AuthService) FindSessionList(tx *sqlx.Tx, username string) ([]Session, error) {
+	sessions, err := s.authRepo.FindSessionList(tx, username, s.date.Now())
+	if err != nil {
+		s.log.Error("failed to find sessions", "error", err, "username", username)
+		return nil, shared.ErrInternal
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession logs out the session, tokens bound to it become invalid
+func (s *AuthService) DeleteSession(tx *sqlx.Tx, username string, id string) error {
+	if err := s.authRepo.DeleteSession(tx, id, username); err != nil {
+		s.log.Error("failed to delete session", "error", err, "username", username, "id", id)
+
+		if errors.Is(err, ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	return nil
+}
+
+// CreateStreamToken issues token for product streams of clients unable to set headers, e.g. browser
+// EventSource and WebSocket. The token is passed in URL, so it is short-lived and bound to the session
+func (s *AuthService) CreateStreamToken(username string, sessionID string) (string, time.Time, error) {
+	if sessionID == "" {
+		s.log.Error("stream token requires session", "username", username)
+		return "", time.Time{}, ErrInvalidToken
+	}
+
+	expiresAt := s.date.Now().Add(s.settings.StreamTokenTTL)
+
+	payload := strings.Join([]string{
+		streamTokenPurpose,
+		base64.RawURLEncoding.EncodeToString([]byte(username)),
+		sessionID,
+		strconv.FormatInt(expiresAt.Unix(), 10),
+	}, ".")
+
+	return payload + "." + s.signer.Sign(payload), expiresAt, nil
+}
+
+// IdentifyStreamToken checks stream token and its session, returns the user
+func (s *AuthService) IdentifyStreamToken(tx *sqlx.Tx, token string, ip string) (shared.Subject, error) {
+	username, sessionID, expiresAt, ok := s.parseStreamToken(token)
+	if !ok {
+		s.log.Error("invalid stream token")
+		return shared.Subject{}, ErrInvalidToken
+	}
+
+	if !s.date.Now().Before(expiresAt) {
+		s.log.Error("stream token has expired", "username", username, "session", sessionID)
+		return shared.Subject{}, ErrInvalidToken
+	}
+
+	return s.identifySession(tx, username, sessionID, ip)
+}
+
+func (s *AuthService) parseStreamToken(token string) (string, string, time.Time, bool) {
+	payload, signature, ok := cutLast(token, ".")
+	if !ok || !s.signer.Verify(payload, signature) {
+		return "", "", time.Time{}, false
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 4 || parts[0] != streamTokenPurpose || parts[2] == "" {
+		return "", "", time.Time{}, false
+	}
+
+	username, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	return string(username), parts[2], time.Unix(expiresAt, 0), true
+}
+
+// createSession records the login and issues token bound to the session
+func (s *AuthService) createSession(tx *sqlx.Tx, user User, client Client, now time.Time) (string, error) {
+	if err := s.authRepo.DeleteExpiredSessions(tx, user.Name, now); err != nil {
+		s.log.Error("failed to delete expired sessions", "error", err, "username", user.Name)
+		return "", shared.ErrInternal
+	}
+
+	id, err := s.crypto.RandomString(SessionIDSize)
+	if err != nil {
+		s.log.Error("failed to generate session id", "error", err, "username", user.Name)
+		return "", shared.ErrInternal
+	}
+
+	session := Session{
+		ID:         id,
+		UserName:   user.Name,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.settings.SessionTTL),
+	}
+
+	if err := s.authRepo.CreateSession(tx, session); err != nil {
+		s.log.Error("failed to create session", "error", err, "username", user.Name)
+		return "", shared.ErrInternal
+	}
+
+	token, err := s.jwt.GenerateToken(user.Name, string(user.Role), id)
+	if err != nil {
+		s.log.Error("failed to generate token", "error", err, "username", user.Name)
+		return "", shared.ErrInternal
+	}
+
+	return token, nil
+}
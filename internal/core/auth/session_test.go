@@ -0,0 +1,487 @@
+package auth_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestFindSessionList() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsername = "test name"
+		mockNow      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockSessions = []auth.Session{
+			{ID: "test session1", UserName: mockUsername, UserAgent: "test agent", IP: "127.0.0.1", CreatedAt: mockNow, LastSeenAt: mockNow, ExpiresAt: mockNow.Add(time.Hour)},
+			{ID: "test session2", UserName: mockUsername, UserAgent: "test agent", IP: "127.0.0.2", CreatedAt: mockNow, LastSeenAt: mockNow, ExpiresAt: mockNow.Add(time.Hour)},
+		}
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected []auth.Session
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindSessionList(f.tx, mockUsername, mockNow).Return(mockSessions, nil),
+				)
+			},
+			expected: mockSessions,
+			err:      nil,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindSessionList(f.tx, mockUsername, mockNow).Return(nil, shared.ErrNoData),
+				)
+			},
+			expected: nil,
+			err:      shared.ErrInternal,
+		},
+	}
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			sessions, err := service.FindSessionList(f.tx, mockUsername)
+			s.Equal(row.expected, sessions)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestDeleteSession() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockUsername  = "test name"
+		mockSessionID = "test session"
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DeleteSession(f.tx, mockSessionID, mockUsername).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "session not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DeleteSession(f.tx, mockSessionID, mockUsername).Return(auth.ErrSessionNotFound),
+				)
+			},
+			err: auth.ErrSessionNotFound,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().DeleteSession(f.tx, mockSessionID, mockUsername).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.DeleteSession(f.tx, mockUsername, mockSessionID)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestCreateStreamToken() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockNow     = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockPayload = "stream.dGVzdCBuYW1l.test session.946684860"
+	)
+
+	testList := []struct {
+		name      string
+		prepare   func(f *fields)
+		sessionID string
+		expected  string
+		expiresAt time.Time
+		err       error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.signer.EXPECT().Sign(mockPayload).Return("signature"),
+				)
+			},
+			sessionID: "test session",
+			expected:  mockPayload + ".signature",
+			expiresAt: mockNow.Add(mockSettings.StreamTokenTTL),
+			err:       nil,
+		},
+		{
+			name:      "request without session",
+			sessionID: "",
+			expected:  "",
+			err:       auth.ErrInvalidToken,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			token, expiresAt, err := service.CreateStreamToken("test name", row.sessionID)
+			s.Equal(row.expected, token)
+			s.Equal(row.expiresAt, expiresAt)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestIdentifyStreamToken() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockIP        = "127.0.0.1"
+		mockSessionID = "test session"
+		mockNow       = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockPayload   = "stream.dGVzdCBuYW1l.test session.946684860"
+		mockSession   = auth.Session{
+			ID:         mockSessionID,
+			UserName:   "test name",
+			CreatedAt:  mockNow.Add(-10 * time.Minute),
+			LastSeenAt: mockNow.Add(-10 * time.Second),
+			ExpiresAt:  mockNow.Add(10 * time.Minute),
+		}
+		mockUser = auth.User{Name: "test name", Role: auth.RoleUser}
+	)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		args     string
+		expected shared.Subject
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(mockUser, nil),
+				)
+			},
+			args:     mockPayload + ".signature",
+			expected: shared.NewSubject("test name", string(auth.RoleUser)),
+			err:      nil,
+		},
+		{
+			name: "invalid signature",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(false),
+				)
+			},
+			args:     mockPayload + ".signature",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "signed token of another purpose",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify("dGVzdCBuYW1l.dGVzdEBleGFtcGxlLmNvbQ.946684860", "signature").Return(true),
+				)
+			},
+			args:     "dGVzdCBuYW1l.dGVzdEBleGFtcGxlLmNvbQ.946684860.signature",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name:     "malformed token",
+			args:     "test token",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "token has expired",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow.Add(time.Minute)),
+				)
+			},
+			args:     mockPayload + ".signature",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "session has been deleted",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(auth.Session{}, auth.ErrSessionNotFound),
+				)
+			},
+			args:     mockPayload + ".signature",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "user is disabled",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(mockSession, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, "test name").Return(auth.User{Name: "test name", Disabled: true}, nil),
+				)
+			},
+			args:     mockPayload + ".signature",
+			expected: shared.Subject{},
+			err:      auth.ErrInvalidToken,
+		},
+		{
+			name: "failed to find session",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindSession(f.tx, mockSessionID).Return(auth.Session{}, shared.ErrNoData),
+				)
+			},
+			args:     mockPayload + ".signature",
+			expected: shared.Subject{},
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			subject, err := service.IdentifyStreamToken(f.tx, row.args, mockIP)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, subject)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// findLoginAttempts returns ErrTooManyAttempts if login for key is blocked at the moment
+func (s *AuthService) findLoginAttempts(tx *sqlx.Tx, key string, freeAttempts int, now time.Time) (LoginAttempts, error) {
+	attempts, err := s.authRepo.FindLoginAttempts(tx, key)
+	if err != nil {
+		if errors.Is(err, shared.ErrNoData) {
+			return LoginAttempts{Key: key}, nil
+		}
+
+		s.log.Error("failed to find login attempts", "error", err, "key", key)
+		return LoginAttempts{}, shared.ErrInternal
+	}
+
+	if now.Sub(attempts.LastFailureAt) > s.settings.Throttle.ResetAfter {
+		return LoginAttempts{Key: key}, nil
+	}
+
+	if now.Before(s.settings.Throttle.LockedUntil(attempts, freeAttempts)) {
+		s.log.Error(ErrTooManyAttempts.Error(), "key", key, "failures", attempts.Failures)
+		return LoginAttempts{}, ErrTooManyAttempts
+	}
+
+	return attempts, nil
+}
+
+// recordLoginFailure increments failed attempts of keys. The counter is incremented by the database,
+// so concurrent failures are not lost
+func (s *AuthService) recordLoginFailure(tx *sqlx.Tx, now time.Time, keys ...string) error {
+	for _, key := range keys {
+		if _, err := s.authRepo.IncrementLoginAttempts(tx, key, now, now.Add(-s.settings.Throttle.ResetAfter)); err != nil {
+			s.log.Error("failed to save login attempts", "error", err, "key", key)
+			return shared.ErrInternal
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// VerifyEmail activates pending account by the token from verification link.
+// The token is bound to the email, so it becomes invalid when the email is changed
+func (s *AuthService) VerifyEmail(tx *sqlx.Tx, token string) error {
+	username, email, expiresAt, ok := s.parseVerificationToken(token)
+	if !ok {
+		s.log.Error(ErrInvalidVerificationToken.Error())
+		return ErrInvalidVerificationToken
+	}
+
+	if !s.date.Now().Before(expiresAt) {
+		s.log.Error("verification token has expired", "username", username)
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrInvalidVerificationToken
+		}
+
+		return shared.ErrInternal
+	}
+
+	if user.Email != email {
+		s.log.Error("verification token was issued for another email", "username", username)
+		return ErrInvalidVerificationToken
+	}
+
+	if !user.Pending {
+		return nil
+	}
+
+	if err := s.authRepo.VerifyEmail(tx, username); err != nil {
+		s.log.Error("failed to verify email", "error", err, "username", username)
+		return shared.ErrInternal
+	}
+
+	s.log.Info("email has been verified", "username", username)
+	return nil
+}
+
+// ResendVerification sends new verification link to pending account
+func (s *AuthService) ResendVerification(tx *sqlx.Tx, username string) error {
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if !user.Pending {
+		s.log.Error(ErrEmailAlreadyVerified.Error(), "username", username)
+		return ErrEmailAlreadyVerified
+	}
+
+	if !validEmail(user.Email) {
+		s.log.Error(ErrInvalidEmail.Error(), "username", username, "email", user.Email)
+		return ErrInvalidEmail
+	}
+
+	return s.sendVerification(user)
+}
+
+// CheckVerified returns ErrEmailNotVerified if account is pending
+func (s *AuthService) CheckVerified(tx *sqlx.Tx, username string) error {
+	user, err := s.authRepo.FindUser(tx, username)
+	if err != nil {
+		s.log.Error("failed to find user", "error", err, "username", username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	if user.Pending {
+		return ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+// sendVerification sends link with token signed for username, email and expiration time
+func (s *AuthService) sendVerification(user User) error {
+	expiresAt := s.date.Now().Add(s.settings.Verification.TTL)
+
+	payload := strings.Join([]string{
+		base64.RawURLEncoding.EncodeToString([]byte(user.Name)),
+		base64.RawURLEncoding.EncodeToString([]byte(user.Email)),
+		strconv.FormatInt(expiresAt.Unix(), 10),
+	}, ".")
+	token := payload + "." + s.signer.Sign(payload)
+
+	message := shared.Message{
+		To:      user.Email,
+		Subject: "Email verification",
+		Body: fmt.Sprintf(
+			"Follow the link to verify your email: %s?token=%s\nThe link is valid for %s. If you did not register, ignore this message.",
+			s.settings.Verification.URL, url.QueryEscape(token), s.settings.Verification.TTL,
+		),
+	}
+
+	if err := s.notifier.Send(message); err != nil {
+		s.log.Error("failed to send verification", "error", err, "username", user.Name)
+		return shared.ErrInternal
+	}
+
+	return nil
+}
+
+func (s *AuthService) parseVerificationToken(token string) (string, string, time.Time, bool) {
+	payload, signature, ok := cutLast(token, ".")
+	if !ok || !s.signer.Verify(payload, signature) {
+		return "", "", time.Time{}, false
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return "", "", time.Time{}, false
+	}
+
+	username, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	email, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	return string(username), string(email), time.Unix(expiresAt, 0), true
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
+
+// validEmail email is a bare address without display name
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
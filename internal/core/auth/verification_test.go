@@ -0,0 +1,386 @@
+package auth_test
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+)
+
+func (s *RunAuthSuite) TestVerifyEmail() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockNow  = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockUser = auth.User{Name: "test name", Password: "test hashed pass", Email: "test@example.com", Pending: true}
+
+		// base64url of name and email, expiration time is 2000-01-02
+		mockPayload = "dGVzdCBuYW1l.dGVzdEBleGFtcGxlLmNvbQ.946771200"
+		mockToken   = mockPayload + ".signature"
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    string
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.authRepo.EXPECT().VerifyEmail(f.tx, mockUser.Name).Return(nil),
+				)
+			},
+			args: mockToken,
+			err:  nil,
+		},
+		{
+			name: "already verified",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{Name: mockUser.Name, Email: mockUser.Email}, nil),
+				)
+			},
+			args: mockToken,
+			err:  nil,
+		},
+		{
+			name: "malformed token",
+			args: "token",
+			err:  auth.ErrInvalidVerificationToken,
+		},
+		{
+			name: "invalid signature",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(false),
+				)
+			},
+			args: mockToken,
+			err:  auth.ErrInvalidVerificationToken,
+		},
+		{
+			name: "expired token",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow.Add(24*time.Hour)),
+				)
+			},
+			args: mockToken,
+			err:  auth.ErrInvalidVerificationToken,
+		},
+		{
+			name: "email has been changed",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{Name: mockUser.Name, Email: "other@example.com", Pending: true}, nil),
+				)
+			},
+			args: mockToken,
+			err:  auth.ErrInvalidVerificationToken,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			args: mockToken,
+			err:  auth.ErrInvalidVerificationToken,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.signer.EXPECT().Verify(mockPayload, "signature").Return(true),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindUser(f.tx, mockUser.Name).Return(mockUser, nil),
+					f.authRepo.EXPECT().VerifyEmail(f.tx, mockUser.Name).Return(shared.ErrNoData),
+				)
+			},
+			args: mockToken,
+			err:  shared.ErrInternal,
+		},
+	}
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.VerifyEmail(f.tx, row.args)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestResendVerification() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	var (
+		mockNow      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockUser     = auth.User{Name: "test name", Password: "test hashed pass", Email: "test@example.com", Pending: true}
+		mockPayload  = "dGVzdCBuYW1l.dGVzdEBleGFtcGxlLmNvbQ.946771200"
+		mockUsername = mockUser.Name
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(mockUser, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.signer.EXPECT().Sign(mockPayload).Return("signature"),
+					f.notifier.EXPECT().Send(gomock.Cond(func(x any) bool {
+						return x.(shared.Message).To == mockUser.Email
+					})).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "already verified",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{Name: mockUsername, Email: mockUser.Email}, nil),
+				)
+			},
+			err: auth.ErrEmailAlreadyVerified,
+		},
+		{
+			name: "invalid email",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{Name: mockUsername, Pending: true}, nil),
+				)
+			},
+			err: auth.ErrInvalidEmail,
+		},
+		{
+			name: "user not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{}, auth.ErrUserNotFound),
+				)
+			},
+			err: auth.ErrUserNotFound,
+		},
+	}
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.ResendVerification(f.tx, mockUsername)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestCheckVerified() {
+	type fields struct {
+		tx       *sqlx.Tx
+		crypto   *mockshared.MockCrypto
+		jwt      *mockshared.MockJwt
+		date     *mockshared.MockDateTool
+		totp     *mockshared.MockTOTP
+		notifier *mockshared.MockNotifier
+		signer   *mockshared.MockSigner
+		authRepo *mockauth.MockAuthRepo
+
+		identityProviders *mockauth.MockIdentityProviders
+		breachedPasswords *mockauth.MockBreachedPasswords
+		productsOwnership *mockauth.MockProductsOwnership
+	}
+
+	mockUsername := "test name"
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{Name: mockUsername}, nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "email is not verified",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{Name: mockUsername, Pending: true}, nil),
+				)
+			},
+			err: auth.ErrEmailNotVerified,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.authRepo.EXPECT().FindUser(f.tx, mockUsername).Return(auth.User{}, shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:       &sqlx.Tx{},
+				crypto:   mockshared.NewMockCrypto(ctrl),
+				jwt:      mockshared.NewMockJwt(ctrl),
+				date:     mockshared.NewMockDateTool(ctrl),
+				totp:     mockshared.NewMockTOTP(ctrl),
+				notifier: mockshared.NewMockNotifier(ctrl),
+				signer:   mockshared.NewMockSigner(ctrl),
+				authRepo: mockauth.NewMockAuthRepo(ctrl),
+
+				identityProviders: mockauth.NewMockIdentityProviders(ctrl),
+				breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+				productsOwnership: mockauth.NewMockProductsOwnership(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := auth.NewAuthService(
+				s.log,
+				f.crypto,
+				f.jwt,
+				f.date,
+				f.totp,
+				f.notifier,
+				f.signer,
+				f.authRepo,
+				f.identityProviders,
+				f.breachedPasswords,
+				f.productsOwnership,
+				mockSettings,
+			)
+
+			err := service.CheckVerified(f.tx, mockUsername)
+			s.Equal(row.err, err)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"time"
+)
+
+// Message event saved in the request transaction and waiting to be published
+type Message struct {
+	ID        uint64            `db:"id"`
+	Topic     string            `db:"topic"`
+	Key       string            `db:"message_key"`
+	Headers   map[string]string `db:"-"`
+	Payload   []byte            `db:"payload"`
+	Attempts  int               `db:"attempts"`
+	CreatedAt time.Time         `db:"created_at"`
+}
+
+// Result of publishing a claimed message,
+// Skipped message has not been published because an earlier message with the same key has failed
+type Result struct {
+	PublishedAt time.Time
+	Err         error
+	Skipped     bool
+}
+
+// messageKey messages with the same topic and key are published in order
+type messageKey struct {
+	topic string
+	key   string
+}
+
+// Settings relay parameters.
+// Claimed messages are leased for Lease, it must be longer than publishing of a batch.
+// Failed message is retried after BaseDelay, the delay doubles with each next failure up to MaxDelay.
+// Sent messages are deleted after Retention, zero Retention keeps them
+type Settings struct {
+	BatchSize int
+	Lease     time.Duration
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Retention time.Duration
+}
+
+// RetryAt time of the next attempt after the failure
+func (s Settings) RetryAt(now time.Time, attempts int) time.Time {
+	delay := s.MaxDelay
+	if shift := attempts - 1; shift < 32 && s.BaseDelay<<shift < s.MaxDelay {
+		delay = s.BaseDelay << shift
+	}
+
+	return now.Add(delay)
+}
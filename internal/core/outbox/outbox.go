@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// OutboxService publishes messages saved in the outbox
+type OutboxService struct {
+	log  *slog.Logger
+	date shared.DateTool
+
+	outboxRepo OutboxRepo
+	eventBus   shared.EventBus
+
+	settings Settings
+}
+
+// NewOutboxService constructor for OutboxService
+func NewOutboxService(
+	log *slog.Logger,
+	date shared.DateTool,
+
+	outboxRepo OutboxRepo,
+	eventBus shared.EventBus,
+
+	settings Settings,
+) *OutboxService {
+	return &OutboxService{
+		log:  log,
+		date: date,
+
+		outboxRepo: outboxRepo,
+		eventBus:   eventBus,
+
+		settings: settings,
+	}
+}
+
+// ClaimMessages leases a batch of pending messages for the time of publishing,
+// so after the transaction is committed other relays skip their keys while they are published.
+// Messages of a key are claimed only when none of its earlier messages is leased or postponed, so the order of a key is kept.
+// Messages whose results are not recorded before the lease expires, e.g. after a crash, are published again,
+// delivery is at least once
+func (s *OutboxService) ClaimMessages(tx *sqlx.Tx) ([]Message, error) {
+	now := s.date.Now()
+
+	messages, err := s.outboxRepo.ClaimPendingMessages(tx, now, now.Add(s.settings.Lease), s.settings.BatchSize)
+	if err != nil {
+		s.log.Error("failed to claim pending outbox messages", "error", err)
+		return nil, shared.ErrInternal
+	}
+
+	return messages, nil
+}
+
+// PublishMessages publishes claimed messages and returns their results in the same order,
+// it is called outside of transaction.
+// After a failure the next messages with the same topic and key are skipped
+func (s *OutboxService) PublishMessages(messages []Message) []Result {
+	results := make([]Result, 0, len(messages))
+
+	failed := make(map[messageKey]struct{})
+	for _, message := range messages {
+		key := messageKey{message.Topic, message.Key}
+		if _, ok := failed[key]; ok {
+			results = append(results, Result{Skipped: true})
+			continue
+		}
+
+		result := Result{PublishedAt: s.date.Now()}
+		if err := s.eventBus.Publish(message.Topic, message.Key, message.Headers, message.Payload); err != nil {
+			failed[key] = struct{}{}
+			result.Err = err
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// RecordResults marks published messages as sent and postpones failed ones, skipped messages are released
+func (s *OutboxService) RecordResults(tx *sqlx.Tx, messages []Message, results []Result) error {
+	for i, result := range results {
+		message := messages[i]
+
+		switch {
+		case result.Skipped:
+			if err := s.outboxRepo.ReleaseMessage(tx, message.ID); err != nil {
+				s.log.Error("failed to release outbox message", "error", err, "id", message.ID)
+				return shared.ErrInternal
+			}
+		case result.Err != nil:
+			attempts := message.Attempts + 1
+			nextAttemptAt := s.settings.RetryAt(result.PublishedAt, attempts)
+
+			s.log.Error("failed to publish outbox message", "error", result.Err, "id", message.ID, "topic", message.Topic, "attempts", attempts)
+
+			if err := s.outboxRepo.MarkFailed(tx, message.ID, attempts, nextAttemptAt); err != nil {
+				s.log.Error("failed to postpone outbox message", "error", err, "id", message.ID)
+				return shared.ErrInternal
+			}
+		default:
+			if err := s.outboxRepo.MarkSent(tx, message.ID, result.PublishedAt); err != nil {
+				s.log.Error("failed to mark outbox message as sent", "error", err, "id", message.ID)
+				return shared.ErrInternal
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeleteSentBatch deletes a batch of messages sent more than Retention ago and returns its size
+func (s *OutboxService) DeleteSentBatch(tx *sqlx.Tx) (int, error) {
+	if s.settings.Retention <= 0 {
+		return 0, nil
+	}
+
+	sentBefore := s.date.Now().Add(-s.settings.Retention)
+
+	n, err := s.outboxRepo.DeleteSentMessages(tx, sentBefore, s.settings.BatchSize)
+	if err != nil {
+		s.log.Error("failed to delete sent outbox messages", "error", err, "sentBefore", sentBefore)
+		return 0, shared.ErrInternal
+	}
+
+	return n, nil
+}
@@ -0,0 +1,329 @@
+package outbox_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/outbox"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockoutbox "github.com/fallra1n/product-keeper/internal/mocks/outbox"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+var mockSettings = outbox.Settings{
+	BatchSize: 2,
+	Lease:     time.Minute,
+	BaseDelay: time.Second,
+	MaxDelay:  time.Minute,
+	Retention: 24 * time.Hour,
+}
+
+type RunOutboxSuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunOutboxSuite(t *testing.T) {
+	suite.Run(t, new(RunOutboxSuite))
+}
+
+func (s *RunOutboxSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+}
+
+type fields struct {
+	tx   *sqlx.Tx
+	date *mockshared.MockDateTool
+
+	outboxRepo *mockoutbox.MockOutboxRepo
+	eventBus   *mockshared.MockEventBus
+}
+
+func newFields(ctrl *gomock.Controller) fields {
+	return fields{
+		tx:   &sqlx.Tx{},
+		date: mockshared.NewMockDateTool(ctrl),
+
+		outboxRepo: mockoutbox.NewMockOutboxRepo(ctrl),
+		eventBus:   mockshared.NewMockEventBus(ctrl),
+	}
+}
+
+func (f fields) service(log *slog.Logger) *outbox.OutboxService {
+	return outbox.NewOutboxService(log, f.date, f.outboxRepo, f.eventBus, mockSettings)
+}
+
+var (
+	mockNow      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockMessages = []outbox.Message{
+		{ID: 1, Topic: "test topic", Key: "1", Headers: map[string]string{"test header": "test value"}, Payload: []byte("test payload1"), CreatedAt: mockNow},
+		{ID: 2, Topic: "test topic", Key: "2", Payload: []byte("test payload2"), Attempts: 3, CreatedAt: mockNow},
+	}
+	errPublish = errors.New("broker is not available")
+)
+
+func (s *RunOutboxSuite) TestClaimMessages() {
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected []outbox.Message
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.outboxRepo.EXPECT().ClaimPendingMessages(f.tx, mockNow, mockNow.Add(mockSettings.Lease), mockSettings.BatchSize).Return(mockMessages, nil),
+				)
+			},
+			expected: mockMessages,
+			err:      nil,
+		},
+		{
+			name: "no pending messages",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.outboxRepo.EXPECT().ClaimPendingMessages(f.tx, mockNow, mockNow.Add(mockSettings.Lease), mockSettings.BatchSize).Return([]outbox.Message{}, nil),
+				)
+			},
+			expected: []outbox.Message{},
+			err:      nil,
+		},
+		{
+			name: "failed to claim messages",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.outboxRepo.EXPECT().ClaimPendingMessages(f.tx, mockNow, mockNow.Add(mockSettings.Lease), mockSettings.BatchSize).Return(nil, shared.ErrNoData),
+				)
+			},
+			expected: nil,
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			messages, err := f.service(s.log).ClaimMessages(f.tx)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, messages)
+		})
+	}
+}
+
+func (s *RunOutboxSuite) TestPublishMessages() {
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		messages []outbox.Message
+		expected []outbox.Result
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.eventBus.EXPECT().Publish("test topic", "1", map[string]string{"test header": "test value"}, []byte("test payload1")).Return(nil),
+					f.date.EXPECT().Now().Return(mockNow.Add(time.Second)),
+					f.eventBus.EXPECT().Publish("test topic", "2", nil, []byte("test payload2")).Return(errPublish),
+				)
+			},
+			messages: mockMessages,
+			expected: []outbox.Result{
+				{PublishedAt: mockNow},
+				{PublishedAt: mockNow.Add(time.Second), Err: errPublish},
+			},
+		},
+		{
+			name: "messages with the key of failed message are skipped",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.eventBus.EXPECT().Publish("test topic", "1", nil, []byte("test created")).Return(errPublish),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.eventBus.EXPECT().Publish("test topic", "2", nil, []byte("test other")).Return(nil),
+				)
+			},
+			messages: []outbox.Message{
+				{ID: 1, Topic: "test topic", Key: "1", Payload: []byte("test created"), CreatedAt: mockNow},
+				{ID: 2, Topic: "test topic", Key: "1", Payload: []byte("test updated"), CreatedAt: mockNow},
+				{ID: 3, Topic: "test topic", Key: "2", Payload: []byte("test other"), CreatedAt: mockNow},
+			},
+			expected: []outbox.Result{
+				{PublishedAt: mockNow, Err: errPublish},
+				{Skipped: true},
+				{PublishedAt: mockNow},
+			},
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			s.Equal(row.expected, f.service(s.log).PublishMessages(row.messages))
+		})
+	}
+}
+
+func (s *RunOutboxSuite) TestRecordResults() {
+	results := []outbox.Result{
+		{PublishedAt: mockNow},
+		{PublishedAt: mockNow, Err: errPublish},
+		{Skipped: true},
+	}
+	messages := append(mockMessages, outbox.Message{ID: 3, Topic: "test topic", Key: "2", Payload: []byte("test payload3"), CreatedAt: mockNow})
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.outboxRepo.EXPECT().MarkSent(f.tx, uint64(1), mockNow).Return(nil),
+					// the fourth attempt is postponed for 8 base delays
+					f.outboxRepo.EXPECT().MarkFailed(f.tx, uint64(2), 4, mockNow.Add(8*time.Second)).Return(nil),
+					f.outboxRepo.EXPECT().ReleaseMessage(f.tx, uint64(3)).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "failed to mark message as sent",
+			prepare: func(f *fields) {
+				f.outboxRepo.EXPECT().MarkSent(f.tx, uint64(1), mockNow).Return(shared.ErrNoData)
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "failed to postpone message",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.outboxRepo.EXPECT().MarkSent(f.tx, uint64(1), mockNow).Return(nil),
+					f.outboxRepo.EXPECT().MarkFailed(f.tx, uint64(2), 4, mockNow.Add(8*time.Second)).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "failed to release message",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.outboxRepo.EXPECT().MarkSent(f.tx, uint64(1), mockNow).Return(nil),
+					f.outboxRepo.EXPECT().MarkFailed(f.tx, uint64(2), 4, mockNow.Add(8*time.Second)).Return(nil),
+					f.outboxRepo.EXPECT().ReleaseMessage(f.tx, uint64(3)).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			s.Equal(row.err, f.service(s.log).RecordResults(f.tx, messages, results))
+		})
+	}
+}
+
+func (s *RunOutboxSuite) TestDeleteSentBatch() {
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		settings outbox.Settings
+		expected int
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.outboxRepo.EXPECT().DeleteSentMessages(f.tx, mockNow.Add(-mockSettings.Retention), mockSettings.BatchSize).Return(2, nil),
+				)
+			},
+			settings: mockSettings,
+			expected: 2,
+			err:      nil,
+		},
+		{
+			name:     "zero retention keeps sent messages",
+			settings: outbox.Settings{BatchSize: mockSettings.BatchSize},
+			expected: 0,
+			err:      nil,
+		},
+		{
+			name: "failed to delete sent messages",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.outboxRepo.EXPECT().DeleteSentMessages(f.tx, mockNow.Add(-mockSettings.Retention), mockSettings.BatchSize).Return(0, shared.ErrNoData),
+				)
+			},
+			settings: mockSettings,
+			expected: 0,
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := outbox.NewOutboxService(s.log, f.date, f.outboxRepo, f.eventBus, row.settings)
+
+			n, err := service.DeleteSentBatch(f.tx)
+			s.Equal(row.expected, n)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunOutboxSuite) TestRetryAt() {
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.Equal(now.Add(time.Second), mockSettings.RetryAt(now, 1))
+	s.Equal(now.Add(2*time.Second), mockSettings.RetryAt(now, 2))
+	s.Equal(now.Add(32*time.Second), mockSettings.RetryAt(now, 6))
+	s.Equal(now.Add(time.Minute), mockSettings.RetryAt(now, 7))
+	s.Equal(now.Add(time.Minute), mockSettings.RetryAt(now, 100))
+}
@@ -0,0 +1,19 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// OutboxRepo ...
+type OutboxRepo interface {
+	// ClaimPendingMessages leases due messages until leaseUntil, claims of relays are serialized
+	ClaimPendingMessages(tx *sqlx.Tx, now, leaseUntil time.Time, limit int) ([]Message, error)
+	MarkSent(tx *sqlx.Tx, id uint64, sentAt time.Time) error
+	MarkFailed(tx *sqlx.Tx, id uint64, attempts int, nextAttemptAt time.Time) error
+	// ReleaseMessage removes the lease, the message is claimed again by the next batch
+	ReleaseMessage(tx *sqlx.Tx, id uint64) error
+	// DeleteSentMessages returns the number of deleted messages
+	DeleteSentMessages(tx *sqlx.Tx, sentBefore time.Time, limit int) (int, error)
+}
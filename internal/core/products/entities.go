@@ -3,6 +3,8 @@ package products
 import (
 	"errors"
 	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
 )
 
 var (
@@ -30,12 +32,30 @@ const (
 	Empty SortType = ""
 )
 
+const (
+	// ActionRead view product
+	ActionRead = "product:read"
+
+	// ActionDelete delete product
+	ActionDelete = "product:delete"
+
+	// ActionUpdateName change product name
+	ActionUpdateName = "product:update:name"
+
+	// ActionUpdatePrice change product price
+	ActionUpdatePrice = "product:update:price"
+
+	// ActionUpdateQuantity change product quantity
+	ActionUpdateQuantity = "product:update:quantity"
+)
+
 // Product info about product
 type Product struct {
 	ID        uint64    `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
 	Price     uint64    `json:"price" db:"price"`
 	Quantity  uint64    `json:"quantity" db:"quantity"`
+	OwnerID   uint64    `json:"owner_id" db:"owner_id"`
 	OwnerName string    `json:"owner_name" db:"owner_name"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
@@ -58,3 +78,58 @@ func NewProduct(
 		CreatedAt: createdAt,
 	}
 }
+
+// ProductFilter FindProductPage params, zero fields are not applied.
+// Name is matched as a case-insensitive substring
+type ProductFilter struct {
+	Name     string
+	MinPrice uint64
+	MaxPrice uint64
+	InStock  bool
+	SortBy   SortType
+	Limit    uint64
+	Offset   uint64
+}
+
+// ProductPage products of the filter page and the number of all products matching the filter
+type ProductPage struct {
+	Products []Product
+	Total    uint64
+}
+
+// EventType type of product event
+type EventType string
+
+const (
+	// EventViewed product has been viewed by its owner or staff
+	EventViewed EventType = "product.viewed"
+
+	// EventCreated product has been created
+	EventCreated EventType = "product.created"
+
+	// EventUpdated product has been updated
+	EventUpdated EventType = "product.updated"
+
+	// EventDeleted product has been deleted
+	EventDeleted EventType = "product.deleted"
+
+	// EventStockChanged product quantity has been changed, published only to product streams
+	EventStockChanged EventType = "product.stock_changed"
+)
+
+// EventSchemaVersion version of event schema, increased on incompatible changes
+const EventSchemaVersion = 1
+
+// Event envelope of product event.
+// Product is the state after the event or the last state of deleted product,
+// Previous is the state before the update. Trace of the request is published in message headers
+type Event struct {
+	ID            string
+	Type          EventType
+	OccurredAt    time.Time
+	Actor         string
+	SchemaVersion int
+	Product       Product
+	Previous      *Product
+	Trace         shared.Trace
+}
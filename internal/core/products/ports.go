@@ -8,12 +8,23 @@ import (
 type ProductsRepo interface {
 	CreateProduct(tx *sqlx.Tx, product Product) (uint64, error)
 	FindProduct(tx *sqlx.Tx, id uint64) (Product, error)
+	// FindProductForUpdate locks the product until the end of the transaction
+	FindProductForUpdate(tx *sqlx.Tx, id uint64) (Product, error)
 	UpdateProduct(tx *sqlx.Tx, newProduct Product) (Product, error)
 	DeleteProduct(tx *sqlx.Tx, id uint64) error
 	FindProductList(tx *sqlx.Tx, username string, productName string, sortBy SortType) ([]Product, error)
+	FindOwnerProducts(tx *sqlx.Tx, ownerName string) ([]Product, error)
+	TransferProducts(tx *sqlx.Tx, fromName, toName string) error
+	DeleteProducts(tx *sqlx.Tx, ownerName string) error
+	FindProductPage(tx *sqlx.Tx, username string, filter ProductFilter) (ProductPage, error)
 }
 
-// ProductsStatistics ...
+// ProductsStatistics product events, saved in the transaction and published asynchronously
 type ProductsStatistics interface {
-	Send(p Product) error
+	Send(tx *sqlx.Tx, event Event) error
+}
+
+// ProductsWebhooks product events delivered to webhooks of the product owner, saved in the transaction
+type ProductsWebhooks interface {
+	Enqueue(tx *sqlx.Tx, event Event) error
 }
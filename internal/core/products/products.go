@@ -11,46 +11,65 @@ import (
 
 // ProductsService ...
 type ProductsService struct {
-	log  *slog.Logger
-	date shared.DateTool
+	log        *slog.Logger
+	date       shared.DateTool
+	ids        shared.IDGenerator
+	authorizer shared.Authorizer
 
 	productsRepo       ProductsRepo
 	productsStatistics ProductsStatistics
+	productsWebhooks   ProductsWebhooks
+
+	stream *streamHub
 }
 
 // NewProductsService ...
 func NewProductsService(
 	log *slog.Logger,
 	date shared.DateTool,
+	ids shared.IDGenerator,
+	authorizer shared.Authorizer,
 
 	productsRepo ProductsRepo,
 	productsStatistics ProductsStatistics,
+	productsWebhooks ProductsWebhooks,
 ) *ProductsService {
 	return &ProductsService{
-		log:  log,
-		date: date,
+		log:        log,
+		date:       date,
+		ids:        ids,
+		authorizer: authorizer,
 
 		productsRepo:       productsRepo,
 		productsStatistics: productsStatistics,
+		productsWebhooks:   productsWebhooks,
+
+		stream: newStreamHub(),
 	}
 }
 
-// CreateProduct ...
-func (s *ProductsService) CreateProduct(tx *sqlx.Tx, product Product) (uint64, error) {
+// CreateProduct creates product, the returned events are published by PublishEvents after the transaction is committed
+func (s *ProductsService) CreateProduct(tx *sqlx.Tx, user shared.Subject, product Product) (uint64, []Event, error) {
 	product.CreatedAt = s.date.Now()
 
 	id, err := s.productsRepo.CreateProduct(tx, product)
 	if err != nil {
 		s.log.Error("failed to create product", "error", err)
-		return 0, shared.ErrInternal
+		return 0, nil, shared.ErrInternal
+	}
+
+	product.ID = id
+	event, err := s.sendEvent(tx, EventCreated, user, product, nil)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	s.log.Info("product has been created", "id", id)
-	return id, nil
+	return id, []Event{event}, nil
 }
 
 // FindProduct ...
-func (s *ProductsService) FindProduct(tx *sqlx.Tx, id uint64, username string) (Product, error) {
+func (s *ProductsService) FindProduct(tx *sqlx.Tx, id uint64, user shared.Subject) (Product, error) {
 	product, err := s.productsRepo.FindProduct(tx, id)
 	if err != nil {
 		s.log.Error("failed to find product by id", "error", err, "id", id)
@@ -61,24 +80,23 @@ func (s *ProductsService) FindProduct(tx *sqlx.Tx, id uint64, username string) (
 		return Product{}, shared.ErrInternal
 	}
 
-	if product.OwnerName != username {
-		s.log.Error(ErrPermissionDenied.Error(), "username", username, "id", id, "ownername", product.OwnerName)
+	if !s.authorizer.Authorize(user, ActionRead, product.OwnerName) {
+		s.log.Error(ErrPermissionDenied.Error(), "username", user.Name, "role", user.Role, "id", id, "ownername", product.OwnerName)
 		return Product{}, ErrPermissionDenied
 	}
 
-	if err := s.productsStatistics.Send(product); err != nil {
-		s.log.Error("failed to send product view to statistics", "error", err, "id", id)
-		return Product{}, shared.ErrInternal
+	if _, err := s.sendEvent(tx, EventViewed, user, product, nil); err != nil {
+		return Product{}, err
 	}
 
 	return product, nil
 }
 
-// UpdateProduct ...
-func (s *ProductsService) UpdateProduct(tx *sqlx.Tx, newProduct Product) (Product, error) {
-	product, err := s.productsRepo.FindProduct(tx, newProduct.ID)
+// FindAnyProduct finding product without owner check, used by admins
+func (s *ProductsService) FindAnyProduct(tx *sqlx.Tx, id uint64) (Product, error) {
+	product, err := s.productsRepo.FindProduct(tx, id)
 	if err != nil {
-		s.log.Error("failed to find product by id", "error", err, "id", newProduct.ID)
+		s.log.Error("failed to find product by id", "error", err, "id", id)
 		if errors.Is(err, shared.ErrNoData) {
 			return Product{}, ErrProductNotFound
 		}
@@ -86,43 +104,74 @@ func (s *ProductsService) UpdateProduct(tx *sqlx.Tx, newProduct Product) (Produc
 		return Product{}, shared.ErrInternal
 	}
 
-	if product.OwnerName != newProduct.OwnerName {
-		s.log.Error(ErrPermissionDenied.Error(), "username", newProduct.OwnerName, "id", newProduct.ID, "ownername", product.OwnerName)
-		return Product{}, ErrPermissionDenied
+	return product, nil
+}
+
+// UpdateProduct updates product, the returned events are published by PublishEvents after the transaction is committed.
+// The product is locked before permissions of the changed fields are checked,
+// so a concurrent update cannot change a field the user is not allowed to write back
+func (s *ProductsService) UpdateProduct(tx *sqlx.Tx, user shared.Subject, newProduct Product) (Product, []Event, error) {
+	product, err := s.productsRepo.FindProductForUpdate(tx, newProduct.ID)
+	if err != nil {
+		s.log.Error("failed to find product by id", "error", err, "id", newProduct.ID)
+		if errors.Is(err, shared.ErrNoData) {
+			return Product{}, nil, ErrProductNotFound
+		}
+
+		return Product{}, nil, shared.ErrInternal
+	}
+
+	for _, action := range updateActions(product, newProduct) {
+		if !s.authorizer.Authorize(user, action, product.OwnerName) {
+			s.log.Error(ErrPermissionDenied.Error(), "username", user.Name, "role", user.Role, "id", newProduct.ID, "ownername", product.OwnerName, "action", action)
+			return Product{}, nil, ErrPermissionDenied
+		}
 	}
 
+	newProduct.OwnerName = product.OwnerName
+
 	data, err := s.productsRepo.UpdateProduct(tx, newProduct)
 	if err != nil {
 		s.log.Error("failed to update product", "error", err, "id", newProduct.ID)
-		return Product{}, shared.ErrInternal
+		return Product{}, nil, shared.ErrInternal
 	}
 
-	return data, nil
+	event, err := s.sendEvent(tx, EventUpdated, user, data, &product)
+	if err != nil {
+		return Product{}, nil, err
+	}
+
+	return data, []Event{event}, nil
 }
 
-// DeleteProduct ...
-func (s *ProductsService) DeleteProduct(tx *sqlx.Tx, id uint64, username string) error {
+// DeleteProduct deletes product, the returned events are published by PublishEvents after the transaction is committed
+func (s *ProductsService) DeleteProduct(tx *sqlx.Tx, id uint64, user shared.Subject) ([]Event, error) {
 	product, err := s.productsRepo.FindProduct(tx, id)
 	if err != nil {
 		s.log.Error("failed to find product by id", "error", err, "id", id)
 		if errors.Is(err, shared.ErrNoData) {
-			return ErrProductNotFound
+			return nil, ErrProductNotFound
 		}
 
-		return shared.ErrInternal
+		return nil, shared.ErrInternal
 	}
 
-	if product.OwnerName != username {
-		s.log.Error(ErrPermissionDenied.Error(), "username", username, "id", id, "ownername", product.OwnerName)
-		return ErrPermissionDenied
+	if !s.authorizer.Authorize(user, ActionDelete, product.OwnerName) {
+		s.log.Error(ErrPermissionDenied.Error(), "username", user.Name, "role", user.Role, "id", id, "ownername", product.OwnerName)
+		return nil, ErrPermissionDenied
 	}
 
 	if err := s.productsRepo.DeleteProduct(tx, id); err != nil {
 		s.log.Error("failed to delete product", "error", err, "id", id)
-		return shared.ErrInternal
+		return nil, shared.ErrInternal
 	}
 
-	return nil
+	event, err := s.sendEvent(tx, EventDeleted, user, product, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Event{event}, nil
 }
 
 // FindProductList ...
@@ -139,3 +188,187 @@ func (s *ProductsService) FindProductList(tx *sqlx.Tx, username string, productN
 
 	return data, nil
 }
+
+// TransferProducts transfers all products of the owner to another user, used when the account is deleted.
+// An update event is sent for every product, the returned function publishes them after the transaction is committed
+func (s *ProductsService) TransferProducts(tx *sqlx.Tx, actor shared.Subject, fromName, toName string) (func(), error) {
+	list, err := s.productsRepo.FindOwnerProducts(tx, fromName)
+	if err != nil {
+		s.log.Error("failed to find owner products", "error", err, "ownername", fromName)
+		return nil, shared.ErrInternal
+	}
+
+	if err := s.productsRepo.TransferProducts(tx, fromName, toName); err != nil {
+		s.log.Error("failed to transfer products", "error", err, "ownername", fromName, "receiver", toName)
+		return nil, shared.ErrInternal
+	}
+
+	transferred, err := s.productsRepo.FindOwnerProducts(tx, toName)
+	if err != nil {
+		s.log.Error("failed to find owner products", "error", err, "ownername", toName)
+		return nil, shared.ErrInternal
+	}
+
+	byID := make(map[uint64]Product, len(transferred))
+	for _, product := range transferred {
+		byID[product.ID] = product
+	}
+
+	events := make([]Event, 0, len(list))
+	for _, previous := range list {
+		event, err := s.sendEvent(tx, EventUpdated, actor, byID[previous.ID], &previous)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	s.log.Info("products have been transferred", "ownername", fromName, "receiver", toName, "count", len(list))
+	return func() { s.PublishEvents(events) }, nil
+}
+
+// DeleteProducts deletes all products of the owner, used when the account is deleted.
+// A delete event is sent for every product, the returned function publishes them after the transaction is committed
+func (s *ProductsService) DeleteProducts(tx *sqlx.Tx, actor shared.Subject, ownerName string) (func(), error) {
+	list, err := s.productsRepo.FindOwnerProducts(tx, ownerName)
+	if err != nil {
+		s.log.Error("failed to find owner products", "error", err, "ownername", ownerName)
+		return nil, shared.ErrInternal
+	}
+
+	if err := s.productsRepo.DeleteProducts(tx, ownerName); err != nil {
+		s.log.Error("failed to delete products", "error", err, "ownername", ownerName)
+		return nil, shared.ErrInternal
+	}
+
+	events := make([]Event, 0, len(list))
+	for _, product := range list {
+		event, err := s.sendEvent(tx, EventDeleted, actor, product, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	s.log.Info("products have been deleted", "ownername", ownerName, "count", len(list))
+	return func() { s.PublishEvents(events) }, nil
+}
+
+// FindProductPage ...
+func (s *ProductsService) FindProductPage(tx *sqlx.Tx, username string, filter ProductFilter) (ProductPage, error) {
+	page, err := s.productsRepo.FindProductPage(tx, username, filter)
+	if err != nil {
+		s.log.Error("failed to find product page", "error", err, "username", username, "filter", filter)
+		return ProductPage{}, shared.ErrInternal
+	}
+
+	return page, nil
+}
+
+// SubscribeProducts subscribes to changes of the owner products.
+// Events published after lastEventID are returned from the backlog,
+// reset is true if some of them are lost and the client should reload its products
+func (s *ProductsService) SubscribeProducts(ownerName, lastEventID string) (sub *Subscription, missed []StreamEvent, reset bool) {
+	sub, missed, complete := s.stream.subscribe(ownerName, lastEventID)
+	return sub, missed, !complete
+}
+
+// PublishEvents publishes product changes to streams of the product owners.
+// It must be called only after the transaction that produced events is committed
+func (s *ProductsService) PublishEvents(events []Event) {
+	for _, event := range events {
+		s.publishStream(event)
+	}
+}
+
+// UnsubscribeProducts ...
+func (s *ProductsService) UnsubscribeProducts(sub *Subscription) {
+	s.stream.unsubscribe(sub)
+}
+
+// CloseStreams closes all product streams, used on shutdown
+func (s *ProductsService) CloseStreams() {
+	s.stream.close()
+}
+
+// updateActions actions required to change product to newProduct,
+// unchanged product requires only read access
+func updateActions(product, newProduct Product) []string {
+	var actions []string
+
+	if product.Name != newProduct.Name {
+		actions = append(actions, ActionUpdateName)
+	}
+
+	if product.Price != newProduct.Price {
+		actions = append(actions, ActionUpdatePrice)
+	}
+
+	if product.Quantity != newProduct.Quantity {
+		actions = append(actions, ActionUpdateQuantity)
+	}
+
+	if len(actions) == 0 {
+		actions = append(actions, ActionRead)
+	}
+
+	return actions
+}
+
+// sendEvent saves event to statistics and webhooks in the transaction and returns it for product streams,
+// previous is set only for updates
+func (s *ProductsService) sendEvent(tx *sqlx.Tx, eventType EventType, actor shared.Subject, product Product, previous *Product) (Event, error) {
+	id, err := s.ids.NewID()
+	if err != nil {
+		s.log.Error("failed to generate event id", "error", err, "type", eventType, "id", product.ID)
+		return Event{}, shared.ErrInternal
+	}
+
+	event := Event{
+		ID:            id,
+		Type:          eventType,
+		OccurredAt:    s.date.Now(),
+		Actor:         actor.Name,
+		SchemaVersion: EventSchemaVersion,
+		Product:       product,
+		Previous:      previous,
+		Trace:         actor.Trace,
+	}
+
+	if err := s.productsStatistics.Send(tx, event); err != nil {
+		s.log.Error("failed to send product event to statistics", "error", err, "type", eventType, "id", product.ID)
+		return Event{}, shared.ErrInternal
+	}
+
+	if err := s.productsWebhooks.Enqueue(tx, event); err != nil {
+		s.log.Error("failed to send product event to webhooks", "error", err, "type", eventType, "id", product.ID)
+		return Event{}, shared.ErrInternal
+	}
+
+	return event, nil
+}
+
+// publishStream publishes changes of the event to streams of the product owner
+func (s *ProductsService) publishStream(event Event) {
+	if event.Type == EventViewed {
+		return
+	}
+
+	s.stream.publish(event.Product.OwnerName, StreamEvent{
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Product:    event.Product,
+		Previous:   event.Previous,
+	})
+
+	if event.Type == EventUpdated && event.Previous.Quantity != event.Product.Quantity {
+		s.stream.publish(event.Product.OwnerName, StreamEvent{
+			Type:       EventStockChanged,
+			OccurredAt: event.OccurredAt,
+			Product:    event.Product,
+			Previous:   event.Previous,
+		})
+	}
+}
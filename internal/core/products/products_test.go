@@ -31,17 +31,25 @@ func (s *RunProductsSuite) SetupTest() {
 
 func (s *RunProductsSuite) TestCreateProduct() {
 	type fields struct {
-		tx   *sqlx.Tx
-		date *mockshared.MockDateTool
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
 
 		productsRepo       *mockproducts.MockProductsRepo
 		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
 	}
 
 	var (
 		now = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 
 		mockProductID = uint64(567)
+		mockUser      = shared.Subject{
+			Name:  "test username",
+			Role:  "user",
+			Trace: shared.Trace{TraceID: "test trace id", RequestID: "test request id"},
+		}
 	)
 
 	testList := []struct {
@@ -57,17 +65,42 @@ func (s *RunProductsSuite) TestCreateProduct() {
 				mockProduct := products.Product{
 					Name:      "test product",
 					Price:     123,
+					OwnerName: "test username",
 					CreatedAt: now,
 				}
 
+				createdProduct := mockProduct
+				createdProduct.ID = mockProductID
+
 				gomock.InOrder(
 					f.date.EXPECT().Now().Return(now),
 					f.productsRepo.EXPECT().CreateProduct(f.tx, mockProduct).Return(mockProductID, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(now),
+					f.productsStatistics.EXPECT().Send(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventCreated,
+						OccurredAt:    now,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       createdProduct,
+						Trace:         mockUser.Trace,
+					}).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventCreated,
+						OccurredAt:    now,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       createdProduct,
+						Trace:         mockUser.Trace,
+					}).Return(nil),
 				)
 			},
 			args: products.Product{
-				Name:  "test product",
-				Price: 123,
+				Name:      "test product",
+				Price:     123,
+				OwnerName: "test username",
 			},
 			expectedData: mockProductID,
 			err:          nil,
@@ -93,6 +126,55 @@ func (s *RunProductsSuite) TestCreateProduct() {
 			expectedData: uint64(0),
 			err:          shared.ErrInternal,
 		},
+		{
+			name: "failed to send statistics",
+			prepare: func(f *fields) {
+				mockProduct := products.Product{
+					Name:      "test product",
+					Price:     123,
+					CreatedAt: now,
+				}
+
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(now),
+					f.productsRepo.EXPECT().CreateProduct(f.tx, mockProduct).Return(mockProductID, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(now),
+					f.productsStatistics.EXPECT().Send(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args: products.Product{
+				Name:  "test product",
+				Price: 123,
+			},
+			expectedData: uint64(0),
+			err:          shared.ErrInternal,
+		},
+		{
+			name: "failed to send webhooks",
+			prepare: func(f *fields) {
+				mockProduct := products.Product{
+					Name:      "test product",
+					Price:     123,
+					CreatedAt: now,
+				}
+
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(now),
+					f.productsRepo.EXPECT().CreateProduct(f.tx, mockProduct).Return(mockProductID, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(now),
+					f.productsStatistics.EXPECT().Send(f.tx, gomock.Any()).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args: products.Product{
+				Name:  "test product",
+				Price: 123,
+			},
+			expectedData: uint64(0),
+			err:          shared.ErrInternal,
+		},
 	}
 
 	for _, row := range testList {
@@ -101,11 +183,14 @@ func (s *RunProductsSuite) TestCreateProduct() {
 			defer ctrl.Finish()
 
 			f := fields{
-				tx:   &sqlx.Tx{},
-				date: mockshared.NewMockDateTool(ctrl),
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
 
 				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
 				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -114,35 +199,51 @@ func (s *RunProductsSuite) TestCreateProduct() {
 			service := products.NewProductsService(
 				s.log,
 				f.date,
+				f.ids,
+				f.authorizer,
 
 				f.productsRepo,
 				f.productsStatistics,
+				f.productsWebhooks,
 			)
 
-			data, err := service.CreateProduct(f.tx, row.args)
+			data, events, err := service.CreateProduct(f.tx, mockUser, row.args)
 			s.Equal(row.err, err)
 			s.Equal(row.expectedData, data)
+
+			if row.err != nil {
+				s.Empty(events)
+				return
+			}
+
+			s.Require().Len(events, 1)
+			s.Equal(products.EventCreated, events[0].Type)
+			s.Equal(data, events[0].Product.ID)
 		})
 	}
 }
 
 func (s *RunProductsSuite) TestFindProduct() {
 	type fields struct {
-		tx   *sqlx.Tx
-		date *mockshared.MockDateTool
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
 
 		productsRepo       *mockproducts.MockProductsRepo
 		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
 	}
 
 	type args struct {
-		id       uint64
-		username string
+		id   uint64
+		user shared.Subject
 	}
 
 	var (
 		mockProductID = uint64(123)
-		mockUsername  = "test username"
+		mockUser      = shared.NewSubject("test username", "user")
+		mockNow       = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	)
 
 	testList := []struct {
@@ -157,21 +258,39 @@ func (s *RunProductsSuite) TestFindProduct() {
 			prepare: func(f *fields) {
 				mockProduct := products.Product{
 					ID:        mockProductID,
-					OwnerName: mockUsername,
+					OwnerName: mockUser.Name,
 				}
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
-					f.productsStatistics.EXPECT().Send(mockProduct).Return(nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionRead, mockUser.Name).Return(true),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventViewed,
+						OccurredAt:    mockNow,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       mockProduct,
+					}).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventViewed,
+						OccurredAt:    mockNow,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       mockProduct,
+					}).Return(nil),
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			expectedData: products.Product{
 				ID:        mockProductID,
-				OwnerName: mockUsername,
+				OwnerName: mockUser.Name,
 			},
 			err: nil,
 		},
@@ -183,8 +302,8 @@ func (s *RunProductsSuite) TestFindProduct() {
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			expectedData: products.Product{},
 			err:          products.ErrProductNotFound,
@@ -199,11 +318,12 @@ func (s *RunProductsSuite) TestFindProduct() {
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionRead, "other username").Return(false),
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			expectedData: products.Product{},
 			err:          products.ErrPermissionDenied,
@@ -213,17 +333,41 @@ func (s *RunProductsSuite) TestFindProduct() {
 			prepare: func(f *fields) {
 				mockProduct := products.Product{
 					ID:        mockProductID,
-					OwnerName: mockUsername,
+					OwnerName: mockUser.Name,
+				}
+
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionRead, mockUser.Name).Return(true),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args: args{
+				id:   mockProductID,
+				user: mockUser,
+			},
+			expectedData: products.Product{},
+			err:          shared.ErrInternal,
+		},
+		{
+			name: "failed to generate event id",
+			prepare: func(f *fields) {
+				mockProduct := products.Product{
+					ID:        mockProductID,
+					OwnerName: mockUser.Name,
 				}
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
-					f.productsStatistics.EXPECT().Send(mockProduct).Return(shared.ErrNoData),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionRead, mockUser.Name).Return(true),
+					f.ids.EXPECT().NewID().Return("", shared.ErrNoData),
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			expectedData: products.Product{},
 			err:          shared.ErrInternal,
@@ -236,11 +380,14 @@ func (s *RunProductsSuite) TestFindProduct() {
 			defer ctrl.Finish()
 
 			f := fields{
-				tx:   &sqlx.Tx{},
-				date: mockshared.NewMockDateTool(ctrl),
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
 
 				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
 				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -249,36 +396,41 @@ func (s *RunProductsSuite) TestFindProduct() {
 			service := products.NewProductsService(
 				s.log,
 				f.date,
+				f.ids,
+				f.authorizer,
 
 				f.productsRepo,
 				f.productsStatistics,
+				f.productsWebhooks,
 			)
 
-			data, err := service.FindProduct(f.tx, row.args.id, row.args.username)
+			data, err := service.FindProduct(f.tx, row.args.id, row.args.user)
 			s.Equal(row.err, err)
 			s.Equal(row.expectedData, data)
 		})
 	}
 }
 
-func (s *RunProductsSuite) TestUpdateProduct() {
+func (s *RunProductsSuite) TestFindAnyProduct() {
 	type fields struct {
-		tx   *sqlx.Tx
-		date *mockshared.MockDateTool
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
 
 		productsRepo       *mockproducts.MockProductsRepo
 		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
 	}
 
 	var (
 		mockProductID = uint64(123)
-		mockUsername  = "test new username"
 	)
 
 	testList := []struct {
 		name         string
 		prepare      func(f *fields)
-		args         products.Product
+		args         uint64
 		expectedData products.Product
 		err          error
 	}{
@@ -287,34 +439,17 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 			prepare: func(f *fields) {
 				mockProduct := products.Product{
 					ID:        mockProductID,
-					OwnerName: mockUsername,
-					Name:      "test product",
-					Price:     123,
-				}
-
-				mockNewProduct := products.Product{
-					ID:        mockProductID,
-					OwnerName: mockUsername,
-					Name:      "new test product",
-					Price:     1234,
+					OwnerName: "other username",
 				}
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
-					f.productsRepo.EXPECT().UpdateProduct(f.tx, mockNewProduct).Return(mockNewProduct, nil),
 				)
 			},
-			args: products.Product{
-				ID:        mockProductID,
-				OwnerName: mockUsername,
-				Name:      "new test product",
-				Price:     1234,
-			},
+			args: mockProductID,
 			expectedData: products.Product{
 				ID:        mockProductID,
-				OwnerName: mockUsername,
-				Name:      "new test product",
-				Price:     1234,
+				OwnerName: "other username",
 			},
 			err: nil,
 		},
@@ -325,9 +460,229 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(products.Product{}, shared.ErrNoData),
 				)
 			},
-			args: products.Product{
+			args:         mockProductID,
+			expectedData: products.Product{},
+			err:          products.ErrProductNotFound,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(products.Product{}, products.ErrPermissionDenied),
+				)
+			},
+			args:         mockProductID,
+			expectedData: products.Product{},
+			err:          shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
+
+				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
+				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := products.NewProductsService(
+				s.log,
+				f.date,
+				f.ids,
+				f.authorizer,
+
+				f.productsRepo,
+				f.productsStatistics,
+				f.productsWebhooks,
+			)
+
+			data, err := service.FindAnyProduct(f.tx, row.args)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestUpdateProduct() {
+	type fields struct {
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
+
+		productsRepo       *mockproducts.MockProductsRepo
+		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
+	}
+
+	type args struct {
+		user       shared.Subject
+		newProduct products.Product
+	}
+
+	var (
+		mockProductID = uint64(123)
+		mockUser      = shared.NewSubject("test new username", "user")
+		mockWarehouse = shared.NewSubject("test warehouse", "warehouse")
+		mockNow       = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mockProduct = products.Product{
+			ID:        mockProductID,
+			OwnerName: mockUser.Name,
+			Name:      "test product",
+			Price:     123,
+			Quantity:  10,
+		}
+
+		mockNewProduct = products.Product{
+			ID:        mockProductID,
+			OwnerName: mockUser.Name,
+			Name:      "new test product",
+			Price:     1234,
+			Quantity:  10,
+		}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		args         args
+		expectedData products.Product
+		err          error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionUpdateName, mockUser.Name).Return(true),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionUpdatePrice, mockUser.Name).Return(true),
+					f.productsRepo.EXPECT().UpdateProduct(f.tx, mockNewProduct).Return(mockNewProduct, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventUpdated,
+						OccurredAt:    mockNow,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       mockNewProduct,
+						Previous:      &mockProduct,
+					}).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventUpdated,
+						OccurredAt:    mockNow,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       mockNewProduct,
+						Previous:      &mockProduct,
+					}).Return(nil),
+				)
+			},
+			args: args{
+				user: mockUser,
+				newProduct: products.Product{
+					ID:       mockProductID,
+					Name:     "new test product",
+					Price:    1234,
+					Quantity: 10,
+				},
+			},
+			expectedData: mockNewProduct,
+			err:          nil,
+		},
+		{
+			name: "warehouse changes quantity",
+			prepare: func(f *fields) {
+				updated := mockProduct
+				updated.Quantity = 42
+
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockWarehouse, products.ActionUpdateQuantity, mockUser.Name).Return(true),
+					f.productsRepo.EXPECT().UpdateProduct(f.tx, updated).Return(updated, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventUpdated,
+						OccurredAt:    mockNow,
+						Actor:         mockWarehouse.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       updated,
+						Previous:      &mockProduct,
+					}).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventUpdated,
+						OccurredAt:    mockNow,
+						Actor:         mockWarehouse.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       updated,
+						Previous:      &mockProduct,
+					}).Return(nil),
+				)
+			},
+			args: args{
+				user: mockWarehouse,
+				newProduct: products.Product{
+					ID:       mockProductID,
+					Name:     "test product",
+					Price:    123,
+					Quantity: 42,
+				},
+			},
+			expectedData: products.Product{
 				ID:        mockProductID,
-				OwnerName: mockUsername,
+				OwnerName: mockUser.Name,
+				Name:      "test product",
+				Price:     123,
+				Quantity:  42,
+			},
+			err: nil,
+		},
+		{
+			name: "warehouse changes price",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockWarehouse, products.ActionUpdatePrice, mockUser.Name).Return(false),
+				)
+			},
+			args: args{
+				user: mockWarehouse,
+				newProduct: products.Product{
+					ID:       mockProductID,
+					Name:     "test product",
+					Price:    1,
+					Quantity: 10,
+				},
+			},
+			expectedData: products.Product{},
+			err:          products.ErrPermissionDenied,
+		},
+		{
+			name: "product not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(products.Product{}, shared.ErrNoData),
+				)
+			},
+			args: args{
+				user:       mockUser,
+				newProduct: products.Product{ID: mockProductID},
 			},
 			expectedData: products.Product{},
 			err:          products.ErrProductNotFound,
@@ -335,18 +690,19 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 		{
 			name: "permission denied",
 			prepare: func(f *fields) {
-				mockProduct := products.Product{
+				otherProduct := products.Product{
 					ID:        mockProductID,
 					OwnerName: "other username",
 				}
 
 				gomock.InOrder(
-					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(otherProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionRead, "other username").Return(false),
 				)
 			},
-			args: products.Product{
-				ID:        mockProductID,
-				OwnerName: mockUsername,
+			args: args{
+				user:       mockUser,
+				newProduct: products.Product{ID: mockProductID},
 			},
 			expectedData: products.Product{},
 			err:          products.ErrPermissionDenied,
@@ -354,30 +710,46 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 		{
 			name: "internal error(update product)",
 			prepare: func(f *fields) {
-				mockProduct := products.Product{
-					ID:        mockProductID,
-					OwnerName: mockUsername,
-					Name:      "test product",
-					Price:     123,
-				}
-
-				mockNewProduct := products.Product{
-					ID:        mockProductID,
-					OwnerName: mockUsername,
-					Name:      "new test product",
-					Price:     1234,
-				}
-
 				gomock.InOrder(
-					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionUpdateName, mockUser.Name).Return(true),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionUpdatePrice, mockUser.Name).Return(true),
 					f.productsRepo.EXPECT().UpdateProduct(f.tx, mockNewProduct).Return(products.Product{}, shared.ErrNoData),
 				)
 			},
-			args: products.Product{
-				ID:        mockProductID,
-				OwnerName: mockUsername,
-				Name:      "new test product",
-				Price:     1234,
+			args: args{
+				user: mockUser,
+				newProduct: products.Product{
+					ID:       mockProductID,
+					Name:     "new test product",
+					Price:    1234,
+					Quantity: 10,
+				},
+			},
+			expectedData: products.Product{},
+			err:          shared.ErrInternal,
+		},
+		{
+			name: "failed to send statistics",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionUpdateName, mockUser.Name).Return(true),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionUpdatePrice, mockUser.Name).Return(true),
+					f.productsRepo.EXPECT().UpdateProduct(f.tx, mockNewProduct).Return(mockNewProduct, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args: args{
+				user: mockUser,
+				newProduct: products.Product{
+					ID:       mockProductID,
+					Name:     "new test product",
+					Price:    1234,
+					Quantity: 10,
+				},
 			},
 			expectedData: products.Product{},
 			err:          shared.ErrInternal,
@@ -386,12 +758,12 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 			name: "internal error(find product)",
 			prepare: func(f *fields) {
 				gomock.InOrder(
-					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(products.Product{}, products.ErrPermissionDenied),
+					f.productsRepo.EXPECT().FindProductForUpdate(f.tx, mockProductID).Return(products.Product{}, products.ErrPermissionDenied),
 				)
 			},
-			args: products.Product{
-				ID:        mockProductID,
-				OwnerName: mockUsername,
+			args: args{
+				user:       mockUser,
+				newProduct: products.Product{ID: mockProductID},
 			},
 			expectedData: products.Product{},
 			err:          shared.ErrInternal,
@@ -404,11 +776,14 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 			defer ctrl.Finish()
 
 			f := fields{
-				tx:   &sqlx.Tx{},
-				date: mockshared.NewMockDateTool(ctrl),
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
 
 				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
 				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -417,35 +792,51 @@ func (s *RunProductsSuite) TestUpdateProduct() {
 			service := products.NewProductsService(
 				s.log,
 				f.date,
+				f.ids,
+				f.authorizer,
 
 				f.productsRepo,
 				f.productsStatistics,
+				f.productsWebhooks,
 			)
 
-			data, err := service.UpdateProduct(f.tx, row.args)
+			data, events, err := service.UpdateProduct(f.tx, row.args.user, row.args.newProduct)
 			s.Equal(row.err, err)
 			s.Equal(row.expectedData, data)
+
+			if row.err != nil {
+				s.Empty(events)
+				return
+			}
+
+			s.Require().Len(events, 1)
+			s.Equal(products.EventUpdated, events[0].Type)
+			s.Equal(data, events[0].Product)
 		})
 	}
 }
 
 func (s *RunProductsSuite) TestDeleteProduct() {
 	type fields struct {
-		tx   *sqlx.Tx
-		date *mockshared.MockDateTool
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
 
 		productsRepo       *mockproducts.MockProductsRepo
 		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
 	}
 
 	type args struct {
-		id       uint64
-		username string
+		id   uint64
+		user shared.Subject
 	}
 
 	var (
 		mockProductID = uint64(123)
-		mockUsername  = "test username"
+		mockUser      = shared.NewSubject("test username", "user")
+		mockNow       = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	)
 
 	testList := []struct {
@@ -459,18 +850,37 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 			prepare: func(f *fields) {
 				mockProduct := products.Product{
 					ID:        mockProductID,
-					OwnerName: mockUsername,
+					OwnerName: mockUser.Name,
 					Quantity:  123,
 				}
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionDelete, mockUser.Name).Return(true),
 					f.productsRepo.EXPECT().DeleteProduct(f.tx, mockProductID).Return(nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventDeleted,
+						OccurredAt:    mockNow,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       mockProduct,
+					}).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, products.Event{
+						ID:            "test event id",
+						Type:          products.EventDeleted,
+						OccurredAt:    mockNow,
+						Actor:         mockUser.Name,
+						SchemaVersion: products.EventSchemaVersion,
+						Product:       mockProduct,
+					}).Return(nil),
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			err: nil,
 		},
@@ -482,8 +892,8 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			err: products.ErrProductNotFound,
 		},
@@ -497,11 +907,12 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionDelete, "other username").Return(false),
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
 			},
 			err: products.ErrPermissionDenied,
 		},
@@ -510,18 +921,42 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 			prepare: func(f *fields) {
 				mockProduct := products.Product{
 					ID:        mockProductID,
-					OwnerName: mockUsername,
+					OwnerName: mockUser.Name,
 					Quantity:  123,
 				}
 
 				gomock.InOrder(
 					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionDelete, mockUser.Name).Return(true),
 					f.productsRepo.EXPECT().DeleteProduct(f.tx, mockProductID).Return(products.ErrProductNotFound),
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "failed to send statistics",
+			prepare: func(f *fields) {
+				mockProduct := products.Product{
+					ID:        mockProductID,
+					OwnerName: mockUser.Name,
+				}
+
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProduct(f.tx, mockProductID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, products.ActionDelete, mockUser.Name).Return(true),
+					f.productsRepo.EXPECT().DeleteProduct(f.tx, mockProductID).Return(nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			args: args{
+				id:   mockProductID,
+				user: mockUser,
 			},
 			err: shared.ErrInternal,
 		},
@@ -533,8 +968,129 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 				)
 			},
 			args: args{
-				id:       mockProductID,
-				username: mockUsername,
+				id:   mockProductID,
+				user: mockUser,
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
+
+				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
+				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := products.NewProductsService(
+				s.log,
+				f.date,
+				f.ids,
+				f.authorizer,
+
+				f.productsRepo,
+				f.productsStatistics,
+				f.productsWebhooks,
+			)
+
+			events, err := service.DeleteProduct(f.tx, row.args.id, row.args.user)
+			s.Equal(row.err, err)
+
+			if row.err != nil {
+				s.Empty(events)
+				return
+			}
+
+			s.Require().Len(events, 1)
+			s.Equal(products.EventDeleted, events[0].Type)
+			s.Equal(row.args.id, events[0].Product.ID)
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestTransferProducts() {
+	type fields struct {
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
+
+		productsRepo       *mockproducts.MockProductsRepo
+		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
+	}
+
+	var (
+		mockNow      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockActor    = shared.NewSubject("test username", "user")
+		mockProduct  = products.Product{ID: 1, Name: "test product", OwnerID: 10, OwnerName: "test username"}
+		mockReceived = products.Product{ID: 1, Name: "test product", OwnerID: 20, OwnerName: "test receiver"}
+		mockOther    = products.Product{ID: 2, Name: "test other product", OwnerID: 20, OwnerName: "test receiver"}
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				event := products.Event{
+					ID:            "test event id",
+					Type:          products.EventUpdated,
+					OccurredAt:    mockNow,
+					Actor:         mockActor.Name,
+					SchemaVersion: products.EventSchemaVersion,
+					Product:       mockReceived,
+					Previous:      &mockProduct,
+				}
+
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test username").Return([]products.Product{mockProduct}, nil),
+					f.productsRepo.EXPECT().TransferProducts(f.tx, "test username", "test receiver").Return(nil),
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test receiver").Return([]products.Product{mockReceived, mockOther}, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, event).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, event).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "failed to transfer products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test username").Return([]products.Product{mockProduct}, nil),
+					f.productsRepo.EXPECT().TransferProducts(f.tx, "test username", "test receiver").Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "failed to send event",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test username").Return([]products.Product{mockProduct}, nil),
+					f.productsRepo.EXPECT().TransferProducts(f.tx, "test username", "test receiver").Return(nil),
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test receiver").Return([]products.Product{mockReceived}, nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
 			},
 			err: shared.ErrInternal,
 		},
@@ -546,11 +1102,14 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 			defer ctrl.Finish()
 
 			f := fields{
-				tx:   &sqlx.Tx{},
-				date: mockshared.NewMockDateTool(ctrl),
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
 
 				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
 				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -559,24 +1118,135 @@ func (s *RunProductsSuite) TestDeleteProduct() {
 			service := products.NewProductsService(
 				s.log,
 				f.date,
+				f.ids,
+				f.authorizer,
 
 				f.productsRepo,
 				f.productsStatistics,
+				f.productsWebhooks,
 			)
 
-			err := service.DeleteProduct(f.tx, row.args.id, row.args.username)
+			publish, err := service.TransferProducts(f.tx, mockActor, "test username", "test receiver")
 			s.Equal(row.err, err)
+			s.Equal(row.err == nil, publish != nil)
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestDeleteProducts() {
+	type fields struct {
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
+
+		productsRepo       *mockproducts.MockProductsRepo
+		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
+	}
+
+	var (
+		mockNow     = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockActor   = shared.NewSubject("test username", "user")
+		mockProduct = products.Product{ID: 1, Name: "test product", OwnerID: 10, OwnerName: "test username"}
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				event := products.Event{
+					ID:            "test event id",
+					Type:          products.EventDeleted,
+					OccurredAt:    mockNow,
+					Actor:         mockActor.Name,
+					SchemaVersion: products.EventSchemaVersion,
+					Product:       mockProduct,
+				}
+
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test username").Return([]products.Product{mockProduct}, nil),
+					f.productsRepo.EXPECT().DeleteProducts(f.tx, "test username").Return(nil),
+					f.ids.EXPECT().NewID().Return("test event id", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.productsStatistics.EXPECT().Send(f.tx, event).Return(nil),
+					f.productsWebhooks.EXPECT().Enqueue(f.tx, event).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "failed to find products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test username").Return(nil, shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "failed to delete products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindOwnerProducts(f.tx, "test username").Return([]products.Product{mockProduct}, nil),
+					f.productsRepo.EXPECT().DeleteProducts(f.tx, "test username").Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
+
+				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
+				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := products.NewProductsService(
+				s.log,
+				f.date,
+				f.ids,
+				f.authorizer,
+
+				f.productsRepo,
+				f.productsStatistics,
+				f.productsWebhooks,
+			)
+
+			publish, err := service.DeleteProducts(f.tx, mockActor, "test username")
+			s.Equal(row.err, err)
+			s.Equal(row.err == nil, publish != nil)
 		})
 	}
 }
 
 func (s *RunProductsSuite) TestFindProductList() {
 	type fields struct {
-		tx   *sqlx.Tx
-		date *mockshared.MockDateTool
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
 
 		productsRepo       *mockproducts.MockProductsRepo
 		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
 	}
 
 	type args struct {
@@ -660,11 +1330,14 @@ func (s *RunProductsSuite) TestFindProductList() {
 			defer ctrl.Finish()
 
 			f := fields{
-				tx:   &sqlx.Tx{},
-				date: mockshared.NewMockDateTool(ctrl),
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
 
 				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
 				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
 			}
 			if row.prepare != nil {
 				row.prepare(&f)
@@ -673,9 +1346,12 @@ func (s *RunProductsSuite) TestFindProductList() {
 			service := products.NewProductsService(
 				s.log,
 				f.date,
+				f.ids,
+				f.authorizer,
 
 				f.productsRepo,
 				f.productsStatistics,
+				f.productsWebhooks,
 			)
 
 			data, err := service.FindProductList(f.tx, row.args.username, row.args.productName, row.args.sortBy)
@@ -684,3 +1360,95 @@ func (s *RunProductsSuite) TestFindProductList() {
 		})
 	}
 }
+
+func (s *RunProductsSuite) TestFindProductPage() {
+	type fields struct {
+		tx         *sqlx.Tx
+		date       *mockshared.MockDateTool
+		ids        *mockshared.MockIDGenerator
+		authorizer *mockshared.MockAuthorizer
+
+		productsRepo       *mockproducts.MockProductsRepo
+		productsStatistics *mockproducts.MockProductsStatistics
+		productsWebhooks   *mockproducts.MockProductsWebhooks
+	}
+
+	var (
+		mockUsername = "test username"
+		mockFilter   = products.ProductFilter{
+			Name:    "test",
+			InStock: true,
+			SortBy:  products.Name,
+			Limit:   10,
+			Offset:  20,
+		}
+		mockPage = products.ProductPage{
+			Products: []products.Product{{ID: 123, OwnerName: mockUsername}},
+			Total:    21,
+		}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		expectedData products.ProductPage
+		err          error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductPage(f.tx, mockUsername, mockFilter).Return(mockPage, nil),
+				)
+			},
+			expectedData: mockPage,
+			err:          nil,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProductPage(f.tx, mockUsername, mockFilter).Return(products.ProductPage{}, shared.ErrNoData),
+				)
+			},
+			expectedData: products.ProductPage{},
+			err:          shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := fields{
+				tx:         &sqlx.Tx{},
+				date:       mockshared.NewMockDateTool(ctrl),
+				ids:        mockshared.NewMockIDGenerator(ctrl),
+				authorizer: mockshared.NewMockAuthorizer(ctrl),
+
+				productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
+				productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+				productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
+			}
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			service := products.NewProductsService(
+				s.log,
+				f.date,
+				f.ids,
+				f.authorizer,
+
+				f.productsRepo,
+				f.productsStatistics,
+				f.productsWebhooks,
+			)
+
+			data, err := service.FindProductPage(f.tx, mockUsername, mockFilter)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
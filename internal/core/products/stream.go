@@ -0,0 +1,163 @@
+package products
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// streamBacklogSize number of the last events kept for resuming streams
+	streamBacklogSize = 1024
+
+	// subscriptionBufferSize number of events queued for a subscriber before it is dropped as too slow
+	subscriptionBufferSize = 64
+)
+
+// StreamEvent product change pushed to streams of the product owner.
+// ID grows monotonically and is used by clients to resume the stream
+type StreamEvent struct {
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Product    Product   `json:"product"`
+	Previous   *Product  `json:"previous,omitempty"`
+}
+
+// Subscription stream of product changes of one owner.
+// Events is closed when the subscriber is too slow or the service stops streaming,
+// the client is expected to reconnect and resume from the last received event
+type Subscription struct {
+	Events <-chan StreamEvent
+
+	owner  string
+	events chan StreamEvent
+}
+
+// streamHub in-memory pub/sub of product changes with a backlog of the last events.
+// Event ids are prefixed with the hub epoch, so ids issued before a restart are never resumed
+type streamHub struct {
+	mu sync.Mutex
+
+	epoch   string
+	lastSeq uint64
+	backlog []streamEntry
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+type streamEntry struct {
+	seq   uint64
+	owner string
+	event StreamEvent
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// publish assigns an id to the event and sends it to subscribers of the owner
+func (h *streamHub) publish(owner string, event StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastSeq++
+	event.ID = h.epoch + "-" + strconv.FormatUint(h.lastSeq, 10)
+
+	if len(h.backlog) == streamBacklogSize {
+		h.backlog = append(h.backlog[:0], h.backlog[1:]...)
+	}
+	h.backlog = append(h.backlog, streamEntry{seq: h.lastSeq, owner: owner, event: event})
+
+	for sub := range h.subs {
+		if sub.owner != owner {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			delete(h.subs, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// subscribe registers a subscriber of the owner and returns its events published after lastEventID.
+// complete is false if events after lastEventID are not available anymore
+func (h *streamHub) subscribe(owner, lastEventID string) (sub *Subscription, missed []StreamEvent, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events := make(chan StreamEvent, subscriptionBufferSize)
+	sub = &Subscription{Events: events, owner: owner, events: events}
+
+	if h.closed {
+		close(events)
+		return sub, nil, true
+	}
+	h.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	seq, ok := h.parseID(lastEventID)
+	if !ok || seq > h.lastSeq {
+		return sub, nil, false
+	}
+
+	if len(h.backlog) > 0 && seq+1 < h.backlog[0].seq {
+		return sub, nil, false
+	}
+
+	for _, entry := range h.backlog {
+		if entry.seq > seq && entry.owner == owner {
+			missed = append(missed, entry.event)
+		}
+	}
+
+	return sub, missed, true
+}
+
+// unsubscribe removes the subscriber, it is safe to call for dropped subscribers
+func (h *streamHub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// close drops all subscribers and rejects new ones
+func (h *streamHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+// parseID sequence number of the event id issued by this hub
+func (h *streamHub) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
@@ -0,0 +1,242 @@
+package products_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	mockproducts "github.com/fallra1n/product-keeper/internal/mocks/products"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+type RunStreamSuite struct {
+	suite.Suite
+	log *slog.Logger
+
+	tx           *sqlx.Tx
+	productsRepo *mockproducts.MockProductsRepo
+	service      *products.ProductsService
+}
+
+func TestRunStreamSuite(t *testing.T) {
+	suite.Run(t, new(RunStreamSuite))
+}
+
+func (s *RunStreamSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+
+	ctrl := gomock.NewController(s.T())
+
+	date := mockshared.NewMockDateTool(ctrl)
+	date.EXPECT().Now().Return(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)).AnyTimes()
+
+	ids := mockshared.NewMockIDGenerator(ctrl)
+	ids.EXPECT().NewID().Return("test event id", nil).AnyTimes()
+
+	authorizer := mockshared.NewMockAuthorizer(ctrl)
+	authorizer.EXPECT().Authorize(gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+
+	productsStatistics := mockproducts.NewMockProductsStatistics(ctrl)
+	productsStatistics.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	productsWebhooks := mockproducts.NewMockProductsWebhooks(ctrl)
+	productsWebhooks.EXPECT().Enqueue(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	s.tx = &sqlx.Tx{}
+	s.productsRepo = mockproducts.NewMockProductsRepo(ctrl)
+	s.service = products.NewProductsService(s.log, date, ids, authorizer, s.productsRepo, productsStatistics, productsWebhooks)
+}
+
+func (s *RunStreamSuite) createProduct(id uint64, ownerName string) {
+	s.productsRepo.EXPECT().CreateProduct(s.tx, gomock.Any()).Return(id, nil)
+
+	_, events, err := s.service.CreateProduct(s.tx, shared.NewSubject(ownerName, "user"), products.Product{Name: "test product", OwnerName: ownerName})
+	s.Require().NoError(err)
+
+	s.service.PublishEvents(events)
+}
+
+func (s *RunStreamSuite) receive(sub *products.Subscription) products.StreamEvent {
+	select {
+	case event, ok := <-sub.Events:
+		s.Require().True(ok, "subscription is closed")
+		return event
+	case <-time.After(time.Second):
+		s.FailNow("no stream event")
+		return products.StreamEvent{}
+	}
+}
+
+func (s *RunStreamSuite) TestOwnerEvents() {
+	sub, missed, reset := s.service.SubscribeProducts("test username", "")
+	defer s.service.UnsubscribeProducts(sub)
+	s.Empty(missed)
+	s.False(reset)
+
+	s.createProduct(1, "test other username")
+	s.createProduct(2, "test username")
+
+	event := s.receive(sub)
+	s.Equal(products.EventCreated, event.Type)
+	s.Equal(uint64(2), event.Product.ID)
+	s.NotEmpty(event.ID)
+	s.Empty(sub.Events)
+}
+
+func (s *RunStreamSuite) TestStockChanged() {
+	product := products.Product{ID: 1, Name: "test product", Quantity: 10, OwnerName: "test username"}
+	updated := product
+	updated.Quantity = 5
+
+	s.productsRepo.EXPECT().FindProductForUpdate(s.tx, product.ID).Return(product, nil)
+	s.productsRepo.EXPECT().UpdateProduct(s.tx, updated).Return(updated, nil)
+
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	defer s.service.UnsubscribeProducts(sub)
+
+	_, events, err := s.service.UpdateProduct(s.tx, shared.NewSubject("test username", "user"), updated)
+	s.Require().NoError(err)
+
+	s.service.PublishEvents(events)
+
+	event := s.receive(sub)
+	s.Equal(products.EventUpdated, event.Type)
+	s.Equal(&product, event.Previous)
+
+	event = s.receive(sub)
+	s.Equal(products.EventStockChanged, event.Type)
+	s.Equal(uint64(5), event.Product.Quantity)
+}
+
+func (s *RunStreamSuite) TestNotPublishedBeforeCommit() {
+	s.productsRepo.EXPECT().CreateProduct(s.tx, gomock.Any()).Return(uint64(1), nil)
+
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	defer s.service.UnsubscribeProducts(sub)
+
+	// the handler publishes events only after the transaction is committed
+	_, events, err := s.service.CreateProduct(s.tx, shared.NewSubject("test username", "user"), products.Product{Name: "test product", OwnerName: "test username"})
+	s.Require().NoError(err)
+	s.Empty(sub.Events)
+
+	s.service.PublishEvents(events)
+
+	event := s.receive(sub)
+	s.Equal(products.EventCreated, event.Type)
+	s.Equal(uint64(1), event.Product.ID)
+}
+
+func (s *RunStreamSuite) TestTransferredProducts() {
+	product := products.Product{ID: 1, Name: "test product", OwnerName: "test username"}
+	received := products.Product{ID: 1, Name: "test product", OwnerName: "test receiver"}
+
+	s.productsRepo.EXPECT().FindOwnerProducts(s.tx, "test username").Return([]products.Product{product}, nil)
+	s.productsRepo.EXPECT().TransferProducts(s.tx, "test username", "test receiver").Return(nil)
+	s.productsRepo.EXPECT().FindOwnerProducts(s.tx, "test receiver").Return([]products.Product{received}, nil)
+
+	sub, _, _ := s.service.SubscribeProducts("test receiver", "")
+	defer s.service.UnsubscribeProducts(sub)
+
+	publish, err := s.service.TransferProducts(s.tx, shared.NewSubject("test username", "user"), "test username", "test receiver")
+	s.Require().NoError(err)
+	s.Empty(sub.Events)
+
+	publish()
+
+	event := s.receive(sub)
+	s.Equal(products.EventUpdated, event.Type)
+	s.Equal(received, event.Product)
+	s.Equal(&product, event.Previous)
+}
+
+func (s *RunStreamSuite) TestViewedNotStreamed() {
+	product := products.Product{ID: 1, Name: "test product", OwnerName: "test username"}
+	s.productsRepo.EXPECT().FindProduct(s.tx, product.ID).Return(product, nil)
+
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	defer s.service.UnsubscribeProducts(sub)
+
+	_, err := s.service.FindProduct(s.tx, product.ID, shared.NewSubject("test username", "user"))
+	s.Require().NoError(err)
+	s.Empty(sub.Events)
+}
+
+func (s *RunStreamSuite) TestResume() {
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	s.createProduct(1, "test username")
+	first := s.receive(sub)
+	s.service.UnsubscribeProducts(sub)
+
+	s.createProduct(2, "test username")
+	s.createProduct(3, "test other username")
+	s.createProduct(4, "test username")
+
+	sub, missed, reset := s.service.SubscribeProducts("test username", first.ID)
+	defer s.service.UnsubscribeProducts(sub)
+	s.False(reset)
+	s.Require().Len(missed, 2)
+	s.Equal(uint64(2), missed[0].Product.ID)
+	s.Equal(uint64(4), missed[1].Product.ID)
+}
+
+func (s *RunStreamSuite) TestResumeUnknownID() {
+	s.createProduct(1, "test username")
+
+	for _, id := range []string{"unknown", "test-1", "0-1"} {
+		sub, missed, reset := s.service.SubscribeProducts("test username", id)
+		s.True(reset, id)
+		s.Empty(missed, id)
+		s.service.UnsubscribeProducts(sub)
+	}
+}
+
+func (s *RunStreamSuite) TestResumeLostEvents() {
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	s.createProduct(1, "test username")
+	first := s.receive(sub)
+	s.service.UnsubscribeProducts(sub)
+
+	for i := 0; i < 2000; i++ {
+		s.createProduct(uint64(i+2), "test other username")
+	}
+
+	sub, missed, reset := s.service.SubscribeProducts("test username", first.ID)
+	defer s.service.UnsubscribeProducts(sub)
+	s.True(reset)
+	s.Empty(missed)
+}
+
+func (s *RunStreamSuite) TestSlowSubscriberDropped() {
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	defer s.service.UnsubscribeProducts(sub)
+
+	for i := 0; i < 100; i++ {
+		s.createProduct(uint64(i+1), "test username")
+	}
+
+	received := 0
+	for range sub.Events {
+		received++
+	}
+	s.Less(received, 100)
+}
+
+func (s *RunStreamSuite) TestCloseStreams() {
+	sub, _, _ := s.service.SubscribeProducts("test username", "")
+	s.service.CloseStreams()
+
+	_, ok := <-sub.Events
+	s.False(ok)
+
+	sub, _, _ = s.service.SubscribeProducts("test username", "")
+	_, ok = <-sub.Events
+	s.False(ok)
+	s.service.UnsubscribeProducts(sub)
+}
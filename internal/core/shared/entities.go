@@ -9,3 +9,31 @@ var (
 	// ErrInternal internal error, please try again later
 	ErrInternal = errors.New("internal error, please try again later")
 )
+
+// Subject user who performs the action, Trace is set for actions made by http requests
+type Subject struct {
+	Name  string
+	Role  string
+	Trace Trace
+}
+
+// NewSubject constructor for Subject
+func NewSubject(name string, role string) Subject {
+	return Subject{
+		Name: name,
+		Role: role,
+	}
+}
+
+// Trace identifiers of the request, passed to published events
+type Trace struct {
+	TraceID   string
+	RequestID string
+}
+
+// Message notification for user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -8,15 +8,54 @@ import (
 type Crypto interface {
 	HashPassword(password string) (string, error)
 	CompareHashAndPassword(hashedPassword, password string) error
+	HashToken(token string) string
+	CompareHashAndToken(hashedToken, token string) error
+	NeedsRehash(hashedPassword string) bool
+	RandomString(size int) (string, error)
+}
+
+// Signer interface for signing data sent to users
+type Signer interface {
+	Sign(payload string) string
+	Verify(payload string, signature string) bool
 }
 
 // Jwt interface for working with jwt tokens
 type Jwt interface {
-	GenerateToken(username string) (string, error)
-	ParseToken(tokenString string) (string, error)
+	GenerateToken(username string, role string, sessionID string) (string, error)
+	ParseToken(tokenString string) (string, string, string, error)
+	JWKS() ([]byte, error)
+}
+
+// TOTP interface for time-based one-time passwords
+type TOTP interface {
+	GenerateSecret() (string, error)
+	URI(account string, secret string) string
+	Validate(secret string, code string, now time.Time) (int64, bool)
+}
+
+// Notifier interface for sending notifications to users
+type Notifier interface {
+	Send(message Message) error
+}
+
+// EventBus interface for publishing events to other services.
+// Messages with the same key are delivered in order if the broker supports it
+type EventBus interface {
+	Publish(topic, key string, headers map[string]string, payload []byte) error
 }
 
 // DateTool interface for wor working with time
 type DateTool interface {
 	Now() time.Time
 }
+
+// IDGenerator interface for generating unique ids
+type IDGenerator interface {
+	NewID() (string, error)
+}
+
+// Authorizer interface for checking access permissions
+type Authorizer interface {
+	Authorize(subject Subject, action string, owner string) bool
+}
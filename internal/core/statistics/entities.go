@@ -0,0 +1,108 @@
+package statistics
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrProductNotFound product not found
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrPermissionDenied user does not have access to statistics of this product
+	ErrPermissionDenied = errors.New("user does not have access to product statistics")
+
+	// ErrInvalidPeriod unknown aggregation period
+	ErrInvalidPeriod = errors.New("invalid statistics period")
+
+	// ErrInvalidRange time range is empty or too long
+	ErrInvalidRange = errors.New("invalid statistics time range")
+
+	// ErrInvalidLimit limit of top viewed products is out of range
+	ErrInvalidLimit = errors.New("invalid limit of top viewed products")
+)
+
+// ActionRead view product statistics
+const ActionRead = "product:stats:read"
+
+const (
+	// MaxPoints max number of periods in one response
+	MaxPoints = 1000
+
+	// MaxTopLimit max number of top viewed products
+	MaxTopLimit = 100
+
+	// TopDefaultRange range of top viewed products if it is not set
+	TopDefaultRange = 7 * 24 * time.Hour
+)
+
+// Period aggregation period of product views
+type Period string
+
+const (
+	// PeriodHour views per hour
+	PeriodHour Period = "hour"
+
+	// PeriodDay views per day
+	PeriodDay Period = "day"
+)
+
+// Periods all aggregation periods, each view is counted in every period
+var Periods = []Period{PeriodHour, PeriodDay}
+
+// Valid ...
+func (p Period) Valid() bool {
+	return p == PeriodHour || p == PeriodDay
+}
+
+// Duration length of the period
+func (p Period) Duration() time.Duration {
+	if p == PeriodDay {
+		return 24 * time.Hour
+	}
+
+	return time.Hour
+}
+
+// DefaultRange range of statistics if it is not set, 24 hours or 30 days
+func (p Period) DefaultRange() time.Duration {
+	if p == PeriodDay {
+		return 30 * 24 * time.Hour
+	}
+
+	return 24 * time.Hour
+}
+
+// Start start of the period containing t, periods are aligned in UTC
+func (p Period) Start(t time.Time) time.Time {
+	return t.UTC().Truncate(p.Duration())
+}
+
+// View product view read from the events topic
+type View struct {
+	EventID   string
+	ProductID uint64
+	ViewedAt  time.Time
+}
+
+// ViewCount number of product views in the period
+type ViewCount struct {
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	Views       uint64    `json:"views" db:"views"`
+}
+
+// ProductViews number of product views in the time range
+type ProductViews struct {
+	ProductID uint64 `json:"product_id" db:"product_id"`
+	Name      string `json:"name" db:"name"`
+	OwnerName string `json:"owner_name" db:"owner_name"`
+	Views     uint64 `json:"views" db:"views"`
+}
+
+// Settings cleanup of processed events.
+// Event ids are kept for ProcessedEventsRetention to skip redelivered views, so it must not be shorter
+// than retention of the views topic. Zero retention keeps them
+type Settings struct {
+	ProcessedEventsRetention time.Duration
+	CleanupBatchSize         int
+}
@@ -0,0 +1,20 @@
+package statistics
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StatisticsRepo ...
+type StatisticsRepo interface {
+	// AddProcessedEvent returns false if the event has already been processed
+	AddProcessedEvent(tx *sqlx.Tx, id string, processedAt time.Time) (bool, error)
+	// DeleteProcessedEvents returns the number of deleted events
+	DeleteProcessedEvents(tx *sqlx.Tx, processedBefore time.Time, limit int) (int, error)
+	AddViews(tx *sqlx.Tx, productID uint64, period Period, periodStart time.Time, views uint64) error
+	FindViews(tx *sqlx.Tx, productID uint64, period Period, from, to time.Time) ([]ViewCount, error)
+	// FindTopViewed finds products of the owner, or of all owners if ownerName is empty
+	FindTopViewed(tx *sqlx.Tx, ownerName string, from, to time.Time, limit int) ([]ProductViews, error)
+	FindProductOwner(tx *sqlx.Tx, productID uint64) (string, error)
+}
@@ -0,0 +1,168 @@
+package statistics
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// StatisticsService aggregates product views
+type StatisticsService struct {
+	log        *slog.Logger
+	date       shared.DateTool
+	authorizer shared.Authorizer
+
+	statisticsRepo StatisticsRepo
+
+	settings Settings
+}
+
+// NewStatisticsService ...
+func NewStatisticsService(
+	log *slog.Logger,
+	date shared.DateTool,
+	authorizer shared.Authorizer,
+
+	statisticsRepo StatisticsRepo,
+
+	settings Settings,
+) *StatisticsService {
+	return &StatisticsService{
+		log:        log,
+		date:       date,
+		authorizer: authorizer,
+
+		statisticsRepo: statisticsRepo,
+
+		settings: settings,
+	}
+}
+
+// RecordView counts the view in every period.
+// Events are delivered at least once, so a view with already processed event id is skipped
+func (s *StatisticsService) RecordView(tx *sqlx.Tx, view View) error {
+	added, err := s.statisticsRepo.AddProcessedEvent(tx, view.EventID, s.date.Now())
+	if err != nil {
+		s.log.Error("failed to add processed event", "error", err, "event", view.EventID)
+		return shared.ErrInternal
+	}
+
+	if !added {
+		s.log.Info("product view has already been recorded", "event", view.EventID, "id", view.ProductID)
+		return nil
+	}
+
+	for _, period := range Periods {
+		if err := s.statisticsRepo.AddViews(tx, view.ProductID, period, period.Start(view.ViewedAt), 1); err != nil {
+			s.log.Error("failed to add product views", "error", err, "id", view.ProductID, "period", period)
+			return shared.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// DeleteProcessedEvents deletes a batch of event ids processed more than ProcessedEventsRetention ago
+// and returns its size
+func (s *StatisticsService) DeleteProcessedEvents(tx *sqlx.Tx) (int, error) {
+	if s.settings.ProcessedEventsRetention <= 0 {
+		return 0, nil
+	}
+
+	processedBefore := s.date.Now().Add(-s.settings.ProcessedEventsRetention)
+
+	n, err := s.statisticsRepo.DeleteProcessedEvents(tx, processedBefore, s.settings.CleanupBatchSize)
+	if err != nil {
+		s.log.Error("failed to delete processed events", "error", err, "processedBefore", processedBefore)
+		return 0, shared.ErrInternal
+	}
+
+	return n, nil
+}
+
+// FindProductViews finds views of the product per period in [from, to).
+// Zero to means now, zero from means the default range of the period before to
+func (s *StatisticsService) FindProductViews(tx *sqlx.Tx, user shared.Subject, productID uint64, period Period, from, to time.Time) ([]ViewCount, error) {
+	if !period.Valid() {
+		s.log.Error(ErrInvalidPeriod.Error(), "period", period)
+		return nil, ErrInvalidPeriod
+	}
+
+	from, to = s.timeRange(from, to, period.DefaultRange())
+	if !from.Before(to) || to.Sub(from)/period.Duration() > MaxPoints {
+		s.log.Error(ErrInvalidRange.Error(), "from", from, "to", to, "period", period)
+		return nil, ErrInvalidRange
+	}
+
+	owner, err := s.statisticsRepo.FindProductOwner(tx, productID)
+	if err != nil {
+		s.log.Error("failed to find product owner", "error", err, "id", productID)
+		if errors.Is(err, shared.ErrNoData) {
+			return nil, ErrProductNotFound
+		}
+
+		return nil, shared.ErrInternal
+	}
+
+	if !s.authorizer.Authorize(user, ActionRead, owner) {
+		s.log.Error(ErrPermissionDenied.Error(), "username", user.Name, "role", user.Role, "id", productID, "ownername", owner)
+		return nil, ErrPermissionDenied
+	}
+
+	data, err := s.statisticsRepo.FindViews(tx, productID, period, period.Start(from), to)
+	if err != nil {
+		s.log.Error("failed to find product views", "error", err, "id", productID, "period", period)
+		return nil, shared.ErrInternal
+	}
+
+	return data, nil
+}
+
+// FindTopViewed finds the most viewed products in [from, to).
+// Users allowed to read statistics of any product get top of all products, others get top of their own products
+func (s *StatisticsService) FindTopViewed(tx *sqlx.Tx, user shared.Subject, from, to time.Time, limit int) ([]ProductViews, error) {
+	if limit < 1 || limit > MaxTopLimit {
+		s.log.Error(ErrInvalidLimit.Error(), "limit", limit)
+		return nil, ErrInvalidLimit
+	}
+
+	from, to = s.timeRange(from, to, TopDefaultRange)
+	if !from.Before(to) {
+		s.log.Error(ErrInvalidRange.Error(), "from", from, "to", to)
+		return nil, ErrInvalidRange
+	}
+
+	if !s.authorizer.Authorize(user, ActionRead, user.Name) {
+		s.log.Error(ErrPermissionDenied.Error(), "username", user.Name, "role", user.Role)
+		return nil, ErrPermissionDenied
+	}
+
+	ownerName := user.Name
+	if s.authorizer.Authorize(user, ActionRead, "") {
+		ownerName = ""
+	}
+
+	data, err := s.statisticsRepo.FindTopViewed(tx, ownerName, PeriodHour.Start(from), to, limit)
+	if err != nil {
+		s.log.Error("failed to find top viewed products", "error", err, "ownername", ownerName)
+		return nil, shared.ErrInternal
+	}
+
+	return data, nil
+}
+
+func (s *StatisticsService) timeRange(from, to time.Time, defaultRange time.Duration) (time.Time, time.Time) {
+	if to.IsZero() {
+		to = s.date.Now()
+	}
+
+	if from.IsZero() {
+		from = to.Add(-defaultRange)
+	}
+
+	return from, to
+}
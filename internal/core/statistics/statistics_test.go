@@ -0,0 +1,426 @@
+package statistics_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	mockstatistics "github.com/fallra1n/product-keeper/internal/mocks/statistics"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+var mockSettings = statistics.Settings{
+	ProcessedEventsRetention: 24 * time.Hour,
+	CleanupBatchSize:         2,
+}
+
+type RunStatisticsSuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunStatisticsSuite(t *testing.T) {
+	suite.Run(t, new(RunStatisticsSuite))
+}
+
+func (s *RunStatisticsSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+}
+
+type fields struct {
+	tx         *sqlx.Tx
+	date       *mockshared.MockDateTool
+	authorizer *mockshared.MockAuthorizer
+
+	statisticsRepo *mockstatistics.MockStatisticsRepo
+}
+
+func newFields(ctrl *gomock.Controller) fields {
+	return fields{
+		tx:         &sqlx.Tx{},
+		date:       mockshared.NewMockDateTool(ctrl),
+		authorizer: mockshared.NewMockAuthorizer(ctrl),
+
+		statisticsRepo: mockstatistics.NewMockStatisticsRepo(ctrl),
+	}
+}
+
+func (f fields) service(log *slog.Logger) *statistics.StatisticsService {
+	return statistics.NewStatisticsService(log, f.date, f.authorizer, f.statisticsRepo, mockSettings)
+}
+
+func (s *RunStatisticsSuite) TestRecordView() {
+	var (
+		mockNow  = time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+		mockView = statistics.View{
+			EventID:   "test event id",
+			ProductID: 123,
+			ViewedAt:  time.Date(2000, 1, 1, 10, 30, 0, 0, time.UTC),
+		}
+	)
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().AddProcessedEvent(f.tx, "test event id", mockNow).Return(true, nil),
+					f.statisticsRepo.EXPECT().AddViews(f.tx, uint64(123), statistics.PeriodHour, time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC), uint64(1)).Return(nil),
+					f.statisticsRepo.EXPECT().AddViews(f.tx, uint64(123), statistics.PeriodDay, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), uint64(1)).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "duplicate event",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().AddProcessedEvent(f.tx, "test event id", mockNow).Return(false, nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "internal error(add processed event)",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().AddProcessedEvent(f.tx, "test event id", mockNow).Return(false, shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "internal error(add views)",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().AddProcessedEvent(f.tx, "test event id", mockNow).Return(true, nil),
+					f.statisticsRepo.EXPECT().AddViews(f.tx, uint64(123), statistics.PeriodHour, gomock.Any(), uint64(1)).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			err := f.service(s.log).RecordView(f.tx, mockView)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunStatisticsSuite) TestDeleteProcessedEvents() {
+	mockNow := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected int
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().DeleteProcessedEvents(f.tx, mockNow.Add(-mockSettings.ProcessedEventsRetention), mockSettings.CleanupBatchSize).Return(2, nil),
+				)
+			},
+			expected: 2,
+			err:      nil,
+		},
+		{
+			name: "failed to delete processed events",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().DeleteProcessedEvents(f.tx, mockNow.Add(-mockSettings.ProcessedEventsRetention), mockSettings.CleanupBatchSize).Return(0, shared.ErrNoData),
+				)
+			},
+			expected: 0,
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			n, err := f.service(s.log).DeleteProcessedEvents(f.tx)
+			s.Equal(row.expected, n)
+			s.Equal(row.err, err)
+		})
+	}
+
+	// zero retention keeps processed events
+	ctrl := gomock.NewController(s.T())
+	f := newFields(ctrl)
+
+	n, err := statistics.NewStatisticsService(s.log, f.date, f.authorizer, f.statisticsRepo, statistics.Settings{}).DeleteProcessedEvents(f.tx)
+	s.NoError(err)
+	s.Zero(n)
+}
+
+func (s *RunStatisticsSuite) TestFindProductViews() {
+	type args struct {
+		period   statistics.Period
+		from, to time.Time
+	}
+
+	var (
+		mockProductID = uint64(123)
+		mockUser      = shared.NewSubject("test username", "user")
+		mockNow       = time.Date(2000, 1, 2, 10, 30, 0, 0, time.UTC)
+		mockViews     = []statistics.ViewCount{
+			{PeriodStart: time.Date(2000, 1, 2, 9, 0, 0, 0, time.UTC), Views: 3},
+		}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		args         args
+		expectedData []statistics.ViewCount
+		err          error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().FindProductOwner(f.tx, mockProductID).Return(mockUser.Name, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, mockUser.Name).Return(true),
+					f.statisticsRepo.EXPECT().FindViews(f.tx, mockProductID, statistics.PeriodHour, time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC), mockNow).Return(mockViews, nil),
+				)
+			},
+			args:         args{period: statistics.PeriodHour},
+			expectedData: mockViews,
+			err:          nil,
+		},
+		{
+			name: "explicit range",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.statisticsRepo.EXPECT().FindProductOwner(f.tx, mockProductID).Return(mockUser.Name, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, mockUser.Name).Return(true),
+					f.statisticsRepo.EXPECT().FindViews(f.tx, mockProductID, statistics.PeriodDay, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), mockNow).Return(mockViews, nil),
+				)
+			},
+			args: args{
+				period: statistics.PeriodDay,
+				from:   time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC),
+				to:     mockNow,
+			},
+			expectedData: mockViews,
+			err:          nil,
+		},
+		{
+			name:    "invalid period",
+			prepare: nil,
+			args:    args{period: "week"},
+			err:     statistics.ErrInvalidPeriod,
+		},
+		{
+			name:    "empty range",
+			prepare: nil,
+			args: args{
+				period: statistics.PeriodHour,
+				from:   mockNow,
+				to:     mockNow,
+			},
+			err: statistics.ErrInvalidRange,
+		},
+		{
+			name:    "too long range",
+			prepare: nil,
+			args: args{
+				period: statistics.PeriodHour,
+				from:   mockNow.Add(-(statistics.MaxPoints + 1) * time.Hour),
+				to:     mockNow,
+			},
+			err: statistics.ErrInvalidRange,
+		},
+		{
+			name: "product not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().FindProductOwner(f.tx, mockProductID).Return("", shared.ErrNoData),
+				)
+			},
+			args: args{period: statistics.PeriodHour},
+			err:  statistics.ErrProductNotFound,
+		},
+		{
+			name: "permission denied",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().FindProductOwner(f.tx, mockProductID).Return("other username", nil),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, "other username").Return(false),
+				)
+			},
+			args: args{period: statistics.PeriodHour},
+			err:  statistics.ErrPermissionDenied,
+		},
+		{
+			name: "internal error(find views)",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.statisticsRepo.EXPECT().FindProductOwner(f.tx, mockProductID).Return(mockUser.Name, nil),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, mockUser.Name).Return(true),
+					f.statisticsRepo.EXPECT().FindViews(f.tx, mockProductID, statistics.PeriodHour, gomock.Any(), mockNow).Return(nil, shared.ErrNoData),
+				)
+			},
+			args: args{period: statistics.PeriodHour},
+			err:  shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			data, err := f.service(s.log).FindProductViews(f.tx, mockUser, mockProductID, row.args.period, row.args.from, row.args.to)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
+
+func (s *RunStatisticsSuite) TestFindTopViewed() {
+	var (
+		mockUser  = shared.NewSubject("test username", "user")
+		mockAdmin = shared.NewSubject("test admin", "admin")
+		mockNow   = time.Date(2000, 1, 8, 10, 30, 0, 0, time.UTC)
+		mockFrom  = time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC)
+		mockTop   = []statistics.ProductViews{
+			{ProductID: 123, Name: "test product", OwnerName: mockUser.Name, Views: 42},
+		}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		user         shared.Subject
+		limit        int
+		expectedData []statistics.ProductViews
+		err          error
+	}{
+		{
+			name: "top of own products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, mockUser.Name).Return(true),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, "").Return(false),
+					f.statisticsRepo.EXPECT().FindTopViewed(f.tx, mockUser.Name, mockFrom, mockNow, 10).Return(mockTop, nil),
+				)
+			},
+			user:         mockUser,
+			limit:        10,
+			expectedData: mockTop,
+			err:          nil,
+		},
+		{
+			name: "top of all products",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authorizer.EXPECT().Authorize(mockAdmin, statistics.ActionRead, mockAdmin.Name).Return(true),
+					f.authorizer.EXPECT().Authorize(mockAdmin, statistics.ActionRead, "").Return(true),
+					f.statisticsRepo.EXPECT().FindTopViewed(f.tx, "", mockFrom, mockNow, 10).Return(mockTop, nil),
+				)
+			},
+			user:         mockAdmin,
+			limit:        10,
+			expectedData: mockTop,
+			err:          nil,
+		},
+		{
+			name:    "invalid limit",
+			prepare: nil,
+			user:    mockUser,
+			limit:   statistics.MaxTopLimit + 1,
+			err:     statistics.ErrInvalidLimit,
+		},
+		{
+			name: "permission denied",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, mockUser.Name).Return(false),
+				)
+			},
+			user:  mockUser,
+			limit: 10,
+			err:   statistics.ErrPermissionDenied,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, mockUser.Name).Return(true),
+					f.authorizer.EXPECT().Authorize(mockUser, statistics.ActionRead, "").Return(false),
+					f.statisticsRepo.EXPECT().FindTopViewed(f.tx, mockUser.Name, mockFrom, mockNow, 10).Return(nil, shared.ErrNoData),
+				)
+			},
+			user:  mockUser,
+			limit: 10,
+			err:   shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			data, err := f.service(s.log).FindTopViewed(f.tx, row.user, time.Time{}, time.Time{}, row.limit)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+// FindDeliveryList the last deliveries of the webhook
+func (s *WebhooksService) FindDeliveryList(tx *sqlx.Tx, webhookID uint64, ownerName string) ([]Delivery, error) {
+	if _, err := s.FindWebhook(tx, webhookID, ownerName); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhooksRepo.FindDeliveryList(tx, webhookID, deliveryListLimit)
+	if err != nil {
+		s.log.Error("failed to find webhook delivery list", "error", err, "webhook", webhookID)
+		return nil, shared.ErrInternal
+	}
+
+	return deliveries, nil
+}
+
+// FindDelivery delivery of the webhook with the log of its attempts
+func (s *WebhooksService) FindDelivery(tx *sqlx.Tx, id uint64, webhookID uint64, ownerName string) (Delivery, []Attempt, error) {
+	delivery, err := s.findDelivery(tx, id, webhookID, ownerName)
+	if err != nil {
+		return Delivery{}, nil, err
+	}
+
+	attempts, err := s.webhooksRepo.FindAttempts(tx, id)
+	if err != nil {
+		s.log.Error("failed to find webhook delivery attempts", "error", err, "id", id)
+		return Delivery{}, nil, shared.ErrInternal
+	}
+
+	return delivery, attempts, nil
+}
+
+// RetryDelivery returns failed delivery to the queue with a new set of attempts
+func (s *WebhooksService) RetryDelivery(tx *sqlx.Tx, id uint64, webhookID uint64, ownerName string) (Delivery, error) {
+	delivery, err := s.findDelivery(tx, id, webhookID, ownerName)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	if delivery.Status != DeliveryFailed {
+		s.log.Error(ErrDeliveryNotFailed.Error(), "id", id, "status", delivery.Status)
+		return Delivery{}, ErrDeliveryNotFailed
+	}
+
+	delivery.Status = DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = s.date.Now()
+
+	if err := s.webhooksRepo.UpdateDelivery(tx, delivery); err != nil {
+		s.log.Error("failed to retry webhook delivery", "error", err, "id", id)
+		return Delivery{}, shared.ErrInternal
+	}
+
+	s.log.Info("webhook delivery has been queued again", "id", id, "webhook", webhookID)
+	return delivery, nil
+}
+
+// ClaimDeliveries locks a batch of due deliveries and postpones them for the time of sending,
+// so after the transaction is committed other workers skip them while they are sent.
+// Deliveries whose attempts are not recorded before the lease expires, e.g. after a crash, are sent again,
+// delivery is at least once
+func (s *WebhooksService) ClaimDeliveries(tx *sqlx.Tx) ([]PendingDelivery, error) {
+	now := s.date.Now()
+
+	deliveries, err := s.webhooksRepo.FindPendingDeliveries(tx, now, s.settings.BatchSize)
+	if err != nil {
+		s.log.Error("failed to find pending webhook deliveries", "error", err)
+		return nil, shared.ErrInternal
+	}
+
+	// deliveries are sent one by one, each of them takes up to the timeout
+	leaseUntil := now.Add(time.Duration(len(deliveries)+1) * s.settings.Timeout)
+
+	for i := range deliveries {
+		deliveries[i].NextAttemptAt = leaseUntil
+
+		if err := s.webhooksRepo.UpdateDelivery(tx, deliveries[i].Delivery); err != nil {
+			s.log.Error("failed to claim webhook delivery", "error", err, "id", deliveries[i].ID)
+			return nil, shared.ErrInternal
+		}
+	}
+
+	return deliveries, nil
+}
+
+// SendDeliveries sends claimed deliveries and returns their attempts in the same order,
+// it is called outside of transaction
+func (s *WebhooksService) SendDeliveries(deliveries []PendingDelivery) []Attempt {
+	attempts := make([]Attempt, 0, len(deliveries))
+
+	for _, pending := range deliveries {
+		delivery := pending.Delivery
+		delivery.Attempts++
+
+		attempt := Attempt{
+			DeliveryID:  delivery.ID,
+			AttemptedAt: s.date.Now(),
+		}
+
+		var err error
+		attempt.StatusCode, err = s.webhookSender.Send(pending.URL, pending.Secret, delivery, attempt.AttemptedAt)
+		switch {
+		case err != nil:
+			attempt.Error = err.Error()
+		case attempt.StatusCode < 200 || attempt.StatusCode > 299:
+			attempt.Error = fmt.Sprintf("unexpected response status %d", attempt.StatusCode)
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts
+}
+
+// RecordAttempts logs attempts of claimed deliveries and saves their results,
+// failed deliveries are postponed until they run out of attempts
+func (s *WebhooksService) RecordAttempts(tx *sqlx.Tx, deliveries []PendingDelivery, attempts []Attempt) error {
+	for i, attempt := range attempts {
+		delivery := deliveries[i].Delivery
+		delivery.Attempts++
+
+		if attempt.Error == "" {
+			delivery.Status = DeliveryDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &attempt.AttemptedAt
+		} else {
+			delivery.LastError = attempt.Error
+
+			if delivery.Attempts >= s.settings.MaxAttempts {
+				delivery.Status = DeliveryFailed
+				s.log.Error("webhook delivery has failed", "error", attempt.Error, "id", delivery.ID, "webhook", delivery.WebhookID, "attempts", delivery.Attempts)
+			} else {
+				delivery.NextAttemptAt = s.settings.RetryAt(attempt.AttemptedAt, delivery.Attempts)
+				s.log.Error("failed to send webhook delivery", "error", attempt.Error, "id", delivery.ID, "webhook", delivery.WebhookID, "attempts", delivery.Attempts)
+			}
+		}
+
+		if err := s.webhooksRepo.UpdateDelivery(tx, delivery); err != nil {
+			// the webhook has been deleted while the delivery was sent
+			if errors.Is(err, shared.ErrNoData) {
+				continue
+			}
+
+			s.log.Error("failed to update webhook delivery", "error", err, "id", delivery.ID)
+			return shared.ErrInternal
+		}
+
+		if err := s.webhooksRepo.AddAttempt(tx, attempt); err != nil {
+			s.log.Error("failed to add webhook delivery attempt", "error", err, "id", delivery.ID)
+			return shared.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+// findDelivery delivery of the webhook owned by the user
+func (s *WebhooksService) findDelivery(tx *sqlx.Tx, id uint64, webhookID uint64, ownerName string) (Delivery, error) {
+	if _, err := s.FindWebhook(tx, webhookID, ownerName); err != nil {
+		return Delivery{}, err
+	}
+
+	delivery, err := s.webhooksRepo.FindDelivery(tx, id, webhookID)
+	if err != nil {
+		s.log.Error("failed to find webhook delivery", "error", err, "id", id, "webhook", webhookID)
+		if errors.Is(err, shared.ErrNoData) {
+			return Delivery{}, ErrDeliveryNotFound
+		}
+
+		return Delivery{}, shared.ErrInternal
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,325 @@
+package webhooks_test
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+)
+
+func (s *RunWebhooksSuite) TestClaimDeliveries() {
+	var (
+		mockNow        = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockDeliveries = func() []webhooks.PendingDelivery {
+			return []webhooks.PendingDelivery{
+				{Delivery: webhooks.Delivery{ID: 1, Status: webhooks.DeliveryPending, NextAttemptAt: mockNow}, URL: "https://example.com/hook1"},
+				{Delivery: webhooks.Delivery{ID: 2, Status: webhooks.DeliveryPending, NextAttemptAt: mockNow}, URL: "https://example.com/hook2"},
+			}
+		}
+		// two deliveries are sent one by one, each of them takes up to the timeout
+		mockLease = mockNow.Add(3 * mockSettings.Timeout)
+	)
+
+	claimed := func() []webhooks.PendingDelivery {
+		deliveries := mockDeliveries()
+		for i := range deliveries {
+			deliveries[i].NextAttemptAt = mockLease
+		}
+		return deliveries
+	}
+
+	testList := []struct {
+		name     string
+		prepare  func(f *fields)
+		expected []webhooks.PendingDelivery
+		err      error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().FindPendingDeliveries(f.tx, mockNow, mockSettings.BatchSize).Return(mockDeliveries(), nil),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, claimed()[0].Delivery).Return(nil),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, claimed()[1].Delivery).Return(nil),
+				)
+			},
+			expected: claimed(),
+			err:      nil,
+		},
+		{
+			name: "no pending deliveries",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().FindPendingDeliveries(f.tx, mockNow, mockSettings.BatchSize).Return([]webhooks.PendingDelivery{}, nil),
+				)
+			},
+			expected: []webhooks.PendingDelivery{},
+			err:      nil,
+		},
+		{
+			name: "failed to find deliveries",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().FindPendingDeliveries(f.tx, mockNow, mockSettings.BatchSize).Return(nil, shared.ErrNoData),
+				)
+			},
+			expected: nil,
+			err:      shared.ErrInternal,
+		},
+		{
+			name: "failed to claim delivery",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().FindPendingDeliveries(f.tx, mockNow, mockSettings.BatchSize).Return(mockDeliveries(), nil),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			expected: nil,
+			err:      shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			deliveries, err := f.service(s.log).ClaimDeliveries(f.tx)
+			s.Equal(row.err, err)
+			s.Equal(row.expected, deliveries)
+		})
+	}
+}
+
+func (s *RunWebhooksSuite) TestSendDeliveries() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	var (
+		mockNow        = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockDeliveries = []webhooks.PendingDelivery{
+			{Delivery: webhooks.Delivery{ID: 1, EventType: products.EventCreated, Payload: []byte("test payload1")}, URL: "https://example.com/hook1", Secret: "test secret1"},
+			{Delivery: webhooks.Delivery{ID: 2, EventType: products.EventUpdated, Payload: []byte("test payload2"), Attempts: 1}, URL: "https://example.com/hook2", Secret: "test secret2"},
+			{Delivery: webhooks.Delivery{ID: 3, EventType: products.EventDeleted, Payload: []byte("test payload3")}, URL: "https://example.com/hook3", Secret: "test secret3"},
+		}
+	)
+
+	attempted := func(delivery webhooks.Delivery) webhooks.Delivery {
+		delivery.Attempts++
+		return delivery
+	}
+
+	f := newFields(ctrl)
+	gomock.InOrder(
+		f.date.EXPECT().Now().Return(mockNow),
+		f.webhookSender.EXPECT().Send("https://example.com/hook1", "test secret1", attempted(mockDeliveries[0].Delivery), mockNow).Return(204, nil),
+		f.date.EXPECT().Now().Return(mockNow.Add(time.Second)),
+		f.webhookSender.EXPECT().Send("https://example.com/hook2", "test secret2", attempted(mockDeliveries[1].Delivery), mockNow.Add(time.Second)).Return(500, nil),
+		f.date.EXPECT().Now().Return(mockNow.Add(2*time.Second)),
+		f.webhookSender.EXPECT().Send("https://example.com/hook3", "test secret3", attempted(mockDeliveries[2].Delivery), mockNow.Add(2*time.Second)).Return(0, errors.New("connection refused")),
+	)
+
+	attempts := f.service(s.log).SendDeliveries(mockDeliveries)
+	s.Equal([]webhooks.Attempt{
+		{DeliveryID: 1, AttemptedAt: mockNow, StatusCode: 204},
+		{DeliveryID: 2, AttemptedAt: mockNow.Add(time.Second), StatusCode: 500, Error: "unexpected response status 500"},
+		{DeliveryID: 3, AttemptedAt: mockNow.Add(2 * time.Second), Error: "connection refused"},
+	}, attempts)
+}
+
+func (s *RunWebhooksSuite) TestRecordAttempts() {
+	var (
+		mockNow        = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockLease      = mockNow.Add(time.Minute)
+		mockDeliveries = []webhooks.PendingDelivery{
+			{
+				Delivery: webhooks.Delivery{ID: 1, WebhookID: 10, Status: webhooks.DeliveryPending, NextAttemptAt: mockLease},
+				URL:      "https://example.com/hook1",
+			},
+			{
+				Delivery: webhooks.Delivery{ID: 2, WebhookID: 20, Status: webhooks.DeliveryPending, Attempts: 1, NextAttemptAt: mockLease, LastError: "timeout"},
+				URL:      "https://example.com/hook2",
+			},
+			{
+				Delivery: webhooks.Delivery{ID: 3, WebhookID: 30, Status: webhooks.DeliveryPending, Attempts: 2, NextAttemptAt: mockLease},
+				URL:      "https://example.com/hook3",
+			},
+		}
+		mockAttempts = []webhooks.Attempt{
+			{DeliveryID: 1, AttemptedAt: mockNow, StatusCode: 200},
+			{DeliveryID: 2, AttemptedAt: mockNow, StatusCode: 500, Error: "unexpected response status 500"},
+			{DeliveryID: 3, AttemptedAt: mockNow, Error: "connection refused"},
+		}
+	)
+
+	attempted := func(delivery webhooks.Delivery, change func(d *webhooks.Delivery)) webhooks.Delivery {
+		delivery.Attempts++
+		change(&delivery)
+		return delivery
+	}
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, attempted(mockDeliveries[0].Delivery, func(d *webhooks.Delivery) {
+						d.Status = webhooks.DeliveryDelivered
+						d.DeliveredAt = &mockNow
+					})).Return(nil),
+					f.webhooksRepo.EXPECT().AddAttempt(f.tx, mockAttempts[0]).Return(nil),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, attempted(mockDeliveries[1].Delivery, func(d *webhooks.Delivery) {
+						d.LastError = "unexpected response status 500"
+						d.NextAttemptAt = mockNow.Add(2 * time.Second)
+					})).Return(nil),
+					f.webhooksRepo.EXPECT().AddAttempt(f.tx, mockAttempts[1]).Return(nil),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, attempted(mockDeliveries[2].Delivery, func(d *webhooks.Delivery) {
+						d.Status = webhooks.DeliveryFailed
+						d.LastError = "connection refused"
+					})).Return(nil),
+					f.webhooksRepo.EXPECT().AddAttempt(f.tx, mockAttempts[2]).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "webhook has been deleted",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, gomock.Any()).Return(shared.ErrNoData),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, gomock.Any()).Return(nil),
+					f.webhooksRepo.EXPECT().AddAttempt(f.tx, mockAttempts[1]).Return(nil),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, gomock.Any()).Return(nil),
+					f.webhooksRepo.EXPECT().AddAttempt(f.tx, mockAttempts[2]).Return(nil),
+				)
+			},
+			err: nil,
+		},
+		{
+			name: "failed to update delivery",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, gomock.Any()).Return(errors.New("connection lost")),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+		{
+			name: "failed to add attempt",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, gomock.Any()).Return(nil),
+					f.webhooksRepo.EXPECT().AddAttempt(f.tx, gomock.Any()).Return(shared.ErrNoData),
+				)
+			},
+			err: shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			err := f.service(s.log).RecordAttempts(f.tx, mockDeliveries, mockAttempts)
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunWebhooksSuite) TestRetryDelivery() {
+	var (
+		mockNow      = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockDelivery = webhooks.Delivery{ID: 1, WebhookID: 10, Status: webhooks.DeliveryFailed, Attempts: 3, LastError: "timeout"}
+		retried      = webhooks.Delivery{ID: 1, WebhookID: 10, Status: webhooks.DeliveryPending, NextAttemptAt: mockNow, LastError: "timeout"}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		expectedData webhooks.Delivery
+		err          error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(10), "test username").Return(webhooks.Webhook{ID: 10}, nil),
+					f.webhooksRepo.EXPECT().FindDelivery(f.tx, uint64(1), uint64(10)).Return(mockDelivery, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().UpdateDelivery(f.tx, retried).Return(nil),
+				)
+			},
+			expectedData: retried,
+			err:          nil,
+		},
+		{
+			name: "webhook of another user",
+			prepare: func(f *fields) {
+				f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(10), "test username").Return(webhooks.Webhook{}, shared.ErrNoData)
+			},
+			expectedData: webhooks.Delivery{},
+			err:          webhooks.ErrWebhookNotFound,
+		},
+		{
+			name: "delivery not found",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(10), "test username").Return(webhooks.Webhook{ID: 10}, nil),
+					f.webhooksRepo.EXPECT().FindDelivery(f.tx, uint64(1), uint64(10)).Return(webhooks.Delivery{}, shared.ErrNoData),
+				)
+			},
+			expectedData: webhooks.Delivery{},
+			err:          webhooks.ErrDeliveryNotFound,
+		},
+		{
+			name: "delivery has not failed",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(10), "test username").Return(webhooks.Webhook{ID: 10}, nil),
+					f.webhooksRepo.EXPECT().FindDelivery(f.tx, uint64(1), uint64(10)).Return(webhooks.Delivery{ID: 1, WebhookID: 10, Status: webhooks.DeliveryPending}, nil),
+				)
+			},
+			expectedData: webhooks.Delivery{},
+			err:          webhooks.ErrDeliveryNotFailed,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			data, err := f.service(s.log).RetryDelivery(f.tx, 1, 10, "test username")
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
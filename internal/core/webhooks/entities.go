@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+)
+
+var (
+	// ErrWebhookNotFound webhook not found
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrDeliveryNotFound webhook delivery not found
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrInvalidURL webhook url is not an absolute http or https url
+	ErrInvalidURL = errors.New("invalid webhook url")
+
+	// ErrInvalidEvents webhook events are empty or contain unknown event type
+	ErrInvalidEvents = errors.New("invalid webhook events")
+
+	// ErrDeliveryNotFailed only failed delivery can be retried
+	ErrDeliveryNotFailed = errors.New("webhook delivery has not failed")
+)
+
+// Events product events available for webhook subscriptions
+var Events = []products.EventType{products.EventCreated, products.EventUpdated, products.EventDeleted}
+
+// Webhook http callback of the user, receives events of the user products
+type Webhook struct {
+	ID        uint64
+	OwnerName string
+	URL       string
+	Secret    string
+	Events    []products.EventType
+	Active    bool
+	CreatedAt time.Time
+}
+
+// DeliveryStatus status of webhook delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending delivery waits for the next attempt
+	DeliveryPending DeliveryStatus = "pending"
+
+	// DeliveryDelivered receiver responded with 2xx status
+	DeliveryDelivered DeliveryStatus = "delivered"
+
+	// DeliveryFailed all attempts have failed, delivery is kept as dead letter until it is retried by the owner
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery event sent to the webhook
+type Delivery struct {
+	ID            uint64             `db:"id"`
+	WebhookID     uint64             `db:"webhook_id"`
+	EventID       string             `db:"event_id"`
+	EventType     products.EventType `db:"event_type"`
+	Payload       []byte             `db:"payload"`
+	Status        DeliveryStatus     `db:"status"`
+	Attempts      int                `db:"attempts"`
+	NextAttemptAt time.Time          `db:"next_attempt_at"`
+	LastError     string             `db:"last_error"`
+	CreatedAt     time.Time          `db:"created_at"`
+	DeliveredAt   *time.Time         `db:"delivered_at"`
+}
+
+// PendingDelivery delivery with url and secret of its webhook
+type PendingDelivery struct {
+	Delivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// Attempt log of a delivery attempt, StatusCode is zero if the receiver did not respond
+type Attempt struct {
+	DeliveryID  uint64    `db:"delivery_id"`
+	AttemptedAt time.Time `db:"attempted_at"`
+	StatusCode  int       `db:"status_code"`
+	Error       string    `db:"error"`
+}
+
+// Payload body of webhook request.
+// Product is the state after the event or the last state of deleted product, Previous is the state before the update
+type Payload struct {
+	ID         string             `json:"id"`
+	Type       products.EventType `json:"type"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Actor      string             `json:"actor"`
+	Product    products.Product   `json:"product"`
+	Previous   *products.Product  `json:"previous,omitempty"`
+}
+
+// Settings delivery parameters.
+// Failed delivery is retried after BaseDelay, the delay doubles with each next failure up to MaxDelay.
+// After MaxAttempts the delivery is failed. Timeout is the longest time of sending one delivery
+type Settings struct {
+	BatchSize   int
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Timeout     time.Duration
+}
+
+// RetryAt time of the next attempt after the failure
+func (s Settings) RetryAt(now time.Time, attempts int) time.Time {
+	delay := s.MaxDelay
+	if shift := attempts - 1; shift < 32 && s.BaseDelay<<shift < s.MaxDelay {
+		delay = s.BaseDelay << shift
+	}
+
+	return now.Add(delay)
+}
@@ -0,0 +1,32 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+)
+
+// WebhooksRepo ...
+type WebhooksRepo interface {
+	CreateWebhook(tx *sqlx.Tx, webhook Webhook) (uint64, error)
+	FindWebhook(tx *sqlx.Tx, id uint64, ownerName string) (Webhook, error)
+	FindWebhookList(tx *sqlx.Tx, ownerName string) ([]Webhook, error)
+	UpdateWebhook(tx *sqlx.Tx, webhook Webhook) error
+	DeleteWebhook(tx *sqlx.Tx, id uint64, ownerName string) error
+	FindSubscribedWebhooks(tx *sqlx.Tx, ownerName string, eventType products.EventType) ([]Webhook, error)
+
+	AddDelivery(tx *sqlx.Tx, delivery Delivery) error
+	FindPendingDeliveries(tx *sqlx.Tx, now time.Time, limit int) ([]PendingDelivery, error)
+	FindDeliveryList(tx *sqlx.Tx, webhookID uint64, limit int) ([]Delivery, error)
+	FindDelivery(tx *sqlx.Tx, id uint64, webhookID uint64) (Delivery, error)
+	UpdateDelivery(tx *sqlx.Tx, delivery Delivery) error
+	AddAttempt(tx *sqlx.Tx, attempt Attempt) error
+	FindAttempts(tx *sqlx.Tx, deliveryID uint64) ([]Attempt, error)
+}
+
+// WebhookSender sends delivery payload signed with the webhook secret, returns response status code
+type WebhookSender interface {
+	Send(url string, secret string, delivery Delivery, sentAt time.Time) (int, error)
+}
@@ -0,0 +1,223 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/url"
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+const (
+	webhookSecretSize = 32
+
+	// deliveryListLimit number of the last deliveries returned for a webhook
+	deliveryListLimit = 100
+)
+
+// WebhooksService ...
+type WebhooksService struct {
+	log    *slog.Logger
+	date   shared.DateTool
+	crypto shared.Crypto
+
+	webhooksRepo  WebhooksRepo
+	webhookSender WebhookSender
+
+	settings Settings
+}
+
+// NewWebhooksService constructor for WebhooksService
+func NewWebhooksService(
+	log *slog.Logger,
+	date shared.DateTool,
+	crypto shared.Crypto,
+
+	webhooksRepo WebhooksRepo,
+	webhookSender WebhookSender,
+
+	settings Settings,
+) *WebhooksService {
+	return &WebhooksService{
+		log:    log,
+		date:   date,
+		crypto: crypto,
+
+		webhooksRepo:  webhooksRepo,
+		webhookSender: webhookSender,
+
+		settings: settings,
+	}
+}
+
+// CreateWebhook creates active webhook with generated secret, the secret is used to sign deliveries
+func (s *WebhooksService) CreateWebhook(tx *sqlx.Tx, ownerName string, rawURL string, events []products.EventType) (Webhook, error) {
+	if err := validateWebhook(rawURL, events); err != nil {
+		s.log.Error(err.Error(), "username", ownerName, "url", rawURL, "events", events)
+		return Webhook{}, err
+	}
+
+	secret, err := s.crypto.RandomString(webhookSecretSize)
+	if err != nil {
+		s.log.Error("failed to generate webhook secret", "error", err)
+		return Webhook{}, shared.ErrInternal
+	}
+
+	webhook := Webhook{
+		OwnerName: ownerName,
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedAt: s.date.Now(),
+	}
+
+	webhook.ID, err = s.webhooksRepo.CreateWebhook(tx, webhook)
+	if err != nil {
+		s.log.Error("failed to create webhook", "error", err, "username", ownerName)
+		return Webhook{}, shared.ErrInternal
+	}
+
+	s.log.Info("webhook has been created", "id", webhook.ID, "username", ownerName)
+	return webhook, nil
+}
+
+// FindWebhook ...
+func (s *WebhooksService) FindWebhook(tx *sqlx.Tx, id uint64, ownerName string) (Webhook, error) {
+	webhook, err := s.webhooksRepo.FindWebhook(tx, id, ownerName)
+	if err != nil {
+		s.log.Error("failed to find webhook", "error", err, "id", id, "username", ownerName)
+		if errors.Is(err, shared.ErrNoData) {
+			return Webhook{}, ErrWebhookNotFound
+		}
+
+		return Webhook{}, shared.ErrInternal
+	}
+
+	return webhook, nil
+}
+
+// FindWebhookList ...
+func (s *WebhooksService) FindWebhookList(tx *sqlx.Tx, ownerName string) ([]Webhook, error) {
+	webhooks, err := s.webhooksRepo.FindWebhookList(tx, ownerName)
+	if err != nil {
+		s.log.Error("failed to find webhook list", "error", err, "username", ownerName)
+		return nil, shared.ErrInternal
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook changes url, events and activity of the webhook, the secret is kept
+func (s *WebhooksService) UpdateWebhook(tx *sqlx.Tx, ownerName string, newWebhook Webhook) (Webhook, error) {
+	if err := validateWebhook(newWebhook.URL, newWebhook.Events); err != nil {
+		s.log.Error(err.Error(), "username", ownerName, "url", newWebhook.URL, "events", newWebhook.Events)
+		return Webhook{}, err
+	}
+
+	webhook, err := s.FindWebhook(tx, newWebhook.ID, ownerName)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	webhook.URL = newWebhook.URL
+	webhook.Events = newWebhook.Events
+	webhook.Active = newWebhook.Active
+
+	if err := s.webhooksRepo.UpdateWebhook(tx, webhook); err != nil {
+		s.log.Error("failed to update webhook", "error", err, "id", webhook.ID)
+		return Webhook{}, shared.ErrInternal
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes webhook with its deliveries
+func (s *WebhooksService) DeleteWebhook(tx *sqlx.Tx, id uint64, ownerName string) error {
+	if err := s.webhooksRepo.DeleteWebhook(tx, id, ownerName); err != nil {
+		s.log.Error("failed to delete webhook", "error", err, "id", id, "username", ownerName)
+		if errors.Is(err, shared.ErrNoData) {
+			return ErrWebhookNotFound
+		}
+
+		return shared.ErrInternal
+	}
+
+	s.log.Info("webhook has been deleted", "id", id, "username", ownerName)
+	return nil
+}
+
+// Enqueue saves deliveries of the event to active webhooks of the product owner subscribed to it,
+// deliveries are sent by the worker after commit
+func (s *WebhooksService) Enqueue(tx *sqlx.Tx, event products.Event) error {
+	if !slices.Contains(Events, event.Type) {
+		return nil
+	}
+
+	webhooks, err := s.webhooksRepo.FindSubscribedWebhooks(tx, event.Product.OwnerName, event.Type)
+	if err != nil {
+		s.log.Error("failed to find subscribed webhooks", "error", err, "username", event.Product.OwnerName, "type", event.Type)
+		return shared.ErrInternal
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(Payload{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Actor:      event.Actor,
+		Product:    event.Product,
+		Previous:   event.Previous,
+	})
+	if err != nil {
+		s.log.Error("failed to encode webhook payload", "error", err, "event", event.ID)
+		return shared.ErrInternal
+	}
+
+	now := s.date.Now()
+	for _, webhook := range webhooks {
+		delivery := Delivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+
+		if err := s.webhooksRepo.AddDelivery(tx, delivery); err != nil {
+			s.log.Error("failed to add webhook delivery", "error", err, "webhook", webhook.ID, "event", event.ID)
+			return shared.ErrInternal
+		}
+	}
+
+	return nil
+}
+
+func validateWebhook(rawURL string, events []products.EventType) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+
+	if len(events) == 0 {
+		return ErrInvalidEvents
+	}
+
+	for _, event := range events {
+		if !slices.Contains(Events, event) {
+			return ErrInvalidEvents
+		}
+	}
+
+	return nil
+}
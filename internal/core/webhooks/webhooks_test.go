@@ -0,0 +1,402 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	mockwebhooks "github.com/fallra1n/product-keeper/internal/mocks/webhooks"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+var mockSettings = webhooks.Settings{
+	BatchSize:   2,
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    time.Minute,
+	Timeout:     10 * time.Second,
+}
+
+type RunWebhooksSuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunWebhooksSuite(t *testing.T) {
+	suite.Run(t, new(RunWebhooksSuite))
+}
+
+func (s *RunWebhooksSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+}
+
+type fields struct {
+	tx     *sqlx.Tx
+	date   *mockshared.MockDateTool
+	crypto *mockshared.MockCrypto
+
+	webhooksRepo  *mockwebhooks.MockWebhooksRepo
+	webhookSender *mockwebhooks.MockWebhookSender
+}
+
+func newFields(ctrl *gomock.Controller) fields {
+	return fields{
+		tx:     &sqlx.Tx{},
+		date:   mockshared.NewMockDateTool(ctrl),
+		crypto: mockshared.NewMockCrypto(ctrl),
+
+		webhooksRepo:  mockwebhooks.NewMockWebhooksRepo(ctrl),
+		webhookSender: mockwebhooks.NewMockWebhookSender(ctrl),
+	}
+}
+
+func (f fields) service(log *slog.Logger) *webhooks.WebhooksService {
+	return webhooks.NewWebhooksService(log, f.date, f.crypto, f.webhooksRepo, f.webhookSender, mockSettings)
+}
+
+func (s *RunWebhooksSuite) TestCreateWebhook() {
+	type args struct {
+		url    string
+		events []products.EventType
+	}
+
+	var (
+		mockNow     = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockEvents  = []products.EventType{products.EventCreated, products.EventDeleted}
+		mockWebhook = webhooks.Webhook{
+			OwnerName: "test username",
+			URL:       "https://example.com/hook",
+			Secret:    "test secret",
+			Events:    mockEvents,
+			Active:    true,
+			CreatedAt: mockNow,
+		}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		args         args
+		expectedData webhooks.Webhook
+		err          error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(32).Return("test secret", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().CreateWebhook(f.tx, mockWebhook).Return(uint64(1), nil),
+				)
+			},
+			args: args{url: "https://example.com/hook", events: mockEvents},
+			expectedData: func() webhooks.Webhook {
+				webhook := mockWebhook
+				webhook.ID = 1
+				return webhook
+			}(),
+			err: nil,
+		},
+		{
+			name:         "invalid url",
+			args:         args{url: "ftp://example.com/hook", events: mockEvents},
+			expectedData: webhooks.Webhook{},
+			err:          webhooks.ErrInvalidURL,
+		},
+		{
+			name:         "relative url",
+			args:         args{url: "/hook", events: mockEvents},
+			expectedData: webhooks.Webhook{},
+			err:          webhooks.ErrInvalidURL,
+		},
+		{
+			name:         "no events",
+			args:         args{url: "https://example.com/hook"},
+			expectedData: webhooks.Webhook{},
+			err:          webhooks.ErrInvalidEvents,
+		},
+		{
+			name:         "views are not available",
+			args:         args{url: "https://example.com/hook", events: []products.EventType{products.EventViewed}},
+			expectedData: webhooks.Webhook{},
+			err:          webhooks.ErrInvalidEvents,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.crypto.EXPECT().RandomString(32).Return("test secret", nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().CreateWebhook(f.tx, mockWebhook).Return(uint64(0), shared.ErrNoData),
+				)
+			},
+			args:         args{url: "https://example.com/hook", events: mockEvents},
+			expectedData: webhooks.Webhook{},
+			err:          shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			data, err := f.service(s.log).CreateWebhook(f.tx, "test username", row.args.url, row.args.events)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
+
+func (s *RunWebhooksSuite) TestUpdateWebhook() {
+	var (
+		mockWebhook = webhooks.Webhook{
+			ID:        1,
+			OwnerName: "test username",
+			URL:       "https://example.com/hook",
+			Secret:    "test secret",
+			Events:    []products.EventType{products.EventCreated},
+			Active:    true,
+		}
+		newWebhook = webhooks.Webhook{
+			ID:     1,
+			URL:    "https://example.com/new",
+			Events: []products.EventType{products.EventUpdated},
+			Active: false,
+		}
+		updatedWebhook = webhooks.Webhook{
+			ID:        1,
+			OwnerName: "test username",
+			URL:       "https://example.com/new",
+			Secret:    "test secret",
+			Events:    []products.EventType{products.EventUpdated},
+			Active:    false,
+		}
+	)
+
+	testList := []struct {
+		name         string
+		prepare      func(f *fields)
+		args         webhooks.Webhook
+		expectedData webhooks.Webhook
+		err          error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(1), "test username").Return(mockWebhook, nil),
+					f.webhooksRepo.EXPECT().UpdateWebhook(f.tx, updatedWebhook).Return(nil),
+				)
+			},
+			args:         newWebhook,
+			expectedData: updatedWebhook,
+			err:          nil,
+		},
+		{
+			name: "webhook not found",
+			prepare: func(f *fields) {
+				f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(1), "test username").Return(webhooks.Webhook{}, shared.ErrNoData)
+			},
+			args:         newWebhook,
+			expectedData: webhooks.Webhook{},
+			err:          webhooks.ErrWebhookNotFound,
+		},
+		{
+			name: "invalid events",
+			args: webhooks.Webhook{
+				ID:     1,
+				URL:    "https://example.com/new",
+				Events: []products.EventType{"product.unknown"},
+			},
+			expectedData: webhooks.Webhook{},
+			err:          webhooks.ErrInvalidEvents,
+		},
+		{
+			name: "internal error",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindWebhook(f.tx, uint64(1), "test username").Return(mockWebhook, nil),
+					f.webhooksRepo.EXPECT().UpdateWebhook(f.tx, updatedWebhook).Return(shared.ErrNoData),
+				)
+			},
+			args:         newWebhook,
+			expectedData: webhooks.Webhook{},
+			err:          shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			data, err := f.service(s.log).UpdateWebhook(f.tx, "test username", row.args)
+			s.Equal(row.err, err)
+			s.Equal(row.expectedData, data)
+		})
+	}
+}
+
+func (s *RunWebhooksSuite) TestDeleteWebhook() {
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				f.webhooksRepo.EXPECT().DeleteWebhook(f.tx, uint64(1), "test username").Return(nil)
+			},
+			err: nil,
+		},
+		{
+			name: "webhook not found",
+			prepare: func(f *fields) {
+				f.webhooksRepo.EXPECT().DeleteWebhook(f.tx, uint64(1), "test username").Return(shared.ErrNoData)
+			},
+			err: webhooks.ErrWebhookNotFound,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			err := f.service(s.log).DeleteWebhook(f.tx, 1, "test username")
+			s.Equal(row.err, err)
+		})
+	}
+}
+
+func (s *RunWebhooksSuite) TestEnqueue() {
+	var (
+		mockNow   = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockEvent = products.Event{
+			ID:         "test event id",
+			Type:       products.EventUpdated,
+			OccurredAt: mockNow,
+			Actor:      "test actor",
+			Product:    products.Product{ID: 123, Name: "new name", OwnerName: "test username"},
+			Previous:   &products.Product{ID: 123, Name: "old name", OwnerName: "test username"},
+		}
+		mockWebhooks = []webhooks.Webhook{{ID: 1}, {ID: 2}}
+	)
+
+	mockPayload, err := json.Marshal(webhooks.Payload{
+		ID:         mockEvent.ID,
+		Type:       mockEvent.Type,
+		OccurredAt: mockEvent.OccurredAt,
+		Actor:      mockEvent.Actor,
+		Product:    mockEvent.Product,
+		Previous:   mockEvent.Previous,
+	})
+	s.Require().NoError(err)
+
+	delivery := func(webhookID uint64) webhooks.Delivery {
+		return webhooks.Delivery{
+			WebhookID:     webhookID,
+			EventID:       "test event id",
+			EventType:     products.EventUpdated,
+			Payload:       mockPayload,
+			Status:        webhooks.DeliveryPending,
+			NextAttemptAt: mockNow,
+			CreatedAt:     mockNow,
+		}
+	}
+
+	testList := []struct {
+		name    string
+		prepare func(f *fields)
+		args    products.Event
+		err     error
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindSubscribedWebhooks(f.tx, "test username", products.EventUpdated).Return(mockWebhooks, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().AddDelivery(f.tx, delivery(1)).Return(nil),
+					f.webhooksRepo.EXPECT().AddDelivery(f.tx, delivery(2)).Return(nil),
+				)
+			},
+			args: mockEvent,
+			err:  nil,
+		},
+		{
+			name: "no subscribed webhooks",
+			prepare: func(f *fields) {
+				f.webhooksRepo.EXPECT().FindSubscribedWebhooks(f.tx, "test username", products.EventUpdated).Return(nil, nil)
+			},
+			args: mockEvent,
+			err:  nil,
+		},
+		{
+			name: "views are not delivered",
+			args: products.Event{ID: "test event id", Type: products.EventViewed},
+			err:  nil,
+		},
+		{
+			name: "internal error(find webhooks)",
+			prepare: func(f *fields) {
+				f.webhooksRepo.EXPECT().FindSubscribedWebhooks(f.tx, "test username", products.EventUpdated).Return(nil, shared.ErrNoData)
+			},
+			args: mockEvent,
+			err:  shared.ErrInternal,
+		},
+		{
+			name: "internal error(add delivery)",
+			prepare: func(f *fields) {
+				gomock.InOrder(
+					f.webhooksRepo.EXPECT().FindSubscribedWebhooks(f.tx, "test username", products.EventUpdated).Return(mockWebhooks, nil),
+					f.date.EXPECT().Now().Return(mockNow),
+					f.webhooksRepo.EXPECT().AddDelivery(f.tx, delivery(1)).Return(shared.ErrNoData),
+				)
+			},
+			args: mockEvent,
+			err:  shared.ErrInternal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			ctrl := gomock.NewController(s.T())
+			defer ctrl.Finish()
+
+			f := newFields(ctrl)
+			if row.prepare != nil {
+				row.prepare(&f)
+			}
+
+			err := f.service(s.log).Enqueue(f.tx, row.args)
+			s.Equal(row.err, err)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package authgrpchandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/interceptor"
+	productkeeperv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/v1"
+)
+
+// AuthHandler ...
+type AuthHandler struct {
+	productkeeperv1.UnimplementedAuthServiceServer
+
+	log *slog.Logger
+	db  *sqlx.DB
+
+	authService *auth.AuthService
+}
+
+// NewAuthHandler constructor for AuthHandler
+func NewAuthHandler(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService) *AuthHandler {
+	return &AuthHandler{
+		log: log,
+		db:  db,
+
+		authService: authService,
+	}
+}
+
+// Register ...
+func (h *AuthHandler) Register(ctx context.Context, req *productkeeperv1.RegisterRequest) (*productkeeperv1.RegisterResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username and password are required")
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	user := auth.NewUser(req.GetUsername(), req.GetPassword())
+	user.Email = req.GetEmail()
+
+	if err := h.authService.CreateUser(tx, user); err != nil {
+		h.log.Error("Register: " + err.Error())
+		switch {
+		case errors.Is(err, auth.ErrUserAlreadyExist):
+			return nil, status.Error(codes.AlreadyExists, "username already exists")
+		case errors.Is(err, auth.ErrInvalidEmail):
+			return nil, status.Error(codes.InvalidArgument, "invalid email address")
+		case errors.Is(err, auth.ErrPasswordTooShort), errors.Is(err, auth.ErrPasswordTooLong), errors.Is(err, auth.ErrPasswordBreached):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	h.log.Info("Register: a user has been successfully registered")
+	return &productkeeperv1.RegisterResponse{}, nil
+}
+
+// Login ...
+func (h *AuthHandler) Login(ctx context.Context, req *productkeeperv1.LoginRequest) (*productkeeperv1.LoginResponse, error) {
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	res, err := h.authService.LoginUser(tx, auth.NewUser(
+		req.GetUsername(),
+		req.GetPassword(),
+	), auth.NewClient(interceptor.ClientIP(ctx), interceptor.UserAgent(ctx)))
+	if err != nil {
+		h.log.Error("Login: " + err.Error())
+		switch {
+		case errors.Is(err, auth.ErrIncorrectPassword):
+			// failed attempt must be saved
+			if err := tx.Commit(); err != nil {
+				h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+			}
+
+			return nil, status.Error(codes.Unauthenticated, "incorrect username or password")
+		case errors.Is(err, auth.ErrTooManyAttempts):
+			return nil, status.Error(codes.ResourceExhausted, "too many failed login attempts, try again later")
+		case errors.Is(err, auth.ErrUserDisabled):
+			return nil, status.Error(codes.PermissionDenied, "user account has been disabled")
+		default:
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	if res.MFAChallenge != "" {
+		h.log.Info("Login: second factor is required")
+		return &productkeeperv1.LoginResponse{MfaChallenge: res.MFAChallenge}, nil
+	}
+
+	h.log.Info("Login: a user has been successfully authorized")
+	return &productkeeperv1.LoginResponse{Token: res.Token}, nil
+}
+
+// LoginMFA ...
+func (h *AuthHandler) LoginMFA(ctx context.Context, req *productkeeperv1.LoginMFARequest) (*productkeeperv1.LoginMFAResponse, error) {
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	token, err := h.authService.LoginMFA(tx, req.GetMfaChallenge(), req.GetCode(), auth.NewClient(interceptor.ClientIP(ctx), interceptor.UserAgent(ctx)))
+	if err != nil {
+		h.log.Error("LoginMFA: " + err.Error())
+		switch {
+		case errors.Is(err, auth.ErrInvalidTOTPCode):
+			// challenge must be consumed and failed attempt saved,
+			// otherwise the code could be guessed with one password login
+			if err := tx.Commit(); err != nil {
+				h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+			}
+
+			return nil, status.Error(codes.Unauthenticated, "invalid two-factor code")
+		case errors.Is(err, auth.ErrTooManyAttempts):
+			return nil, status.Error(codes.ResourceExhausted, "too many failed login attempts, try again later")
+		case errors.Is(err, auth.ErrInvalidMFAChallenge):
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired mfa challenge")
+		case errors.Is(err, auth.ErrUserDisabled):
+			return nil, status.Error(codes.PermissionDenied, "user account has been disabled")
+		default:
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	h.log.Info("LoginMFA: a user has been successfully authorized")
+	return &productkeeperv1.LoginMFAResponse{Token: token}, nil
+}
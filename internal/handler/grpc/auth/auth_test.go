@@ -0,0 +1,219 @@
+package authgrpchandler_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	authgrpchandler "github.com/fallra1n/product-keeper/internal/handler/grpc/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/grpctest"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	productkeeperv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/v1"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+var (
+	mockNow  = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUser = auth.User{Name: "test name", Password: "test hash", Role: auth.RoleUser}
+)
+
+type RunAuthSuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunAuthSuite(t *testing.T) {
+	suite.Run(t, new(RunAuthSuite))
+}
+
+func (s *RunAuthSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+}
+
+type fields struct {
+	crypto            *mockshared.MockCrypto
+	date              *mockshared.MockDateTool
+	authRepo          *mockauth.MockAuthRepo
+	breachedPasswords *mockauth.MockBreachedPasswords
+}
+
+// serve starts server with the auth handler and returns its client
+func (s *RunAuthSuite) serve(prepare func(f fields)) productkeeperv1.AuthServiceClient {
+	ctrl := gomock.NewController(s.T())
+
+	f := fields{
+		crypto:            mockshared.NewMockCrypto(ctrl),
+		date:              mockshared.NewMockDateTool(ctrl),
+		authRepo:          mockauth.NewMockAuthRepo(ctrl),
+		breachedPasswords: mockauth.NewMockBreachedPasswords(ctrl),
+	}
+	if prepare != nil {
+		prepare(f)
+	}
+
+	authService := auth.NewAuthService(
+		s.log,
+		f.crypto,
+		mockshared.NewMockJwt(ctrl),
+		f.date,
+		mockshared.NewMockTOTP(ctrl),
+		mockshared.NewMockNotifier(ctrl),
+		mockshared.NewMockSigner(ctrl),
+		f.authRepo,
+		mockauth.NewMockIdentityProviders(ctrl),
+		f.breachedPasswords,
+		mockauth.NewMockProductsOwnership(ctrl),
+		auth.Settings{
+			SessionTTL: time.Hour,
+			Password:   auth.PasswordPolicy{MinLength: 8},
+			Throttle: auth.LoginThrottle{
+				AccountFreeAttempts: 3,
+				IPFreeAttempts:      3,
+				BaseDelay:           time.Minute,
+				MaxDelay:            time.Hour,
+				ResetAfter:          time.Hour,
+			},
+		},
+	)
+
+	db := grpctest.NewDB()
+	server := grpc.NewServer()
+	productkeeperv1.RegisterAuthServiceServer(server, authgrpchandler.NewAuthHandler(s.log, db, authService))
+
+	return productkeeperv1.NewAuthServiceClient(grpctest.Dial(s.T(), server))
+}
+
+func (s *RunAuthSuite) TestRegister() {
+	testList := []struct {
+		name    string
+		prepare func(f fields)
+		req     *productkeeperv1.RegisterRequest
+		code    codes.Code
+	}{
+		{
+			name: "empty username",
+			req:  &productkeeperv1.RegisterRequest{Password: "test password", Email: "test@example.com"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "invalid email",
+			req:  &productkeeperv1.RegisterRequest{Username: mockUser.Name, Password: "test password", Email: "test"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "password is too short",
+			req:  &productkeeperv1.RegisterRequest{Username: mockUser.Name, Password: "short", Email: "test@example.com"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "username already exists",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains("test password").Return(false),
+					f.crypto.EXPECT().HashPassword("test password").Return(mockUser.Password, nil),
+					f.authRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(auth.ErrUserAlreadyExist),
+				)
+			},
+			req:  &productkeeperv1.RegisterRequest{Username: mockUser.Name, Password: "test password", Email: "test@example.com"},
+			code: codes.AlreadyExists,
+		},
+		{
+			name: "failed to hash password",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.breachedPasswords.EXPECT().Contains("test password").Return(false),
+					f.crypto.EXPECT().HashPassword("test password").Return("", shared.ErrInternal),
+				)
+			},
+			req:  &productkeeperv1.RegisterRequest{Username: mockUser.Name, Password: "test password", Email: "test@example.com"},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			client := s.serve(row.prepare)
+
+			_, err := client.Register(context.Background(), row.req)
+			s.Equal(row.code, status.Code(err))
+		})
+	}
+}
+
+func (s *RunAuthSuite) TestLogin() {
+	testList := []struct {
+		name    string
+		prepare func(f fields)
+		code    codes.Code
+	}{
+		{
+			name: "user not found",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(gomock.Any(), gomock.Any()).Return(auth.LoginAttempts{}, shared.ErrNoData).Times(2),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(auth.User{}, auth.ErrUserNotFound),
+					f.crypto.EXPECT().HashPassword("test password").Return(mockUser.Password, nil),
+					f.authRepo.EXPECT().IncrementLoginAttempts(gomock.Any(), gomock.Any(), mockNow, gomock.Any()).Return(auth.LoginAttempts{}, nil).Times(2),
+				)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "too many attempts",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(gomock.Any(), auth.AccountAttemptsKey(mockUser.Name)).Return(auth.LoginAttempts{
+						Key:           auth.AccountAttemptsKey(mockUser.Name),
+						Failures:      10,
+						LastFailureAt: mockNow,
+					}, nil),
+				)
+			},
+			code: codes.ResourceExhausted,
+		},
+		{
+			name: "user is disabled",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(gomock.Any(), gomock.Any()).Return(auth.LoginAttempts{}, shared.ErrNoData).Times(2),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(auth.User{Name: mockUser.Name, Password: mockUser.Password, Disabled: true}, nil),
+					f.crypto.EXPECT().CompareHashAndPassword(mockUser.Password, "test password").Return(nil),
+				)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "failed to find user",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.date.EXPECT().Now().Return(mockNow),
+					f.authRepo.EXPECT().FindLoginAttempts(gomock.Any(), gomock.Any()).Return(auth.LoginAttempts{}, shared.ErrNoData).Times(2),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(auth.User{}, shared.ErrNoData),
+				)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			client := s.serve(row.prepare)
+
+			_, err := client.Login(context.Background(), &productkeeperv1.LoginRequest{Username: mockUser.Name, Password: "test password"})
+			s.Equal(row.code, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package grpctest helpers for tests of grpc handlers and interceptors
+package grpctest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize size of the in-memory connection buffer
+const bufSize = 1 << 20
+
+// errQueryNotSupported queries are expected to go to mocked repositories
+var errQueryNotSupported = errors.New("grpctest: queries are not supported")
+
+// Dial serves server on an in-memory listener and returns a client connected to it.
+// The server and the connection are closed when the test finishes
+func Dial(t testing.TB, server *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(bufSize)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpctest: cannot dial server: %s", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return conn
+}
+
+// NewDB database whose transactions do nothing, used with mocked repositories
+func NewDB() *sqlx.DB {
+	return sqlx.NewDb(sql.OpenDB(connector{}), "postgres")
+}
+
+type connector struct{}
+
+func (connector) Connect(context.Context) (driver.Conn, error) {
+	return conn{}, nil
+}
+
+func (connector) Driver() driver.Driver {
+	return nopDriver{}
+}
+
+type nopDriver struct{}
+
+func (nopDriver) Open(string) (driver.Conn, error) {
+	return conn{}, nil
+}
+
+type conn struct{}
+
+func (conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errQueryNotSupported
+}
+
+func (conn) Close() error {
+	return nil
+}
+
+func (conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error {
+	return nil
+}
+
+func (tx) Rollback() error {
+	return nil
+}
@@ -0,0 +1,20 @@
+package interceptor
+
+const (
+	// AuthMetadata ...
+	AuthMetadata = "authorization"
+	// RequestIDMetadata request id, generated if the client has not set it
+	RequestIDMetadata = "x-request-id"
+	// TraceparentMetadata w3c trace context
+	TraceparentMetadata = "traceparent"
+	// UserAgentMetadata ...
+	UserAgentMetadata = "user-agent"
+)
+
+type contextKey int
+
+const (
+	userContext contextKey = iota
+	sessionContext
+	traceContext
+)
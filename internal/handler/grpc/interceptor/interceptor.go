@@ -0,0 +1,193 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/pkg/tracing"
+)
+
+// Recovery converts panics of handlers to internal errors
+func Recovery(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error(fmt.Sprintf("Recovery: panic in %s: %v", info.FullMethod, r), "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RequestTrace sets request id from x-request-id metadata and trace id from traceparent metadata,
+// missing or invalid ids are generated. Request id is returned in x-request-id header
+func RequestTrace(log *slog.Logger, ids shared.IDGenerator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := incoming(ctx, RequestIDMetadata)
+		if !tracing.ValidRequestID(requestID) {
+			id, err := ids.NewID()
+			if err != nil {
+				log.Error("RequestTrace: " + err.Error())
+			}
+			requestID = id
+		}
+
+		traceID, ok := tracing.ParseTraceparent(incoming(ctx, TraceparentMetadata))
+		if !ok {
+			id, err := ids.NewID()
+			if err != nil {
+				log.Error("RequestTrace: " + err.Error())
+			}
+			traceID = strings.ReplaceAll(id, "-", "")
+		}
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID)); err != nil {
+			log.Error("RequestTrace: " + err.Error())
+		}
+
+		ctx = context.WithValue(ctx, traceContext, shared.Trace{TraceID: traceID, RequestID: requestID})
+		return handler(ctx, req)
+	}
+}
+
+// UserIdentity authorizes calls by jwt token from authorization metadata,
+// methods of publicServices are called without authorization
+func UserIdentity(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService, publicServices ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if slices.Contains(publicServices, serviceName(info.FullMethod)) {
+			return handler(ctx, req)
+		}
+
+		header := incoming(ctx, AuthMetadata)
+		if header == "" {
+			return nil, status.Error(codes.Unauthenticated, "empty auth metadata")
+		}
+
+		headerParts := strings.Split(header, " ")
+		if len(headerParts) != 2 {
+			return nil, status.Error(codes.Unauthenticated, "invalid auth metadata")
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		defer tx.Rollback()
+
+		user, sessionID, err := authService.Identify(tx, headerParts[1], ClientIP(ctx))
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				return nil, status.Error(codes.Unauthenticated, "invalid auth token")
+			}
+
+			log.Error("UserIdentity: " + err.Error())
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+
+		ctx = context.WithValue(ctx, userContext, user)
+		ctx = context.WithValue(ctx, sessionContext, sessionID)
+		return handler(ctx, req)
+	}
+}
+
+// RequireVerified denies calls of users whose email is not verified yet,
+// calls without authorization are skipped, must be used after UserIdentity
+func RequireVerified(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		user, ok := ctx.Value(userContext).(shared.Subject)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		defer tx.Rollback()
+
+		if err := authService.CheckVerified(tx, user.Name); err != nil {
+			switch {
+			case errors.Is(err, auth.ErrEmailNotVerified):
+				return nil, status.Error(codes.PermissionDenied, "email is not verified")
+			case errors.Is(err, auth.ErrUserNotFound):
+				return nil, status.Error(codes.Unauthenticated, "user not found")
+			default:
+				log.Error("RequireVerified: " + err.Error())
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// User subject of the call set by UserIdentity with trace set by RequestTrace
+func User(ctx context.Context) (shared.Subject, bool) {
+	user, ok := ctx.Value(userContext).(shared.Subject)
+	if !ok {
+		return shared.Subject{}, false
+	}
+
+	user.Trace, _ = ctx.Value(traceContext).(shared.Trace)
+	return user, true
+}
+
+// ClientIP address of the client connection
+func ClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// UserAgent user agent of the client from metadata
+func UserAgent(ctx context.Context) string {
+	return incoming(ctx, UserAgentMetadata)
+}
+
+// incoming the first value of incoming metadata key
+func incoming(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// serviceName returns service of "/package.Service/Method"
+func serviceName(fullMethod string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service
+}
@@ -0,0 +1,398 @@
+package interceptor_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/grpctest"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/interceptor"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+	"github.com/fallra1n/product-keeper/pkg/tracing"
+)
+
+const (
+	privateService = "test.PrivateService"
+	publicService  = "test.PublicService"
+)
+
+var (
+	mockNow     = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockSession = auth.Session{
+		ID:         "test session",
+		UserName:   "test name",
+		LastSeenAt: mockNow,
+		ExpiresAt:  mockNow.Add(time.Hour),
+	}
+	mockUser = auth.User{Name: "test name", Role: auth.RoleUser}
+)
+
+type RunInterceptorSuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunInterceptorSuite(t *testing.T) {
+	suite.Run(t, new(RunInterceptorSuite))
+}
+
+func (s *RunInterceptorSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+}
+
+type fields struct {
+	ids      *mockshared.MockIDGenerator
+	jwt      *mockshared.MockJwt
+	date     *mockshared.MockDateTool
+	authRepo *mockauth.MockAuthRepo
+}
+
+// recorder test service handler, keeps the context of the last call
+type recorder struct {
+	ctx context.Context
+}
+
+// serviceDesc service with one Call method, the handler only records the context
+func serviceDesc(name string) *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: name,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Call",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, unary grpc.UnaryServerInterceptor) (any, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, _ any) (any, error) {
+					srv.(*recorder).ctx = ctx
+					return &emptypb.Empty{}, nil
+				}
+
+				return unary(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + name + "/Call"}, handler)
+			},
+		}},
+	}
+}
+
+// serve starts server with all interceptors and returns the client and the recorder of handler calls
+func (s *RunInterceptorSuite) serve(f fields) (*grpc.ClientConn, *recorder) {
+	ctrl := gomock.NewController(s.T())
+
+	authService := auth.NewAuthService(
+		s.log,
+		mockshared.NewMockCrypto(ctrl),
+		f.jwt,
+		f.date,
+		mockshared.NewMockTOTP(ctrl),
+		mockshared.NewMockNotifier(ctrl),
+		mockshared.NewMockSigner(ctrl),
+		f.authRepo,
+		mockauth.NewMockIdentityProviders(ctrl),
+		mockauth.NewMockBreachedPasswords(ctrl),
+		mockauth.NewMockProductsOwnership(ctrl),
+		auth.Settings{SessionTTL: time.Hour},
+	)
+
+	db := grpctest.NewDB()
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		interceptor.Recovery(s.log),
+		interceptor.RequestTrace(s.log, f.ids),
+		interceptor.UserIdentity(s.log, db, authService, publicService),
+		interceptor.RequireVerified(s.log, db, authService),
+	))
+
+	rec := &recorder{}
+	server.RegisterService(serviceDesc(privateService), rec)
+	server.RegisterService(serviceDesc(publicService), rec)
+
+	return grpctest.Dial(s.T(), server), rec
+}
+
+func (s *RunInterceptorSuite) newFields() fields {
+	ctrl := gomock.NewController(s.T())
+
+	return fields{
+		ids:      mockshared.NewMockIDGenerator(ctrl),
+		jwt:      mockshared.NewMockJwt(ctrl),
+		date:     mockshared.NewMockDateTool(ctrl),
+		authRepo: mockauth.NewMockAuthRepo(ctrl),
+	}
+}
+
+// expectIdentity expectations of successful Identify for "test-token"
+func expectIdentity(f fields, user auth.User) []any {
+	return []any{
+		f.jwt.EXPECT().ParseToken("test-token").Return(mockUser.Name, string(auth.RoleAdmin), mockSession.ID, nil),
+		f.authRepo.EXPECT().FindSession(gomock.Any(), mockSession.ID).Return(mockSession, nil),
+		f.date.EXPECT().Now().Return(mockNow),
+		f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(user, nil),
+	}
+}
+
+func invoke(conn *grpc.ClientConn, service string, md metadata.MD, opts ...grpc.CallOption) error {
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	return conn.Invoke(ctx, "/"+service+"/Call", &emptypb.Empty{}, &emptypb.Empty{}, opts...)
+}
+
+func (s *RunInterceptorSuite) TestUserIdentity() {
+	testList := []struct {
+		name     string
+		prepare  func(f fields)
+		service  string
+		auth     string
+		expected shared.Subject
+		code     codes.Code
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					expectIdentity(f, mockUser),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(mockUser, nil),
+				)...)
+			},
+			service: privateService,
+			auth:    "Bearer test-token",
+			// the role is taken from the account, not from the token
+			expected: shared.NewSubject(mockUser.Name, string(auth.RoleUser)),
+			code:     codes.OK,
+		},
+		{
+			name:    "public service",
+			service: publicService,
+			code:    codes.OK,
+		},
+		{
+			name:    "empty auth metadata",
+			service: privateService,
+			code:    codes.Unauthenticated,
+		},
+		{
+			name:    "invalid auth metadata",
+			service: privateService,
+			auth:    "test-token",
+			code:    codes.Unauthenticated,
+		},
+		{
+			name: "invalid token",
+			prepare: func(f fields) {
+				f.jwt.EXPECT().ParseToken("test-token").Return("", "", "", shared.ErrNoData)
+			},
+			service: privateService,
+			auth:    "Bearer test-token",
+			code:    codes.Unauthenticated,
+		},
+		{
+			name: "user is disabled",
+			prepare: func(f fields) {
+				gomock.InOrder(expectIdentity(f, auth.User{Name: mockUser.Name, Role: auth.RoleUser, Disabled: true})...)
+			},
+			service: privateService,
+			auth:    "Bearer test-token",
+			code:    codes.Unauthenticated,
+		},
+		{
+			name: "failed to find session",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.jwt.EXPECT().ParseToken("test-token").Return(mockUser.Name, string(auth.RoleUser), mockSession.ID, nil),
+					f.authRepo.EXPECT().FindSession(gomock.Any(), mockSession.ID).Return(auth.Session{}, shared.ErrNoData),
+				)
+			},
+			service: privateService,
+			auth:    "Bearer test-token",
+			code:    codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			f := s.newFields()
+			f.ids.EXPECT().NewID().Return("test-id", nil).AnyTimes()
+			if row.prepare != nil {
+				row.prepare(f)
+			}
+
+			conn, rec := s.serve(f)
+
+			md := metadata.MD{}
+			if row.auth != "" {
+				md.Set(interceptor.AuthMetadata, row.auth)
+			}
+
+			err := invoke(conn, row.service, md)
+			s.Equal(row.code, status.Code(err))
+			if row.code != codes.OK {
+				s.Nil(rec.ctx)
+				return
+			}
+
+			user, ok := interceptor.User(rec.ctx)
+			s.Equal(row.expected.Name != "", ok)
+			s.Equal(row.expected.Name, user.Name)
+			s.Equal(row.expected.Role, user.Role)
+		})
+	}
+}
+
+func (s *RunInterceptorSuite) TestRequireVerified() {
+	testList := []struct {
+		name    string
+		prepare func(f fields)
+		code    codes.Code
+	}{
+		{
+			name: "verified user",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					expectIdentity(f, mockUser),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(mockUser, nil),
+				)...)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "email is not verified",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					expectIdentity(f, mockUser),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(auth.User{Name: mockUser.Name, Pending: true}, nil),
+				)...)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "user not found",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					expectIdentity(f, mockUser),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(auth.User{}, auth.ErrUserNotFound),
+				)...)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "internal error",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					expectIdentity(f, mockUser),
+					f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(auth.User{}, shared.ErrNoData),
+				)...)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			f := s.newFields()
+			f.ids.EXPECT().NewID().Return("test-id", nil).AnyTimes()
+			row.prepare(f)
+
+			conn, _ := s.serve(f)
+
+			err := invoke(conn, privateService, metadata.Pairs(interceptor.AuthMetadata, "Bearer test-token"))
+			s.Equal(row.code, status.Code(err))
+		})
+	}
+}
+
+func (s *RunInterceptorSuite) TestRequestTrace() {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	testList := []struct {
+		name        string
+		prepare     func(f fields)
+		md          metadata.MD
+		expected    shared.Trace
+		responseTag string
+	}{
+		{
+			name: "ids from metadata",
+			md: metadata.Pairs(
+				interceptor.RequestIDMetadata, "test request id",
+				interceptor.TraceparentMetadata, "00-"+traceID+"-00f067aa0ba902b7-01",
+			),
+			expected: shared.Trace{TraceID: traceID, RequestID: "test request id"},
+		},
+		{
+			name: "missing ids are generated",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.ids.EXPECT().NewID().Return("generated-request-id", nil),
+					f.ids.EXPECT().NewID().Return("generated-trace-id", nil),
+				)
+			},
+			md:       metadata.MD{},
+			expected: shared.Trace{TraceID: "generatedtraceid", RequestID: "generated-request-id"},
+		},
+		{
+			name: "invalid ids are replaced",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.ids.EXPECT().NewID().Return("generated-request-id", nil),
+					f.ids.EXPECT().NewID().Return("generated-trace-id", nil),
+				)
+			},
+			md: metadata.Pairs(
+				interceptor.RequestIDMetadata, strings.Repeat("a", tracing.MaxRequestIDLength+1),
+				interceptor.TraceparentMetadata, "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			),
+			expected: shared.Trace{TraceID: "generatedtraceid", RequestID: "generated-request-id"},
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			f := s.newFields()
+			if row.prepare != nil {
+				row.prepare(f)
+			}
+
+			gomock.InOrder(append(
+				expectIdentity(f, mockUser),
+				f.authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(mockUser, nil),
+			)...)
+
+			conn, rec := s.serve(f)
+
+			row.md.Set(interceptor.AuthMetadata, "Bearer test-token")
+
+			var header metadata.MD
+			err := invoke(conn, privateService, row.md, grpc.Header(&header))
+			s.Require().NoError(err)
+
+			user, ok := interceptor.User(rec.ctx)
+			s.True(ok)
+			s.Equal(row.expected, user.Trace)
+			s.Equal([]string{row.expected.RequestID}, header.Get(interceptor.RequestIDMetadata))
+		})
+	}
+}
+
+func (s *RunInterceptorSuite) TestRecovery() {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptor.Recovery(s.log)))
+	server.RegisterService(serviceDesc(publicService), nil)
+
+	conn := grpctest.Dial(s.T(), server)
+
+	// the handler panics on the nil recorder
+	err := invoke(conn, publicService, metadata.MD{})
+	s.Equal(codes.Internal, status.Code(err))
+}
@@ -0,0 +1,257 @@
+package productsgrpchandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/interceptor"
+	productkeeperv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/v1"
+)
+
+// ProductsHandler ...
+type ProductsHandler struct {
+	productkeeperv1.UnimplementedProductServiceServer
+
+	log *slog.Logger
+	db  *sqlx.DB
+
+	productsService *products.ProductsService
+}
+
+// NewProductsHandler constructor for ProductsHandler
+func NewProductsHandler(log *slog.Logger, db *sqlx.DB, productsService *products.ProductsService) *ProductsHandler {
+	return &ProductsHandler{
+		log: log,
+		db:  db,
+
+		productsService: productsService,
+	}
+}
+
+// CreateProduct ...
+func (h *ProductsHandler) CreateProduct(ctx context.Context, req *productkeeperv1.CreateProductRequest) (*productkeeperv1.CreateProductResponse, error) {
+	user, ok := interceptor.User(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	if err := validateProduct(req.GetName(), req.GetPrice(), req.GetQuantity()); err != nil {
+		return nil, err
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	id, events, err := h.productsService.CreateProduct(tx, user, products.Product{
+		Name:      req.GetName(),
+		Price:     req.GetPrice(),
+		Quantity:  req.GetQuantity(),
+		OwnerName: user.Name,
+	})
+	if err != nil {
+		h.log.Error("CreateProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	h.productsService.PublishEvents(events)
+
+	h.log.Info("CreateProduct: product has been successfully created")
+	return &productkeeperv1.CreateProductResponse{Id: id}, nil
+}
+
+// GetProduct ...
+func (h *ProductsHandler) GetProduct(ctx context.Context, req *productkeeperv1.GetProductRequest) (*productkeeperv1.GetProductResponse, error) {
+	user, ok := interceptor.User(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	product, err := h.productsService.FindProduct(tx, req.GetId(), user)
+	if err != nil {
+		h.log.Error("GetProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	h.log.Info("GetProduct: product data has been successfully received")
+	return &productkeeperv1.GetProductResponse{Product: productMessage(product)}, nil
+}
+
+// UpdateProduct ...
+func (h *ProductsHandler) UpdateProduct(ctx context.Context, req *productkeeperv1.UpdateProductRequest) (*productkeeperv1.UpdateProductResponse, error) {
+	user, ok := interceptor.User(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	if err := validateProduct(req.GetName(), req.GetPrice(), req.GetQuantity()); err != nil {
+		return nil, err
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	updated, events, err := h.productsService.UpdateProduct(tx, user, products.Product{
+		ID:       req.GetId(),
+		Name:     req.GetName(),
+		Price:    req.GetPrice(),
+		Quantity: req.GetQuantity(),
+	})
+	if err != nil {
+		h.log.Error("UpdateProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	h.productsService.PublishEvents(events)
+
+	h.log.Info("UpdateProduct: product data has been successfully updated")
+	return &productkeeperv1.UpdateProductResponse{Product: productMessage(updated)}, nil
+}
+
+// DeleteProduct ...
+func (h *ProductsHandler) DeleteProduct(ctx context.Context, req *productkeeperv1.DeleteProductRequest) (*productkeeperv1.DeleteProductResponse, error) {
+	user, ok := interceptor.User(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	events, err := h.productsService.DeleteProduct(tx, req.GetId(), user)
+	if err != nil {
+		h.log.Error("DeleteProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	h.productsService.PublishEvents(events)
+
+	h.log.Info("DeleteProduct: product has been successfully deleted")
+	return &productkeeperv1.DeleteProductResponse{}, nil
+}
+
+// ListProducts ...
+func (h *ProductsHandler) ListProducts(ctx context.Context, req *productkeeperv1.ListProductsRequest) (*productkeeperv1.ListProductsResponse, error) {
+	user, ok := interceptor.User(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+
+	var sortBy products.SortType
+	switch req.GetSortBy() {
+	case productkeeperv1.SortBy_SORT_BY_LAST_CREATE:
+		sortBy = products.LastCreate
+	case productkeeperv1.SortBy_SORT_BY_NAME:
+		sortBy = products.Name
+	case productkeeperv1.SortBy_SORT_BY_UNSPECIFIED:
+		sortBy = products.Empty
+	default:
+		return nil, status.Error(codes.InvalidArgument, "invalid sort_by")
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	defer tx.Rollback()
+
+	productList, err := h.productsService.FindProductList(tx, user.Name, req.GetName(), sortBy)
+	if err != nil && !errors.Is(err, products.ErrProductListNotFound) {
+		h.log.Error("ListProducts: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	res := &productkeeperv1.ListProductsResponse{
+		Products: make([]*productkeeperv1.Product, 0, len(productList)),
+	}
+	for _, product := range productList {
+		res.Products = append(res.Products, productMessage(product))
+	}
+
+	h.log.Info("ListProducts: products has been successfully received")
+	return res, nil
+}
+
+// productsError status of products service error
+func productsError(err error) error {
+	switch {
+	case errors.Is(err, products.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product with such id does not exist")
+	case errors.Is(err, products.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// validateProduct requires the same fields as http api
+func validateProduct(name string, price, quantity uint64) error {
+	if name == "" || price == 0 || quantity == 0 {
+		return status.Error(codes.InvalidArgument, "name, price and quantity are required")
+	}
+
+	return nil
+}
+
+func productMessage(product products.Product) *productkeeperv1.Product {
+	return &productkeeperv1.Product{
+		Id:        product.ID,
+		Name:      product.Name,
+		Price:     product.Price,
+		Quantity:  product.Quantity,
+		OwnerName: product.OwnerName,
+		CreatedAt: timestamppb.New(product.CreatedAt),
+	}
+}
@@ -0,0 +1,382 @@
+package productsgrpchandler_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/grpctest"
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/interceptor"
+	productsgrpchandler "github.com/fallra1n/product-keeper/internal/handler/grpc/products"
+	mockauth "github.com/fallra1n/product-keeper/internal/mocks/auth"
+	mockproducts "github.com/fallra1n/product-keeper/internal/mocks/products"
+	mockshared "github.com/fallra1n/product-keeper/internal/mocks/shared"
+	productkeeperv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/v1"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+var (
+	mockNow     = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUser    = auth.User{Name: "test name", Role: auth.RoleUser}
+	mockSession = auth.Session{
+		ID:         "test session",
+		UserName:   mockUser.Name,
+		LastSeenAt: mockNow,
+		ExpiresAt:  mockNow.Add(time.Hour),
+	}
+	mockProduct = products.Product{
+		ID:        1,
+		Name:      "test product",
+		Price:     100,
+		Quantity:  10,
+		OwnerName: mockUser.Name,
+		CreatedAt: mockNow,
+	}
+)
+
+type RunProductsSuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunProductsSuite(t *testing.T) {
+	suite.Run(t, new(RunProductsSuite))
+}
+
+func (s *RunProductsSuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+}
+
+type fields struct {
+	date               *mockshared.MockDateTool
+	ids                *mockshared.MockIDGenerator
+	authorizer         *mockshared.MockAuthorizer
+	productsRepo       *mockproducts.MockProductsRepo
+	productsStatistics *mockproducts.MockProductsStatistics
+	productsWebhooks   *mockproducts.MockProductsWebhooks
+}
+
+// serve starts server with the products handler behind UserIdentity, every call is made by mockUser
+func (s *RunProductsSuite) serve(prepare func(f fields)) productkeeperv1.ProductServiceClient {
+	ctrl := gomock.NewController(s.T())
+
+	f := fields{
+		date:               mockshared.NewMockDateTool(ctrl),
+		ids:                mockshared.NewMockIDGenerator(ctrl),
+		authorizer:         mockshared.NewMockAuthorizer(ctrl),
+		productsRepo:       mockproducts.NewMockProductsRepo(ctrl),
+		productsStatistics: mockproducts.NewMockProductsStatistics(ctrl),
+		productsWebhooks:   mockproducts.NewMockProductsWebhooks(ctrl),
+	}
+	f.date.EXPECT().Now().Return(mockNow).AnyTimes()
+	f.ids.EXPECT().NewID().Return("test-id", nil).AnyTimes()
+	if prepare != nil {
+		prepare(f)
+	}
+
+	jwt := mockshared.NewMockJwt(ctrl)
+	jwt.EXPECT().ParseToken("test-token").Return(mockUser.Name, string(mockUser.Role), mockSession.ID, nil).AnyTimes()
+
+	authRepo := mockauth.NewMockAuthRepo(ctrl)
+	authRepo.EXPECT().FindSession(gomock.Any(), mockSession.ID).Return(mockSession, nil).AnyTimes()
+	authRepo.EXPECT().FindUser(gomock.Any(), mockUser.Name).Return(mockUser, nil).AnyTimes()
+
+	authService := auth.NewAuthService(
+		s.log,
+		mockshared.NewMockCrypto(ctrl),
+		jwt,
+		f.date,
+		mockshared.NewMockTOTP(ctrl),
+		mockshared.NewMockNotifier(ctrl),
+		mockshared.NewMockSigner(ctrl),
+		authRepo,
+		mockauth.NewMockIdentityProviders(ctrl),
+		mockauth.NewMockBreachedPasswords(ctrl),
+		mockauth.NewMockProductsOwnership(ctrl),
+		auth.Settings{SessionTTL: time.Hour},
+	)
+
+	productsService := products.NewProductsService(
+		s.log,
+		f.date,
+		f.ids,
+		f.authorizer,
+
+		f.productsRepo,
+		f.productsStatistics,
+		f.productsWebhooks,
+	)
+
+	db := grpctest.NewDB()
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		interceptor.UserIdentity(s.log, db, authService),
+	))
+	productkeeperv1.RegisterProductServiceServer(server, productsgrpchandler.NewProductsHandler(s.log, db, productsService))
+
+	return productkeeperv1.NewProductServiceClient(grpctest.Dial(s.T(), server))
+}
+
+func authorized() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), interceptor.AuthMetadata, "Bearer test-token")
+}
+
+// expectEvent expectations of the event sent to statistics and webhooks
+func expectEvent(f fields, eventType products.EventType) []any {
+	return []any{
+		f.productsStatistics.EXPECT().Send(gomock.Any(), gomock.Cond(func(x any) bool {
+			return x.(products.Event).Type == eventType
+		})).Return(nil),
+		f.productsWebhooks.EXPECT().Enqueue(gomock.Any(), gomock.Any()).Return(nil),
+	}
+}
+
+func (s *RunProductsSuite) TestCreateProduct() {
+	testList := []struct {
+		name     string
+		prepare  func(f fields)
+		req      *productkeeperv1.CreateProductRequest
+		expected uint64
+		code     codes.Code
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					[]any{f.productsRepo.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).Return(mockProduct.ID, nil)},
+					expectEvent(f, products.EventCreated)...,
+				)...)
+			},
+			req:      &productkeeperv1.CreateProductRequest{Name: mockProduct.Name, Price: mockProduct.Price, Quantity: mockProduct.Quantity},
+			expected: mockProduct.ID,
+			code:     codes.OK,
+		},
+		{
+			name: "empty name",
+			req:  &productkeeperv1.CreateProductRequest{Price: mockProduct.Price, Quantity: mockProduct.Quantity},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "zero price",
+			req:  &productkeeperv1.CreateProductRequest{Name: mockProduct.Name, Quantity: mockProduct.Quantity},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "failed to create product",
+			prepare: func(f fields) {
+				f.productsRepo.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).Return(uint64(0), shared.ErrNoData)
+			},
+			req:  &productkeeperv1.CreateProductRequest{Name: mockProduct.Name, Price: mockProduct.Price, Quantity: mockProduct.Quantity},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			client := s.serve(row.prepare)
+
+			res, err := client.CreateProduct(authorized(), row.req)
+			s.Equal(row.code, status.Code(err))
+			s.Equal(row.expected, res.GetId())
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestGetProduct() {
+	testList := []struct {
+		name     string
+		prepare  func(f fields)
+		expected *productkeeperv1.Product
+		code     codes.Code
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					[]any{
+						f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(mockProduct, nil),
+						f.authorizer.EXPECT().Authorize(gomock.Any(), products.ActionRead, mockProduct.OwnerName).Return(true),
+					},
+					expectEvent(f, products.EventViewed)...,
+				)...)
+			},
+			expected: &productkeeperv1.Product{
+				Id:        mockProduct.ID,
+				Name:      mockProduct.Name,
+				Price:     mockProduct.Price,
+				Quantity:  mockProduct.Quantity,
+				OwnerName: mockProduct.OwnerName,
+			},
+			code: codes.OK,
+		},
+		{
+			name: "product not found",
+			prepare: func(f fields) {
+				f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(products.Product{}, shared.ErrNoData)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "permission denied",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(gomock.Any(), products.ActionRead, mockProduct.OwnerName).Return(false),
+				)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "failed to find product",
+			prepare: func(f fields) {
+				f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(products.Product{}, shared.ErrInternal)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			client := s.serve(row.prepare)
+
+			res, err := client.GetProduct(authorized(), &productkeeperv1.GetProductRequest{Id: mockProduct.ID})
+			s.Equal(row.code, status.Code(err))
+			if row.expected == nil {
+				s.Nil(res)
+				return
+			}
+
+			s.Equal(row.expected.GetId(), res.GetProduct().GetId())
+			s.Equal(row.expected.GetName(), res.GetProduct().GetName())
+			s.Equal(row.expected.GetPrice(), res.GetProduct().GetPrice())
+			s.Equal(row.expected.GetQuantity(), res.GetProduct().GetQuantity())
+			s.Equal(row.expected.GetOwnerName(), res.GetProduct().GetOwnerName())
+			s.Equal(mockNow, res.GetProduct().GetCreatedAt().AsTime())
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestDeleteProduct() {
+	testList := []struct {
+		name    string
+		prepare func(f fields)
+		code    codes.Code
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f fields) {
+				gomock.InOrder(append(
+					[]any{
+						f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(mockProduct, nil),
+						f.authorizer.EXPECT().Authorize(gomock.Any(), products.ActionDelete, mockProduct.OwnerName).Return(true),
+						f.productsRepo.EXPECT().DeleteProduct(gomock.Any(), mockProduct.ID).Return(nil),
+					},
+					expectEvent(f, products.EventDeleted)...,
+				)...)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "permission denied",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(gomock.Any(), products.ActionDelete, mockProduct.OwnerName).Return(false),
+				)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "failed to delete product",
+			prepare: func(f fields) {
+				gomock.InOrder(
+					f.productsRepo.EXPECT().FindProduct(gomock.Any(), mockProduct.ID).Return(mockProduct, nil),
+					f.authorizer.EXPECT().Authorize(gomock.Any(), products.ActionDelete, mockProduct.OwnerName).Return(true),
+					f.productsRepo.EXPECT().DeleteProduct(gomock.Any(), mockProduct.ID).Return(shared.ErrNoData),
+				)
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			client := s.serve(row.prepare)
+
+			_, err := client.DeleteProduct(authorized(), &productkeeperv1.DeleteProductRequest{Id: mockProduct.ID})
+			s.Equal(row.code, status.Code(err))
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestListProducts() {
+	testList := []struct {
+		name     string
+		prepare  func(f fields)
+		req      *productkeeperv1.ListProductsRequest
+		expected int
+		code     codes.Code
+	}{
+		{
+			name: "successful launch",
+			prepare: func(f fields) {
+				f.productsRepo.EXPECT().FindProductList(gomock.Any(), mockUser.Name, "test", products.Name).Return([]products.Product{mockProduct, mockProduct}, nil)
+			},
+			req:      &productkeeperv1.ListProductsRequest{Name: "test", SortBy: productkeeperv1.SortBy_SORT_BY_NAME},
+			expected: 2,
+			code:     codes.OK,
+		},
+		{
+			name: "empty list",
+			prepare: func(f fields) {
+				f.productsRepo.EXPECT().FindProductList(gomock.Any(), mockUser.Name, "", products.Empty).Return(nil, shared.ErrNoData)
+			},
+			req:  &productkeeperv1.ListProductsRequest{},
+			code: codes.OK,
+		},
+		{
+			name: "invalid sort_by",
+			req:  &productkeeperv1.ListProductsRequest{SortBy: productkeeperv1.SortBy(100)},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "failed to find product list",
+			prepare: func(f fields) {
+				f.productsRepo.EXPECT().FindProductList(gomock.Any(), mockUser.Name, "", products.LastCreate).Return(nil, shared.ErrInternal)
+			},
+			req:  &productkeeperv1.ListProductsRequest{SortBy: productkeeperv1.SortBy_SORT_BY_LAST_CREATE},
+			code: codes.Internal,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			client := s.serve(row.prepare)
+
+			res, err := client.ListProducts(authorized(), row.req)
+			s.Equal(row.code, status.Code(err))
+			s.Len(res.GetProducts(), row.expected)
+		})
+	}
+}
+
+func (s *RunProductsSuite) TestUnauthenticated() {
+	handler := productsgrpchandler.NewProductsHandler(s.log, grpctest.NewDB(), nil)
+
+	// handlers are called without UserIdentity
+	_, err := handler.GetProduct(context.Background(), &productkeeperv1.GetProductRequest{Id: mockProduct.ID})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+
+	_, err = handler.ListProducts(context.Background(), &productkeeperv1.ListProductsRequest{})
+	s.Equal(codes.Unauthenticated, status.Code(err))
+}
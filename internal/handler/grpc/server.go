@@ -0,0 +1,37 @@
+package grpchandler
+
+import (
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/fallra1n/product-keeper/internal/handler/grpc/interceptor"
+	productkeeperv1 "github.com/fallra1n/product-keeper/pkg/api/productkeeper/v1"
+)
+
+// SetupServer ...
+func SetupServer(
+	log *slog.Logger,
+	creds credentials.TransportCredentials,
+	requestTrace grpc.UnaryServerInterceptor,
+	userIdentity grpc.UnaryServerInterceptor,
+	requireVerified grpc.UnaryServerInterceptor,
+	authHandlers productkeeperv1.AuthServiceServer,
+	productHandlers productkeeperv1.ProductServiceServer,
+) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(
+			interceptor.Recovery(log),
+			requestTrace,
+			userIdentity,
+			requireVerified,
+		),
+	)
+
+	productkeeperv1.RegisterAuthServiceServer(server, authHandlers)
+	productkeeperv1.RegisterProductServiceServer(server, productHandlers)
+
+	return server
+}
@@ -0,0 +1,193 @@
+package adminhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+)
+
+// AdminHandler ...
+type AdminHandler struct {
+	log *slog.Logger
+	db  *sqlx.DB
+
+	authService     *auth.AuthService
+	productsService *products.ProductsService
+}
+
+// NewAdminHandler constructor for AdminHandler
+func NewAdminHandler(
+	log *slog.Logger,
+	db *sqlx.DB,
+	authService *auth.AuthService,
+	productsService *products.ProductsService,
+) *AdminHandler {
+	return &AdminHandler{
+		log: log,
+		db:  db,
+
+		authService:     authService,
+		productsService: productsService,
+	}
+}
+
+// FindUserList ...
+func (h *AdminHandler) FindUserList(c *gin.Context) {
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	users, err := h.authService.FindUserList(tx)
+	if err != nil {
+		h.log.Error("FindUserList: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal server error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	usersResponse := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		usersResponse = append(usersResponse, UserResponse{
+			ID:       user.ID,
+			Name:     user.Name,
+			Role:     string(user.Role),
+			Disabled: user.Disabled,
+		})
+	}
+
+	h.log.Info("FindUserList: users has been successfully received")
+	c.JSON(http.StatusOK, usersResponse)
+}
+
+// DisableUser ...
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	name := c.Param("name")
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.DisableUser(tx, name); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.log.Error("DisableUser: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+			return
+		}
+
+		h.log.Error("DisableUser: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal server error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("DisableUser: user has been successfully disabled")
+	c.JSON(http.StatusOK, DefaultResponse{"user has been successfully disabled"})
+}
+
+// UnlockUser ...
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	name := c.Param("name")
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.UnlockUser(tx, name); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.log.Error("UnlockUser: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+			return
+		}
+
+		h.log.Error("UnlockUser: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal server error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("UnlockUser: user has been successfully unlocked")
+	c.JSON(http.StatusOK, DefaultResponse{"user has been successfully unlocked"})
+}
+
+// FindProduct ...
+func (h *AdminHandler) FindProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("FindProduct: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	product, err := h.productsService.FindAnyProduct(tx, id)
+	if err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			h.log.Error("FindProduct: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"product with such id does not exist"})
+			return
+		}
+
+		h.log.Error("FindProduct: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal server error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("FindProduct: product data has been successfully received")
+	c.JSON(http.StatusOK, ProductResponse{
+		ID:        product.ID,
+		Name:      product.Name,
+		Price:     product.Price,
+		Quantity:  product.Quantity,
+		OwnerID:   product.OwnerID,
+		OwnerName: product.OwnerName,
+		CreatedAt: product.CreatedAt,
+	})
+}
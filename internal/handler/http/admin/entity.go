@@ -0,0 +1,27 @@
+package adminhttphandler
+
+import "time"
+
+// DefaultResponse ...
+type DefaultResponse struct {
+	Message string `json:"message"`
+}
+
+// UserResponse ...
+type UserResponse struct {
+	ID       uint64 `json:"id"`
+	Name     string `json:"username"`
+	Role     string `json:"role"`
+	Disabled bool   `json:"disabled"`
+}
+
+// ProductResponse ...
+type ProductResponse struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Price     uint64    `json:"price"`
+	Quantity  uint64    `json:"quantity"`
+	OwnerID   uint64    `json:"owner_id"`
+	OwnerName string    `json:"owner_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
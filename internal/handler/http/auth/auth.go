@@ -5,11 +5,19 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+const (
+	// oidcStateCookie binds oidc login state to the browser that started the login
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/user/oidc"
 )
 
 // AuthHandler ...
@@ -32,7 +40,7 @@ func NewAuthHandler(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService
 
 // UserRegister ...
 func (h *AuthHandler) UserRegister(c *gin.Context) {
-	var req AuthRequest
+	var req RegisterRequest
 
 	if err := c.BindJSON(&req); err != nil {
 		h.log.Error("UserRegister: " + err.Error())
@@ -48,9 +56,10 @@ func (h *AuthHandler) UserRegister(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	err = h.authService.CreateUser(tx, auth.NewUser(
-		req.Name, req.Password,
-	))
+	user := auth.NewUser(req.Name, req.Password)
+	user.Email = req.Email
+
+	err = h.authService.CreateUser(tx, user)
 
 	if err != nil {
 		if errors.Is(err, auth.ErrUserAlreadyExist) {
@@ -59,6 +68,18 @@ func (h *AuthHandler) UserRegister(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, auth.ErrInvalidEmail) {
+			h.log.Error("UserRegister: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid email address"})
+			return
+		}
+
+		if isPasswordPolicyError(err) {
+			h.log.Error("UserRegister: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{err.Error()})
+			return
+		}
+
 		h.log.Error("UserRegister: " + err.Error())
 		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
 		return
@@ -70,7 +91,7 @@ func (h *AuthHandler) UserRegister(c *gin.Context) {
 	}
 
 	h.log.Info("UserRegister: a user has been successfully registered")
-	c.JSON(http.StatusOK, DefaultResponse{"a user has been successfully registered"})
+	c.JSON(http.StatusOK, DefaultResponse{"a user has been successfully registered, check your email to verify it"})
 }
 
 // UserLogin ...
@@ -91,34 +112,306 @@ func (h *AuthHandler) UserLogin(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	token, err := h.authService.LoginUser(tx, auth.NewUser(
+	res, err := h.authService.LoginUser(tx, auth.NewUser(
 		req.Name,
 		req.Password,
-	))
+	), auth.NewClient(c.ClientIP(), c.Request.UserAgent()))
 
 	if err != nil {
-		if errors.Is(err, auth.ErrIncorrectPassword) {
+		switch {
+		case errors.Is(err, auth.ErrIncorrectPassword):
+			// failed attempt must be saved
+			if err := tx.Commit(); err != nil {
+				h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+			}
+
+			h.log.Error("UserLogin: " + err.Error())
+			c.JSON(http.StatusUnauthorized, DefaultResponse{"incorrect username or password"})
+		case errors.Is(err, auth.ErrTooManyAttempts):
+			h.log.Error("UserLogin: " + err.Error())
+			c.JSON(http.StatusTooManyRequests, DefaultResponse{"too many failed login attempts, try again later"})
+		case errors.Is(err, auth.ErrUserDisabled):
+			h.log.Error("UserLogin: " + err.Error())
+			c.JSON(http.StatusForbidden, DefaultResponse{"user account has been disabled"})
+		default:
 			h.log.Error("UserLogin: " + err.Error())
-			c.JSON(http.StatusBadRequest, DefaultResponse{"incorrect password"})
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if res.MFAChallenge != "" {
+		h.log.Info("UserLogin: second factor is required")
+		c.JSON(http.StatusOK, LoginResponse{MFAChallenge: res.MFAChallenge})
+		return
+	}
+
+	h.log.Info("UserLogin: a user has been successfully authorized")
+	c.JSON(http.StatusOK, LoginResponse{Token: res.Token})
+}
+
+// CreateAPIKey ...
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req APIKeyRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("CreateAPIKey: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	rawKey, key, err := h.authService.CreateAPIKey(tx, username.(string), req.Name, req.Scopes)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidScope) {
+			h.log.Error("CreateAPIKey: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid scopes"})
 			return
 		}
 
-		if errors.Is(err, auth.ErrUserNotFound) {
-			h.log.Error("UserLogin: " + err.Error())
-			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+		h.log.Error("CreateAPIKey: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("CreateAPIKey: api key has been successfully created")
+	c.JSON(http.StatusCreated, APIKeyResponse{
+		ID:        key.ID,
+		Key:       rawKey,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+	})
+}
+
+// FindAPIKeyList ...
+func (h *AuthHandler) FindAPIKeyList(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	keys, err := h.authService.FindAPIKeyList(tx, username.(string))
+	if err != nil {
+		h.log.Error("FindAPIKeyList: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	keysResponse := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		keysResponse = append(keysResponse, APIKeyResponse{
+			ID:         key.ID,
+			Name:       key.Name,
+			Scopes:     key.Scopes,
+			CreatedAt:  key.CreatedAt,
+			LastUsedAt: key.LastUsedAt,
+			RevokedAt:  key.RevokedAt,
+		})
+	}
+
+	h.log.Info("FindAPIKeyList: api keys has been successfully received")
+	c.JSON(http.StatusOK, keysResponse)
+}
+
+// RevokeAPIKey ...
+func (h *AuthHandler) RevokeAPIKey(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("RevokeAPIKey: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.RevokeAPIKey(tx, id, username.(string)); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			h.log.Error("RevokeAPIKey: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"api key not found"})
 			return
 		}
 
-		h.log.Error("UserLogin: " + err.Error())
+		h.log.Error("RevokeAPIKey: " + err.Error())
 		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
 		return
 	}
 
 	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
 		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
 		return
 	}
 
-	h.log.Info("UserLogin: a user has been successfully authorized")
-	c.JSON(http.StatusOK, LoginResponse{token})
+	h.log.Info("RevokeAPIKey: api key has been successfully revoked")
+	c.JSON(http.StatusOK, DefaultResponse{"api key has been successfully revoked"})
+}
+
+// OIDCLogin redirects user to identity provider
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	provider := c.Query("provider")
+	if provider == "" {
+		h.log.Error("OIDCLogin: empty provider param")
+		c.JSON(http.StatusBadRequest, DefaultResponse{"empty provider param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	url, state, err := h.authService.StartOIDCLogin(tx, provider)
+	if err != nil {
+		if errors.Is(err, auth.ErrUnknownProvider) {
+			h.log.Error("OIDCLogin: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"unknown identity provider"})
+			return
+		}
+
+		h.log.Error("OIDCLogin: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	// the callback is a top-level redirect from the provider, Lax cookies are sent with it
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(auth.OIDCStateTTL.Seconds()), oidcCookiePath, "", true, true)
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// OIDCCallback finishes login after redirect from identity provider
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if errParam := c.Query("error"); errParam != "" {
+		h.log.Error("OIDCCallback: identity provider returned error: " + errParam)
+		c.JSON(http.StatusBadRequest, DefaultResponse{"identity provider returned error: " + errParam})
+		return
+	}
+
+	state := c.Query("state")
+	code := c.Query("code")
+	if state == "" || code == "" {
+		h.log.Error("OIDCCallback: empty state or code param")
+		c.JSON(http.StatusBadRequest, DefaultResponse{"empty state or code param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	// the state is redeemed only once, the cookie is not needed after the callback
+	boundState, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", true, true)
+
+	res, err := h.authService.FinishOIDCLogin(tx, state, boundState, code, auth.NewClient(c.ClientIP(), c.Request.UserAgent()))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidOIDCState):
+			h.log.Error("OIDCCallback: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid or expired login state"})
+		case errors.Is(err, auth.ErrOIDCExchange):
+			h.log.Error("OIDCCallback: " + err.Error())
+			c.JSON(http.StatusUnauthorized, DefaultResponse{"failed to authorize with identity provider"})
+		case errors.Is(err, auth.ErrUserAlreadyExist):
+			h.log.Error("OIDCCallback: " + err.Error())
+			c.JSON(http.StatusConflict, DefaultResponse{"username already exists"})
+		case errors.Is(err, auth.ErrUserDisabled):
+			h.log.Error("OIDCCallback: " + err.Error())
+			c.JSON(http.StatusForbidden, DefaultResponse{"user account has been disabled"})
+		default:
+			h.log.Error("OIDCCallback: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if res.MFAChallenge != "" {
+		h.log.Info("OIDCCallback: second factor is required")
+		c.JSON(http.StatusOK, LoginResponse{MFAChallenge: res.MFAChallenge})
+		return
+	}
+
+	h.log.Info("OIDCCallback: a user has been successfully authorized")
+	c.JSON(http.StatusOK, LoginResponse{Token: res.Token})
+}
+
+// JWKS ...
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	jwks, err := h.authService.JWKS()
+	if err != nil {
+		h.log.Error("JWKS: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", jwks)
 }
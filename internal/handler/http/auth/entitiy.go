@@ -1,5 +1,7 @@
 package authhttphandler
 
+import "time"
+
 // DefaultResponse ...
 type DefaultResponse struct {
 	Message string `json:"message"`
@@ -11,7 +13,116 @@ type AuthRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResponse ...
+// RegisterRequest ...
+type RegisterRequest struct {
+	Name     string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+}
+
+// LoginResponse contains either token or mfa challenge for /user/login/2fa
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	MFAChallenge string `json:"mfa_challenge,omitempty"`
+}
+
+// MFALoginRequest ...
+type MFALoginRequest struct {
+	Challenge string `json:"mfa_challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+// TOTPSetupResponse ...
+type TOTPSetupResponse struct {
+	URI string `json:"uri"`
+}
+
+// TOTPVerifyRequest ...
+type TOTPVerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPVerifyResponse ...
+type TOTPVerifyResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TOTPDisableRequest ...
+type TOTPDisableRequest struct {
+	// Password is not required for users who sign in with an identity provider
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// APIKeyRequest ...
+type APIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+// APIKeyResponse ...
+type APIKeyResponse struct {
+	ID         uint64     `json:"id"`
+	Key        string     `json:"key,omitempty"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// ChangePasswordRequest ...
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// PasswordResetRequest ...
+type PasswordResetRequest struct {
+	Name string `json:"username" binding:"required"`
+}
+
+// ResetPasswordRequest ...
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ProfileRequest ...
+type ProfileRequest struct {
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+	Timezone    string `json:"timezone"`
+}
+
+// ProfileResponse ...
+type ProfileResponse struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+	Timezone    string `json:"timezone"`
+}
+
+// DeleteAccountRequest ...
+type DeleteAccountRequest struct {
+	Password   string `json:"password" binding:"required"`
+	Products   string `json:"products" binding:"required"`
+	TransferTo string `json:"transfer_to"`
+}
+
+// StreamTokenResponse token for product streams, passed as stream_token query parameter
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionResponse ...
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
 }
@@ -0,0 +1,211 @@
+package authhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// LoginMFA ...
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req MFALoginRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("LoginMFA: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	token, err := h.authService.LoginMFA(tx, req.Challenge, req.Code, auth.NewClient(c.ClientIP(), c.Request.UserAgent()))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidTOTPCode):
+			// challenge must be consumed and failed attempt saved,
+			// otherwise the code could be guessed with one password login
+			if err := tx.Commit(); err != nil {
+				h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+			}
+
+			h.log.Error("LoginMFA: " + err.Error())
+			c.JSON(http.StatusUnauthorized, DefaultResponse{"invalid two-factor code"})
+		case errors.Is(err, auth.ErrTooManyAttempts):
+			h.log.Error("LoginMFA: " + err.Error())
+			c.JSON(http.StatusTooManyRequests, DefaultResponse{"too many failed login attempts, try again later"})
+		case errors.Is(err, auth.ErrInvalidMFAChallenge):
+			h.log.Error("LoginMFA: " + err.Error())
+			c.JSON(http.StatusUnauthorized, DefaultResponse{"invalid or expired mfa challenge"})
+		case errors.Is(err, auth.ErrUserDisabled):
+			h.log.Error("LoginMFA: " + err.Error())
+			c.JSON(http.StatusForbidden, DefaultResponse{"user account has been disabled"})
+		default:
+			h.log.Error("LoginMFA: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("LoginMFA: a user has been successfully authorized")
+	c.JSON(http.StatusOK, LoginResponse{Token: token})
+}
+
+// SetupTOTP ...
+func (h *AuthHandler) SetupTOTP(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	uri, err := h.authService.SetupTOTP(tx, username.(string))
+	if err != nil {
+		if errors.Is(err, auth.ErrTOTPAlreadyEnabled) {
+			h.log.Error("SetupTOTP: " + err.Error())
+			c.JSON(http.StatusConflict, DefaultResponse{"two-factor authentication is already enabled"})
+			return
+		}
+
+		h.log.Error("SetupTOTP: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("SetupTOTP: totp secret has been successfully generated")
+	c.JSON(http.StatusOK, TOTPSetupResponse{uri})
+}
+
+// VerifyTOTP ...
+func (h *AuthHandler) VerifyTOTP(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req TOTPVerifyRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("VerifyTOTP: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := h.authService.VerifyTOTP(tx, username.(string), req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTOTPNotFound):
+			h.log.Error("VerifyTOTP: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"two-factor authentication is not set up"})
+		case errors.Is(err, auth.ErrTOTPAlreadyEnabled):
+			h.log.Error("VerifyTOTP: " + err.Error())
+			c.JSON(http.StatusConflict, DefaultResponse{"two-factor authentication is already enabled"})
+		case errors.Is(err, auth.ErrInvalidTOTPCode):
+			h.log.Error("VerifyTOTP: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid two-factor code"})
+		default:
+			h.log.Error("VerifyTOTP: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("VerifyTOTP: two-factor authentication has been successfully enabled")
+	c.JSON(http.StatusOK, TOTPVerifyResponse{codes})
+}
+
+// DisableTOTP ...
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req TOTPDisableRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("DisableTOTP: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	err = h.authService.DisableTOTP(tx, username.(string), req.Password, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrIncorrectPassword):
+			h.log.Error("DisableTOTP: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"incorrect password"})
+		case errors.Is(err, auth.ErrInvalidTOTPCode):
+			h.log.Error("DisableTOTP: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid two-factor code"})
+		case errors.Is(err, auth.ErrTOTPNotFound):
+			h.log.Error("DisableTOTP: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"two-factor authentication is not enabled"})
+		case errors.Is(err, auth.ErrUserNotFound):
+			h.log.Error("DisableTOTP: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+		default:
+			h.log.Error("DisableTOTP: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("DisableTOTP: two-factor authentication has been successfully disabled")
+	c.JSON(http.StatusOK, DefaultResponse{"two-factor authentication has been disabled"})
+}
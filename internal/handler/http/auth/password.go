@@ -0,0 +1,148 @@
+package authhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// ChangePassword ...
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("ChangePassword: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	err = h.authService.ChangePassword(tx, username.(string), c.GetString(middleware.SessionContext), req.OldPassword, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrIncorrectPassword):
+			h.log.Error("ChangePassword: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"incorrect password"})
+		case errors.Is(err, auth.ErrPasswordNotSet):
+			h.log.Error("ChangePassword: " + err.Error())
+			c.JSON(http.StatusForbidden, DefaultResponse{err.Error()})
+		case isPasswordPolicyError(err):
+			h.log.Error("ChangePassword: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{err.Error()})
+		case errors.Is(err, auth.ErrUserNotFound):
+			h.log.Error("ChangePassword: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+		default:
+			h.log.Error("ChangePassword: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("ChangePassword: password has been successfully changed")
+	c.JSON(http.StatusOK, DefaultResponse{"password has been successfully changed"})
+}
+
+// RequestPasswordReset ...
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("RequestPasswordReset: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.RequestPasswordReset(tx, req.Name); err != nil {
+		h.log.Error("RequestPasswordReset: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("RequestPasswordReset: password reset has been requested")
+	c.JSON(http.StatusOK, DefaultResponse{"if the user exists, a password reset token has been sent"})
+}
+
+// ResetPassword ...
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("ResetPassword: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	err = h.authService.ResetPassword(tx, req.Token, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidResetToken):
+			h.log.Error("ResetPassword: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid or expired password reset token"})
+		case isPasswordPolicyError(err):
+			h.log.Error("ResetPassword: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{err.Error()})
+		default:
+			h.log.Error("ResetPassword: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("ResetPassword: password has been successfully reset")
+	c.JSON(http.StatusOK, DefaultResponse{"password has been successfully reset"})
+}
+
+func isPasswordPolicyError(err error) bool {
+	return errors.Is(err, auth.ErrPasswordTooShort) ||
+		errors.Is(err, auth.ErrPasswordTooLong) ||
+		errors.Is(err, auth.ErrPasswordBreached)
+}
@@ -0,0 +1,168 @@
+package authhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// FindProfile ...
+func (h *AuthHandler) FindProfile(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	profile, err := h.authService.FindProfile(tx, username.(string))
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			h.log.Error("FindProfile: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+			return
+		}
+
+		h.log.Error("FindProfile: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("FindProfile: profile has been successfully received")
+	c.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+// UpdateProfile ...
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req ProfileRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("UpdateProfile: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	profile, err := h.authService.UpdateProfile(tx, username.(string), auth.Profile{
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Timezone:    req.Timezone,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidEmail), errors.Is(err, auth.ErrInvalidTimezone):
+			h.log.Error("UpdateProfile: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{err.Error()})
+		case errors.Is(err, auth.ErrUserNotFound):
+			h.log.Error("UpdateProfile: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+		default:
+			h.log.Error("UpdateProfile: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("UpdateProfile: profile has been successfully updated")
+	c.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+// DeleteAccount ...
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("DeleteAccount: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	publish, err := h.authService.DeleteAccount(tx, username.(string), req.Password, auth.ProductsAction(req.Products), req.TransferTo)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrIncorrectPassword):
+			h.log.Error("DeleteAccount: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"incorrect password"})
+		case errors.Is(err, auth.ErrPasswordNotSet):
+			h.log.Error("DeleteAccount: " + err.Error())
+			c.JSON(http.StatusForbidden, DefaultResponse{err.Error()})
+		case errors.Is(err, auth.ErrInvalidProductsAction), errors.Is(err, auth.ErrInvalidTransferTarget):
+			h.log.Error("DeleteAccount: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{err.Error()})
+		case errors.Is(err, auth.ErrUserNotFound):
+			h.log.Error("DeleteAccount: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+		default:
+			h.log.Error("DeleteAccount: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	publish()
+
+	h.log.Info("DeleteAccount: account has been successfully deleted")
+	c.JSON(http.StatusOK, DefaultResponse{"account has been successfully deleted"})
+}
+
+func newProfileResponse(profile auth.Profile) ProfileResponse {
+	return ProfileResponse{
+		ID:          profile.ID,
+		Name:        profile.Name,
+		DisplayName: profile.DisplayName,
+		Email:       profile.Email,
+		Timezone:    profile.Timezone,
+	}
+}
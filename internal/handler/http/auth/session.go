@@ -0,0 +1,113 @@
+package authhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// FindSessionList ...
+func (h *AuthHandler) FindSessionList(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	sessions, err := h.authService.FindSessionList(tx, username.(string))
+	if err != nil {
+		h.log.Error("FindSessionList: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	current := c.GetString(middleware.SessionContext)
+
+	sessionsResponse := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		sessionsResponse = append(sessionsResponse, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == current,
+		})
+	}
+
+	h.log.Info("FindSessionList: sessions has been successfully received")
+	c.JSON(http.StatusOK, sessionsResponse)
+}
+
+// DeleteSession ...
+func (h *AuthHandler) DeleteSession(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.DeleteSession(tx, username.(string), c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			h.log.Error("DeleteSession: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"session not found"})
+			return
+		}
+
+		h.log.Error("DeleteSession: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("DeleteSession: session has been successfully deleted")
+	c.JSON(http.StatusOK, DefaultResponse{"session has been successfully deleted"})
+}
+
+// CreateStreamToken ...
+func (h *AuthHandler) CreateStreamToken(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	token, expiresAt, err := h.authService.CreateStreamToken(username.(string), c.GetString(middleware.SessionContext))
+	if err != nil {
+		h.log.Error("CreateStreamToken: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("CreateStreamToken: stream token has been successfully created")
+	c.JSON(http.StatusOK, StreamTokenResponse{Token: token, ExpiresAt: expiresAt})
+}
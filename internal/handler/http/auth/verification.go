@@ -0,0 +1,94 @@
+package authhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// VerifyEmail activates account by the token from verification link
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		h.log.Error("VerifyEmail: empty token param")
+		c.JSON(http.StatusBadRequest, DefaultResponse{"empty token param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.VerifyEmail(tx, token); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidVerificationToken):
+			h.log.Error("VerifyEmail: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid or expired verification link"})
+		default:
+			h.log.Error("VerifyEmail: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("VerifyEmail: email has been successfully verified")
+	c.JSON(http.StatusOK, DefaultResponse{"email has been successfully verified"})
+}
+
+// ResendVerification sends new verification link to the user
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.authService.ResendVerification(tx, username.(string)); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrEmailAlreadyVerified):
+			h.log.Error("ResendVerification: " + err.Error())
+			c.JSON(http.StatusConflict, DefaultResponse{"email is already verified"})
+		case errors.Is(err, auth.ErrInvalidEmail):
+			h.log.Error("ResendVerification: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid email address, update it in the profile"})
+		case errors.Is(err, auth.ErrUserNotFound):
+			h.log.Error("ResendVerification: " + err.Error())
+			c.JSON(http.StatusNotFound, DefaultResponse{"user not found"})
+		default:
+			h.log.Error("ResendVerification: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("ResendVerification: verification link has been sent")
+	c.JSON(http.StatusOK, DefaultResponse{"verification link has been sent"})
+}
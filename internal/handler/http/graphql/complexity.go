@@ -0,0 +1,102 @@
+package graphqlhttphandler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// limitArgument page size argument of paginated fields
+const limitArgument = "limit"
+
+// paginatedFields fields returning a page of limit items
+var paginatedFields = map[string]bool{
+	productsField: true,
+}
+
+// measure returns complexity and depth of the selection set at depth.
+// Every field costs 1, fields selected under a paginated field are counted page size times.
+// Introspection fields are not counted, their size is bounded by the schema
+func measure(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, vars map[string]any, depth int) (int, int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+
+	complexity, maxDepth := 0, depth-1
+	for _, selection := range set.Selections {
+		var (
+			cost     int
+			subDepth int
+		)
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+
+			cost, subDepth = measure(selection.SelectionSet, fragments, vars, depth+1)
+			cost = 1 + cost*listSize(selection, vars)
+			subDepth = max(subDepth, depth)
+		case *ast.InlineFragment:
+			cost, subDepth = measure(selection.SelectionSet, fragments, vars, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			cost, subDepth = measure(fragment.SelectionSet, fragments, vars, depth)
+		}
+
+		complexity += cost
+		maxDepth = max(maxDepth, subDepth)
+	}
+
+	return complexity, maxDepth
+}
+
+// listSize page size of paginated field, 1 for other fields
+func listSize(field *ast.Field, vars map[string]any) int {
+	if !paginatedFields[field.Name.Value] {
+		return 1
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != limitArgument {
+			continue
+		}
+
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				return pageSize(n)
+			}
+		case *ast.Variable:
+			// json numbers are decoded as float64, default value of the variable is not known here
+			if n, ok := vars[value.Name.Value].(float64); ok {
+				return pageSize(int(n))
+			}
+			return maxPageSize
+		}
+	}
+
+	return defaultPageSize
+}
+
+// pageSize limit clamped to allowed range, invalid limits are rejected by resolvers
+func pageSize(limit int) int {
+	return min(max(limit, 1), maxPageSize)
+}
+
+// fragments fragment definitions of the document by name
+func fragments(doc *ast.Document) map[string]*ast.FragmentDefinition {
+	defs := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			defs[fragment.Name.Value] = fragment
+		}
+	}
+
+	return defs
+}
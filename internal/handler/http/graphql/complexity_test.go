@@ -0,0 +1,206 @@
+package graphqlhttphandler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+
+	graphqlhttphandler "github.com/fallra1n/product-keeper/internal/handler/http/graphql"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+	"github.com/fallra1n/product-keeper/pkg/logging"
+)
+
+type RunComplexitySuite struct {
+	suite.Suite
+	log *slog.Logger
+}
+
+func TestRunComplexitySuite(t *testing.T) {
+	suite.Run(t, new(RunComplexitySuite))
+}
+
+func (s *RunComplexitySuite) SetupTest() {
+	s.log = logging.SetupLogger("local")
+	gin.SetMode(gin.TestMode)
+}
+
+// rejection sends the query to a handler with settings every query exceeds
+// and returns the message of the rejected query
+func (s *RunComplexitySuite) rejection(settings graphqlhttphandler.Settings, query string, vars map[string]any) string {
+	handler, err := graphqlhttphandler.NewGraphQLHandler(s.log, nil, nil, settings)
+	s.Require().NoError(err)
+
+	router := gin.New()
+	router.POST("/graphql", func(c *gin.Context) {
+		c.Set(middleware.UserContext, "test name")
+	}, handler.Query)
+
+	body, err := json.Marshal(graphqlhttphandler.Request{Query: query, Variables: vars})
+	s.Require().NoError(err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	s.Require().Equal(http.StatusBadRequest, w.Code, w.Body.String())
+
+	var res struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	s.Require().NoError(json.Unmarshal(w.Body.Bytes(), &res))
+	s.Require().Len(res.Errors, 1)
+
+	return res.Errors[0].Message
+}
+
+// measure complexity and depth of the query reported by the handler
+func (s *RunComplexitySuite) measure(query string, vars map[string]any) (int, int) {
+	var complexity, depth, limit int
+
+	message := s.rejection(graphqlhttphandler.Settings{MaxComplexity: -1, MaxDepth: math.MaxInt}, query, vars)
+	_, err := fmt.Sscanf(message, "query complexity %d exceeds limit %d", &complexity, &limit)
+	s.Require().NoError(err, message)
+
+	message = s.rejection(graphqlhttphandler.Settings{MaxComplexity: math.MaxInt, MaxDepth: -1}, query, vars)
+	_, err = fmt.Sscanf(message, "query depth %d exceeds limit %d", &depth, &limit)
+	s.Require().NoError(err, message)
+
+	return complexity, depth
+}
+
+func (s *RunComplexitySuite) TestMeasure() {
+	testList := []struct {
+		name       string
+		query      string
+		vars       map[string]any
+		complexity int
+		depth      int
+	}{
+		{
+			name:       "plain fields",
+			query:      `{ product(id: "1") { id name price } }`,
+			complexity: 4,
+			depth:      2,
+		},
+		{
+			name:  "default page size",
+			query: `{ products { items { id } totalCount } }`,
+			// 1 + (items 2 + totalCount 1) * 20
+			complexity: 61,
+			depth:      3,
+		},
+		{
+			name:       "limit multiplies nested fields",
+			query:      `{ products(limit: 5) { items { id name } } }`,
+			complexity: 16,
+			depth:      3,
+		},
+		{
+			name:       "limit above the maximum is clamped",
+			query:      `{ products(limit: 1000) { items { id } } }`,
+			complexity: 201,
+			depth:      3,
+		},
+		{
+			name:       "limit below the minimum is clamped",
+			query:      `{ products(limit: -5) { items { id } } }`,
+			complexity: 3,
+			depth:      3,
+		},
+		{
+			name:       "limit from variable",
+			query:      `query Page($limit: Int) { products(limit: $limit) { items { id } } }`,
+			vars:       map[string]any{"limit": 3},
+			complexity: 7,
+			depth:      3,
+		},
+		{
+			name:  "variable without value",
+			query: `query Page($limit: Int = 1) { products(limit: $limit) { items { id } } }`,
+			// default value of the variable is not known, the largest page is assumed
+			complexity: 201,
+			depth:      3,
+		},
+		{
+			name: "aliases are counted separately",
+			query: `{
+				first: products(limit: 2) { items { id } }
+				second: products(limit: 3) { items { id } }
+				one: product(id: "1") { id }
+			}`,
+			complexity: 5 + 7 + 2,
+			depth:      3,
+		},
+		{
+			name: "fragment spreads",
+			query: `
+				query { products(limit: 2) { ...page } }
+				fragment page on ProductPage { items { ...fields } }
+				fragment fields on Product { id name }
+			`,
+			complexity: 7,
+			depth:      3,
+		},
+		{
+			name:       "inline fragments",
+			query:      `{ products(limit: 2) { ... on ProductPage { items { ... on Product { id name } } } } }`,
+			complexity: 7,
+			depth:      3,
+		},
+		{
+			name:       "introspection fields are not counted",
+			query:      `{ __typename product(id: "1") { __typename id } }`,
+			complexity: 2,
+			depth:      2,
+		},
+		{
+			name:       "mutation",
+			query:      `mutation { createProduct(input: {name: "test", price: 1, quantity: 1}) { id name } }`,
+			complexity: 3,
+			depth:      2,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			complexity, depth := s.measure(row.query, row.vars)
+			s.Equal(row.complexity, complexity)
+			s.Equal(row.depth, depth)
+		})
+	}
+}
+
+func (s *RunComplexitySuite) TestLimits() {
+	const query = `{ products(limit: 5) { items { id name } } }`
+
+	testList := []struct {
+		name     string
+		settings graphqlhttphandler.Settings
+		message  string
+	}{
+		{
+			name:     "too complex",
+			settings: graphqlhttphandler.Settings{MaxComplexity: 15, MaxDepth: 3},
+			message:  "query complexity 16 exceeds limit 15",
+		},
+		{
+			name:     "too deep",
+			settings: graphqlhttphandler.Settings{MaxComplexity: 16, MaxDepth: 2},
+			message:  "query depth 3 exceeds limit 2",
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			s.Equal(row.message, s.rejection(row.settings, query, nil))
+		})
+	}
+}
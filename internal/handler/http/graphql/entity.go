@@ -0,0 +1,59 @@
+package graphqlhttphandler
+
+import "github.com/graphql-go/graphql/gqlerrors"
+
+// Request graphql request of POST body
+type Request struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ErrorResponse request rejected before execution
+type ErrorResponse struct {
+	Errors []gqlerrors.FormattedError `json:"errors"`
+}
+
+// Settings limits of graphql queries
+type Settings struct {
+	MaxComplexity int
+	MaxDepth      int
+}
+
+const (
+	// defaultPageSize products returned without limit argument
+	defaultPageSize = 20
+
+	// maxPageSize the largest limit argument
+	maxPageSize = 100
+)
+
+const (
+	// CodeBadUserInput invalid arguments
+	CodeBadUserInput = "BAD_USER_INPUT"
+	// CodeNotFound ...
+	CodeNotFound = "NOT_FOUND"
+	// CodeForbidden ...
+	CodeForbidden = "FORBIDDEN"
+	// CodeInternal ...
+	CodeInternal = "INTERNAL_SERVER_ERROR"
+)
+
+// resolverError error of a field with code in extensions
+type resolverError struct {
+	code    string
+	message string
+}
+
+func (e resolverError) Error() string {
+	return e.message
+}
+
+// Extensions ...
+func (e resolverError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+type contextKey int
+
+const userContext contextKey = iota
@@ -0,0 +1,160 @@
+package graphqlhttphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// GraphQLHandler ...
+type GraphQLHandler struct {
+	log *slog.Logger
+	db  *sqlx.DB
+
+	productsService *products.ProductsService
+
+	settings Settings
+	schema   graphql.Schema
+}
+
+// NewGraphQLHandler constructor for GraphQLHandler
+func NewGraphQLHandler(log *slog.Logger, db *sqlx.DB, productsService *products.ProductsService, settings Settings) (*GraphQLHandler, error) {
+	h := &GraphQLHandler{
+		log: log,
+		db:  db,
+
+		productsService: productsService,
+
+		settings: settings,
+	}
+
+	schema, err := h.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	h.schema = schema
+
+	return h, nil
+}
+
+// Query executes graphql query or mutation of the user,
+// the query is rejected before execution if it is invalid or exceeds complexity limits
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req Request
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("GraphQL: " + err.Error())
+		c.JSON(http.StatusBadRequest, errorResponse("failed to decode request"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		h.log.Error("GraphQL: " + err.Error())
+		c.JSON(http.StatusBadRequest, ErrorResponse{gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	if res := graphql.ValidateDocument(&h.schema, doc, nil); !res.IsValid {
+		h.log.Error("GraphQL: invalid query", "errors", len(res.Errors))
+		c.JSON(http.StatusBadRequest, ErrorResponse{res.Errors})
+		return
+	}
+
+	op, err := findOperation(doc, req.OperationName)
+	if err != nil {
+		h.log.Error("GraphQL: " + err.Error())
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+
+	complexity, depth := measure(op.SelectionSet, fragments(doc), req.Variables, 1)
+	if complexity > h.settings.MaxComplexity {
+		h.log.Error("GraphQL: query is too complex", "complexity", complexity)
+		c.JSON(http.StatusBadRequest, errorResponse(fmt.Sprintf("query complexity %d exceeds limit %d", complexity, h.settings.MaxComplexity)))
+		return
+	}
+
+	if depth > h.settings.MaxDepth {
+		h.log.Error("GraphQL: query is too deep", "depth", depth)
+		c.JSON(http.StatusBadRequest, errorResponse(fmt.Sprintf("query depth %d exceeds limit %d", depth, h.settings.MaxDepth)))
+		return
+	}
+
+	// route requires read scope, api keys need write scope for mutations
+	if scopes, ok := c.Get(middleware.ScopesContext); ok && op.Operation == ast.OperationTypeMutation {
+		if !slices.Contains(scopes.([]string), auth.ScopeProductsWrite) {
+			c.JSON(http.StatusForbidden, errorResponse("api key does not have scope "+auth.ScopeProductsWrite))
+			return
+		}
+	}
+
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+	user.Trace = middleware.Trace(c)
+
+	res := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(c.Request.Context(), userContext, user),
+	})
+
+	h.log.Info("GraphQL: query has been executed", "operation", op.Operation, "errors", len(res.Errors))
+	c.JSON(http.StatusOK, res)
+}
+
+// findOperation operation of the document to execute, name is required if there are several operations
+func findOperation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if name == "" {
+			if found != nil {
+				return nil, errors.New("operationName is required for document with several operations")
+			}
+			found = op
+			continue
+		}
+
+		if op.Name != nil && op.Name.Value == name {
+			return op, nil
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+
+	return found, nil
+}
+
+func errorResponse(message string) ErrorResponse {
+	return ErrorResponse{gqlerrors.FormatErrors(errors.New(message))}
+}
@@ -0,0 +1,398 @@
+package graphqlhttphandler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+)
+
+const (
+	productField  = "product"
+	productsField = "products"
+)
+
+// newSchema schema of products queries and mutations, resolvers call products service
+// in a transaction per field like http handlers
+func (h *GraphQLHandler) newSchema() (graphql.Schema, error) {
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"price":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"quantity":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Number of items in stock"},
+			"inStock":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"ownerName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"createdAt": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	productPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductPage",
+		Fields: graphql.Fields{
+			"items":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(productType)))},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Number of products matching the filter"},
+		},
+	})
+
+	sortByType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "SortBy",
+		Values: graphql.EnumValueConfigMap{
+			"LAST_CREATE": &graphql.EnumValueConfig{Value: string(products.LastCreate)},
+			"NAME":        &graphql.EnumValueConfig{Value: string(products.Name)},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"nameContains": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Case-insensitive substring of the name"},
+			"minPrice":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"maxPrice":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"inStock":      &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+
+	productInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"price":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+			"quantity": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			productField: &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: h.resolveProduct,
+			},
+			productsField: &graphql.Field{
+				Type:        graphql.NewNonNull(productPageType),
+				Description: fmt.Sprintf("Products of the user, limit is from 1 to %d", maxPageSize),
+				Args: graphql.FieldConfigArgument{
+					"filter":      &graphql.ArgumentConfig{Type: filterType},
+					"sortBy":      &graphql.ArgumentConfig{Type: sortByType},
+					limitArgument: &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"offset":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: h.resolveProducts,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInputType)},
+				},
+				Resolve: h.resolveCreateProduct,
+			},
+			"updateProduct": &graphql.Field{
+				Type: graphql.NewNonNull(productType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(productInputType)},
+				},
+				Resolve: h.resolveUpdateProduct,
+			},
+			"deleteProduct": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: h.resolveDeleteProduct,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+// resolveProduct ...
+func (h *GraphQLHandler) resolveProduct(p graphql.ResolveParams) (any, error) {
+	user := p.Context.Value(userContext).(shared.Subject)
+
+	id, err := idArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, errInternal
+	}
+	defer tx.Rollback()
+
+	product, err := h.productsService.FindProduct(tx, id, user)
+	if err != nil {
+		h.log.Error("GraphQL product: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, errInternal
+	}
+
+	return productResult(product), nil
+}
+
+// resolveProducts ...
+func (h *GraphQLHandler) resolveProducts(p graphql.ResolveParams) (any, error) {
+	user := p.Context.Value(userContext).(shared.Subject)
+
+	filter, err := filterArguments(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, errInternal
+	}
+	defer tx.Rollback()
+
+	page, err := h.productsService.FindProductPage(tx, user.Name, filter)
+	if err != nil {
+		h.log.Error("GraphQL products: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, errInternal
+	}
+
+	items := make([]map[string]any, 0, len(page.Products))
+	for _, product := range page.Products {
+		items = append(items, productResult(product))
+	}
+
+	return map[string]any{
+		"items":      items,
+		"totalCount": page.Total,
+	}, nil
+}
+
+// resolveCreateProduct ...
+func (h *GraphQLHandler) resolveCreateProduct(p graphql.ResolveParams) (any, error) {
+	user := p.Context.Value(userContext).(shared.Subject)
+
+	product, err := productInput(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	product.OwnerName = user.Name
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, errInternal
+	}
+	defer tx.Rollback()
+
+	id, events, err := h.productsService.CreateProduct(tx, user, product)
+	if err != nil {
+		h.log.Error("GraphQL createProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	// created product is returned without view event
+	created, err := h.productsService.FindAnyProduct(tx, id)
+	if err != nil {
+		h.log.Error("GraphQL createProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, errInternal
+	}
+
+	h.productsService.PublishEvents(events)
+
+	h.log.Info("GraphQL createProduct: product has been successfully created")
+	return productResult(created), nil
+}
+
+// resolveUpdateProduct ...
+func (h *GraphQLHandler) resolveUpdateProduct(p graphql.ResolveParams) (any, error) {
+	user := p.Context.Value(userContext).(shared.Subject)
+
+	id, err := idArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := productInput(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	product.ID = id
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, errInternal
+	}
+	defer tx.Rollback()
+
+	updated, events, err := h.productsService.UpdateProduct(tx, user, product)
+	if err != nil {
+		h.log.Error("GraphQL updateProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, errInternal
+	}
+
+	h.productsService.PublishEvents(events)
+
+	h.log.Info("GraphQL updateProduct: product data has been successfully updated")
+	return productResult(updated), nil
+}
+
+// resolveDeleteProduct ...
+func (h *GraphQLHandler) resolveDeleteProduct(p graphql.ResolveParams) (any, error) {
+	user := p.Context.Value(userContext).(shared.Subject)
+
+	id, err := idArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		return nil, errInternal
+	}
+	defer tx.Rollback()
+
+	events, err := h.productsService.DeleteProduct(tx, id, user)
+	if err != nil {
+		h.log.Error("GraphQL deleteProduct: " + err.Error())
+		return nil, productsError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		return nil, errInternal
+	}
+
+	h.productsService.PublishEvents(events)
+
+	h.log.Info("GraphQL deleteProduct: product has been successfully deleted")
+	return true, nil
+}
+
+var errInternal = resolverError{CodeInternal, "internal error"}
+
+// productsError resolver error of products service error
+func productsError(err error) error {
+	switch {
+	case errors.Is(err, products.ErrProductNotFound):
+		return resolverError{CodeNotFound, "product with such id does not exist"}
+	case errors.Is(err, products.ErrPermissionDenied):
+		return resolverError{CodeForbidden, "permission denied"}
+	default:
+		return errInternal
+	}
+}
+
+func idArgument(args map[string]any) (uint64, error) {
+	id, err := strconv.ParseUint(args["id"].(string), 10, 64)
+	if err != nil {
+		return 0, resolverError{CodeBadUserInput, "invalid id"}
+	}
+
+	return id, nil
+}
+
+// productInput product of input argument, the same fields are required as in http api
+func productInput(args map[string]any) (products.Product, error) {
+	input := args["input"].(map[string]any)
+
+	name := input["name"].(string)
+	price := input["price"].(int)
+	quantity := input["quantity"].(int)
+
+	if name == "" || price <= 0 || quantity <= 0 {
+		return products.Product{}, resolverError{CodeBadUserInput, "name, positive price and quantity are required"}
+	}
+
+	return products.Product{
+		Name:     name,
+		Price:    uint64(price),
+		Quantity: uint64(quantity),
+	}, nil
+}
+
+// filterArguments filter and page of products field
+func filterArguments(args map[string]any) (products.ProductFilter, error) {
+	limit, _ := args[limitArgument].(int)
+	offset, _ := args["offset"].(int)
+	if limit < 1 || limit > maxPageSize || offset < 0 {
+		return products.ProductFilter{}, resolverError{CodeBadUserInput, fmt.Sprintf("limit must be from 1 to %d and offset must not be negative", maxPageSize)}
+	}
+
+	filter := products.ProductFilter{
+		Limit:  uint64(limit),
+		Offset: uint64(offset),
+	}
+
+	if sortBy, ok := args["sortBy"].(string); ok {
+		filter.SortBy = products.SortType(sortBy)
+	}
+
+	input, ok := args["filter"].(map[string]any)
+	if !ok {
+		return filter, nil
+	}
+
+	filter.Name, _ = input["nameContains"].(string)
+	filter.InStock, _ = input["inStock"].(bool)
+
+	minPrice, _ := input["minPrice"].(int)
+	maxPrice, _ := input["maxPrice"].(int)
+	if minPrice < 0 || maxPrice < 0 {
+		return products.ProductFilter{}, resolverError{CodeBadUserInput, "price must not be negative"}
+	}
+	filter.MinPrice = uint64(minPrice)
+	filter.MaxPrice = uint64(maxPrice)
+
+	return filter, nil
+}
+
+// productResult product fields of the schema
+func productResult(product products.Product) map[string]any {
+	return map[string]any{
+		"id":        strconv.FormatUint(product.ID, 10),
+		"name":      product.Name,
+		"price":     product.Price,
+		"quantity":  product.Quantity,
+		"inStock":   product.Quantity > 0,
+		"ownerName": product.OwnerName,
+		"createdAt": product.CreatedAt,
+	}
+}
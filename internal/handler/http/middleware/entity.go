@@ -3,8 +3,26 @@ package middleware
 const (
 	// AuthHeader ...
 	AuthHeader = "Authorization"
+	// APIKeyHeader ...
+	APIKeyHeader = "X-API-Key"
+	// StreamTokenQuery stream token for clients unable to set headers, e.g. browser EventSource and WebSocket
+	StreamTokenQuery = "stream_token"
+	// RequestIDHeader request id, generated if the client has not set it
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader w3c trace context
+	TraceparentHeader = "traceparent"
 	// UserContext ...
 	UserContext = "username"
+	// RoleContext ...
+	RoleContext = "role"
+	// SessionContext session id, set only for requests authorized by jwt token
+	SessionContext = "session"
+	// ScopesContext api key scopes, set only for requests authorized by api key
+	ScopesContext = "scopes"
+	// RequestIDContext ...
+	RequestIDContext = "request_id"
+	// TraceIDContext ...
+	TraceIDContext = "trace_id"
 )
 
 // DefaultResponse ...
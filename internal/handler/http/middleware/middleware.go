@@ -1,17 +1,65 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
 
-	"github.com/fallra1n/product-keeper/pkg/jwt"
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/pkg/tracing"
 )
 
-// UserIdentity ...
-func UserIdentity() gin.HandlerFunc {
+// RequestTrace sets request id from X-Request-ID header and trace id from traceparent header,
+// missing or invalid ids are generated. Request id is returned in X-Request-ID response header
+func RequestTrace(log *slog.Logger, ids shared.IDGenerator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !tracing.ValidRequestID(requestID) {
+			id, err := ids.NewID()
+			if err != nil {
+				log.Error("RequestTrace: " + err.Error())
+			}
+			requestID = id
+		}
+
+		traceID, ok := tracing.ParseTraceparent(c.GetHeader(TraceparentHeader))
+		if !ok {
+			id, err := ids.NewID()
+			if err != nil {
+				log.Error("RequestTrace: " + err.Error())
+			}
+			traceID = strings.ReplaceAll(id, "-", "")
+		}
+
+		c.Set(RequestIDContext, requestID)
+		c.Set(TraceIDContext, traceID)
+		c.Header(RequestIDHeader, requestID)
+	}
+}
+
+// Trace ids of the request set by RequestTrace
+func Trace(c *gin.Context) shared.Trace {
+	return shared.Trace{
+		TraceID:   c.GetString(TraceIDContext),
+		RequestID: c.GetString(RequestIDContext),
+	}
+}
+
+// UserIdentity authorizes the request by jwt token or by api key
+func UserIdentity(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			apiKeyIdentity(c, log, db, authService, key)
+			return
+		}
+
 		header := c.GetHeader(AuthHeader)
 		if header == "" {
 			c.JSON(http.StatusUnauthorized, DefaultResponse{"empty auth header"})
@@ -24,12 +72,189 @@ func UserIdentity() gin.HandlerFunc {
 			return
 		}
 
-		username, err := jwt.ParseToken(headerParts[1])
+		tokenIdentity(c, log, db, authService, headerParts[1])
+	}
+}
+
+// StreamIdentity authorizes the request by stream token from query, requests without it are authorized
+// the same way as by UserIdentity
+func StreamIdentity(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService) gin.HandlerFunc {
+	userIdentity := UserIdentity(log, db, authService)
+
+	return func(c *gin.Context) {
+		token := c.Query(StreamTokenQuery)
+		if token == "" {
+			userIdentity(c)
+			return
+		}
+
+		tx, err := db.Beginx()
 		if err != nil {
+			log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+			return
+		}
+		defer tx.Rollback()
+
+		user, err := authService.IdentifyStreamToken(tx, token, c.ClientIP())
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				c.JSON(http.StatusUnauthorized, DefaultResponse{"invalid stream token"})
+				return
+			}
+
+			log.Error("StreamIdentity: " + err.Error())
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+			return
+		}
+
+		c.Set(UserContext, user.Name)
+		c.Set(RoleContext, user.Role)
+	}
+}
+
+func tokenIdentity(c *gin.Context, log *slog.Logger, db *sqlx.DB, authService *auth.AuthService, token string) {
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	user, sessionID, err := authService.Identify(tx, token, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
 			c.JSON(http.StatusUnauthorized, DefaultResponse{"invalid auth token"})
 			return
 		}
 
-		c.Set(UserContext, username)
+		log.Error("UserIdentity: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	c.Set(UserContext, user.Name)
+	c.Set(RoleContext, user.Role)
+	c.Set(SessionContext, sessionID)
+}
+
+func apiKeyIdentity(c *gin.Context, log *slog.Logger, db *sqlx.DB, authService *auth.AuthService, key string) {
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	user, scopes, err := authService.AuthenticateAPIKey(tx, key)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidAPIKey) || errors.Is(err, auth.ErrUserDisabled) {
+			c.JSON(http.StatusUnauthorized, DefaultResponse{"invalid api key"})
+			return
+		}
+
+		log.Error("UserIdentity: " + err.Error())
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	c.Set(UserContext, user.Name)
+	c.Set(RoleContext, user.Role)
+	c.Set(ScopesContext, scopes)
+}
+
+// RequireRole allows the request only if the user role from the token is one of roles,
+// must be used after UserIdentity
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(UserContext); !ok {
+			c.Abort()
+			return
+		}
+
+		role := c.GetString(RoleContext)
+		if !slices.Contains(roles, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, DefaultResponse{"permission denied"})
+			return
+		}
+	}
+}
+
+// RequireScope allows the request authorized by api key only if the key has the scope,
+// requests authorized by jwt token are not limited
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, ok := c.Get(ScopesContext)
+		if !ok {
+			return
+		}
+
+		if !slices.Contains(scopes.([]string), scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, DefaultResponse{"api key does not have scope " + scope})
+			return
+		}
+	}
+}
+
+// RequireToken denies requests authorized by api key
+func RequireToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ScopesContext); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, DefaultResponse{"api keys are not allowed here"})
+			return
+		}
+	}
+}
+
+// RequireVerified denies requests of users whose email is not verified yet,
+// must be used after UserIdentity
+func RequireVerified(log *slog.Logger, db *sqlx.DB, authService *auth.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := c.Get(UserContext)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+			return
+		}
+		defer tx.Rollback()
+
+		if err := authService.CheckVerified(tx, username.(string)); err != nil {
+			switch {
+			case errors.Is(err, auth.ErrEmailNotVerified):
+				c.AbortWithStatusJSON(http.StatusForbidden, DefaultResponse{"email is not verified"})
+			case errors.Is(err, auth.ErrUserNotFound):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, DefaultResponse{"user not found"})
+			default:
+				log.Error("RequireVerified: " + err.Error())
+				c.AbortWithStatusJSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+			}
+			return
+		}
 	}
 }
@@ -6,6 +6,27 @@ import "github.com/gin-gonic/gin"
 type AuthHandler interface {
 	UserRegister(c *gin.Context)
 	UserLogin(c *gin.Context)
+	LoginMFA(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	RequestPasswordReset(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	FindProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	DeleteAccount(c *gin.Context)
+	FindSessionList(c *gin.Context)
+	DeleteSession(c *gin.Context)
+	CreateStreamToken(c *gin.Context)
+	OIDCLogin(c *gin.Context)
+	OIDCCallback(c *gin.Context)
+	CreateAPIKey(c *gin.Context)
+	FindAPIKeyList(c *gin.Context)
+	RevokeAPIKey(c *gin.Context)
+	SetupTOTP(c *gin.Context)
+	VerifyTOTP(c *gin.Context)
+	DisableTOTP(c *gin.Context)
+	JWKS(c *gin.Context)
 }
 
 // ProductsHandler ...
@@ -15,4 +36,37 @@ type ProductsHandler interface {
 	UpdateProduct(c *gin.Context)
 	DeleteProduct(c *gin.Context)
 	FindProductList(c *gin.Context)
+	StreamProducts(c *gin.Context)
+	StreamProductsWS(c *gin.Context)
+}
+
+// StatisticsHandler ...
+type StatisticsHandler interface {
+	FindProductViews(c *gin.Context)
+	FindTopViewed(c *gin.Context)
+}
+
+// WebhooksHandler ...
+type WebhooksHandler interface {
+	CreateWebhook(c *gin.Context)
+	FindWebhookList(c *gin.Context)
+	FindWebhook(c *gin.Context)
+	UpdateWebhook(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	FindDeliveryList(c *gin.Context)
+	FindDelivery(c *gin.Context)
+	RetryDelivery(c *gin.Context)
+}
+
+// GraphQLHandler ...
+type GraphQLHandler interface {
+	Query(c *gin.Context)
+}
+
+// AdminHandler ...
+type AdminHandler interface {
+	FindUserList(c *gin.Context)
+	DisableUser(c *gin.Context)
+	UnlockUser(c *gin.Context)
+	FindProduct(c *gin.Context)
 }
@@ -22,3 +22,15 @@ type ProductResponse struct {
 	Quantity  uint64    `json:"quantity" binding:"required"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// StreamSettings parameters of product change streams
+type StreamSettings struct {
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+}
+
+// StreamResetMessage sent to websocket stream when events after the last received one are lost,
+// the client should reload its products
+type StreamResetMessage struct {
+	Type string `json:"type"`
+}
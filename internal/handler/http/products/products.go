@@ -11,6 +11,7 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/shared"
 	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
 )
 
@@ -20,15 +21,19 @@ type ProductsHandler struct {
 	db  *sqlx.DB
 
 	productsService *products.ProductsService
+
+	stream StreamSettings
 }
 
 // NewProductsHandler constructor for ProductsHandler
-func NewProductsHandler(log *slog.Logger, db *sqlx.DB, productsService *products.ProductsService) *ProductsHandler {
+func NewProductsHandler(log *slog.Logger, db *sqlx.DB, productsService *products.ProductsService, stream StreamSettings) *ProductsHandler {
 	return &ProductsHandler{
 		log: log,
 		db:  db,
 
 		productsService: productsService,
+
+		stream: stream,
 	}
 }
 
@@ -54,7 +59,10 @@ func (h *ProductsHandler) CreateProduct(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	id, err := h.productsService.CreateProduct(tx, products.Product{
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+	user.Trace = middleware.Trace(c)
+
+	id, events, err := h.productsService.CreateProduct(tx, user, products.Product{
 		Name:      req.Name,
 		Price:     req.Price,
 		Quantity:  req.Quantity,
@@ -72,6 +80,8 @@ func (h *ProductsHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	h.productsService.PublishEvents(events)
+
 	h.log.Info("CreateProduct: product has been successfully created")
 	c.JSON(http.StatusCreated, map[string]any{
 		"product_id": id,
@@ -100,7 +110,10 @@ func (h *ProductsHandler) FindProduct(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	product, err := h.productsService.FindProduct(tx, id, username.(string))
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+	user.Trace = middleware.Trace(c)
+
+	product, err := h.productsService.FindProduct(tx, id, user)
 	if err != nil {
 		if errors.Is(err, products.ErrProductNotFound) {
 			h.log.Error("GetProductByID: " + err.Error())
@@ -164,12 +177,14 @@ func (h *ProductsHandler) UpdateProduct(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	updated, err := h.productsService.UpdateProduct(tx, products.Product{
-		ID:        id,
-		Name:      req.Name,
-		Price:     req.Price,
-		Quantity:  req.Quantity,
-		OwnerName: username.(string),
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+	user.Trace = middleware.Trace(c)
+
+	updated, events, err := h.productsService.UpdateProduct(tx, user, products.Product{
+		ID:       id,
+		Name:     req.Name,
+		Price:    req.Price,
+		Quantity: req.Quantity,
 	})
 	if err != nil {
 		if errors.Is(err, products.ErrProductNotFound) {
@@ -195,6 +210,8 @@ func (h *ProductsHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	h.productsService.PublishEvents(events)
+
 	h.log.Info("UpdateProductByID: product data has been successfully updated")
 	c.JSON(http.StatusOK, ProductResponse{
 		ID:       updated.ID,
@@ -226,7 +243,11 @@ func (h *ProductsHandler) DeleteProduct(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	if err := h.productsService.DeleteProduct(tx, id, username.(string)); err != nil {
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+	user.Trace = middleware.Trace(c)
+
+	events, err := h.productsService.DeleteProduct(tx, id, user)
+	if err != nil {
 		if errors.Is(err, products.ErrProductNotFound) {
 			h.log.Error("DeleteProductByID: " + err.Error())
 			c.JSON(http.StatusNotFound, DefaultResponse{"product with such id does not exist"})
@@ -250,6 +271,8 @@ func (h *ProductsHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
+	h.productsService.PublishEvents(events)
+
 	h.log.Info("DeleteProductByID: product has been successfully deleted")
 	c.JSON(http.StatusOK, DefaultResponse{"product has been successfully deleted"})
 }
@@ -0,0 +1,211 @@
+package productshttphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+const (
+	// lastEventIDHeader id of the last received event, sent by EventSource on reconnect
+	lastEventIDHeader = "Last-Event-ID"
+
+	// lastEventIDQuery id of the last received event for clients unable to set headers
+	lastEventIDQuery = "last_event_id"
+
+	// streamReset event sent when events after the last received one are lost
+	streamReset = "reset"
+
+	// streamRetry reconnection delay suggested to EventSource clients in milliseconds
+	streamRetry = 3000
+)
+
+// upgrader rejects cross-origin websocket requests
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// StreamProducts streams changes of the user products as server-sent events
+func (h *ProductsHandler) StreamProducts(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	sub, missed, reset := h.productsService.SubscribeProducts(username.(string), lastEventID(c))
+	defer h.productsService.UnsubscribeProducts(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// stream outlives server write timeout, deadline is moved before every write
+	rc := http.NewResponseController(c.Writer)
+	write := func(format string, args ...any) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(h.stream.WriteTimeout)); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(c.Writer, format, args...); err != nil {
+			return err
+		}
+
+		return rc.Flush()
+	}
+
+	writeEvent := func(event products.StreamEvent) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		return write("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	}
+
+	if err := write("retry: %d\n\n", streamRetry); err != nil {
+		h.log.Error("StreamProducts: " + err.Error())
+		return
+	}
+
+	if reset {
+		if err := write("event: %s\ndata: {}\n\n", streamReset); err != nil {
+			h.log.Error("StreamProducts: " + err.Error())
+			return
+		}
+	}
+
+	for _, event := range missed {
+		if err := writeEvent(event); err != nil {
+			h.log.Error("StreamProducts: " + err.Error())
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.stream.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := write(": heartbeat\n\n"); err != nil {
+				h.log.Error("StreamProducts: " + err.Error())
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				h.log.Info("StreamProducts: stream has been closed", "username", username)
+				return
+			}
+
+			if err := writeEvent(event); err != nil {
+				h.log.Error("StreamProducts: " + err.Error())
+				return
+			}
+		}
+	}
+}
+
+// StreamProductsWS streams changes of the user products over websocket,
+// heartbeat is sent as ping frames and connection is closed if pongs stop coming
+func (h *ProductsHandler) StreamProductsWS(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	// upgrader responds with error itself
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.log.Error("StreamProductsWS: " + err.Error())
+		return
+	}
+	defer conn.Close()
+
+	sub, missed, reset := h.productsService.SubscribeProducts(username.(string), lastEventID(c))
+	defer h.productsService.UnsubscribeProducts(sub)
+
+	// client messages are not expected, reading handles pongs and close frames
+	closed := make(chan struct{})
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(2 * h.stream.Heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.stream.Heartbeat))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(v any) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(h.stream.WriteTimeout)); err != nil {
+			return err
+		}
+
+		return conn.WriteJSON(v)
+	}
+
+	if reset {
+		if err := write(StreamResetMessage{Type: streamReset}); err != nil {
+			h.log.Error("StreamProductsWS: " + err.Error())
+			return
+		}
+	}
+
+	for _, event := range missed {
+		if err := write(event); err != nil {
+			h.log.Error("StreamProductsWS: " + err.Error())
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.stream.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.stream.WriteTimeout)); err != nil {
+				h.log.Error("StreamProductsWS: " + err.Error())
+				return
+			}
+		case event, ok := <-sub.Events:
+			if !ok {
+				h.log.Info("StreamProductsWS: stream has been closed", "username", username)
+				msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "stream closed, reconnect with last_event_id")
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(h.stream.WriteTimeout))
+				return
+			}
+
+			if err := write(event); err != nil {
+				h.log.Error("StreamProductsWS: " + err.Error())
+				return
+			}
+		}
+	}
+}
+
+// lastEventID id of the last event received by the client before reconnect
+func lastEventID(c *gin.Context) string {
+	if id := c.GetHeader(lastEventIDHeader); id != "" {
+		return id
+	}
+
+	return c.Query(lastEventIDQuery)
+}
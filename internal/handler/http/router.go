@@ -3,30 +3,124 @@ package httphandler
 import (
 	"log/slog"
 
-	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
 	"github.com/gin-gonic/gin"
+
+	"github.com/fallra1n/product-keeper/internal/core/auth"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
 )
 
 // SetupRouter ...
-func SetupRouter(log *slog.Logger, auth AuthHandler, productHandlers ProductsHandler) *gin.Engine {
+func SetupRouter(
+	log *slog.Logger,
+	requestTrace gin.HandlerFunc,
+	userIdentity gin.HandlerFunc,
+	streamIdentity gin.HandlerFunc,
+	requireVerified gin.HandlerFunc,
+	authHandlers AuthHandler,
+	productHandlers ProductsHandler,
+	statisticsHandlers StatisticsHandler,
+	webhooksHandlers WebhooksHandler,
+	adminHandlers AdminHandler,
+	graphqlHandlers GraphQLHandler,
+) *gin.Engine {
 	router := gin.Default()
 
+	// server is not behind a proxy, client ip for login throttling is taken from the connection
+	if err := router.SetTrustedProxies(nil); err != nil {
+		log.Error("failed to set trusted proxies: " + err.Error())
+	}
+
 	// TODO using custom logger
 
-	router.POST("/user/register", auth.UserRegister)
-	router.POST("/user/login", auth.UserLogin)
+	router.Use(requestTrace)
+
+	router.GET("/.well-known/jwks.json", authHandlers.JWKS)
+
+	router.POST("/user/register", authHandlers.UserRegister)
+	router.POST("/user/login", authHandlers.UserLogin)
+	router.POST("/user/login/2fa", authHandlers.LoginMFA)
+	router.POST("/user/password/reset/request", authHandlers.RequestPasswordReset)
+	router.POST("/user/password/reset", authHandlers.ResetPassword)
+	router.POST("/user/password", userIdentity, middleware.RequireToken(), authHandlers.ChangePassword)
+	router.GET("/user/verify", authHandlers.VerifyEmail)
+	router.POST("/user/verify/resend", userIdentity, middleware.RequireToken(), authHandlers.ResendVerification)
+	router.POST("/user/stream-token", userIdentity, middleware.RequireToken(), authHandlers.CreateStreamToken)
+	router.GET("/user/oidc/login", authHandlers.OIDCLogin)
+	router.GET("/user/oidc/callback", authHandlers.OIDCCallback)
+
+	me := router.Group("/user/me", userIdentity, middleware.RequireToken())
+	{
+		me.GET("", authHandlers.FindProfile)
+		me.PUT("", authHandlers.UpdateProfile)
+		me.DELETE("", authHandlers.DeleteAccount)
+	}
+
+	sessions := router.Group("/user/sessions", userIdentity, middleware.RequireToken())
+	{
+		sessions.GET("", authHandlers.FindSessionList)
+		sessions.DELETE("/:id", authHandlers.DeleteSession)
+	}
+
+	apiKeys := router.Group("/user/api-keys", userIdentity, middleware.RequireToken())
+	{
+		apiKeys.POST("", authHandlers.CreateAPIKey)
+		apiKeys.GET("", authHandlers.FindAPIKeyList)
+		apiKeys.DELETE("/:id", authHandlers.RevokeAPIKey)
+	}
+
+	twoFactor := router.Group("/user/2fa", userIdentity, middleware.RequireToken())
+	{
+		twoFactor.POST("/setup", authHandlers.SetupTOTP)
+		twoFactor.POST("/verify", authHandlers.VerifyTOTP)
+		twoFactor.POST("/disable", authHandlers.DisableTOTP)
+	}
+
+	read := middleware.RequireScope(auth.ScopeProductsRead)
+	write := middleware.RequireScope(auth.ScopeProductsWrite)
+
+	products := router.Group("/products", userIdentity, requireVerified)
+	{
+		products.GET("", read, productHandlers.FindProductList)
+		products.GET("/top-viewed", read, statisticsHandlers.FindTopViewed)
+	}
+
+	// browser EventSource and WebSocket cannot set headers, so streams also accept stream token in query
+	streams := router.Group("/products", streamIdentity, requireVerified)
+	{
+		streams.GET("/stream", read, productHandlers.StreamProducts)
+		streams.GET("/ws", read, productHandlers.StreamProductsWS)
+	}
+
+	product := router.Group("/product", userIdentity, requireVerified)
+	{
+		product.POST("/add", write, productHandlers.CreateProduct)
+		product.GET("/:id", read, productHandlers.FindProduct)
+		product.PUT("/:id", write, productHandlers.UpdateProduct)
+		product.DELETE("/:id", write, productHandlers.DeleteProduct)
+		product.GET("/:id/stats", read, statisticsHandlers.FindProductViews)
+	}
+
+	// mutations additionally require write scope, checked after the query is parsed
+	router.POST("/graphql", userIdentity, requireVerified, read, graphqlHandlers.Query)
 
-	products := router.Group("/products", middleware.UserIdentity())
+	webhooks := router.Group("/webhooks", userIdentity, requireVerified)
 	{
-		products.GET("", productHandlers.FindProductList)
+		webhooks.POST("", write, webhooksHandlers.CreateWebhook)
+		webhooks.GET("", read, webhooksHandlers.FindWebhookList)
+		webhooks.GET("/:id", read, webhooksHandlers.FindWebhook)
+		webhooks.PUT("/:id", write, webhooksHandlers.UpdateWebhook)
+		webhooks.DELETE("/:id", write, webhooksHandlers.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", read, webhooksHandlers.FindDeliveryList)
+		webhooks.GET("/:id/deliveries/:delivery_id", read, webhooksHandlers.FindDelivery)
+		webhooks.POST("/:id/deliveries/:delivery_id/retry", write, webhooksHandlers.RetryDelivery)
 	}
 
-	product := router.Group("/product", middleware.UserIdentity())
+	admin := router.Group("/admin", userIdentity, middleware.RequireRole(string(auth.RoleAdmin)), middleware.RequireScope(auth.ScopeAdmin))
 	{
-		product.POST("/add", productHandlers.CreateProduct)
-		product.GET("/:id", productHandlers.FindProduct)
-		product.PUT("/:id", productHandlers.UpdateProduct)
-		product.DELETE("/:id", productHandlers.DeleteProduct)
+		admin.GET("/users", adminHandlers.FindUserList)
+		admin.PUT("/users/:name/disable", adminHandlers.DisableUser)
+		admin.PUT("/users/:name/unlock", adminHandlers.UnlockUser)
+		admin.GET("/product/:id", adminHandlers.FindProduct)
 	}
 
 	return router
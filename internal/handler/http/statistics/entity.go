@@ -0,0 +1,15 @@
+package statisticshttphandler
+
+import "github.com/fallra1n/product-keeper/internal/core/statistics"
+
+// DefaultResponse ...
+type DefaultResponse struct {
+	Message string `json:"message"`
+}
+
+// ProductViewsResponse ...
+type ProductViewsResponse struct {
+	ProductID uint64                 `json:"product_id"`
+	Period    statistics.Period      `json:"period"`
+	Views     []statistics.ViewCount `json:"views"`
+}
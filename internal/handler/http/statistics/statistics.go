@@ -0,0 +1,188 @@
+package statisticshttphandler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/shared"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// defaultTopLimit number of top viewed products if limit param is not set
+const defaultTopLimit = 10
+
+// StatisticsHandler ...
+type StatisticsHandler struct {
+	log *slog.Logger
+	db  *sqlx.DB
+
+	statisticsService *statistics.StatisticsService
+}
+
+// NewStatisticsHandler constructor for StatisticsHandler
+func NewStatisticsHandler(log *slog.Logger, db *sqlx.DB, statisticsService *statistics.StatisticsService) *StatisticsHandler {
+	return &StatisticsHandler{
+		log: log,
+		db:  db,
+
+		statisticsService: statisticsService,
+	}
+}
+
+// FindProductViews ...
+func (h *StatisticsHandler) FindProductViews(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("FindProductViews: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	period := statistics.Period(c.DefaultQuery("period", string(statistics.PeriodHour)))
+
+	from, to, err := parseRange(c)
+	if err != nil {
+		h.log.Error("FindProductViews: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid from or to param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+
+	views, err := h.statisticsService.FindProductViews(tx, user, id, period, from, to)
+	if err != nil {
+		h.log.Error("FindProductViews: " + err.Error())
+
+		switch {
+		case errors.Is(err, statistics.ErrInvalidPeriod):
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid period param"})
+		case errors.Is(err, statistics.ErrInvalidRange):
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid time range"})
+		case errors.Is(err, statistics.ErrProductNotFound):
+			c.JSON(http.StatusNotFound, DefaultResponse{"product with such id does not exist"})
+		case errors.Is(err, statistics.ErrPermissionDenied):
+			c.JSON(http.StatusForbidden, DefaultResponse{"permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal server error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("FindProductViews: product statistics has been successfully received")
+	c.JSON(http.StatusOK, ProductViewsResponse{
+		ProductID: id,
+		Period:    period,
+		Views:     views,
+	})
+}
+
+// FindTopViewed ...
+func (h *StatisticsHandler) FindTopViewed(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	limit := defaultTopLimit
+	if limitParam := c.Query("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			h.log.Error("FindTopViewed: " + err.Error())
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid limit param"})
+			return
+		}
+	}
+
+	from, to, err := parseRange(c)
+	if err != nil {
+		h.log.Error("FindTopViewed: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid from or to param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	user := shared.NewSubject(username.(string), c.GetString(middleware.RoleContext))
+
+	top, err := h.statisticsService.FindTopViewed(tx, user, from, to, limit)
+	if err != nil {
+		h.log.Error("FindTopViewed: " + err.Error())
+
+		switch {
+		case errors.Is(err, statistics.ErrInvalidLimit):
+			c.JSON(http.StatusBadRequest, DefaultResponse{fmt.Sprintf("limit must be from 1 to %d", statistics.MaxTopLimit)})
+		case errors.Is(err, statistics.ErrInvalidRange):
+			c.JSON(http.StatusBadRequest, DefaultResponse{"invalid time range"})
+		case errors.Is(err, statistics.ErrPermissionDenied):
+			c.JSON(http.StatusForbidden, DefaultResponse{"permission denied"})
+		default:
+			c.JSON(http.StatusInternalServerError, DefaultResponse{"internal server error"})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("FindTopViewed: top viewed products has been successfully received")
+	c.JSON(http.StatusOK, top)
+}
+
+// parseRange parses optional from and to params in RFC 3339
+func parseRange(c *gin.Context) (time.Time, time.Time, error) {
+	var from, to time.Time
+
+	if param := c.Query("from"); param != "" {
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	}
+
+	if param := c.Query("to"); param != "" {
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,75 @@
+package webhookshttphandler
+
+import (
+	"time"
+
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+)
+
+// DefaultResponse ...
+type DefaultResponse struct {
+	Message string `json:"message"`
+}
+
+// WebhookRequest webhook is active if active is not set
+type WebhookRequest struct {
+	URL    string               `json:"url" binding:"required"`
+	Events []products.EventType `json:"events" binding:"required"`
+	Active *bool                `json:"active"`
+}
+
+// WebhookResponse secret is returned only on creation
+type WebhookResponse struct {
+	ID        uint64               `json:"id"`
+	URL       string               `json:"url"`
+	Secret    string               `json:"secret,omitempty"`
+	Events    []products.EventType `json:"events"`
+	Active    bool                 `json:"active"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// DeliveryResponse attempt log is returned for a single delivery
+type DeliveryResponse struct {
+	ID            uint64                  `json:"id"`
+	EventID       string                  `json:"event_id"`
+	EventType     products.EventType      `json:"event_type"`
+	Status        webhooks.DeliveryStatus `json:"status"`
+	Attempts      int                     `json:"attempts"`
+	NextAttemptAt time.Time               `json:"next_attempt_at"`
+	LastError     string                  `json:"last_error,omitempty"`
+	CreatedAt     time.Time               `json:"created_at"`
+	DeliveredAt   *time.Time              `json:"delivered_at"`
+	AttemptLog    []AttemptResponse       `json:"attempt_log,omitempty"`
+}
+
+// AttemptResponse ...
+type AttemptResponse struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func newWebhookResponse(webhook webhooks.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func newDeliveryResponse(delivery webhooks.Delivery) DeliveryResponse {
+	return DeliveryResponse{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		LastError:     delivery.LastError,
+		CreatedAt:     delivery.CreatedAt,
+		DeliveredAt:   delivery.DeliveredAt,
+	}
+}
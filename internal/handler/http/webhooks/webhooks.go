@@ -0,0 +1,403 @@
+package webhookshttphandler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/core/webhooks"
+	"github.com/fallra1n/product-keeper/internal/handler/http/middleware"
+)
+
+// WebhooksHandler ...
+type WebhooksHandler struct {
+	log *slog.Logger
+	db  *sqlx.DB
+
+	webhooksService *webhooks.WebhooksService
+}
+
+// NewWebhooksHandler constructor for WebhooksHandler
+func NewWebhooksHandler(log *slog.Logger, db *sqlx.DB, webhooksService *webhooks.WebhooksService) *WebhooksHandler {
+	return &WebhooksHandler{
+		log: log,
+		db:  db,
+
+		webhooksService: webhooksService,
+	}
+}
+
+// CreateWebhook ...
+func (h *WebhooksHandler) CreateWebhook(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("CreateWebhook: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	webhook, err := h.webhooksService.CreateWebhook(tx, username.(string), req.URL, req.Events)
+	if err != nil {
+		h.log.Error("CreateWebhook: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	res := newWebhookResponse(webhook)
+	res.Secret = webhook.Secret
+
+	h.log.Info("CreateWebhook: webhook has been successfully created")
+	c.JSON(http.StatusCreated, res)
+}
+
+// FindWebhookList ...
+func (h *WebhooksHandler) FindWebhookList(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	data, err := h.webhooksService.FindWebhookList(tx, username.(string))
+	if err != nil {
+		h.log.Error("FindWebhookList: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	res := make([]WebhookResponse, 0, len(data))
+	for _, webhook := range data {
+		res = append(res, newWebhookResponse(webhook))
+	}
+
+	h.log.Info("FindWebhookList: webhooks has been successfully received")
+	c.JSON(http.StatusOK, res)
+}
+
+// FindWebhook ...
+func (h *WebhooksHandler) FindWebhook(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("FindWebhook: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	webhook, err := h.webhooksService.FindWebhook(tx, id, username.(string))
+	if err != nil {
+		h.log.Error("FindWebhook: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("FindWebhook: webhook has been successfully received")
+	c.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+// UpdateWebhook ...
+func (h *WebhooksHandler) UpdateWebhook(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("UpdateWebhook: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.log.Error("UpdateWebhook: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"failed to decode request"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	webhook, err := h.webhooksService.UpdateWebhook(tx, username.(string), webhooks.Webhook{
+		ID:     id,
+		URL:    req.URL,
+		Events: req.Events,
+		Active: req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		h.log.Error("UpdateWebhook: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("UpdateWebhook: webhook has been successfully updated")
+	c.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+// DeleteWebhook ...
+func (h *WebhooksHandler) DeleteWebhook(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("DeleteWebhook: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := h.webhooksService.DeleteWebhook(tx, id, username.(string)); err != nil {
+		h.log.Error("DeleteWebhook: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("DeleteWebhook: webhook has been successfully deleted")
+	c.JSON(http.StatusOK, DefaultResponse{"webhook has been deleted"})
+}
+
+// FindDeliveryList ...
+func (h *WebhooksHandler) FindDeliveryList(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log.Error("FindDeliveryList: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	data, err := h.webhooksService.FindDeliveryList(tx, id, username.(string))
+	if err != nil {
+		h.log.Error("FindDeliveryList: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	res := make([]DeliveryResponse, 0, len(data))
+	for _, delivery := range data {
+		res = append(res, newDeliveryResponse(delivery))
+	}
+
+	h.log.Info("FindDeliveryList: webhook deliveries has been successfully received")
+	c.JSON(http.StatusOK, res)
+}
+
+// FindDelivery ...
+func (h *WebhooksHandler) FindDelivery(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, deliveryID, err := deliveryParams(c)
+	if err != nil {
+		h.log.Error("FindDelivery: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	delivery, attempts, err := h.webhooksService.FindDelivery(tx, deliveryID, id, username.(string))
+	if err != nil {
+		h.log.Error("FindDelivery: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	res := newDeliveryResponse(delivery)
+	for _, attempt := range attempts {
+		res.AttemptLog = append(res.AttemptLog, AttemptResponse{
+			AttemptedAt: attempt.AttemptedAt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+		})
+	}
+
+	h.log.Info("FindDelivery: webhook delivery has been successfully received")
+	c.JSON(http.StatusOK, res)
+}
+
+// RetryDelivery ...
+func (h *WebhooksHandler) RetryDelivery(c *gin.Context) {
+	username, ok := c.Get(middleware.UserContext)
+	if !ok {
+		return
+	}
+
+	id, deliveryID, err := deliveryParams(c)
+	if err != nil {
+		h.log.Error("RetryDelivery: " + err.Error())
+		c.JSON(http.StatusBadRequest, DefaultResponse{"invalid id param"})
+		return
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		h.log.Error(fmt.Sprintf("cannot start transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+	defer tx.Rollback()
+
+	delivery, err := h.webhooksService.RetryDelivery(tx, deliveryID, id, username.(string))
+	if err != nil {
+		h.log.Error("RetryDelivery: " + err.Error())
+		h.errorResponse(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.log.Error(fmt.Sprintf("cannot commit transaction: %s", err))
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+		return
+	}
+
+	h.log.Info("RetryDelivery: webhook delivery has been successfully queued")
+	c.JSON(http.StatusAccepted, newDeliveryResponse(delivery))
+}
+
+// errorResponse responds with status of the service error
+func (h *WebhooksHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhooks.ErrInvalidURL):
+		c.JSON(http.StatusBadRequest, DefaultResponse{"url must be an absolute http or https url"})
+	case errors.Is(err, webhooks.ErrInvalidEvents):
+		c.JSON(http.StatusBadRequest, DefaultResponse{"events must be product.created, product.updated or product.deleted"})
+	case errors.Is(err, webhooks.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, DefaultResponse{"webhook with such id does not exist"})
+	case errors.Is(err, webhooks.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, DefaultResponse{"delivery with such id does not exist"})
+	case errors.Is(err, webhooks.ErrDeliveryNotFailed):
+		c.JSON(http.StatusConflict, DefaultResponse{"only failed delivery can be retried"})
+	default:
+		c.JSON(http.StatusInternalServerError, DefaultResponse{"internal error"})
+	}
+}
+
+func deliveryParams(c *gin.Context) (uint64, uint64, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return id, deliveryID, nil
+}
@@ -0,0 +1,97 @@
+package kafkahandler
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/IBM/sarama"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/fallra1n/product-keeper/internal/adapters/products-statistics/events"
+	"github.com/fallra1n/product-keeper/internal/core/products"
+	"github.com/fallra1n/product-keeper/internal/core/statistics"
+)
+
+// ViewsHandler consumes product view events and records them to statistics
+type ViewsHandler struct {
+	log *slog.Logger
+	db  *sqlx.DB
+
+	statisticsService *statistics.StatisticsService
+}
+
+// NewViewsHandler constructor for ViewsHandler
+func NewViewsHandler(log *slog.Logger, db *sqlx.DB, statisticsService *statistics.StatisticsService) *ViewsHandler {
+	return &ViewsHandler{
+		log: log,
+		db:  db,
+
+		statisticsService: statisticsService,
+	}
+}
+
+// Setup ...
+func (h *ViewsHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// Cleanup ...
+func (h *ViewsHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim records views one by one and marks them as consumed.
+// If a view can not be recorded the session is stopped and the message is consumed again after rebalance
+func (h *ViewsHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			if err := h.handle(msg); err != nil {
+				return err
+			}
+
+			session.MarkMessage(msg, "")
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// handle records the view, malformed messages and other events are skipped
+func (h *ViewsHandler) handle(msg *sarama.ConsumerMessage) error {
+	event, err := events.Unmarshal(msg.Value)
+	if err != nil {
+		h.log.Error("ConsumeViews: "+err.Error(), "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+		return nil
+	}
+
+	if event.Type != products.EventViewed || event.SchemaVersion > products.EventSchemaVersion {
+		h.log.Warn("ConsumeViews: event has been skipped", "type", event.Type, "version", event.SchemaVersion, "event", event.ID)
+		return nil
+	}
+
+	tx, err := h.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("cannot start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = h.statisticsService.RecordView(tx, statistics.View{
+		EventID:   event.ID,
+		ProductID: event.Product.ID,
+		ViewedAt:  event.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("ConsumeViews: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cannot commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -11,8 +11,10 @@ package mockauth
 
 import (
 	reflect "reflect"
+	time "time"
 
 	auth "github.com/fallra1n/product-keeper/internal/core/auth"
+	shared "github.com/fallra1n/product-keeper/internal/core/shared"
 	sqlx "github.com/jmoiron/sqlx"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +42,77 @@ func (m *MockAuthRepo) EXPECT() *MockAuthRepoMockRecorder {
 	return m.recorder
 }
 
+// CreateAPIKey mocks base method.
+func (m *MockAuthRepo) CreateAPIKey(tx *sqlx.Tx, key auth.APIKey) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", tx, key)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAuthRepoMockRecorder) CreateAPIKey(tx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAuthRepo)(nil).CreateAPIKey), tx, key)
+}
+
+// CreateIdentity mocks base method.
+func (m *MockAuthRepo) CreateIdentity(tx *sqlx.Tx, provider, subject, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdentity", tx, provider, subject, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdentity indicates an expected call of CreateIdentity.
+func (mr *MockAuthRepoMockRecorder) CreateIdentity(tx, provider, subject, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdentity", reflect.TypeOf((*MockAuthRepo)(nil).CreateIdentity), tx, provider, subject, username)
+}
+
+// CreateMFAChallenge mocks base method.
+func (m *MockAuthRepo) CreateMFAChallenge(tx *sqlx.Tx, challenge auth.MFAChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMFAChallenge", tx, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMFAChallenge indicates an expected call of CreateMFAChallenge.
+func (mr *MockAuthRepoMockRecorder) CreateMFAChallenge(tx, challenge any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMFAChallenge", reflect.TypeOf((*MockAuthRepo)(nil).CreateMFAChallenge), tx, challenge)
+}
+
+// CreateOIDCState mocks base method.
+func (m *MockAuthRepo) CreateOIDCState(tx *sqlx.Tx, state auth.OIDCState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCState", tx, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCState indicates an expected call of CreateOIDCState.
+func (mr *MockAuthRepoMockRecorder) CreateOIDCState(tx, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCState", reflect.TypeOf((*MockAuthRepo)(nil).CreateOIDCState), tx, state)
+}
+
+// CreateSession mocks base method.
+func (m *MockAuthRepo) CreateSession(tx *sqlx.Tx, session auth.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", tx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockAuthRepoMockRecorder) CreateSession(tx, session any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockAuthRepo)(nil).CreateSession), tx, session)
+}
+
 // CreateUser mocks base method.
 func (m *MockAuthRepo) CreateUser(tx *sqlx.Tx, user auth.User) error {
 	m.ctrl.T.Helper()
@@ -54,17 +127,623 @@ func (mr *MockAuthRepoMockRecorder) CreateUser(tx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthRepo)(nil).CreateUser), tx, user)
 }
 
-// FindPassword mocks base method.
-func (m *MockAuthRepo) FindPassword(tx *sqlx.Tx, name string) (string, error) {
+// DeleteExpiredSessions mocks base method.
+func (m *MockAuthRepo) DeleteExpiredSessions(tx *sqlx.Tx, username string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", tx, username, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockAuthRepoMockRecorder) DeleteExpiredSessions(tx, username, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockAuthRepo)(nil).DeleteExpiredSessions), tx, username, now)
+}
+
+// DeleteLoginAttempts mocks base method.
+func (m *MockAuthRepo) DeleteLoginAttempts(tx *sqlx.Tx, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttempts", tx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginAttempts indicates an expected call of DeleteLoginAttempts.
+func (mr *MockAuthRepoMockRecorder) DeleteLoginAttempts(tx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempts", reflect.TypeOf((*MockAuthRepo)(nil).DeleteLoginAttempts), tx, key)
+}
+
+// DeleteSession mocks base method.
+func (m *MockAuthRepo) DeleteSession(tx *sqlx.Tx, id, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", tx, id, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockAuthRepoMockRecorder) DeleteSession(tx, id, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockAuthRepo)(nil).DeleteSession), tx, id, username)
+}
+
+// DeleteSessions mocks base method.
+func (m *MockAuthRepo) DeleteSessions(tx *sqlx.Tx, username, exceptID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessions", tx, username, exceptID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessions indicates an expected call of DeleteSessions.
+func (mr *MockAuthRepoMockRecorder) DeleteSessions(tx, username, exceptID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessions", reflect.TypeOf((*MockAuthRepo)(nil).DeleteSessions), tx, username, exceptID)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockAuthRepo) DeleteTOTP(tx *sqlx.Tx, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", tx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockAuthRepoMockRecorder) DeleteTOTP(tx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockAuthRepo)(nil).DeleteTOTP), tx, username)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAuthRepo) DeleteUser(tx *sqlx.Tx, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", tx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAuthRepoMockRecorder) DeleteUser(tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthRepo)(nil).DeleteUser), tx, id)
+}
+
+// DisableUser mocks base method.
+func (m *MockAuthRepo) DisableUser(tx *sqlx.Tx, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", tx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockAuthRepoMockRecorder) DisableUser(tx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockAuthRepo)(nil).DisableUser), tx, name)
+}
+
+// FindAPIKey mocks base method.
+func (m *MockAuthRepo) FindAPIKey(tx *sqlx.Tx, prefix string) (auth.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAPIKey", tx, prefix)
+	ret0, _ := ret[0].(auth.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAPIKey indicates an expected call of FindAPIKey.
+func (mr *MockAuthRepoMockRecorder) FindAPIKey(tx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAPIKey", reflect.TypeOf((*MockAuthRepo)(nil).FindAPIKey), tx, prefix)
+}
+
+// FindAPIKeyList mocks base method.
+func (m *MockAuthRepo) FindAPIKeyList(tx *sqlx.Tx, ownerName string) ([]auth.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAPIKeyList", tx, ownerName)
+	ret0, _ := ret[0].([]auth.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAPIKeyList indicates an expected call of FindAPIKeyList.
+func (mr *MockAuthRepoMockRecorder) FindAPIKeyList(tx, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAPIKeyList", reflect.TypeOf((*MockAuthRepo)(nil).FindAPIKeyList), tx, ownerName)
+}
+
+// FindIdentity mocks base method.
+func (m *MockAuthRepo) FindIdentity(tx *sqlx.Tx, provider, subject string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIdentity", tx, provider, subject)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIdentity indicates an expected call of FindIdentity.
+func (mr *MockAuthRepoMockRecorder) FindIdentity(tx, provider, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIdentity", reflect.TypeOf((*MockAuthRepo)(nil).FindIdentity), tx, provider, subject)
+}
+
+// FindLoginAttempts mocks base method.
+func (m *MockAuthRepo) FindLoginAttempts(tx *sqlx.Tx, key string) (auth.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLoginAttempts", tx, key)
+	ret0, _ := ret[0].(auth.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLoginAttempts indicates an expected call of FindLoginAttempts.
+func (mr *MockAuthRepoMockRecorder) FindLoginAttempts(tx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLoginAttempts", reflect.TypeOf((*MockAuthRepo)(nil).FindLoginAttempts), tx, key)
+}
+
+// FindProfile mocks base method.
+func (m *MockAuthRepo) FindProfile(tx *sqlx.Tx, name string) (auth.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProfile", tx, name)
+	ret0, _ := ret[0].(auth.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProfile indicates an expected call of FindProfile.
+func (mr *MockAuthRepoMockRecorder) FindProfile(tx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProfile", reflect.TypeOf((*MockAuthRepo)(nil).FindProfile), tx, name)
+}
+
+// FindRecoveryCodes mocks base method.
+func (m *MockAuthRepo) FindRecoveryCodes(tx *sqlx.Tx, username string) ([]auth.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRecoveryCodes", tx, username)
+	ret0, _ := ret[0].([]auth.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRecoveryCodes indicates an expected call of FindRecoveryCodes.
+func (mr *MockAuthRepoMockRecorder) FindRecoveryCodes(tx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecoveryCodes", reflect.TypeOf((*MockAuthRepo)(nil).FindRecoveryCodes), tx, username)
+}
+
+// FindSession mocks base method.
+func (m *MockAuthRepo) FindSession(tx *sqlx.Tx, id string) (auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSession", tx, id)
+	ret0, _ := ret[0].(auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSession indicates an expected call of FindSession.
+func (mr *MockAuthRepoMockRecorder) FindSession(tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSession", reflect.TypeOf((*MockAuthRepo)(nil).FindSession), tx, id)
+}
+
+// FindSessionList mocks base method.
+func (m *MockAuthRepo) FindSessionList(tx *sqlx.Tx, username string, now time.Time) ([]auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSessionList", tx, username, now)
+	ret0, _ := ret[0].([]auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSessionList indicates an expected call of FindSessionList.
+func (mr *MockAuthRepoMockRecorder) FindSessionList(tx, username, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSessionList", reflect.TypeOf((*MockAuthRepo)(nil).FindSessionList), tx, username, now)
+}
+
+// FindTOTP mocks base method.
+func (m *MockAuthRepo) FindTOTP(tx *sqlx.Tx, username string) (auth.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTOTP", tx, username)
+	ret0, _ := ret[0].(auth.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTOTP indicates an expected call of FindTOTP.
+func (mr *MockAuthRepoMockRecorder) FindTOTP(tx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTOTP", reflect.TypeOf((*MockAuthRepo)(nil).FindTOTP), tx, username)
+}
+
+// FindUser mocks base method.
+func (m *MockAuthRepo) FindUser(tx *sqlx.Tx, name string) (auth.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUser", tx, name)
+	ret0, _ := ret[0].(auth.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUser indicates an expected call of FindUser.
+func (mr *MockAuthRepoMockRecorder) FindUser(tx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUser", reflect.TypeOf((*MockAuthRepo)(nil).FindUser), tx, name)
+}
+
+// FindUserList mocks base method.
+func (m *MockAuthRepo) FindUserList(tx *sqlx.Tx) ([]auth.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserList", tx)
+	ret0, _ := ret[0].([]auth.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserList indicates an expected call of FindUserList.
+func (mr *MockAuthRepoMockRecorder) FindUserList(tx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserList", reflect.TypeOf((*MockAuthRepo)(nil).FindUserList), tx)
+}
+
+// IncrementLoginAttempts mocks base method.
+func (m *MockAuthRepo) IncrementLoginAttempts(tx *sqlx.Tx, key string, failedAt, resetBefore time.Time) (auth.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementLoginAttempts", tx, key, failedAt, resetBefore)
+	ret0, _ := ret[0].(auth.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementLoginAttempts indicates an expected call of IncrementLoginAttempts.
+func (mr *MockAuthRepoMockRecorder) IncrementLoginAttempts(tx, key, failedAt, resetBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementLoginAttempts", reflect.TypeOf((*MockAuthRepo)(nil).IncrementLoginAttempts), tx, key, failedAt, resetBefore)
+}
+
+// PopMFAChallenge mocks base method.
+func (m *MockAuthRepo) PopMFAChallenge(tx *sqlx.Tx, challenge string) (auth.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PopMFAChallenge", tx, challenge)
+	ret0, _ := ret[0].(auth.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PopMFAChallenge indicates an expected call of PopMFAChallenge.
+func (mr *MockAuthRepoMockRecorder) PopMFAChallenge(tx, challenge any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopMFAChallenge", reflect.TypeOf((*MockAuthRepo)(nil).PopMFAChallenge), tx, challenge)
+}
+
+// PopOIDCState mocks base method.
+func (m *MockAuthRepo) PopOIDCState(tx *sqlx.Tx, state string) (auth.OIDCState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PopOIDCState", tx, state)
+	ret0, _ := ret[0].(auth.OIDCState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PopOIDCState indicates an expected call of PopOIDCState.
+func (mr *MockAuthRepoMockRecorder) PopOIDCState(tx, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopOIDCState", reflect.TypeOf((*MockAuthRepo)(nil).PopOIDCState), tx, state)
+}
+
+// PopPasswordReset mocks base method.
+func (m *MockAuthRepo) PopPasswordReset(tx *sqlx.Tx, selector string) (auth.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PopPasswordReset", tx, selector)
+	ret0, _ := ret[0].(auth.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PopPasswordReset indicates an expected call of PopPasswordReset.
+func (mr *MockAuthRepoMockRecorder) PopPasswordReset(tx, selector any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopPasswordReset", reflect.TypeOf((*MockAuthRepo)(nil).PopPasswordReset), tx, selector)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockAuthRepo) ReplaceRecoveryCodes(tx *sqlx.Tx, username string, hashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", tx, username, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockAuthRepoMockRecorder) ReplaceRecoveryCodes(tx, username, hashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockAuthRepo)(nil).ReplaceRecoveryCodes), tx, username, hashes)
+}
+
+// ResetEmailVerification mocks base method.
+func (m *MockAuthRepo) ResetEmailVerification(tx *sqlx.Tx, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetEmailVerification", tx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetEmailVerification indicates an expected call of ResetEmailVerification.
+func (mr *MockAuthRepoMockRecorder) ResetEmailVerification(tx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetEmailVerification", reflect.TypeOf((*MockAuthRepo)(nil).ResetEmailVerification), tx, name)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAuthRepo) RevokeAPIKey(tx *sqlx.Tx, id uint64, ownerName string, revokedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", tx, id, ownerName, revokedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAuthRepoMockRecorder) RevokeAPIKey(tx, id, ownerName, revokedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAuthRepo)(nil).RevokeAPIKey), tx, id, ownerName, revokedAt)
+}
+
+// SavePasswordReset mocks base method.
+func (m *MockAuthRepo) SavePasswordReset(tx *sqlx.Tx, reset auth.PasswordReset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePasswordReset", tx, reset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePasswordReset indicates an expected call of SavePasswordReset.
+func (mr *MockAuthRepoMockRecorder) SavePasswordReset(tx, reset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePasswordReset", reflect.TypeOf((*MockAuthRepo)(nil).SavePasswordReset), tx, reset)
+}
+
+// SaveTOTP mocks base method.
+func (m *MockAuthRepo) SaveTOTP(tx *sqlx.Tx, totp auth.TOTP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTP", tx, totp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTP indicates an expected call of SaveTOTP.
+func (mr *MockAuthRepoMockRecorder) SaveTOTP(tx, totp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTP", reflect.TypeOf((*MockAuthRepo)(nil).SaveTOTP), tx, totp)
+}
+
+// TouchSession mocks base method.
+func (m *MockAuthRepo) TouchSession(tx *sqlx.Tx, id string, lastSeenAt time.Time, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", tx, id, lastSeenAt, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockAuthRepoMockRecorder) TouchSession(tx, id, lastSeenAt, ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockAuthRepo)(nil).TouchSession), tx, id, lastSeenAt, ip)
+}
+
+// UpdateAPIKeyLastUsed mocks base method.
+func (m *MockAuthRepo) UpdateAPIKeyLastUsed(tx *sqlx.Tx, id uint64, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAPIKeyLastUsed", tx, id, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAPIKeyLastUsed indicates an expected call of UpdateAPIKeyLastUsed.
+func (mr *MockAuthRepoMockRecorder) UpdateAPIKeyLastUsed(tx, id, lastUsedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyLastUsed", reflect.TypeOf((*MockAuthRepo)(nil).UpdateAPIKeyLastUsed), tx, id, lastUsedAt)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockAuthRepo) UpdatePassword(tx *sqlx.Tx, name, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", tx, name, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockAuthRepoMockRecorder) UpdatePassword(tx, name, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockAuthRepo)(nil).UpdatePassword), tx, name, password)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockAuthRepo) UpdateProfile(tx *sqlx.Tx, profile auth.Profile) (auth.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", tx, profile)
+	ret0, _ := ret[0].(auth.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockAuthRepoMockRecorder) UpdateProfile(tx, profile any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockAuthRepo)(nil).UpdateProfile), tx, profile)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockAuthRepo) UseRecoveryCode(tx *sqlx.Tx, id uint64, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", tx, id, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockAuthRepoMockRecorder) UseRecoveryCode(tx, id, usedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockAuthRepo)(nil).UseRecoveryCode), tx, id, usedAt)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAuthRepo) VerifyEmail(tx *sqlx.Tx, name string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindPassword", tx, name)
+	ret := m.ctrl.Call(m, "VerifyEmail", tx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAuthRepoMockRecorder) VerifyEmail(tx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuthRepo)(nil).VerifyEmail), tx, name)
+}
+
+// MockIdentityProviders is a mock of IdentityProviders interface.
+type MockIdentityProviders struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdentityProvidersMockRecorder
+}
+
+// MockIdentityProvidersMockRecorder is the mock recorder for MockIdentityProviders.
+type MockIdentityProvidersMockRecorder struct {
+	mock *MockIdentityProviders
+}
+
+// NewMockIdentityProviders creates a new mock instance.
+func NewMockIdentityProviders(ctrl *gomock.Controller) *MockIdentityProviders {
+	mock := &MockIdentityProviders{ctrl: ctrl}
+	mock.recorder = &MockIdentityProvidersMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdentityProviders) EXPECT() *MockIdentityProvidersMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockIdentityProviders) AuthCodeURL(provider, state, nonce, codeVerifier string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", provider, state, nonce, codeVerifier)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindPassword indicates an expected call of FindPassword.
-func (mr *MockAuthRepoMockRecorder) FindPassword(tx, name any) *gomock.Call {
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockIdentityProvidersMockRecorder) AuthCodeURL(provider, state, nonce, codeVerifier any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockIdentityProviders)(nil).AuthCodeURL), provider, state, nonce, codeVerifier)
+}
+
+// Exchange mocks base method.
+func (m *MockIdentityProviders) Exchange(provider, code, codeVerifier, nonce string) (auth.ExternalIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", provider, code, codeVerifier, nonce)
+	ret0, _ := ret[0].(auth.ExternalIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockIdentityProvidersMockRecorder) Exchange(provider, code, codeVerifier, nonce any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockIdentityProviders)(nil).Exchange), provider, code, codeVerifier, nonce)
+}
+
+// MockBreachedPasswords is a mock of BreachedPasswords interface.
+type MockBreachedPasswords struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreachedPasswordsMockRecorder
+}
+
+// MockBreachedPasswordsMockRecorder is the mock recorder for MockBreachedPasswords.
+type MockBreachedPasswordsMockRecorder struct {
+	mock *MockBreachedPasswords
+}
+
+// NewMockBreachedPasswords creates a new mock instance.
+func NewMockBreachedPasswords(ctrl *gomock.Controller) *MockBreachedPasswords {
+	mock := &MockBreachedPasswords{ctrl: ctrl}
+	mock.recorder = &MockBreachedPasswordsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreachedPasswords) EXPECT() *MockBreachedPasswordsMockRecorder {
+	return m.recorder
+}
+
+// Contains mocks base method.
+func (m *MockBreachedPasswords) Contains(password string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Contains", password)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Contains indicates an expected call of Contains.
+func (mr *MockBreachedPasswordsMockRecorder) Contains(password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Contains", reflect.TypeOf((*MockBreachedPasswords)(nil).Contains), password)
+}
+
+// MockProductsOwnership is a mock of ProductsOwnership interface.
+type MockProductsOwnership struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductsOwnershipMockRecorder
+}
+
+// MockProductsOwnershipMockRecorder is the mock recorder for MockProductsOwnership.
+type MockProductsOwnershipMockRecorder struct {
+	mock *MockProductsOwnership
+}
+
+// NewMockProductsOwnership creates a new mock instance.
+func NewMockProductsOwnership(ctrl *gomock.Controller) *MockProductsOwnership {
+	mock := &MockProductsOwnership{ctrl: ctrl}
+	mock.recorder = &MockProductsOwnershipMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductsOwnership) EXPECT() *MockProductsOwnershipMockRecorder {
+	return m.recorder
+}
+
+// DeleteProducts mocks base method.
+func (m *MockProductsOwnership) DeleteProducts(tx *sqlx.Tx, actor shared.Subject, ownerName string) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProducts", tx, actor, ownerName)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProducts indicates an expected call of DeleteProducts.
+func (mr *MockProductsOwnershipMockRecorder) DeleteProducts(tx, actor, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProducts", reflect.TypeOf((*MockProductsOwnership)(nil).DeleteProducts), tx, actor, ownerName)
+}
+
+// TransferProducts mocks base method.
+func (m *MockProductsOwnership) TransferProducts(tx *sqlx.Tx, actor shared.Subject, fromName, toName string) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferProducts", tx, actor, fromName, toName)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferProducts indicates an expected call of TransferProducts.
+func (mr *MockProductsOwnershipMockRecorder) TransferProducts(tx, actor, fromName, toName any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPassword", reflect.TypeOf((*MockAuthRepo)(nil).FindPassword), tx, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferProducts", reflect.TypeOf((*MockProductsOwnership)(nil).TransferProducts), tx, actor, fromName, toName)
 }
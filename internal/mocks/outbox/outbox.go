@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/core/outbox/ports.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=./internal/mocks/outbox/outbox.go -source=./internal/core/outbox/ports.go -package=mockoutbox
+//
+
+// Package mockoutbox is a generated GoMock package.
+package mockoutbox
+
+import (
+	reflect "reflect"
+	time "time"
+
+	outbox "github.com/fallra1n/product-keeper/internal/core/outbox"
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOutboxRepo is a mock of OutboxRepo interface.
+type MockOutboxRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepoMockRecorder
+}
+
+// MockOutboxRepoMockRecorder is the mock recorder for MockOutboxRepo.
+type MockOutboxRepoMockRecorder struct {
+	mock *MockOutboxRepo
+}
+
+// NewMockOutboxRepo creates a new mock instance.
+func NewMockOutboxRepo(ctrl *gomock.Controller) *MockOutboxRepo {
+	mock := &MockOutboxRepo{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepo) EXPECT() *MockOutboxRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimPendingMessages mocks base method.
+func (m *MockOutboxRepo) ClaimPendingMessages(tx *sqlx.Tx, now, leaseUntil time.Time, limit int) ([]outbox.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingMessages", tx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]outbox.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingMessages indicates an expected call of ClaimPendingMessages.
+func (mr *MockOutboxRepoMockRecorder) ClaimPendingMessages(tx, now, leaseUntil, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingMessages", reflect.TypeOf((*MockOutboxRepo)(nil).ClaimPendingMessages), tx, now, leaseUntil, limit)
+}
+
+// DeleteSentMessages mocks base method.
+func (m *MockOutboxRepo) DeleteSentMessages(tx *sqlx.Tx, sentBefore time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSentMessages", tx, sentBefore, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSentMessages indicates an expected call of DeleteSentMessages.
+func (mr *MockOutboxRepoMockRecorder) DeleteSentMessages(tx, sentBefore, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSentMessages", reflect.TypeOf((*MockOutboxRepo)(nil).DeleteSentMessages), tx, sentBefore, limit)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxRepo) MarkFailed(tx *sqlx.Tx, id uint64, attempts int, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", tx, id, attempts, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxRepoMockRecorder) MarkFailed(tx, id, attempts, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxRepo)(nil).MarkFailed), tx, id, attempts, nextAttemptAt)
+}
+
+// MarkSent mocks base method.
+func (m *MockOutboxRepo) MarkSent(tx *sqlx.Tx, id uint64, sentAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", tx, id, sentAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockOutboxRepoMockRecorder) MarkSent(tx, id, sentAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockOutboxRepo)(nil).MarkSent), tx, id, sentAt)
+}
+
+// ReleaseMessage mocks base method.
+func (m *MockOutboxRepo) ReleaseMessage(tx *sqlx.Tx, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseMessage", tx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseMessage indicates an expected call of ReleaseMessage.
+func (mr *MockOutboxRepoMockRecorder) ReleaseMessage(tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseMessage", reflect.TypeOf((*MockOutboxRepo)(nil).ReleaseMessage), tx, id)
+}
@@ -69,6 +69,35 @@ func (mr *MockProductsRepoMockRecorder) DeleteProduct(tx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProductsRepo)(nil).DeleteProduct), tx, id)
 }
 
+// DeleteProducts mocks base method.
+func (m *MockProductsRepo) DeleteProducts(tx *sqlx.Tx, ownerName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProducts", tx, ownerName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProducts indicates an expected call of DeleteProducts.
+func (mr *MockProductsRepoMockRecorder) DeleteProducts(tx, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProducts", reflect.TypeOf((*MockProductsRepo)(nil).DeleteProducts), tx, ownerName)
+}
+
+// FindOwnerProducts mocks base method.
+func (m *MockProductsRepo) FindOwnerProducts(tx *sqlx.Tx, ownerName string) ([]products.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOwnerProducts", tx, ownerName)
+	ret0, _ := ret[0].([]products.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOwnerProducts indicates an expected call of FindOwnerProducts.
+func (mr *MockProductsRepoMockRecorder) FindOwnerProducts(tx, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOwnerProducts", reflect.TypeOf((*MockProductsRepo)(nil).FindOwnerProducts), tx, ownerName)
+}
+
 // FindProduct mocks base method.
 func (m *MockProductsRepo) FindProduct(tx *sqlx.Tx, id uint64) (products.Product, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +113,21 @@ func (mr *MockProductsRepoMockRecorder) FindProduct(tx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProduct", reflect.TypeOf((*MockProductsRepo)(nil).FindProduct), tx, id)
 }
 
+// FindProductForUpdate mocks base method.
+func (m *MockProductsRepo) FindProductForUpdate(tx *sqlx.Tx, id uint64) (products.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductForUpdate", tx, id)
+	ret0, _ := ret[0].(products.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductForUpdate indicates an expected call of FindProductForUpdate.
+func (mr *MockProductsRepoMockRecorder) FindProductForUpdate(tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductForUpdate", reflect.TypeOf((*MockProductsRepo)(nil).FindProductForUpdate), tx, id)
+}
+
 // FindProductList mocks base method.
 func (m *MockProductsRepo) FindProductList(tx *sqlx.Tx, username, productName string, sortBy products.SortType) ([]products.Product, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +143,35 @@ func (mr *MockProductsRepoMockRecorder) FindProductList(tx, username, productNam
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductList", reflect.TypeOf((*MockProductsRepo)(nil).FindProductList), tx, username, productName, sortBy)
 }
 
+// TransferProducts mocks base method.
+func (m *MockProductsRepo) TransferProducts(tx *sqlx.Tx, fromName, toName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferProducts", tx, fromName, toName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferProducts indicates an expected call of TransferProducts.
+func (mr *MockProductsRepoMockRecorder) TransferProducts(tx, fromName, toName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferProducts", reflect.TypeOf((*MockProductsRepo)(nil).TransferProducts), tx, fromName, toName)
+}
+
+// FindProductPage mocks base method.
+func (m *MockProductsRepo) FindProductPage(tx *sqlx.Tx, username string, filter products.ProductFilter) (products.ProductPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductPage", tx, username, filter)
+	ret0, _ := ret[0].(products.ProductPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductPage indicates an expected call of FindProductPage.
+func (mr *MockProductsRepoMockRecorder) FindProductPage(tx, username, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductPage", reflect.TypeOf((*MockProductsRepo)(nil).FindProductPage), tx, username, filter)
+}
+
 // UpdateProduct mocks base method.
 func (m *MockProductsRepo) UpdateProduct(tx *sqlx.Tx, newProduct products.Product) (products.Product, error) {
 	m.ctrl.T.Helper()
@@ -138,15 +211,52 @@ func (m *MockProductsStatistics) EXPECT() *MockProductsStatisticsMockRecorder {
 }
 
 // Send mocks base method.
-func (m *MockProductsStatistics) Send(p products.Product) error {
+func (m *MockProductsStatistics) Send(tx *sqlx.Tx, event products.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Send", p)
+	ret := m.ctrl.Call(m, "Send", tx, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Send indicates an expected call of Send.
-func (mr *MockProductsStatisticsMockRecorder) Send(p any) *gomock.Call {
+func (mr *MockProductsStatisticsMockRecorder) Send(tx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockProductsStatistics)(nil).Send), tx, event)
+}
+
+// MockProductsWebhooks is a mock of ProductsWebhooks interface.
+type MockProductsWebhooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductsWebhooksMockRecorder
+}
+
+// MockProductsWebhooksMockRecorder is the mock recorder for MockProductsWebhooks.
+type MockProductsWebhooksMockRecorder struct {
+	mock *MockProductsWebhooks
+}
+
+// NewMockProductsWebhooks creates a new mock instance.
+func NewMockProductsWebhooks(ctrl *gomock.Controller) *MockProductsWebhooks {
+	mock := &MockProductsWebhooks{ctrl: ctrl}
+	mock.recorder = &MockProductsWebhooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductsWebhooks) EXPECT() *MockProductsWebhooksMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockProductsWebhooks) Enqueue(tx *sqlx.Tx, event products.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", tx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockProductsWebhooksMockRecorder) Enqueue(tx, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockProductsStatistics)(nil).Send), p)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockProductsWebhooks)(nil).Enqueue), tx, event)
 }
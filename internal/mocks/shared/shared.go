@@ -13,6 +13,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	shared "github.com/fallra1n/product-keeper/internal/core/shared"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -53,6 +54,20 @@ func (mr *MockCryptoMockRecorder) CompareHashAndPassword(hashedPassword, passwor
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareHashAndPassword", reflect.TypeOf((*MockCrypto)(nil).CompareHashAndPassword), hashedPassword, password)
 }
 
+// CompareHashAndToken mocks base method.
+func (m *MockCrypto) CompareHashAndToken(hashedToken, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareHashAndToken", hashedToken, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareHashAndToken indicates an expected call of CompareHashAndToken.
+func (mr *MockCryptoMockRecorder) CompareHashAndToken(hashedToken, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareHashAndToken", reflect.TypeOf((*MockCrypto)(nil).CompareHashAndToken), hashedToken, token)
+}
+
 // HashPassword mocks base method.
 func (m *MockCrypto) HashPassword(password string) (string, error) {
 	m.ctrl.T.Helper()
@@ -68,6 +83,100 @@ func (mr *MockCryptoMockRecorder) HashPassword(password any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashPassword", reflect.TypeOf((*MockCrypto)(nil).HashPassword), password)
 }
 
+// HashToken mocks base method.
+func (m *MockCrypto) HashToken(token string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashToken", token)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// HashToken indicates an expected call of HashToken.
+func (mr *MockCryptoMockRecorder) HashToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashToken", reflect.TypeOf((*MockCrypto)(nil).HashToken), token)
+}
+
+// NeedsRehash mocks base method.
+func (m *MockCrypto) NeedsRehash(hashedPassword string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hashedPassword)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockCryptoMockRecorder) NeedsRehash(hashedPassword any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockCrypto)(nil).NeedsRehash), hashedPassword)
+}
+
+// RandomString mocks base method.
+func (m *MockCrypto) RandomString(size int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RandomString", size)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RandomString indicates an expected call of RandomString.
+func (mr *MockCryptoMockRecorder) RandomString(size any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RandomString", reflect.TypeOf((*MockCrypto)(nil).RandomString), size)
+}
+
+// MockSigner is a mock of Signer interface.
+type MockSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignerMockRecorder
+}
+
+// MockSignerMockRecorder is the mock recorder for MockSigner.
+type MockSignerMockRecorder struct {
+	mock *MockSigner
+}
+
+// NewMockSigner creates a new mock instance.
+func NewMockSigner(ctrl *gomock.Controller) *MockSigner {
+	mock := &MockSigner{ctrl: ctrl}
+	mock.recorder = &MockSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSigner) EXPECT() *MockSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method.
+func (m *MockSigner) Sign(payload string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", payload)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockSignerMockRecorder) Sign(payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockSigner)(nil).Sign), payload)
+}
+
+// Verify mocks base method.
+func (m *MockSigner) Verify(payload, signature string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", payload, signature)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockSignerMockRecorder) Verify(payload, signature any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockSigner)(nil).Verify), payload, signature)
+}
+
 // MockJwt is a mock of Jwt interface.
 type MockJwt struct {
 	ctrl     *gomock.Controller
@@ -92,27 +201,44 @@ func (m *MockJwt) EXPECT() *MockJwtMockRecorder {
 }
 
 // GenerateToken mocks base method.
-func (m *MockJwt) GenerateToken(username string) (string, error) {
+func (m *MockJwt) GenerateToken(username, role, sessionID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", username)
+	ret := m.ctrl.Call(m, "GenerateToken", username, role, sessionID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockJwtMockRecorder) GenerateToken(username any) *gomock.Call {
+func (mr *MockJwtMockRecorder) GenerateToken(username, role, sessionID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockJwt)(nil).GenerateToken), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockJwt)(nil).GenerateToken), username, role, sessionID)
+}
+
+// JWKS mocks base method.
+func (m *MockJwt) JWKS() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockJwtMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockJwt)(nil).JWKS))
 }
 
 // ParseToken mocks base method.
-func (m *MockJwt) ParseToken(tokenString string) (string, error) {
+func (m *MockJwt) ParseToken(tokenString string) (string, string, string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ParseToken", tokenString)
 	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
 }
 
 // ParseToken indicates an expected call of ParseToken.
@@ -121,6 +247,147 @@ func (mr *MockJwtMockRecorder) ParseToken(tokenString any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockJwt)(nil).ParseToken), tokenString)
 }
 
+// MockTOTP is a mock of TOTP interface.
+type MockTOTP struct {
+	ctrl     *gomock.Controller
+	recorder *MockTOTPMockRecorder
+}
+
+// MockTOTPMockRecorder is the mock recorder for MockTOTP.
+type MockTOTPMockRecorder struct {
+	mock *MockTOTP
+}
+
+// NewMockTOTP creates a new mock instance.
+func NewMockTOTP(ctrl *gomock.Controller) *MockTOTP {
+	mock := &MockTOTP{ctrl: ctrl}
+	mock.recorder = &MockTOTPMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTOTP) EXPECT() *MockTOTPMockRecorder {
+	return m.recorder
+}
+
+// GenerateSecret mocks base method.
+func (m *MockTOTP) GenerateSecret() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateSecret")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateSecret indicates an expected call of GenerateSecret.
+func (mr *MockTOTPMockRecorder) GenerateSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSecret", reflect.TypeOf((*MockTOTP)(nil).GenerateSecret))
+}
+
+// URI mocks base method.
+func (m *MockTOTP) URI(account, secret string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "URI", account, secret)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// URI indicates an expected call of URI.
+func (mr *MockTOTPMockRecorder) URI(account, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URI", reflect.TypeOf((*MockTOTP)(nil).URI), account, secret)
+}
+
+// Validate mocks base method.
+func (m *MockTOTP) Validate(secret, code string, now time.Time) (int64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", secret, code, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockTOTPMockRecorder) Validate(secret, code, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockTOTP)(nil).Validate), secret, code, now)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockNotifier) Send(message shared.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockNotifierMockRecorder) Send(message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockNotifier)(nil).Send), message)
+}
+
+// MockEventBus is a mock of EventBus interface.
+type MockEventBus struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventBusMockRecorder
+}
+
+// MockEventBusMockRecorder is the mock recorder for MockEventBus.
+type MockEventBusMockRecorder struct {
+	mock *MockEventBus
+}
+
+// NewMockEventBus creates a new mock instance.
+func NewMockEventBus(ctrl *gomock.Controller) *MockEventBus {
+	mock := &MockEventBus{ctrl: ctrl}
+	mock.recorder = &MockEventBusMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventBus) EXPECT() *MockEventBusMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventBus) Publish(topic, key string, headers map[string]string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", topic, key, headers, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventBusMockRecorder) Publish(topic, key, headers, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventBus)(nil).Publish), topic, key, headers, payload)
+}
+
 // MockDateTool is a mock of DateTool interface.
 type MockDateTool struct {
 	ctrl     *gomock.Controller
@@ -157,3 +424,78 @@ func (mr *MockDateToolMockRecorder) Now() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Now", reflect.TypeOf((*MockDateTool)(nil).Now))
 }
+
+// MockIDGenerator is a mock of IDGenerator interface.
+type MockIDGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockIDGeneratorMockRecorder
+}
+
+// MockIDGeneratorMockRecorder is the mock recorder for MockIDGenerator.
+type MockIDGeneratorMockRecorder struct {
+	mock *MockIDGenerator
+}
+
+// NewMockIDGenerator creates a new mock instance.
+func NewMockIDGenerator(ctrl *gomock.Controller) *MockIDGenerator {
+	mock := &MockIDGenerator{ctrl: ctrl}
+	mock.recorder = &MockIDGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIDGenerator) EXPECT() *MockIDGeneratorMockRecorder {
+	return m.recorder
+}
+
+// NewID mocks base method.
+func (m *MockIDGenerator) NewID() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewID")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewID indicates an expected call of NewID.
+func (mr *MockIDGeneratorMockRecorder) NewID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewID", reflect.TypeOf((*MockIDGenerator)(nil).NewID))
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockAuthorizer) Authorize(subject shared.Subject, action, owner string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", subject, action, owner)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAuthorizerMockRecorder) Authorize(subject, action, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthorizer)(nil).Authorize), subject, action, owner)
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/core/statistics/ports.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=./internal/mocks/statistics/statistics.go -source=./internal/core/statistics/ports.go -package=mockstatistics
+//
+
+// Package mockstatistics is a generated GoMock package.
+package mockstatistics
+
+import (
+	reflect "reflect"
+	time "time"
+
+	statistics "github.com/fallra1n/product-keeper/internal/core/statistics"
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStatisticsRepo is a mock of StatisticsRepo interface.
+type MockStatisticsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatisticsRepoMockRecorder
+}
+
+// MockStatisticsRepoMockRecorder is the mock recorder for MockStatisticsRepo.
+type MockStatisticsRepoMockRecorder struct {
+	mock *MockStatisticsRepo
+}
+
+// NewMockStatisticsRepo creates a new mock instance.
+func NewMockStatisticsRepo(ctrl *gomock.Controller) *MockStatisticsRepo {
+	mock := &MockStatisticsRepo{ctrl: ctrl}
+	mock.recorder = &MockStatisticsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatisticsRepo) EXPECT() *MockStatisticsRepoMockRecorder {
+	return m.recorder
+}
+
+// AddProcessedEvent mocks base method.
+func (m *MockStatisticsRepo) AddProcessedEvent(tx *sqlx.Tx, id string, processedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProcessedEvent", tx, id, processedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProcessedEvent indicates an expected call of AddProcessedEvent.
+func (mr *MockStatisticsRepoMockRecorder) AddProcessedEvent(tx, id, processedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProcessedEvent", reflect.TypeOf((*MockStatisticsRepo)(nil).AddProcessedEvent), tx, id, processedAt)
+}
+
+// AddViews mocks base method.
+func (m *MockStatisticsRepo) AddViews(tx *sqlx.Tx, productID uint64, period statistics.Period, periodStart time.Time, views uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddViews", tx, productID, period, periodStart, views)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddViews indicates an expected call of AddViews.
+func (mr *MockStatisticsRepoMockRecorder) AddViews(tx, productID, period, periodStart, views any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddViews", reflect.TypeOf((*MockStatisticsRepo)(nil).AddViews), tx, productID, period, periodStart, views)
+}
+
+// DeleteProcessedEvents mocks base method.
+func (m *MockStatisticsRepo) DeleteProcessedEvents(tx *sqlx.Tx, processedBefore time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProcessedEvents", tx, processedBefore, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProcessedEvents indicates an expected call of DeleteProcessedEvents.
+func (mr *MockStatisticsRepoMockRecorder) DeleteProcessedEvents(tx, processedBefore, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProcessedEvents", reflect.TypeOf((*MockStatisticsRepo)(nil).DeleteProcessedEvents), tx, processedBefore, limit)
+}
+
+// FindProductOwner mocks base method.
+func (m *MockStatisticsRepo) FindProductOwner(tx *sqlx.Tx, productID uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductOwner", tx, productID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductOwner indicates an expected call of FindProductOwner.
+func (mr *MockStatisticsRepoMockRecorder) FindProductOwner(tx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductOwner", reflect.TypeOf((*MockStatisticsRepo)(nil).FindProductOwner), tx, productID)
+}
+
+// FindTopViewed mocks base method.
+func (m *MockStatisticsRepo) FindTopViewed(tx *sqlx.Tx, ownerName string, from, to time.Time, limit int) ([]statistics.ProductViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTopViewed", tx, ownerName, from, to, limit)
+	ret0, _ := ret[0].([]statistics.ProductViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTopViewed indicates an expected call of FindTopViewed.
+func (mr *MockStatisticsRepoMockRecorder) FindTopViewed(tx, ownerName, from, to, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTopViewed", reflect.TypeOf((*MockStatisticsRepo)(nil).FindTopViewed), tx, ownerName, from, to, limit)
+}
+
+// FindViews mocks base method.
+func (m *MockStatisticsRepo) FindViews(tx *sqlx.Tx, productID uint64, period statistics.Period, from, to time.Time) ([]statistics.ViewCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindViews", tx, productID, period, from, to)
+	ret0, _ := ret[0].([]statistics.ViewCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindViews indicates an expected call of FindViews.
+func (mr *MockStatisticsRepoMockRecorder) FindViews(tx, productID, period, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindViews", reflect.TypeOf((*MockStatisticsRepo)(nil).FindViews), tx, productID, period, from, to)
+}
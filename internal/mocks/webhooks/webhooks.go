@@ -0,0 +1,271 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/core/webhooks/ports.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=./internal/mocks/webhooks/webhooks.go -source=./internal/core/webhooks/ports.go -package=mockwebhooks
+//
+
+// Package mockwebhooks is a generated GoMock package.
+package mockwebhooks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	products "github.com/fallra1n/product-keeper/internal/core/products"
+	webhooks "github.com/fallra1n/product-keeper/internal/core/webhooks"
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebhooksRepo is a mock of WebhooksRepo interface.
+type MockWebhooksRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksRepoMockRecorder
+}
+
+// MockWebhooksRepoMockRecorder is the mock recorder for MockWebhooksRepo.
+type MockWebhooksRepoMockRecorder struct {
+	mock *MockWebhooksRepo
+}
+
+// NewMockWebhooksRepo creates a new mock instance.
+func NewMockWebhooksRepo(ctrl *gomock.Controller) *MockWebhooksRepo {
+	mock := &MockWebhooksRepo{ctrl: ctrl}
+	mock.recorder = &MockWebhooksRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooksRepo) EXPECT() *MockWebhooksRepoMockRecorder {
+	return m.recorder
+}
+
+// AddAttempt mocks base method.
+func (m *MockWebhooksRepo) AddAttempt(tx *sqlx.Tx, attempt webhooks.Attempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAttempt", tx, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAttempt indicates an expected call of AddAttempt.
+func (mr *MockWebhooksRepoMockRecorder) AddAttempt(tx, attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttempt", reflect.TypeOf((*MockWebhooksRepo)(nil).AddAttempt), tx, attempt)
+}
+
+// AddDelivery mocks base method.
+func (m *MockWebhooksRepo) AddDelivery(tx *sqlx.Tx, delivery webhooks.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDelivery", tx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDelivery indicates an expected call of AddDelivery.
+func (mr *MockWebhooksRepoMockRecorder) AddDelivery(tx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDelivery", reflect.TypeOf((*MockWebhooksRepo)(nil).AddDelivery), tx, delivery)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhooksRepo) CreateWebhook(tx *sqlx.Tx, webhook webhooks.Webhook) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", tx, webhook)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhooksRepoMockRecorder) CreateWebhook(tx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhooksRepo)(nil).CreateWebhook), tx, webhook)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhooksRepo) DeleteWebhook(tx *sqlx.Tx, id uint64, ownerName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", tx, id, ownerName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhooksRepoMockRecorder) DeleteWebhook(tx, id, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhooksRepo)(nil).DeleteWebhook), tx, id, ownerName)
+}
+
+// FindAttempts mocks base method.
+func (m *MockWebhooksRepo) FindAttempts(tx *sqlx.Tx, deliveryID uint64) ([]webhooks.Attempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAttempts", tx, deliveryID)
+	ret0, _ := ret[0].([]webhooks.Attempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAttempts indicates an expected call of FindAttempts.
+func (mr *MockWebhooksRepoMockRecorder) FindAttempts(tx, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAttempts", reflect.TypeOf((*MockWebhooksRepo)(nil).FindAttempts), tx, deliveryID)
+}
+
+// FindDelivery mocks base method.
+func (m *MockWebhooksRepo) FindDelivery(tx *sqlx.Tx, id, webhookID uint64) (webhooks.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDelivery", tx, id, webhookID)
+	ret0, _ := ret[0].(webhooks.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDelivery indicates an expected call of FindDelivery.
+func (mr *MockWebhooksRepoMockRecorder) FindDelivery(tx, id, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDelivery", reflect.TypeOf((*MockWebhooksRepo)(nil).FindDelivery), tx, id, webhookID)
+}
+
+// FindDeliveryList mocks base method.
+func (m *MockWebhooksRepo) FindDeliveryList(tx *sqlx.Tx, webhookID uint64, limit int) ([]webhooks.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveryList", tx, webhookID, limit)
+	ret0, _ := ret[0].([]webhooks.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveryList indicates an expected call of FindDeliveryList.
+func (mr *MockWebhooksRepoMockRecorder) FindDeliveryList(tx, webhookID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveryList", reflect.TypeOf((*MockWebhooksRepo)(nil).FindDeliveryList), tx, webhookID, limit)
+}
+
+// FindPendingDeliveries mocks base method.
+func (m *MockWebhooksRepo) FindPendingDeliveries(tx *sqlx.Tx, now time.Time, limit int) ([]webhooks.PendingDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPendingDeliveries", tx, now, limit)
+	ret0, _ := ret[0].([]webhooks.PendingDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPendingDeliveries indicates an expected call of FindPendingDeliveries.
+func (mr *MockWebhooksRepoMockRecorder) FindPendingDeliveries(tx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPendingDeliveries", reflect.TypeOf((*MockWebhooksRepo)(nil).FindPendingDeliveries), tx, now, limit)
+}
+
+// FindSubscribedWebhooks mocks base method.
+func (m *MockWebhooksRepo) FindSubscribedWebhooks(tx *sqlx.Tx, ownerName string, eventType products.EventType) ([]webhooks.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscribedWebhooks", tx, ownerName, eventType)
+	ret0, _ := ret[0].([]webhooks.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscribedWebhooks indicates an expected call of FindSubscribedWebhooks.
+func (mr *MockWebhooksRepoMockRecorder) FindSubscribedWebhooks(tx, ownerName, eventType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscribedWebhooks", reflect.TypeOf((*MockWebhooksRepo)(nil).FindSubscribedWebhooks), tx, ownerName, eventType)
+}
+
+// FindWebhook mocks base method.
+func (m *MockWebhooksRepo) FindWebhook(tx *sqlx.Tx, id uint64, ownerName string) (webhooks.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhook", tx, id, ownerName)
+	ret0, _ := ret[0].(webhooks.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhook indicates an expected call of FindWebhook.
+func (mr *MockWebhooksRepoMockRecorder) FindWebhook(tx, id, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhook", reflect.TypeOf((*MockWebhooksRepo)(nil).FindWebhook), tx, id, ownerName)
+}
+
+// FindWebhookList mocks base method.
+func (m *MockWebhooksRepo) FindWebhookList(tx *sqlx.Tx, ownerName string) ([]webhooks.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhookList", tx, ownerName)
+	ret0, _ := ret[0].([]webhooks.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookList indicates an expected call of FindWebhookList.
+func (mr *MockWebhooksRepoMockRecorder) FindWebhookList(tx, ownerName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookList", reflect.TypeOf((*MockWebhooksRepo)(nil).FindWebhookList), tx, ownerName)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhooksRepo) UpdateDelivery(tx *sqlx.Tx, delivery webhooks.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", tx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhooksRepoMockRecorder) UpdateDelivery(tx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhooksRepo)(nil).UpdateDelivery), tx, delivery)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockWebhooksRepo) UpdateWebhook(tx *sqlx.Tx, webhook webhooks.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", tx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockWebhooksRepoMockRecorder) UpdateWebhook(tx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockWebhooksRepo)(nil).UpdateWebhook), tx, webhook)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(url, secret string, delivery webhooks.Delivery, sentAt time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", url, secret, delivery, sentAt)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(url, secret, delivery, sentAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), url, secret, delivery, sentAt)
+}
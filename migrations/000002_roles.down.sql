@@ -0,0 +1,3 @@
+ALTER TABLE auth$users
+  DROP COLUMN disabled,
+  DROP COLUMN role;
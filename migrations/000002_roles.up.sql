@@ -0,0 +1,3 @@
+ALTER TABLE auth$users
+  ADD COLUMN role     VARCHAR(32) NOT NULL DEFAULT 'user',
+  ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS auth$api_keys
+  (
+     id           SERIAL PRIMARY KEY,
+     prefix       VARCHAR(32) NOT NULL UNIQUE,
+     hash         VARCHAR(255) NOT NULL,
+     owner_name   VARCHAR(255) NOT NULL,
+     name         VARCHAR(255) NOT NULL,
+     scopes       TEXT[] NOT NULL,
+     created_at   TIMESTAMP NOT NULL,
+     last_used_at TIMESTAMP,
+     revoked_at   TIMESTAMP,
+     FOREIGN KEY (owner_name) REFERENCES auth$users(name)
+  );
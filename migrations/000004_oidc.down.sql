@@ -0,0 +1,3 @@
+DROP TABLE auth$identities;
+
+DROP TABLE auth$oidc_states;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS auth$oidc_states
+  (
+     state         VARCHAR(64) PRIMARY KEY,
+     provider      VARCHAR(255) NOT NULL,
+     code_verifier VARCHAR(128) NOT NULL,
+     nonce         VARCHAR(64) NOT NULL,
+     created_at    TIMESTAMP NOT NULL
+  );
+
+CREATE TABLE IF NOT EXISTS auth$identities
+  (
+     provider  VARCHAR(255) NOT NULL,
+     subject   VARCHAR(255) NOT NULL,
+     user_name VARCHAR(255) NOT NULL,
+     PRIMARY KEY (provider, subject),
+     FOREIGN KEY (user_name) REFERENCES auth$users(name)
+  );
@@ -0,0 +1,5 @@
+DROP TABLE auth$mfa_challenges;
+
+DROP TABLE auth$recovery_codes;
+
+DROP TABLE auth$totp;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS auth$totp
+  (
+     user_name VARCHAR(255) PRIMARY KEY,
+     secret    VARCHAR(64) NOT NULL,
+     enabled   BOOLEAN NOT NULL DEFAULT FALSE,
+     last_step BIGINT NOT NULL DEFAULT 0,
+     FOREIGN KEY (user_name) REFERENCES auth$users(name)
+  );
+
+CREATE TABLE IF NOT EXISTS auth$recovery_codes
+  (
+     id        SERIAL PRIMARY KEY,
+     user_name VARCHAR(255) NOT NULL,
+     hash      VARCHAR(255) NOT NULL,
+     used_at   TIMESTAMP,
+     FOREIGN KEY (user_name) REFERENCES auth$users(name)
+  );
+
+CREATE TABLE IF NOT EXISTS auth$mfa_challenges
+  (
+     challenge  VARCHAR(64) PRIMARY KEY,
+     user_name  VARCHAR(255) NOT NULL,
+     created_at TIMESTAMP NOT NULL,
+     FOREIGN KEY (user_name) REFERENCES auth$users(name)
+  );
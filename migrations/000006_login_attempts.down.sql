@@ -0,0 +1 @@
+DROP TABLE auth$login_attempts;
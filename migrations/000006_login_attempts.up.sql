@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth$login_attempts
+  (
+     key             VARCHAR(320) PRIMARY KEY,
+     failures        INT NOT NULL,
+     last_failure_at TIMESTAMP NOT NULL
+  );
@@ -0,0 +1 @@
+DROP TABLE auth$password_resets;
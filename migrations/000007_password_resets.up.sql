@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS auth$password_resets
+  (
+     selector   VARCHAR(32) PRIMARY KEY,
+     hash       VARCHAR(255) NOT NULL,
+     user_name  VARCHAR(255) NOT NULL UNIQUE,
+     created_at TIMESTAMP NOT NULL,
+     FOREIGN KEY (user_name) REFERENCES auth$users(name)
+  );
@@ -0,0 +1,17 @@
+ALTER TABLE products
+  ADD COLUMN owner_name VARCHAR(255) REFERENCES auth$users(name);
+
+UPDATE products p
+SET owner_name = u.name
+FROM auth$users u
+WHERE u.id = p.owner_id;
+
+ALTER TABLE products
+  ALTER COLUMN owner_name SET NOT NULL,
+  DROP COLUMN owner_id;
+
+ALTER TABLE auth$users
+  DROP COLUMN timezone,
+  DROP COLUMN email,
+  DROP COLUMN display_name,
+  DROP COLUMN id;
@@ -0,0 +1,17 @@
+ALTER TABLE auth$users
+  ADD COLUMN id           BIGSERIAL UNIQUE,
+  ADD COLUMN display_name VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN email        VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN timezone     VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE products
+  ADD COLUMN owner_id BIGINT REFERENCES auth$users(id);
+
+UPDATE products p
+SET owner_id = u.id
+FROM auth$users u
+WHERE u.name = p.owner_name;
+
+ALTER TABLE products
+  ALTER COLUMN owner_id SET NOT NULL,
+  DROP COLUMN owner_name;
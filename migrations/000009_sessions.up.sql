@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS auth$sessions
+  (
+     id           VARCHAR(32) PRIMARY KEY,
+     user_name    VARCHAR(255) NOT NULL,
+     user_agent   TEXT NOT NULL,
+     ip           VARCHAR(64) NOT NULL,
+     created_at   TIMESTAMP NOT NULL,
+     last_seen_at TIMESTAMP NOT NULL,
+     expires_at   TIMESTAMP NOT NULL,
+     FOREIGN KEY (user_name) REFERENCES auth$users(name)
+  );
+
+CREATE INDEX IF NOT EXISTS auth$sessions_user_name_idx ON auth$sessions (user_name);
@@ -0,0 +1,2 @@
+ALTER TABLE auth$users
+  ADD COLUMN pending BOOLEAN NOT NULL DEFAULT FALSE;
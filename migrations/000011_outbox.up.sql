@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS outbox
+  (
+     id              BIGSERIAL PRIMARY KEY,
+     topic           VARCHAR(255) NOT NULL,
+     payload         BYTEA NOT NULL,
+     attempts        INT NOT NULL DEFAULT 0,
+     created_at      TIMESTAMP NOT NULL,
+     next_attempt_at TIMESTAMP NOT NULL,
+     sent_at         TIMESTAMP
+  );
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE sent_at IS NULL;
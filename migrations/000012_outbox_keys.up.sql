@@ -0,0 +1,2 @@
+ALTER TABLE outbox
+  ADD COLUMN message_key VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_pending_key_idx;
+ALTER TABLE outbox
+  DROP COLUMN IF EXISTS locked_until;
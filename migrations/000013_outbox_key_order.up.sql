@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS outbox_pending_key_idx ON outbox (topic, message_key, id) WHERE sent_at IS NULL;
+ALTER TABLE outbox
+  ADD COLUMN locked_until TIMESTAMP;
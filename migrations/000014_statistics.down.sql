@@ -0,0 +1,2 @@
+DROP TABLE statistics$processed_events;
+DROP TABLE statistics$product_views;
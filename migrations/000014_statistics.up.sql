@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS statistics$product_views
+  (
+     product_id   BIGINT NOT NULL,
+     period       VARCHAR(16) NOT NULL,
+     period_start TIMESTAMP NOT NULL,
+     views        BIGINT NOT NULL,
+     PRIMARY KEY (product_id, period, period_start)
+  );
+
+CREATE TABLE IF NOT EXISTS statistics$processed_events
+  (
+     id           VARCHAR(64) PRIMARY KEY,
+     processed_at TIMESTAMP NOT NULL
+  );
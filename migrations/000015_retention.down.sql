@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS statistics$processed_events_processed_at_idx;
+
+DROP INDEX IF EXISTS outbox_sent_idx;
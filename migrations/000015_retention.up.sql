@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS outbox_sent_idx ON outbox (sent_at) WHERE sent_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS statistics$processed_events_processed_at_idx ON statistics$processed_events (processed_at);
@@ -0,0 +1,2 @@
+ALTER TABLE outbox
+  ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+DROP TABLE webhooks$delivery_attempts;
+DROP TABLE webhooks$deliveries;
+DROP TABLE webhooks;
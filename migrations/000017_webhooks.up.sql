@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS webhooks
+  (
+     id         BIGSERIAL PRIMARY KEY,
+     owner_name VARCHAR(255) NOT NULL,
+     url        TEXT NOT NULL,
+     secret     VARCHAR(255) NOT NULL,
+     events     TEXT[] NOT NULL,
+     active     BOOLEAN NOT NULL DEFAULT TRUE,
+     created_at TIMESTAMP NOT NULL,
+     FOREIGN KEY (owner_name) REFERENCES auth$users(name) ON DELETE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS webhooks_owner_idx ON webhooks (owner_name);
+
+CREATE TABLE IF NOT EXISTS webhooks$deliveries
+  (
+     id              BIGSERIAL PRIMARY KEY,
+     webhook_id      BIGINT NOT NULL,
+     event_id        VARCHAR(64) NOT NULL,
+     event_type      VARCHAR(64) NOT NULL,
+     payload         BYTEA NOT NULL,
+     status          VARCHAR(16) NOT NULL,
+     attempts        INT NOT NULL DEFAULT 0,
+     next_attempt_at TIMESTAMP NOT NULL,
+     last_error      TEXT NOT NULL DEFAULT '',
+     created_at      TIMESTAMP NOT NULL,
+     delivered_at    TIMESTAMP,
+     FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS webhooks$deliveries_pending_idx ON webhooks$deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhooks$deliveries_webhook_idx ON webhooks$deliveries (webhook_id, id);
+
+CREATE TABLE IF NOT EXISTS webhooks$delivery_attempts
+  (
+     id           BIGSERIAL PRIMARY KEY,
+     delivery_id  BIGINT NOT NULL,
+     attempted_at TIMESTAMP NOT NULL,
+     status_code  INT NOT NULL,
+     error        TEXT NOT NULL,
+     FOREIGN KEY (delivery_id) REFERENCES webhooks$deliveries(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS webhooks$delivery_attempts_delivery_idx ON webhooks$delivery_attempts (delivery_id);
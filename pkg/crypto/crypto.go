@@ -1,18 +1,64 @@
 package crypto
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
 
-// Crypto struct for working with cryptography
-type Crypto struct{}
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/fallra1n/product-keeper/config"
+)
+
+// Crypto struct for working with cryptography.
+// New passwords are hashed with configured algorithm, both argon2id and bcrypt hashes can be compared
+type Crypto struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+}
 
 // NewCrypto constructor for Crypto
-func NewCrypto() *Crypto {
-	return &Crypto{}
+func NewCrypto(cfg config.PasswordHash) (*Crypto, error) {
+	c := &Crypto{
+		algorithm:  cfg.Algorithm,
+		bcryptCost: cfg.BcryptCost,
+		argon2: argon2Params{
+			Memory:     cfg.Argon2Memory,
+			Time:       cfg.Argon2Time,
+			Threads:    cfg.Argon2Threads,
+			SaltLength: cfg.Argon2SaltLength,
+			KeyLength:  cfg.Argon2KeyLength,
+		},
+	}
+
+	switch c.algorithm {
+	case config.PasswordHashArgon2id:
+		if c.argon2.Memory == 0 || c.argon2.Time == 0 || c.argon2.Threads == 0 ||
+			c.argon2.SaltLength == 0 || c.argon2.KeyLength == 0 {
+			return nil, ErrInvalidParams
+		}
+	case config.PasswordHashBcrypt:
+		if c.bcryptCost < bcrypt.MinCost || c.bcryptCost > bcrypt.MaxCost {
+			return nil, ErrInvalidParams
+		}
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+
+	return c, nil
 }
 
 // HashPassword get hashed password
 func (c Crypto) HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if c.algorithm == config.PasswordHashArgon2id {
+		return hashArgon2id(password, c.argon2)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), c.bcryptCost)
 	if err != nil {
 		return "", err
 	}
@@ -20,7 +66,74 @@ func (c Crypto) HashPassword(password string) (string, error) {
 	return string(hash), nil
 }
 
-// CompareHashAndPassword compare passwords
+// CompareHashAndPassword compare passwords, algorithm is detected by hash format
 func (c Crypto) CompareHashAndPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	switch {
+	case strings.HasPrefix(hashedPassword, argon2idPrefix):
+		return compareArgon2id(hashedPassword, password)
+	case isBcrypt(hashedPassword):
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedHashAndPassword
+		}
+
+		return err
+	default:
+		return ErrUnknownHashFormat
+	}
+}
+
+// NeedsRehash reports whether hash is produced by another algorithm or with other parameters than configured
+func (c Crypto) NeedsRehash(hashedPassword string) bool {
+	if c.algorithm == config.PasswordHashArgon2id {
+		if !strings.HasPrefix(hashedPassword, argon2idPrefix) {
+			return true
+		}
+
+		params, _, _, err := decodeArgon2id(hashedPassword)
+		return err != nil || params != c.argon2
+	}
+
+	if !isBcrypt(hashedPassword) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost != c.bcryptCost
+}
+
+// HashToken hash of random high-entropy token, e.g. api key secret. Such tokens cannot be guessed,
+// so sha-256 is enough, a password hashing function would only make every check expensive
+func (c Crypto) HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return tokenHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// CompareHashAndToken compares token with its hash in constant time
+func (c Crypto) CompareHashAndToken(hashedToken, token string) error {
+	if subtle.ConstantTimeCompare([]byte(hashedToken), []byte(c.HashToken(token))) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
+// RandomString get hex encoded string of size random bytes
+func (c Crypto) RandomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+func isBcrypt(hashedPassword string) bool {
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(hashedPassword, prefix) {
+			return true
+		}
+	}
+
+	return false
 }
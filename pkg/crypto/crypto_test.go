@@ -0,0 +1,313 @@
+package crypto_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/pkg/crypto"
+)
+
+// small parameters keep the tests fast
+var (
+	argon2Config = config.PasswordHash{
+		Algorithm:        config.PasswordHashArgon2id,
+		Argon2Time:       1,
+		Argon2Memory:     1024,
+		Argon2Threads:    1,
+		Argon2SaltLength: 16,
+		Argon2KeyLength:  32,
+	}
+	bcryptConfig = config.PasswordHash{
+		Algorithm:  config.PasswordHashBcrypt,
+		BcryptCost: bcrypt.MinCost,
+	}
+)
+
+type Suite struct {
+	suite.Suite
+	crypto *crypto.Crypto
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	c, err := crypto.NewCrypto(argon2Config)
+	s.Require().NoError(err)
+	s.crypto = c
+}
+
+func (s *Suite) TestHashToken() {
+	// sha-256 test vector from FIPS 180-2
+	s.Equal("$sha256$ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", s.crypto.HashToken("abc"))
+
+	hash := s.crypto.HashToken("test token")
+
+	// the hash is deterministic, so keys can be looked up and compared without a password hashing function
+	s.Equal(hash, s.crypto.HashToken("test token"))
+	s.NotEqual(hash, s.crypto.HashToken("other token"))
+
+	s.NoError(s.crypto.CompareHashAndToken(hash, "test token"))
+	s.ErrorIs(s.crypto.CompareHashAndToken(hash, "other token"), crypto.ErrMismatchedHashAndPassword)
+	s.ErrorIs(s.crypto.CompareHashAndToken("test token", "test token"), crypto.ErrMismatchedHashAndPassword)
+}
+
+func (s *Suite) TestNewCrypto() {
+	testList := []struct {
+		name string
+		cfg  config.PasswordHash
+		err  error
+	}{
+		{
+			name: "argon2id",
+			cfg:  argon2Config,
+		},
+		{
+			name: "bcrypt",
+			cfg:  bcryptConfig,
+		},
+		{
+			name: "argon2id without key length",
+			cfg: config.PasswordHash{
+				Algorithm:        config.PasswordHashArgon2id,
+				Argon2Time:       1,
+				Argon2Memory:     1024,
+				Argon2Threads:    1,
+				Argon2SaltLength: 16,
+			},
+			err: crypto.ErrInvalidParams,
+		},
+		{
+			name: "bcrypt cost is too high",
+			cfg:  config.PasswordHash{Algorithm: config.PasswordHashBcrypt, BcryptCost: bcrypt.MaxCost + 1},
+			err:  crypto.ErrInvalidParams,
+		},
+		{
+			name: "unknown algorithm",
+			cfg:  config.PasswordHash{Algorithm: "md5"},
+			err:  crypto.ErrUnknownAlgorithm,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			_, err := crypto.NewCrypto(row.cfg)
+			s.ErrorIs(err, row.err)
+		})
+	}
+}
+
+func (s *Suite) TestHashPassword() {
+	testList := []struct {
+		name   string
+		cfg    config.PasswordHash
+		prefix string
+	}{
+		{
+			name:   "argon2id",
+			cfg:    argon2Config,
+			prefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+		{
+			name:   "bcrypt",
+			cfg:    bcryptConfig,
+			prefix: "$2a$04$",
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			c, err := crypto.NewCrypto(row.cfg)
+			s.Require().NoError(err)
+
+			hash, err := c.HashPassword("test password")
+			s.Require().NoError(err)
+			s.True(strings.HasPrefix(hash, row.prefix), hash)
+
+			// salt is random
+			other, err := c.HashPassword("test password")
+			s.Require().NoError(err)
+			s.NotEqual(hash, other)
+
+			s.NoError(c.CompareHashAndPassword(hash, "test password"))
+			s.ErrorIs(c.CompareHashAndPassword(hash, "other password"), crypto.ErrMismatchedHashAndPassword)
+			s.False(c.NeedsRehash(hash))
+		})
+	}
+}
+
+func (s *Suite) TestCompareLegacyBcrypt() {
+	// hashes saved before argon2id was configured
+	hash, err := bcrypt.GenerateFromPassword([]byte("test password"), bcrypt.MinCost)
+	s.Require().NoError(err)
+
+	s.NoError(s.crypto.CompareHashAndPassword(string(hash), "test password"))
+	s.ErrorIs(s.crypto.CompareHashAndPassword(string(hash), "other password"), crypto.ErrMismatchedHashAndPassword)
+	s.True(s.crypto.NeedsRehash(string(hash)))
+}
+
+func (s *Suite) TestCompareMalformedHash() {
+	hash, err := s.crypto.HashPassword("test password")
+	s.Require().NoError(err)
+	parts := strings.Split(hash, "$")
+
+	testList := []struct {
+		name string
+		hash string
+		err  error
+	}{
+		{
+			name: "unknown format",
+			hash: "test password",
+			err:  crypto.ErrUnknownHashFormat,
+		},
+		{
+			name: "empty hash",
+			hash: "",
+			err:  crypto.ErrUnknownHashFormat,
+		},
+		{
+			name: "missing key",
+			hash: strings.Join(parts[:5], "$"),
+			err:  crypto.ErrInvalidHash,
+		},
+		{
+			name: "unsupported version",
+			hash: strings.Join([]string{"", "argon2id", "v=16", parts[3], parts[4], parts[5]}, "$"),
+			err:  crypto.ErrInvalidHash,
+		},
+		{
+			name: "invalid parameters",
+			hash: strings.Join([]string{"", "argon2id", parts[2], "m=x,t=1,p=1", parts[4], parts[5]}, "$"),
+			err:  crypto.ErrInvalidHash,
+		},
+		{
+			name: "invalid salt",
+			hash: strings.Join([]string{"", "argon2id", parts[2], parts[3], "!!!", parts[5]}, "$"),
+			err:  crypto.ErrInvalidHash,
+		},
+		{
+			name: "empty key",
+			hash: strings.Join([]string{"", "argon2id", parts[2], parts[3], parts[4], ""}, "$"),
+			err:  crypto.ErrInvalidHash,
+		},
+		{
+			name: "truncated bcrypt",
+			hash: "$2a$04$short",
+			err:  bcrypt.ErrHashTooShort,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			s.ErrorIs(s.crypto.CompareHashAndPassword(row.hash, "test password"), row.err)
+			s.True(s.crypto.NeedsRehash(row.hash))
+		})
+	}
+}
+
+func (s *Suite) TestNeedsRehash() {
+	argon2Hash, err := s.crypto.HashPassword("test password")
+	s.Require().NoError(err)
+
+	bcryptCrypto, err := crypto.NewCrypto(bcryptConfig)
+	s.Require().NoError(err)
+
+	bcryptHash, err := bcryptCrypto.HashPassword("test password")
+	s.Require().NoError(err)
+
+	changed := func(change func(cfg *config.PasswordHash)) *crypto.Crypto {
+		cfg := argon2Config
+		change(&cfg)
+
+		c, err := crypto.NewCrypto(cfg)
+		s.Require().NoError(err)
+		return c
+	}
+
+	testList := []struct {
+		name     string
+		crypto   *crypto.Crypto
+		hash     string
+		expected bool
+	}{
+		{
+			name:     "same argon2id parameters",
+			crypto:   s.crypto,
+			hash:     argon2Hash,
+			expected: false,
+		},
+		{
+			name:     "argon2id memory changed",
+			crypto:   changed(func(cfg *config.PasswordHash) { cfg.Argon2Memory = 2048 }),
+			hash:     argon2Hash,
+			expected: true,
+		},
+		{
+			name:     "argon2id time changed",
+			crypto:   changed(func(cfg *config.PasswordHash) { cfg.Argon2Time = 2 }),
+			hash:     argon2Hash,
+			expected: true,
+		},
+		{
+			name:     "argon2id threads changed",
+			crypto:   changed(func(cfg *config.PasswordHash) { cfg.Argon2Threads = 2 }),
+			hash:     argon2Hash,
+			expected: true,
+		},
+		{
+			name:     "argon2id salt length changed",
+			crypto:   changed(func(cfg *config.PasswordHash) { cfg.Argon2SaltLength = 32 }),
+			hash:     argon2Hash,
+			expected: true,
+		},
+		{
+			name:     "argon2id key length changed",
+			crypto:   changed(func(cfg *config.PasswordHash) { cfg.Argon2KeyLength = 64 }),
+			hash:     argon2Hash,
+			expected: true,
+		},
+		{
+			name:     "bcrypt hash with argon2id configured",
+			crypto:   s.crypto,
+			hash:     bcryptHash,
+			expected: true,
+		},
+		{
+			name:     "same bcrypt cost",
+			crypto:   bcryptCrypto,
+			hash:     bcryptHash,
+			expected: false,
+		},
+		{
+			name: "bcrypt cost changed",
+			crypto: func() *crypto.Crypto {
+				c, err := crypto.NewCrypto(config.PasswordHash{Algorithm: config.PasswordHashBcrypt, BcryptCost: bcrypt.MinCost + 1})
+				s.Require().NoError(err)
+				return c
+			}(),
+			hash:     bcryptHash,
+			expected: true,
+		},
+		{
+			name:     "argon2id hash with bcrypt configured",
+			crypto:   bcryptCrypto,
+			hash:     argon2Hash,
+			expected: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			s.Equal(row.expected, row.crypto.NeedsRehash(row.hash))
+
+			// rehashing never breaks login with the old hash
+			s.NoError(row.crypto.CompareHashAndPassword(row.hash, "test password"))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"errors"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+
+	// tokenHashPrefix hash of random token, hex encoded sha-256
+	tokenHashPrefix = "$sha256$"
+)
+
+// bcryptPrefixes bcrypt hash versions
+var bcryptPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+var (
+	// ErrUnknownAlgorithm unsupported hashing algorithm in config
+	ErrUnknownAlgorithm = errors.New("unknown password hashing algorithm")
+	// ErrInvalidParams hashing parameters are out of range
+	ErrInvalidParams = errors.New("invalid password hashing parameters")
+	// ErrUnknownHashFormat hash is produced by unsupported algorithm
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	// ErrInvalidHash hash is broken
+	ErrInvalidHash = errors.New("invalid password hash")
+	// ErrMismatchedHashAndPassword password does not match the hash
+	ErrMismatchedHashAndPassword = errors.New("hashed password is not the hash of the given password")
+)
+
+// argon2Params parameters encoded in argon2id hash
+type argon2Params struct {
+	Memory     uint32
+	Time       uint32
+	Threads    uint8
+	SaltLength uint32
+	KeyLength  uint32
+}
@@ -2,15 +2,15 @@ package jwt
 
 import (
 	"errors"
-	"os"
-	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
-	// TokenTTL token validity period
-	TokenTTL = 20 * time.Minute
+	// AlgorithmRS256 RSA signature with SHA-256
+	AlgorithmRS256 = "RS256"
+	// AlgorithmEdDSA Ed25519 signature
+	AlgorithmEdDSA = "EdDSA"
 )
 
 var (
@@ -22,13 +22,41 @@ var (
 	ErrFailedParseToken = errors.New("failed to parse token")
 	// ErrInvalidToken invalid token
 	ErrInvalidToken = errors.New("invalid token")
-)
-
-var (
-	jwtSecret = os.Getenv("JWT_SECRET")
+	// ErrNoSigningKey there are no active keys and no secret
+	ErrNoSigningKey = errors.New("no active signing key")
+	// ErrUnknownKey token is signed with unknown key
+	ErrUnknownKey = errors.New("unknown signing key")
 )
 
 type tokenClaims struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
+
+// signingKey asymmetric key loaded from config
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	jwk    jsonWebKey
+
+	private any
+	public  any
+}
+
+// jsonWebKey public key in JWK format (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
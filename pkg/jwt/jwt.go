@@ -1,41 +1,91 @@
 package jwt
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/fallra1n/product-keeper/config"
 )
 
 // Jwt struct for working with jwt
-type Jwt struct{}
+type Jwt struct {
+	secret []byte
+	ttl    time.Duration
 
-// NewJwt constructor for Jwt
-func NewJwt() *Jwt {
-	return &Jwt{}
+	keys     map[string]signingKey
+	schedule []config.JwtKey
 }
 
-// GenerateToken generate token
-func (j Jwt) GenerateToken(username string) (string, error) {
-	return GenerateToken(username)
-}
+// NewJwt constructor for Jwt, loads signing keys from config
+func NewJwt(cfg config.Jwt) (*Jwt, error) {
+	j := &Jwt{
+		secret: []byte(cfg.Secret),
+		ttl:    cfg.TTL,
+		keys:   make(map[string]signingKey, len(cfg.Keys)),
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		if _, ok := j.keys[keyCfg.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %s", keyCfg.ID)
+		}
+
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		j.keys[keyCfg.ID] = key
+		j.schedule = append(j.schedule, keyCfg)
+	}
+
+	// newest keys first
+	slices.SortFunc(j.schedule, func(a, b config.JwtKey) int {
+		return b.ActiveFrom.Compare(a.ActiveFrom)
+	})
+
+	if len(j.secret) == 0 && len(j.schedule) == 0 {
+		return nil, ErrNoSigningKey
+	}
 
-// ParseToken parse token
-func (j Jwt) ParseToken(tokenString string) (string, error) {
-	return ParseToken(tokenString)
+	return j, nil
 }
 
-// GenerateToken generate token
-func GenerateToken(username string) (string, error) {
+// GenerateToken generate token bound to the session, signed with the current key
+func (j *Jwt) GenerateToken(username string, role string, sessionID string) (string, error) {
+	now := time.Now()
+
 	claims := &tokenClaims{
-		Username: username,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.ttl)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	key, ok := j.activeKey(now)
+	if !ok {
+		if len(j.secret) == 0 {
+			return "", ErrNoSigningKey
+		}
+
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secret)
+		if err != nil {
+			return "", ErrFailedGenerateToken
+		}
+
+		return tokenString, nil
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
 
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	tokenString, err := token.SignedString(key.private)
 	if err != nil {
 		return "", ErrFailedGenerateToken
 	}
@@ -43,23 +93,85 @@ func GenerateToken(username string) (string, error) {
 	return tokenString, nil
 }
 
-// ParseToken parse token
-func ParseToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
+// ParseToken parse token, returns username, role and session id
+func (j *Jwt) ParseToken(tokenString string) (string, string, string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, j.verificationKey)
 	if err != nil {
-		return "", ErrFailedParseToken
+		return "", "", "", ErrFailedParseToken
 	}
 
 	if !token.Valid {
-		return "", ErrInvalidToken
+		return "", "", "", ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return "", ErrInvalidTokenClaimsType
+		return "", "", "", ErrInvalidTokenClaimsType
+	}
+
+	return claims.Username, claims.Role, claims.SessionID, nil
+}
+
+// JWKS get public keys in JWK Set format, HS256 secret and retired keys are never published
+func (j *Jwt) JWKS() ([]byte, error) {
+	now := time.Now()
+
+	set := jsonWebKeySet{Keys: make([]jsonWebKey, 0, len(j.schedule))}
+	for _, keyCfg := range j.schedule {
+		if j.retired(keyCfg.ID, now) {
+			continue
+		}
+
+		set.Keys = append(set.Keys, j.keys[keyCfg.ID].jwk)
+	}
+
+	return json.Marshal(set)
+}
+
+// activeKey the newest key which is already active
+func (j *Jwt) activeKey(now time.Time) (signingKey, bool) {
+	for _, keyCfg := range j.schedule {
+		if !keyCfg.ActiveFrom.After(now) {
+			return j.keys[keyCfg.ID], true
+		}
+	}
+
+	return signingKey{}, false
+}
+
+// retired reports whether the key was replaced by the next key more than ttl ago.
+// All tokens signed with it have expired, so it is no longer trusted and can be removed from config at any time
+func (j *Jwt) retired(id string, now time.Time) bool {
+	for i, keyCfg := range j.schedule {
+		if keyCfg.ID != id {
+			continue
+		}
+
+		if i == 0 {
+			return false
+		}
+
+		replacedAt := j.schedule[i-1].ActiveFrom
+		return !replacedAt.After(now) && now.Sub(replacedAt) > j.ttl
+	}
+
+	return false
+}
+
+func (j *Jwt) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		if token.Method != jwt.SigningMethodHS256 || len(j.secret) == 0 {
+			return nil, ErrUnknownKey
+		}
+
+		return j.secret, nil
+	}
+
+	key, ok := j.keys[kid]
+	if !ok || token.Method != key.method || j.retired(kid, time.Now()) {
+		return nil, ErrUnknownKey
 	}
 
-	return claims.Username, err
+	return key.public, nil
 }
@@ -0,0 +1,410 @@
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/pkg/jwt"
+)
+
+const ttl = time.Hour
+
+type Suite struct {
+	suite.Suite
+
+	rsaKey   *rsa.PrivateKey
+	rsaPath  string
+	pkcs1    string
+	edKey    ed25519.PrivateKey
+	edPath   string
+	notPEM   string
+	rsaOther string
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupSuite() {
+	dir := s.T().TempDir()
+
+	var err error
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	_, s.edKey, err = ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+
+	s.rsaPath = s.writePKCS8(dir, "rsa.pem", s.rsaKey)
+	s.edPath = s.writePKCS8(dir, "ed25519.pem", s.edKey)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+	s.rsaOther = s.writePKCS8(dir, "other.pem", otherKey)
+
+	// openssl genrsa format
+	s.pkcs1 = filepath.Join(dir, "pkcs1.pem")
+	s.Require().NoError(os.WriteFile(s.pkcs1, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(s.rsaKey),
+	}), 0o600))
+
+	s.notPEM = filepath.Join(dir, "key.txt")
+	s.Require().NoError(os.WriteFile(s.notPEM, []byte("not a key"), 0o600))
+}
+
+func (s *Suite) writePKCS8(dir, name string, key any) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	s.Require().NoError(err)
+
+	path := filepath.Join(dir, name)
+	s.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	return path
+}
+
+func (s *Suite) newJwt(secret string, keys ...config.JwtKey) *jwt.Jwt {
+	j, err := jwt.NewJwt(config.Jwt{Secret: secret, TTL: ttl, Keys: keys})
+	s.Require().NoError(err)
+
+	return j
+}
+
+// header decoded jose header of the token
+func (s *Suite) header(token string) map[string]any {
+	parsed, _, err := gojwt.NewParser().ParseUnverified(token, gojwt.MapClaims{})
+	s.Require().NoError(err)
+
+	return parsed.Header
+}
+
+func (s *Suite) TestNewJwt() {
+	now := time.Now()
+
+	testList := []struct {
+		name    string
+		cfg     config.Jwt
+		isError bool
+	}{
+		{
+			name: "secret only",
+			cfg:  config.Jwt{Secret: "test secret", TTL: ttl},
+		},
+		{
+			name: "keys without secret",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now},
+				{ID: "ed", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now},
+			}},
+		},
+		{
+			name: "pkcs1 rsa key",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.pkcs1, ActiveFrom: now},
+			}},
+		},
+		{
+			name:    "no secret and no keys",
+			cfg:     config.Jwt{TTL: ttl},
+			isError: true,
+		},
+		{
+			name: "duplicate key id",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now},
+				{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.rsaOther, ActiveFrom: now},
+			}},
+			isError: true,
+		},
+		{
+			name: "missing key file",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: filepath.Join(s.T().TempDir(), "missing.pem"), ActiveFrom: now},
+			}},
+			isError: true,
+		},
+		{
+			name: "key is not PEM encoded",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.notPEM, ActiveFrom: now},
+			}},
+			isError: true,
+		},
+		{
+			name: "algorithm does not match key",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "ed", Algorithm: jwt.AlgorithmRS256, Path: s.edPath, ActiveFrom: now},
+			}},
+			isError: true,
+		},
+		{
+			name: "unsupported algorithm",
+			cfg: config.Jwt{TTL: ttl, Keys: []config.JwtKey{
+				{ID: "rsa", Algorithm: "RS512", Path: s.rsaPath, ActiveFrom: now},
+			}},
+			isError: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			_, err := jwt.NewJwt(row.cfg)
+			s.Equal(row.isError, err != nil, err)
+		})
+	}
+}
+
+func (s *Suite) TestActiveKey() {
+	now := time.Now()
+
+	testList := []struct {
+		name string
+		keys []config.JwtKey
+		kid  any
+		alg  string
+	}{
+		{
+			name: "newest active key",
+			keys: []config.JwtKey{
+				{ID: "old", Algorithm: jwt.AlgorithmRS256, Path: s.rsaOther, ActiveFrom: now.Add(-2 * time.Hour)},
+				{ID: "current", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(-time.Minute)},
+				{ID: "next", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now.Add(time.Hour)},
+			},
+			kid: "current",
+			alg: jwt.AlgorithmEdDSA,
+		},
+		{
+			name: "order in config does not matter",
+			keys: []config.JwtKey{
+				{ID: "next", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now.Add(time.Hour)},
+				{ID: "current", Algorithm: jwt.AlgorithmRS256, Path: s.rsaOther, ActiveFrom: now.Add(-time.Minute)},
+				{ID: "old", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(-2 * time.Hour)},
+			},
+			kid: "current",
+			alg: jwt.AlgorithmRS256,
+		},
+		{
+			name: "secret until the first key is active",
+			keys: []config.JwtKey{
+				{ID: "next", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now.Add(time.Hour)},
+			},
+			kid: nil,
+			alg: "HS256",
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			j := s.newJwt("test secret", row.keys...)
+
+			token, err := j.GenerateToken("test name", "user", "test session")
+			s.Require().NoError(err)
+
+			header := s.header(token)
+			s.Equal(row.kid, header["kid"])
+			s.Equal(row.alg, header["alg"])
+
+			username, role, sessionID, err := j.ParseToken(token)
+			s.Require().NoError(err)
+			s.Equal("test name", username)
+			s.Equal("user", role)
+			s.Equal("test session", sessionID)
+		})
+	}
+}
+
+func (s *Suite) TestNoActiveKeyWithoutSecret() {
+	j := s.newJwt("", config.JwtKey{ID: "next", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: time.Now().Add(time.Hour)})
+
+	_, err := j.GenerateToken("test name", "user", "test session")
+	s.ErrorIs(err, jwt.ErrNoSigningKey)
+}
+
+func (s *Suite) TestRotatedKeys() {
+	now := time.Now()
+	previous := config.JwtKey{ID: "previous", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now.Add(-3 * time.Hour)}
+
+	// token signed before rotation
+	token, err := s.newJwt("", previous).GenerateToken("test name", "user", "test session")
+	s.Require().NoError(err)
+
+	testList := []struct {
+		name    string
+		keys    []config.JwtKey
+		isError bool
+	}{
+		{
+			name: "next key is not active yet",
+			keys: []config.JwtKey{
+				previous,
+				{ID: "next", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(time.Hour)},
+			},
+		},
+		{
+			name: "previous key verifies tokens within ttl after rotation",
+			keys: []config.JwtKey{
+				previous,
+				{ID: "next", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(-time.Minute)},
+			},
+		},
+		{
+			name: "previous key is retired after ttl",
+			keys: []config.JwtKey{
+				previous,
+				{ID: "next", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(-ttl - time.Minute)},
+			},
+			isError: true,
+		},
+		{
+			name: "previous key is removed",
+			keys: []config.JwtKey{
+				{ID: "next", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(-time.Minute)},
+			},
+			isError: true,
+		},
+		{
+			name: "kid of another key",
+			keys: []config.JwtKey{
+				{ID: "previous", Algorithm: jwt.AlgorithmRS256, Path: s.rsaOther, ActiveFrom: now.Add(-3 * time.Hour)},
+			},
+			isError: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			_, _, _, err := s.newJwt("", row.keys...).ParseToken(token)
+			s.Equal(row.isError, err != nil, err)
+		})
+	}
+}
+
+func (s *Suite) TestRejectedTokens() {
+	now := time.Now()
+	key := config.JwtKey{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now.Add(-time.Hour)}
+	claims := gojwt.MapClaims{"username": "test name", "role": "admin", "exp": now.Add(ttl).Unix()}
+
+	// HS256 token signed with the public key of kid must not be accepted
+	der, err := x509.MarshalPKIXPublicKey(&s.rsaKey.PublicKey)
+	s.Require().NoError(err)
+	confused := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims)
+	confused.Header["kid"] = key.ID
+	confusedToken, err := confused.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	s.Require().NoError(err)
+
+	secretToken, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims).SignedString([]byte("test secret"))
+	s.Require().NoError(err)
+
+	expired, err := jwt.NewJwt(config.Jwt{TTL: -time.Minute, Keys: []config.JwtKey{key}})
+	s.Require().NoError(err)
+	expiredToken, err := expired.GenerateToken("test name", "user", "test session")
+	s.Require().NoError(err)
+
+	unsignedToken, err := gojwt.NewWithClaims(gojwt.SigningMethodNone, claims).SignedString(gojwt.UnsafeAllowNoneSignatureType)
+	s.Require().NoError(err)
+
+	testList := []struct {
+		name  string
+		jwt   *jwt.Jwt
+		token string
+	}{
+		{
+			name:  "algorithm of kid is replaced",
+			jwt:   s.newJwt("test secret", key),
+			token: confusedToken,
+		},
+		{
+			name:  "HS256 without configured secret",
+			jwt:   s.newJwt("", key),
+			token: secretToken,
+		},
+		{
+			name:  "HS256 with another secret",
+			jwt:   s.newJwt("other secret", key),
+			token: secretToken,
+		},
+		{
+			name:  "expired token",
+			jwt:   s.newJwt("", key),
+			token: expiredToken,
+		},
+		{
+			name:  "unsigned token",
+			jwt:   s.newJwt("test secret", key),
+			token: unsignedToken,
+		},
+		{
+			name:  "malformed token",
+			jwt:   s.newJwt("test secret", key),
+			token: "test token",
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			_, _, _, err := row.jwt.ParseToken(row.token)
+			s.ErrorIs(err, jwt.ErrFailedParseToken)
+		})
+	}
+}
+
+func (s *Suite) TestJWKS() {
+	now := time.Now()
+	j := s.newJwt("test secret",
+		config.JwtKey{ID: "retired", Algorithm: jwt.AlgorithmRS256, Path: s.rsaOther, ActiveFrom: now.Add(-3 * time.Hour)},
+		config.JwtKey{ID: "rsa", Algorithm: jwt.AlgorithmRS256, Path: s.rsaPath, ActiveFrom: now.Add(-2 * time.Hour)},
+		config.JwtKey{ID: "ed", Algorithm: jwt.AlgorithmEdDSA, Path: s.edPath, ActiveFrom: now.Add(-time.Minute)},
+		config.JwtKey{ID: "next", Algorithm: jwt.AlgorithmRS256, Path: s.pkcs1, ActiveFrom: now.Add(time.Hour)},
+	)
+
+	data, err := j.JWKS()
+	s.Require().NoError(err)
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	s.Require().NoError(json.Unmarshal(data, &set))
+
+	// newest first, the next key is published before it signs tokens, the retired key is not published
+	s.Require().Len(set.Keys, 3)
+	s.Equal("next", set.Keys[0]["kid"])
+	s.Equal("ed", set.Keys[1]["kid"])
+	s.Equal("rsa", set.Keys[2]["kid"])
+
+	ed := set.Keys[1]
+	s.Equal("OKP", ed["kty"])
+	s.Equal("Ed25519", ed["crv"])
+	s.Equal(jwt.AlgorithmEdDSA, ed["alg"])
+	s.Equal("sig", ed["use"])
+	s.Equal(base64.RawURLEncoding.EncodeToString(s.edKey.Public().(ed25519.PublicKey)), ed["x"])
+	s.NotContains(ed, "d")
+
+	rsaKey := set.Keys[2]
+	s.Equal("RSA", rsaKey["kty"])
+	s.Equal(jwt.AlgorithmRS256, rsaKey["alg"])
+	s.Equal("sig", rsaKey["use"])
+
+	n, err := base64.RawURLEncoding.DecodeString(rsaKey["n"])
+	s.Require().NoError(err)
+	e, err := base64.RawURLEncoding.DecodeString(rsaKey["e"])
+	s.Require().NoError(err)
+	s.Equal(0, s.rsaKey.N.Cmp(new(big.Int).SetBytes(n)))
+	s.Equal(int64(s.rsaKey.E), new(big.Int).SetBytes(e).Int64())
+	s.NotContains(rsaKey, "d")
+
+	s.NotContains(string(data), "test secret")
+}
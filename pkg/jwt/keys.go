@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/fallra1n/product-keeper/config"
+)
+
+// loadKey reads PEM encoded private key from file
+func loadKey(cfg config.JwtKey) (signingKey, error) {
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("failed to read key %s: %w", cfg.ID, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, fmt.Errorf("key %s is not PEM encoded", cfg.ID)
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		// openssl genrsa writes PKCS1
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return signingKey{}, fmt.Errorf("failed to parse key %s: %w", cfg.ID, err)
+		}
+	}
+
+	key := signingKey{id: cfg.ID, private: private}
+
+	switch cfg.Algorithm {
+	case AlgorithmRS256:
+		rsaKey, ok := private.(*rsa.PrivateKey)
+		if !ok {
+			return signingKey{}, fmt.Errorf("key %s is not RSA key", cfg.ID)
+		}
+
+		key.method = jwt.SigningMethodRS256
+		key.public = &rsaKey.PublicKey
+		key.jwk = jsonWebKey{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}
+	case AlgorithmEdDSA:
+		edKey, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return signingKey{}, fmt.Errorf("key %s is not Ed25519 key", cfg.ID)
+		}
+
+		public := edKey.Public().(ed25519.PublicKey)
+
+		key.method = jwt.SigningMethodEdDSA
+		key.public = public
+		key.jwk = jsonWebKey{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}
+	default:
+		return signingKey{}, fmt.Errorf("key %s has unsupported algorithm %q", cfg.ID, cfg.Algorithm)
+	}
+
+	key.jwk.Use = "sig"
+	key.jwk.Alg = cfg.Algorithm
+	key.jwk.Kid = cfg.ID
+
+	return key, nil
+}
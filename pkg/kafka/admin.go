@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"github.com/fallra1n/product-keeper/config"
+)
+
+const retentionConfig = "retention.ms"
+
+// EnsureTopics creates missing topics with configured partitions, replication factor and retention,
+// returns error if an existing topic has other settings
+func EnsureTopics(urlList []string, cluster config.KafkaCluster, topics []string) error {
+	detail, err := topicDetail(cluster)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := clientConfig(cluster)
+	if err != nil {
+		return err
+	}
+
+	admin, err := sarama.NewClusterAdmin(urlList, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create kafka cluster admin: %w", err)
+	}
+	defer admin.Close()
+
+	existing, err := admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("failed to list kafka topics: %w", err)
+	}
+
+	for _, topic := range topics {
+		if current, ok := existing[topic]; ok {
+			if err := compareTopic(topic, current, *detail); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// the topic may be created at the same time by another service with the same config
+		err := admin.CreateTopic(topic, detail, false)
+		if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+			return fmt.Errorf("failed to create kafka topic %s: %w", topic, err)
+		}
+	}
+
+	return nil
+}
+
+func topicDetail(cluster config.KafkaCluster) (*sarama.TopicDetail, error) {
+	provisioning := cluster.Provisioning
+
+	if provisioning.Partitions < 1 {
+		return nil, fmt.Errorf("kafka provisioning: partitions must be positive, got %d", provisioning.Partitions)
+	}
+
+	if cluster.ReplicationFactor < 1 || cluster.ReplicationFactor > math.MaxInt16 {
+		return nil, fmt.Errorf("kafka provisioning: invalid replication factor %d", cluster.ReplicationFactor)
+	}
+
+	if provisioning.Retention < 0 {
+		return nil, fmt.Errorf("kafka provisioning: negative retention %s", provisioning.Retention)
+	}
+
+	detail := &sarama.TopicDetail{
+		NumPartitions:     provisioning.Partitions,
+		ReplicationFactor: int16(cluster.ReplicationFactor),
+		ConfigEntries:     make(map[string]*string),
+	}
+
+	// zero retention keeps broker default
+	if provisioning.Retention > 0 {
+		retention := strconv.FormatInt(provisioning.Retention.Milliseconds(), 10)
+		detail.ConfigEntries[retentionConfig] = &retention
+	}
+
+	return detail, nil
+}
+
+func compareTopic(topic string, current, expected sarama.TopicDetail) error {
+	if current.NumPartitions != expected.NumPartitions {
+		return fmt.Errorf("kafka topic %s has %d partitions, configured %d", topic, current.NumPartitions, expected.NumPartitions)
+	}
+
+	if current.ReplicationFactor != expected.ReplicationFactor {
+		return fmt.Errorf("kafka topic %s has replication factor %d, configured %d", topic, current.ReplicationFactor, expected.ReplicationFactor)
+	}
+
+	retention, ok := expected.ConfigEntries[retentionConfig]
+	if !ok {
+		return nil
+	}
+
+	// only settings that differ from broker defaults are listed
+	currentRetention, ok := current.ConfigEntries[retentionConfig]
+	if !ok || currentRetention == nil {
+		return fmt.Errorf("kafka topic %s has default retention, configured %s ms", topic, *retention)
+	}
+
+	if *currentRetention != *retention {
+		return fmt.Errorf("kafka topic %s has retention %s ms, configured %s ms", topic, *currentRetention, *retention)
+	}
+
+	return nil
+}
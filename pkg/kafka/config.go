@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/IBM/sarama"
+
+	"github.com/fallra1n/product-keeper/config"
+)
+
+// clientConfig sarama config with authentication and encryption of the cluster
+func clientConfig(cluster config.KafkaCluster) (*sarama.Config, error) {
+	cfg := sarama.NewConfig()
+
+	if err := setSASL(cfg, cluster.SASL); err != nil {
+		return nil, fmt.Errorf("kafka sasl: %w", err)
+	}
+
+	if err := setTLS(cfg, cluster.TLS); err != nil {
+		return nil, fmt.Errorf("kafka tls: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func setSASL(cfg *sarama.Config, sasl config.KafkaSASL) error {
+	switch sasl.Mechanism {
+	case "", "none":
+		return nil
+	case "plain":
+		cfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case "scram-sha-256":
+		cfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		cfg.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{generator: sha256Generator}
+		}
+	case "scram-sha-512":
+		cfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		cfg.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{generator: sha512Generator}
+		}
+	default:
+		return fmt.Errorf("unknown mechanism %q", sasl.Mechanism)
+	}
+
+	if sasl.User == "" || sasl.Password == "" {
+		return errors.New("user and password are required")
+	}
+
+	cfg.Net.SASL.Enable = true
+	cfg.Net.SASL.Handshake = true
+	cfg.Net.SASL.User = sasl.User
+	cfg.Net.SASL.Password = sasl.Password
+
+	return nil
+}
+
+func setTLS(cfg *sarama.Config, settings config.KafkaTLS) error {
+	if !settings.Enabled {
+		if settings.CAFile != "" || settings.CertFile != "" || settings.KeyFile != "" {
+			return errors.New("certificates are set but tls is disabled")
+		}
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+	}
+
+	if settings.CAFile != "" {
+		ca, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return fmt.Errorf("cannot read ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("no certificates in ca file %s", settings.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return errors.New("client certificate requires both cert file and key file")
+	}
+
+	if settings.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	cfg.Net.TLS.Enable = true
+	cfg.Net.TLS.Config = tlsConfig
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/fallra1n/product-keeper/config"
+)
+
+// NewConsumerGroup get new kafka consumer group, new group starts from the oldest messages
+func NewConsumerGroup(urlList []string, cluster config.KafkaCluster, group string) (sarama.ConsumerGroup, error) {
+	cfg, err := clientConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	cfg.Consumer.Return.Errors = true
+
+	conn, err := sarama.NewConsumerGroup(urlList, group, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka consumer group: %w", err)
+	}
+
+	return conn, nil
+}
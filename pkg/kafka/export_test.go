@@ -0,0 +1,10 @@
+package kafka
+
+// unexported config builders and topic checks are tested from kafka_test
+var (
+	SetSASL        = setSASL
+	SetTLS         = setTLS
+	ProducerConfig = producerConfig
+	TopicDetail    = topicDetail
+	CompareTopic   = compareTopic
+)
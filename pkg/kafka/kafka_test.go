@@ -0,0 +1,462 @@
+package kafka_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/config"
+	"github.com/fallra1n/product-keeper/pkg/kafka"
+)
+
+var mockProducer = config.KafkaProducer{
+	Partitioner:  "hash",
+	RequiredAcks: "all",
+	RetryMax:     5,
+	Compression:  "none",
+}
+
+type Suite struct {
+	suite.Suite
+	missing string
+	notPEM  string
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupSuite() {
+	dir := s.T().TempDir()
+
+	s.missing = filepath.Join(dir, "missing.pem")
+	s.notPEM = filepath.Join(dir, "ca.txt")
+	s.Require().NoError(os.WriteFile(s.notPEM, []byte("not a certificate"), 0o600))
+}
+
+func (s *Suite) TestSetSASL() {
+	testList := []struct {
+		name      string
+		sasl      config.KafkaSASL
+		mechanism sarama.SASLMechanism
+		enabled   bool
+		isError   bool
+	}{
+		{
+			name: "empty mechanism",
+			sasl: config.KafkaSASL{},
+		},
+		{
+			name: "none",
+			sasl: config.KafkaSASL{Mechanism: "none", User: "test user"},
+		},
+		{
+			name:      "plain",
+			sasl:      config.KafkaSASL{Mechanism: "plain", User: "test user", Password: "test password"},
+			mechanism: sarama.SASLTypePlaintext,
+			enabled:   true,
+		},
+		{
+			name:      "scram-sha-256",
+			sasl:      config.KafkaSASL{Mechanism: "scram-sha-256", User: "test user", Password: "test password"},
+			mechanism: sarama.SASLTypeSCRAMSHA256,
+			enabled:   true,
+		},
+		{
+			name:      "scram-sha-512",
+			sasl:      config.KafkaSASL{Mechanism: "scram-sha-512", User: "test user", Password: "test password"},
+			mechanism: sarama.SASLTypeSCRAMSHA512,
+			enabled:   true,
+		},
+		{
+			name:    "unknown mechanism",
+			sasl:    config.KafkaSASL{Mechanism: "gssapi", User: "test user", Password: "test password"},
+			isError: true,
+		},
+		{
+			name:    "scram without user",
+			sasl:    config.KafkaSASL{Mechanism: "scram-sha-256", Password: "test password"},
+			isError: true,
+		},
+		{
+			name:    "scram without password",
+			sasl:    config.KafkaSASL{Mechanism: "scram-sha-512", User: "test user"},
+			isError: true,
+		},
+		{
+			name:    "plain without password",
+			sasl:    config.KafkaSASL{Mechanism: "plain", User: "test user"},
+			isError: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			cfg := sarama.NewConfig()
+
+			err := kafka.SetSASL(cfg, row.sasl)
+			s.Equal(row.isError, err != nil, err)
+			if err != nil {
+				return
+			}
+
+			s.Equal(row.enabled, cfg.Net.SASL.Enable)
+			if row.enabled {
+				s.Equal(row.mechanism, cfg.Net.SASL.Mechanism)
+				s.Equal(row.sasl.User, cfg.Net.SASL.User)
+				s.Equal(row.sasl.Password, cfg.Net.SASL.Password)
+			}
+		})
+	}
+}
+
+func (s *Suite) TestSetTLS() {
+	testList := []struct {
+		name    string
+		tls     config.KafkaTLS
+		enabled bool
+		isError bool
+	}{
+		{
+			name: "disabled",
+			tls:  config.KafkaTLS{},
+		},
+		{
+			name:    "enabled with system roots",
+			tls:     config.KafkaTLS{Enabled: true},
+			enabled: true,
+		},
+		{
+			name:    "ca file while tls is disabled",
+			tls:     config.KafkaTLS{CAFile: s.notPEM},
+			isError: true,
+		},
+		{
+			name:    "client certificate while tls is disabled",
+			tls:     config.KafkaTLS{CertFile: "cert.pem", KeyFile: "key.pem"},
+			isError: true,
+		},
+		{
+			name:    "cert file without key file",
+			tls:     config.KafkaTLS{Enabled: true, CertFile: "cert.pem"},
+			isError: true,
+		},
+		{
+			name:    "key file without cert file",
+			tls:     config.KafkaTLS{Enabled: true, KeyFile: "key.pem"},
+			isError: true,
+		},
+		{
+			name:    "unreadable ca file",
+			tls:     config.KafkaTLS{Enabled: true, CAFile: s.missing},
+			isError: true,
+		},
+		{
+			name:    "no certificates in ca file",
+			tls:     config.KafkaTLS{Enabled: true, CAFile: s.notPEM},
+			isError: true,
+		},
+		{
+			name:    "unreadable client certificate",
+			tls:     config.KafkaTLS{Enabled: true, CertFile: s.missing, KeyFile: s.missing},
+			isError: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			cfg := sarama.NewConfig()
+
+			err := kafka.SetTLS(cfg, row.tls)
+			s.Equal(row.isError, err != nil, err)
+			s.Equal(row.enabled, cfg.Net.TLS.Enable)
+		})
+	}
+}
+
+func (s *Suite) TestProducerConfig() {
+	withProducer := func(update func(p *config.KafkaProducer)) config.KafkaCluster {
+		producer := mockProducer
+		update(&producer)
+		return config.KafkaCluster{Producer: producer}
+	}
+
+	testList := []struct {
+		name       string
+		cluster    config.KafkaCluster
+		idempotent bool
+		isError    bool
+	}{
+		{
+			name:    "defaults",
+			cluster: config.KafkaCluster{Producer: mockProducer},
+		},
+		{
+			name: "idempotent",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.Idempotent = true
+			}),
+			idempotent: true,
+		},
+		{
+			name: "idempotent with local acks",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.Idempotent = true
+				p.RequiredAcks = "local"
+			}),
+			isError: true,
+		},
+		{
+			name: "idempotent without acks",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.Idempotent = true
+				p.RequiredAcks = "none"
+			}),
+			isError: true,
+		},
+		{
+			name: "idempotent without retries",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.Idempotent = true
+				p.RetryMax = 0
+			}),
+			isError: true,
+		},
+		{
+			name: "unknown partitioner",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.Partitioner = "sticky"
+			}),
+			isError: true,
+		},
+		{
+			name: "unknown required acks",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.RequiredAcks = "leader"
+			}),
+			isError: true,
+		},
+		{
+			name: "unknown compression",
+			cluster: withProducer(func(p *config.KafkaProducer) {
+				p.Compression = "brotli"
+			}),
+			isError: true,
+		},
+		{
+			name: "invalid sasl",
+			cluster: config.KafkaCluster{
+				Producer: mockProducer,
+				SASL:     config.KafkaSASL{Mechanism: "scram-sha-256"},
+			},
+			isError: true,
+		},
+		{
+			name: "invalid tls",
+			cluster: config.KafkaCluster{
+				Producer: mockProducer,
+				TLS:      config.KafkaTLS{Enabled: true, CAFile: s.missing},
+			},
+			isError: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			cfg, err := kafka.ProducerConfig(row.cluster)
+			s.Equal(row.isError, err != nil, err)
+			if err != nil {
+				return
+			}
+
+			s.Equal(row.idempotent, cfg.Producer.Idempotent)
+			s.True(cfg.Producer.Return.Successes)
+			s.Equal(row.cluster.Producer.RetryMax, cfg.Producer.Retry.Max)
+		})
+	}
+}
+
+func (s *Suite) TestTopicDetail() {
+	testList := []struct {
+		name     string
+		cluster  config.KafkaCluster
+		expected *sarama.TopicDetail
+		isError  bool
+	}{
+		{
+			name: "with retention",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: 3,
+				Provisioning:      config.KafkaProvisioning{Partitions: 6, Retention: time.Hour},
+			},
+			expected: &sarama.TopicDetail{
+				NumPartitions:     6,
+				ReplicationFactor: 3,
+				ConfigEntries:     map[string]*string{"retention.ms": ptr("3600000")},
+			},
+		},
+		{
+			name: "broker default retention",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: 1,
+				Provisioning:      config.KafkaProvisioning{Partitions: 1},
+			},
+			expected: &sarama.TopicDetail{
+				NumPartitions:     1,
+				ReplicationFactor: 1,
+				ConfigEntries:     map[string]*string{},
+			},
+		},
+		{
+			name: "max replication factor",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: math.MaxInt16,
+				Provisioning:      config.KafkaProvisioning{Partitions: 1},
+			},
+			expected: &sarama.TopicDetail{
+				NumPartitions:     1,
+				ReplicationFactor: math.MaxInt16,
+				ConfigEntries:     map[string]*string{},
+			},
+		},
+		{
+			name: "zero partitions",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: 1,
+				Provisioning:      config.KafkaProvisioning{Partitions: 0},
+			},
+			isError: true,
+		},
+		{
+			name: "negative partitions",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: 1,
+				Provisioning:      config.KafkaProvisioning{Partitions: -1},
+			},
+			isError: true,
+		},
+		{
+			name: "zero replication factor",
+			cluster: config.KafkaCluster{
+				Provisioning: config.KafkaProvisioning{Partitions: 1},
+			},
+			isError: true,
+		},
+		{
+			name: "replication factor above int16",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: math.MaxInt16 + 1,
+				Provisioning:      config.KafkaProvisioning{Partitions: 1},
+			},
+			isError: true,
+		},
+		{
+			name: "negative retention",
+			cluster: config.KafkaCluster{
+				ReplicationFactor: 1,
+				Provisioning:      config.KafkaProvisioning{Partitions: 1, Retention: -time.Hour},
+			},
+			isError: true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			detail, err := kafka.TopicDetail(row.cluster)
+			s.Equal(row.isError, err != nil, err)
+			s.Equal(row.expected, detail)
+		})
+	}
+}
+
+func (s *Suite) TestCompareTopic() {
+	expected := sarama.TopicDetail{
+		NumPartitions:     3,
+		ReplicationFactor: 2,
+		ConfigEntries:     map[string]*string{"retention.ms": ptr("3600000")},
+	}
+
+	testList := []struct {
+		name     string
+		current  sarama.TopicDetail
+		expected sarama.TopicDetail
+		isError  bool
+	}{
+		{
+			name:     "same settings",
+			current:  expected,
+			expected: expected,
+		},
+		{
+			name: "broker default retention is not compared",
+			current: sarama.TopicDetail{
+				NumPartitions:     3,
+				ReplicationFactor: 2,
+				ConfigEntries:     map[string]*string{"retention.ms": ptr("60000")},
+			},
+			expected: sarama.TopicDetail{NumPartitions: 3, ReplicationFactor: 2, ConfigEntries: map[string]*string{}},
+		},
+		{
+			name: "partitions mismatch",
+			current: sarama.TopicDetail{
+				NumPartitions:     1,
+				ReplicationFactor: 2,
+				ConfigEntries:     map[string]*string{"retention.ms": ptr("3600000")},
+			},
+			expected: expected,
+			isError:  true,
+		},
+		{
+			name: "replication factor mismatch",
+			current: sarama.TopicDetail{
+				NumPartitions:     3,
+				ReplicationFactor: 1,
+				ConfigEntries:     map[string]*string{"retention.ms": ptr("3600000")},
+			},
+			expected: expected,
+			isError:  true,
+		},
+		{
+			name: "retention mismatch",
+			current: sarama.TopicDetail{
+				NumPartitions:     3,
+				ReplicationFactor: 2,
+				ConfigEntries:     map[string]*string{"retention.ms": ptr("60000")},
+			},
+			expected: expected,
+			isError:  true,
+		},
+		{
+			name:     "topic has default retention",
+			current:  sarama.TopicDetail{NumPartitions: 3, ReplicationFactor: 2},
+			expected: expected,
+			isError:  true,
+		},
+		{
+			name: "topic retention is empty",
+			current: sarama.TopicDetail{
+				NumPartitions:     3,
+				ReplicationFactor: 2,
+				ConfigEntries:     map[string]*string{"retention.ms": nil},
+			},
+			expected: expected,
+			isError:  true,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			err := kafka.CompareTopic("test topic", row.current, row.expected)
+			s.Equal(row.isError, err != nil, err)
+		})
+	}
+}
+
+func ptr(value string) *string {
+	return &value
+}
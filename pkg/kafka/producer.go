@@ -1,41 +1,111 @@
 package kafka
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/IBM/sarama"
+
+	"github.com/fallra1n/product-keeper/config"
 )
 
 // NewSyncProducer get new kafka sync producer
-func NewSyncProducer(urlList []string) sarama.SyncProducer {
-	cfg := sarama.NewConfig()
-
-	cfg.Producer.Partitioner = sarama.NewRandomPartitioner
-	cfg.Producer.RequiredAcks = sarama.WaitForAll
-	cfg.Producer.Return.Successes = true
-	cfg.Producer.Retry.Max = 5
+func NewSyncProducer(urlList []string, cluster config.KafkaCluster) (sarama.SyncProducer, error) {
+	cfg, err := producerConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
 
 	conn, err := sarama.NewSyncProducer(urlList, cfg)
 	if err != nil {
-		log.Fatalf("failed to create kafka producer: %s", err)
+		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
 	}
 
-	return conn
+	return conn, nil
 }
 
 // NewAsyncProducer get new kafka async producer
-func NewAsyncProducer(urlList []string) sarama.AsyncProducer {
-	cfg := sarama.NewConfig()
-
-	cfg.Producer.Partitioner = sarama.NewRandomPartitioner
-	cfg.Producer.RequiredAcks = sarama.WaitForAll
-	cfg.Producer.Return.Successes = true
-	cfg.Producer.Retry.Max = 5
+func NewAsyncProducer(urlList []string, cluster config.KafkaCluster) (sarama.AsyncProducer, error) {
+	cfg, err := producerConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
 
 	conn, err := sarama.NewAsyncProducer(urlList, cfg)
 	if err != nil {
-		log.Fatalf("failed to create kafka producer: %s", err)
+		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+	}
+
+	return conn, nil
+}
+
+// RecordHeaders converts headers to kafka record headers sorted by key
+func RecordHeaders(headers map[string]string) []sarama.RecordHeader {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	records := make([]sarama.RecordHeader, len(keys))
+	for i, key := range keys {
+		records[i] = sarama.RecordHeader{Key: []byte(key), Value: []byte(headers[key])}
+	}
+
+	return records
+}
+
+func producerConfig(cluster config.KafkaCluster) (*sarama.Config, error) {
+	cfg, err := clientConfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	producer := cluster.Producer
+
+	switch producer.Partitioner {
+	case "hash":
+		cfg.Producer.Partitioner = sarama.NewHashPartitioner
+	case "random":
+		cfg.Producer.Partitioner = sarama.NewRandomPartitioner
+	case "round_robin":
+		cfg.Producer.Partitioner = sarama.NewRoundRobinPartitioner
+	default:
+		return nil, fmt.Errorf("kafka producer: unknown partitioner %q", producer.Partitioner)
+	}
+
+	switch producer.RequiredAcks {
+	case "all":
+		cfg.Producer.RequiredAcks = sarama.WaitForAll
+	case "local":
+		cfg.Producer.RequiredAcks = sarama.WaitForLocal
+	case "none":
+		cfg.Producer.RequiredAcks = sarama.NoResponse
+	default:
+		return nil, fmt.Errorf("kafka producer: unknown required acks %q", producer.RequiredAcks)
+	}
+
+	if err := cfg.Producer.Compression.UnmarshalText([]byte(producer.Compression)); err != nil {
+		return nil, fmt.Errorf("kafka producer: %w", err)
+	}
+
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.Retry.Max = producer.RetryMax
+	cfg.Producer.Timeout = producer.Timeout
+
+	// idempotent producer writes each message once per partition even when retrying
+	if producer.Idempotent {
+		if cfg.Producer.RequiredAcks != sarama.WaitForAll {
+			return nil, errors.New("kafka producer: idempotent producer requires required acks all")
+		}
+		if producer.RetryMax < 1 {
+			return nil, errors.New("kafka producer: idempotent producer requires retry max at least 1")
+		}
+
+		cfg.Producer.Idempotent = true
+		cfg.Net.MaxOpenRequests = 1
 	}
 
-	return conn
+	return cfg, nil
 }
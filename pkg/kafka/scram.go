@@ -0,0 +1,41 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"github.com/xdg-go/scram"
+)
+
+var (
+	sha256Generator scram.HashGeneratorFcn = sha256.New
+	sha512Generator scram.HashGeneratorFcn = sha512.New
+)
+
+// scramClient sarama scram client over xdg-go/scram
+type scramClient struct {
+	generator scram.HashGeneratorFcn
+	conv      *scram.ClientConversation
+}
+
+// Begin starts scram conversation
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.generator.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+
+	c.conv = client.NewConversation()
+
+	return nil
+}
+
+// Step processes server challenge
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conv.Step(challenge)
+}
+
+// Done conversation is completed
+func (c *scramClient) Done() bool {
+	return c.conv.Done()
+}
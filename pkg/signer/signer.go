@@ -0,0 +1,46 @@
+package signer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrEmptySecret secret is not set
+var ErrEmptySecret = errors.New("empty signing secret")
+
+// Signer signs data with HMAC-SHA256
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner constructor for Signer
+func NewSigner(secret string) (*Signer, error) {
+	if secret == "" {
+		return nil, ErrEmptySecret
+	}
+
+	return &Signer{secret: []byte(secret)}, nil
+}
+
+// Sign returns base64url encoded signature of payload
+func (s *Signer) Sign(payload string) string {
+	return base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// Verify compares signature with signature of payload in constant time
+func (s *Signer) Verify(payload string, signature string) bool {
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(sig, s.mac(payload))
+}
+
+func (s *Signer) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
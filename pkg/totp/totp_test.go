@@ -0,0 +1,169 @@
+package totp_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/pkg/totp"
+)
+
+// rfcSecret base32 of the RFC 6238 Appendix B SHA-1 key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+type Suite struct {
+	suite.Suite
+	totp *totp.TOTP
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) SetupTest() {
+	s.totp = totp.NewTOTP("product-keeper")
+}
+
+func (s *Suite) TestRFC6238Vectors() {
+	// RFC 6238 Appendix B, SHA-1 codes are 8 digits there, 6-digit codes are their last digits
+	testList := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, row := range testList {
+		s.Run(row.code, func() {
+			step, ok := s.totp.Validate(rfcSecret, row.code, time.Unix(row.unix, 0))
+			s.True(ok)
+			s.Equal(row.unix/30, step)
+		})
+	}
+}
+
+func (s *Suite) TestSkew() {
+	// code of step 1 (T = 30..59)
+	const code = "287082"
+
+	testList := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{
+			name: "previous period",
+			unix: 29,
+			ok:   true,
+		},
+		{
+			name: "same period",
+			unix: 30,
+			ok:   true,
+		},
+		{
+			name: "next period",
+			unix: 89,
+			ok:   true,
+		},
+		{
+			name: "two periods later",
+			unix: 90,
+			ok:   false,
+		},
+		{
+			name: "long ago",
+			unix: 1111111109,
+			ok:   false,
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			step, ok := s.totp.Validate(rfcSecret, code, time.Unix(row.unix, 0))
+			s.Equal(row.ok, ok)
+			if ok {
+				// the step of the code, not of now, so the code can be used only once
+				s.Equal(int64(1), step)
+			}
+		})
+	}
+}
+
+func (s *Suite) TestValidateInvalidInput() {
+	now := time.Unix(59, 0)
+
+	testList := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{
+			name:   "lowercase secret",
+			secret: strings.ToLower(rfcSecret),
+			code:   "287082",
+			ok:     true,
+		},
+		{
+			name:   "wrong code",
+			secret: rfcSecret,
+			code:   "287083",
+		},
+		{
+			name:   "8-digit code",
+			secret: rfcSecret,
+			code:   "94287082",
+		},
+		{
+			name:   "empty code",
+			secret: rfcSecret,
+		},
+		{
+			name:   "invalid secret",
+			secret: "not base32!",
+			code:   "287082",
+		},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			_, ok := s.totp.Validate(row.secret, row.code, now)
+			s.Equal(row.ok, ok)
+		})
+	}
+}
+
+func (s *Suite) TestGenerateSecret() {
+	secret, err := s.totp.GenerateSecret()
+	s.Require().NoError(err)
+
+	// 20 bytes without padding
+	s.Len(secret, 32)
+
+	other, err := s.totp.GenerateSecret()
+	s.Require().NoError(err)
+	s.NotEqual(secret, other)
+}
+
+func (s *Suite) TestURI() {
+	u, err := url.Parse(s.totp.URI("test name", rfcSecret))
+	s.Require().NoError(err)
+
+	s.Equal("otpauth", u.Scheme)
+	s.Equal("totp", u.Host)
+	s.Equal("/product-keeper:test name", u.Path)
+	s.Equal(rfcSecret, u.Query().Get("secret"))
+	s.Equal("product-keeper", u.Query().Get("issuer"))
+	s.Equal("SHA1", u.Query().Get("algorithm"))
+	s.Equal("6", u.Query().Get("digits"))
+	s.Equal("30", u.Query().Get("period"))
+}
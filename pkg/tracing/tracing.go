@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"strings"
+)
+
+// MaxRequestIDLength longer request ids from clients are replaced
+const MaxRequestIDLength = 128
+
+// ValidRequestID returns true if request id from the client can be used as is:
+// it is not empty, not longer than MaxRequestIDLength and contains only printable ascii characters
+func ValidRequestID(id string) bool {
+	return id != "" && len(id) <= MaxRequestIDLength && !strings.ContainsFunc(id, notPrintable)
+}
+
+// ParseTraceparent returns trace id from w3c "version-traceid-parentid-flags" value
+func ParseTraceparent(value string) (string, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || strings.Trim(parts[1], "0") == "" {
+		return "", false
+	}
+
+	for _, r := range parts[1] {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return "", false
+		}
+	}
+
+	return parts[1], true
+}
+
+func notPrintable(r rune) bool {
+	return r < 0x20 || r > 0x7e
+}
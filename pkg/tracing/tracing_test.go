@@ -0,0 +1,69 @@
+package tracing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/fallra1n/product-keeper/pkg/tracing"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestValidRequestID() {
+	testList := []struct {
+		name     string
+		id       string
+		expected bool
+	}{
+		{name: "uuid", id: "0b9c8a1e-6f55-4d0e-9d43-2f1f8b5d7c11", expected: true},
+		{name: "max length", id: strings.Repeat("a", tracing.MaxRequestIDLength), expected: true},
+		{name: "empty", id: "", expected: false},
+		{name: "too long", id: strings.Repeat("a", tracing.MaxRequestIDLength+1), expected: false},
+		{name: "new line", id: "test\nid", expected: false},
+		{name: "not ascii", id: "тест", expected: false},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			s.Equal(row.expected, tracing.ValidRequestID(row.id))
+		})
+	}
+}
+
+func (s *Suite) TestParseTraceparent() {
+	testList := []struct {
+		name     string
+		value    string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "valid",
+			value:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expected: "4bf92f3577b34da6a3ce929d0e0e4736",
+			ok:       true,
+		},
+		{name: "empty", value: "", ok: false},
+		{name: "too few parts", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-01", ok: false},
+		{name: "short trace id", value: "00-4bf92f3577b34da6-00f067aa0ba902b7-01", ok: false},
+		{name: "zero trace id", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ok: false},
+		{name: "upper case", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ok: false},
+		{name: "not hex", value: "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", ok: false},
+	}
+
+	for _, row := range testList {
+		s.Run(row.name, func() {
+			traceID, ok := tracing.ParseTraceparent(row.value)
+			s.Equal(row.ok, ok)
+			s.Equal(row.expected, traceID)
+		})
+	}
+}
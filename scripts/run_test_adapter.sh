@@ -24,7 +24,7 @@ function remove_db() {
 
 function apply_migrations() {
   echo "Applying migrations..."
-  ./scripts/apply_migration.sh 1
+  ./scripts/apply_migration.sh 17
 }
 
 cd deployment